@@ -1,57 +1,314 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 	"time"
 
+	"github.com/gensan0223/snulog/internal/draft"
+	"github.com/gensan0223/snulog/internal/outbox"
 	"github.com/gensan0223/snulog/internal/util"
 	pb "github.com/gensan0223/snulog/proto"
 	"github.com/spf13/cobra"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"golang.org/x/term"
+	"google.golang.org/grpc/status"
 )
 
 // addCmd represents the add command
 var addCmd = &cobra.Command{
-	Use:   "add",
+	Use:   "add [<user> <status> <feeling>]",
 	Short: "Add a new progress and emotion log",
+	Long: `ログを追加する。引数を省略したときは --status と --feeling で指定し、足りない値は端末で尋ねる。
+--template を付けるとチームのテンプレート（snulog template list）の項目を順に尋ね、
+-e/--editor を付けると $VISUAL か $EDITOR でステータスと front matter（気分・公開範囲・ブロッカー）を書く。`,
+	Example: `  snulog add --status "API のレビュー" --feeling "😊"
+  snulog add --template standup --field yesterday="ログイン画面" --field today="API" --feeling "🙂"
+  snulog add -e --template standup`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 && len(args) != 3 {
+			return errors.New("引数は <user> <status> <feeling> の 3 つを指定するか、すべて省略してください")
+		}
+		return nil
+	},
 	Run: func(cmd *cobra.Command, args []string) {
-		if len(args) < 3 {
-			fmt.Println("引数が足りません")
+		config, err := currentConfig()
+		if err != nil {
+			fmt.Println("⛔設定の読み込みに失敗: ", err)
+			return
 		}
+		blocker, _ := cmd.Flags().GetString("blocker")
+		visibilityFlag, _ := cmd.Flags().GetString("visibility")
 
-		entry := &pb.LogEntry{
-			UserName:  args[0],
-			Status:    args[1],
-			Feeling:   args[2],
-			Timestamp: time.Now().Format(time.RFC3339),
+		if len(args) == 3 {
+			status, err := draft.Draft{Status: args[1], Blocker: blocker}.Text(nil)
+			if err != nil {
+				fmt.Println("⛔ログ追加失敗: ", err)
+				return
+			}
+			addEntry(config, args[0], status, args[2], visibilityFlag)
+			return
 		}
 
-		conn, err := grpc.NewClient("localhost:50051", grpc.WithTransportCredentials(insecure.NewCredentials()))
-		if err != nil {
-			fmt.Println("⛔gRPC接続失敗: ", err)
+		user, _ := cmd.Flags().GetString("user")
+		if user == "" {
+			user = config.User
+		}
+		d := draft.Draft{Visibility: visibilityFlag, Blocker: blocker}
+		d.Status, _ = cmd.Flags().GetString("status")
+		d.Feeling, _ = cmd.Flags().GetString("feeling")
+		if d.Answers, err = fieldAnswers(cmd); err != nil {
+			fmt.Println("⛔", err)
 			return
 		}
-		defer util.CloseWithLog(conn)
 
-		conn.Connect()
+		var fields []*pb.TemplateField
+		if name, _ := cmd.Flags().GetString("template"); name != "" {
+			if fields, err = fetchTemplate(config.Team, name); err != nil {
+				fmt.Println("⛔テンプレートの取得に失敗: ", err)
+				return
+			}
+		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-		defer cancel()
+		reader := bufio.NewReader(cmd.InOrStdin())
+		if useEditor, _ := cmd.Flags().GetBool("editor"); useEditor {
+			content, err := draft.Edit(draft.Editor(), draft.Render(d, fields))
+			if err == nil {
+				d, err = draft.Parse(content, fields)
+			}
+			if err != nil {
+				fmt.Println("⛔編集したログの読み込みに失敗: ", err)
+				return
+			}
+			if d.Empty() {
+				fmt.Println("中止しました")
+				return
+			}
+			if d.Visibility == "" {
+				d.Visibility = visibilityFlag
+			}
+		} else if needsInput(user, d, fields) {
+			if !isTerminal(cmd.InOrStdin()) {
+				fmt.Println("⛔--status と --feeling（--template なら --field）を指定するか、端末で実行してください")
+				return
+			}
+			if err := promptDraft(reader, &user, &d, fields); err != nil {
+				fmt.Println("⛔入力の読み込みに失敗: ", err)
+				return
+			}
+		}
+		if user == "" {
+			fmt.Println("⛔user が設定されていません。--user を付けるか snulog config set user <ユーザー名> で設定してください")
+			return
+		}
 
-		client := pb.NewLogServiceClient(conn)
-		res, err := client.AddLogs(ctx, entry)
+		status, err := d.Text(fields)
 		if err != nil {
 			fmt.Println("⛔ログ追加失敗: ", err)
 			return
 		}
-
-		fmt.Printf("✅ログ追加 \nuser: %s\nstatus: %s\nfeeling: %s\ntimestamp: %s\n", entry.UserName, entry.Status, entry.Feeling, entry.Timestamp)
-		fmt.Printf("✅サーバ応答: %s\n", res.Message)
+		addEntry(config, user, status, d.Feeling, d.Visibility)
 	},
 }
 
+// addEntry は公開範囲を確かめてからログを追加する
+func addEntry(config cliConfig, user, status, feeling, visibilityName string) {
+	visibility, ok := visibilityValues[visibilityName]
+	if !ok {
+		fmt.Println("⛔公開範囲は team, managers, private のいずれかを指定してください")
+		return
+	}
+	entry, err := newEntry(config, user, status, feeling, visibility)
+	if err != nil {
+		fmt.Println("⛔ログ追加失敗: ", err)
+		return
+	}
+	postEntry(config, entry)
+}
+
+// fieldAnswers は --field key=value をテンプレートの項目ごとの答えにする
+func fieldAnswers(cmd *cobra.Command) (map[string]string, error) {
+	values, _ := cmd.Flags().GetStringArray("field")
+	answers := map[string]string{}
+	for _, value := range values {
+		key, answer, ok := strings.Cut(value, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("--field は key=value の形で指定してください: %q", value)
+		}
+		answers[key] = answer
+	}
+	return answers, nil
+}
+
+// fetchTemplate はチームのテンプレート name の項目を取得する
+func fetchTemplate(team, name string) ([]*pb.TemplateField, error) {
+	var fields []*pb.TemplateField
+	err := callServer(func(ctx context.Context, client pb.LogServiceClient) error {
+		res, err := client.ListTemplates(ctx, &pb.ListTemplatesRequest{Team: team})
+		if err != nil {
+			return err
+		}
+		for _, t := range res.Templates {
+			if t.Name == name {
+				fields = t.Fields
+				return nil
+			}
+		}
+		return fmt.Errorf("チーム %s にテンプレート %s はありません", team, name)
+	})
+	if err != nil {
+		return nil, errors.New(status.Convert(err).Message())
+	}
+	return fields, nil
+}
+
+// needsInput は尋ねる値が残っているか
+func needsInput(user string, d draft.Draft, fields []*pb.TemplateField) bool {
+	if user == "" || d.Feeling == "" {
+		return true
+	}
+	if len(fields) == 0 {
+		return d.Status == ""
+	}
+	for _, f := range d.Missing(fields) {
+		if !f.Optional {
+			return true
+		}
+	}
+	return false
+}
+
+// promptDraft は足りない値を順に尋ねる。テンプレートの任意の項目とブロッカーは Enter で省ける
+func promptDraft(reader *bufio.Reader, user *string, d *draft.Draft, fields []*pb.TemplateField) error {
+	var err error
+	if *user == "" {
+		if *user, err = prompt(reader, "ユーザー名: "); err != nil {
+			return err
+		}
+	}
+	if len(fields) == 0 {
+		if d.Status == "" {
+			if d.Status, err = prompt(reader, "ステータス: "); err != nil {
+				return err
+			}
+			if d.Blocker == "" {
+				if d.Blocker, err = prompt(reader, draft.BlockerLabel+"（なければ Enter）: "); err != nil {
+					return err
+				}
+			}
+		}
+	} else {
+		blockerKey := draft.BlockerField(fields)
+		for _, f := range d.Missing(fields) {
+			if f.Key == blockerKey && d.Blocker != "" {
+				continue
+			}
+			label := f.Label + ": "
+			if f.Optional {
+				label = f.Label + "（なければ Enter）: "
+			}
+			if d.Answers[f.Key], err = prompt(reader, label); err != nil {
+				return err
+			}
+		}
+	}
+	if d.Feeling == "" {
+		d.Feeling, err = prompt(reader, "気分: ")
+	}
+	return err
+}
+
+// isTerminal は r が端末か
+func isTerminal(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// newEntry は今の時刻と再送に備えた idempotency_key を付けたログを作る
+func newEntry(config cliConfig, user, status, feeling string, visibility pb.Visibility) (*pb.LogEntry, error) {
+	key, err := outbox.NewKey()
+	if err != nil {
+		return nil, err
+	}
+	return &pb.LogEntry{
+		UserName:       user,
+		Status:         status,
+		Feeling:        feeling,
+		Timestamp:      time.Now().In(config.TimeZone).Format(time.RFC3339),
+		Visibility:     visibility,
+		IdempotencyKey: key,
+	}, nil
+}
+
+// postEntry はログを追加して結果を表示する。サーバーに届かなければ outbox に溜める。
+// 追加したか outbox に溜めたら true を返す
+func postEntry(config cliConfig, entry *pb.LogEntry) bool {
+	conn, err := dialServer(config)
+	if err != nil {
+		fmt.Println("⛔gRPC接続失敗: ", err)
+		return false
+	}
+	defer util.CloseWithLog(conn)
+
+	conn.Connect()
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+
+	client := pb.NewLogServiceClient(conn)
+	res, err := client.AddLogs(ctx, entry)
+	if outbox.Offline(err) {
+		return queueEntry(entry, err)
+	}
+	if err != nil {
+		fmt.Println("⛔ログ追加失敗: ", err)
+		return false
+	}
+
+	fmt.Printf("✅ログ追加 \nuser: %s\nstatus: %s\nfeeling: %s\ntimestamp: %s\n", entry.UserName, entry.Status, entry.Feeling, entry.Timestamp)
+	fmt.Printf("✅サーバ応答: %s\n", res.Message)
+	return true
+}
+
+// visibilityValues は --visibility に指定できる値
+var visibilityValues = map[string]pb.Visibility{
+	"team":     pb.Visibility_VISIBILITY_TEAM,
+	"managers": pb.Visibility_VISIBILITY_MANAGERS,
+	"private":  pb.Visibility_VISIBILITY_PRIVATE,
+}
+
+// queueEntry はサーバーに届かなかったログを outbox に溜め、溜められたら true を返す。
+// 届いていた場合も idempotency_key で二重には追加されない
+func queueEntry(entry *pb.LogEntry, cause error) bool {
+	box, err := openOutbox()
+	if err == nil {
+		err = box.Add(entry)
+	}
+	if err != nil {
+		fmt.Println("⛔ログ追加失敗: ", cause)
+		fmt.Println("⛔outbox への保存にも失敗: ", err)
+		return false
+	}
+	fmt.Println("📥サーバーに接続できないため outbox に保存しました。次にサーバーにつながったとき、または snulog sync で送信します: ", cause)
+	return true
+}
+
 func init() {
 	rootCmd.AddCommand(addCmd)
+	addCmd.Flags().String("visibility", "team", "公開範囲 (team, managers, private)。team 以外は snulog login が必要")
+	addCmd.Flags().String("user", "", "ユーザー名（省略すると設定の user）")
+	addCmd.Flags().String("status", "", "ステータス")
+	addCmd.Flags().String("feeling", "", "気分")
+	addCmd.Flags().String("blocker", "", "ブロッカー。ステータスに添えるか、テンプレートのブロッカーの項目に入れる")
+	addCmd.Flags().String("template", "", "チームのテンプレートの項目を尋ねる")
+	addCmd.Flags().StringArray("field", nil, "テンプレートの項目の答え（key=value、繰り返し指定できる）")
+	addCmd.Flags().BoolP("editor", "e", false, "$VISUAL か $EDITOR で書く")
+	addCmd.ValidArgsFunction = completeFirstArg(completeFromServer(pb.CompletionKind_COMPLETION_KIND_USER))
+	cobra.CheckErr(addCmd.RegisterFlagCompletionFunc("user", completeFromServer(pb.CompletionKind_COMPLETION_KIND_USER)))
+	cobra.CheckErr(addCmd.RegisterFlagCompletionFunc("template", completeTemplates))
+	cobra.CheckErr(addCmd.RegisterFlagCompletionFunc("visibility", completeVisibility))
 }
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/gensan0223/snulog/internal/draft"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddArgs(t *testing.T) {
+	assert.NoError(t, addCmd.Args(addCmd, nil))
+	assert.NoError(t, addCmd.Args(addCmd, []string{"alice", "fix bug", "😊"}))
+	assert.Error(t, addCmd.Args(addCmd, []string{"alice"}), "引数が足りなくても panic しない")
+}
+
+func TestNeedsInput(t *testing.T) {
+	assert.True(t, needsInput("", draft.Draft{Status: "a", Feeling: "b"}, nil))
+	assert.True(t, needsInput("alice", draft.Draft{Status: "a"}, nil))
+	assert.False(t, needsInput("alice", draft.Draft{Status: "a", Feeling: "b"}, nil))
+
+	d := draft.Draft{Feeling: "b", Answers: map[string]string{"yesterday": "a"}}
+	assert.True(t, needsInput("alice", d, standupFields))
+	d.Answers["today"] = "c"
+	assert.False(t, needsInput("alice", d, standupFields), "任意の項目は尋ねなくてよい")
+}
+
+func TestPromptDraft(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("alice\nAPI のレビュー\n\n😊\n"))
+	user := ""
+	d := draft.Draft{}
+	require.NoError(t, promptDraft(reader, &user, &d, nil))
+	assert.Equal(t, "alice", user)
+	assert.Equal(t, draft.Draft{Status: "API のレビュー", Feeling: "😊"}, d)
+
+	reader = bufio.NewReader(strings.NewReader("今日の作業\n\nまあまあ\n"))
+	user = "alice"
+	d = draft.Draft{Answers: map[string]string{"yesterday": "昨日の作業"}}
+	require.NoError(t, promptDraft(reader, &user, &d, standupFields))
+	status, err := d.Text(standupFields)
+	require.NoError(t, err)
+	assert.Equal(t, "昨日やったこと: 昨日の作業\n今日やること: 今日の作業", status)
+	assert.Equal(t, "まあまあ", d.Feeling)
+
+	reader = bufio.NewReader(strings.NewReader("今日の作業\n🙂\n"))
+	d = draft.Draft{Blocker: "権限待ち", Answers: map[string]string{"yesterday": "昨日の作業"}}
+	require.NoError(t, promptDraft(reader, &user, &d, standupFields))
+	status, err = d.Text(standupFields)
+	require.NoError(t, err)
+	assert.Equal(t, "昨日やったこと: 昨日の作業\n今日やること: 今日の作業\nブロッカー: 権限待ち", status, "--blocker があればブロッカーは尋ねない")
+}
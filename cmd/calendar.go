@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/heatmap"
+	"github.com/gensan0223/snulog/internal/output"
+	pb "github.com/gensan0223/snulog/proto"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/status"
+)
+
+// defaultCalendarWeeks は --month を省略したときに表示する週の数
+const defaultCalendarWeeks = 26
+
+var calendarCmd = &cobra.Command{
+	Use:   "calendar",
+	Short: "投稿した日や気分を GitHub の草のようなカレンダーで表示する",
+	Long: `ログを投稿した日を曜日×週の格子にして、投稿数の多い日ほど濃く表示する。--mood を付けると気分を赤（悪い）から緑（良い）で表示する。
+気分は 😄 や「疲れた」のようなよく使う絵文字と言葉から読み取り、読み取れない日は別の色にする。
+端末でないときや --plain、--no-color、NO_COLOR 環境変数があるときは色を使わず文字の濃淡で表示する。
+-o を指定すると格子の代わりに日ごとの集計をその形式で出力する。`,
+	Example: `  snulog calendar
+  snulog calendar --user alice --month 2025-01 --mood
+  snulog calendar --weeks 52 -o csv`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := currentConfig()
+		if err == nil {
+			err = checkOutput(cmd, config, calendarColumns)
+		}
+		if err != nil {
+			fmt.Println("⛔", err)
+			return
+		}
+		month, _ := cmd.Flags().GetString("month")
+		weeks, _ := cmd.Flags().GetInt("weeks")
+		from, to, err := calendarRange(month, weeks, time.Now().In(config.TimeZone))
+		if err != nil {
+			fmt.Println("⛔", err)
+			return
+		}
+		user, _ := cmd.Flags().GetString("user")
+		if !cmd.Flags().Changed("user") {
+			user = config.User
+		}
+
+		var days []*pb.CalendarDay
+		err = callServer(func(ctx context.Context, client pb.LogServiceClient) error {
+			res, err := client.GetCalendar(ctx, &pb.CalendarRequest{
+				UserName: user,
+				Since:    from.Format(time.RFC3339),
+				Until:    to.AddDate(0, 0, 1).Format(time.RFC3339),
+			})
+			if err != nil {
+				return err
+			}
+			days = res.Days
+			return nil
+		})
+		if err != nil {
+			fmt.Println("⛔カレンダーの取得に失敗: ", status.Convert(err).Message())
+			return
+		}
+		if cmd.Flags().Changed("output") {
+			if err := printRows(cmd, config, calendarColumns, days); err != nil {
+				fmt.Println("⛔", err)
+			}
+			return
+		}
+
+		metric := heatmap.Count
+		if moodFlag, _ := cmd.Flags().GetBool("mood"); moodFlag {
+			metric = heatmap.Mood
+		}
+		opts, _ := outputOptions(cmd, config)
+		plain, _ := cmd.Flags().GetBool("plain")
+		out := cmd.OutOrStdout()
+		fmt.Fprintln(out, calendarSummary(user, from, to, days))
+		err = heatmap.Render(out, calendarDays(days), heatmap.Options{From: from, To: to, Metric: metric, Color: opts.Color && !plain})
+		if err != nil {
+			fmt.Println("⛔", err)
+		}
+	},
+}
+
+var calendarColumns = []output.Column[*pb.CalendarDay]{
+	{Name: "date", Header: "DATE", Emoji: "📅", Value: func(d *pb.CalendarDay) any { return d.Date }},
+	{Name: "count", Header: "COUNT", Value: func(d *pb.CalendarDay) any { return d.Count }},
+	{Name: "mood", Header: "MOOD", Value: func(d *pb.CalendarDay) any {
+		if d.Scored == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%+.1f", d.Mood)
+	}},
+	{Name: "scored", Header: "SCORED", Value: func(d *pb.CalendarDay) any { return d.Scored }},
+	{Name: "top_feeling", Header: "TOP FEELING", Emoji: "😀", Value: func(d *pb.CalendarDay) any { return d.TopFeeling }},
+}
+
+// calendarRange は表示する最初と最後の日を返す。month（YYYY-MM）が空なら今日までの weeks 週を日曜始まりで表示する
+func calendarRange(month string, weeks int, now time.Time) (time.Time, time.Time, error) {
+	if month != "" {
+		from, err := time.ParseInLocation("2006-01", month, now.Location())
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("--month は YYYY-MM で指定してください: %q", month)
+		}
+		return from, from.AddDate(0, 1, -1), nil
+	}
+	if weeks <= 0 {
+		return time.Time{}, time.Time{}, fmt.Errorf("--weeks は 1 以上を指定してください: %d", weeks)
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return today.AddDate(0, 0, -int(today.Weekday())-(weeks-1)*7), today, nil
+}
+
+// calendarDays は日付をキーにした集計にする
+func calendarDays(days []*pb.CalendarDay) map[string]heatmap.Day {
+	result := make(map[string]heatmap.Day, len(days))
+	for _, d := range days {
+		result[d.Date] = heatmap.Day{Count: d.Count, Mood: d.Mood, Scored: d.Scored}
+	}
+	return result
+}
+
+// calendarSummary は期間と投稿した日数、件数、気分の平均を 1 行にする
+func calendarSummary(user string, from, to time.Time, days []*pb.CalendarDay) string {
+	var count, scored int64
+	var total float64
+	for _, d := range days {
+		count += d.Count
+		scored += d.Scored
+		total += d.Mood * float64(d.Scored)
+	}
+	who := user
+	if who == "" {
+		who = "全員"
+	}
+	summary := fmt.Sprintf("📅 %s 〜 %s（%s）: %d 日に %d 件", from.Format(time.DateOnly), to.Format(time.DateOnly), who, len(days), count)
+	if scored > 0 {
+		summary += fmt.Sprintf("、気分の平均 %+.1f", total/float64(scored))
+	}
+	return summary
+}
+
+func init() {
+	rootCmd.AddCommand(calendarCmd)
+	addOutputFlags(calendarCmd)
+	calendarCmd.Flags().StringP("user", "u", "", "投稿者（省略すると設定の user、空文字なら読めるすべてのログ）")
+	calendarCmd.Flags().String("month", "", "表示する月（YYYY-MM）。省略すると今日までの --weeks 週")
+	calendarCmd.Flags().Int("weeks", defaultCalendarWeeks, "--month を省略したときに表示する週の数")
+	calendarCmd.Flags().Bool("mood", false, "投稿数の代わりに気分で色を付ける")
+	calendarCmd.Flags().Bool("plain", false, "色を使わず文字の濃淡で表示する")
+	cobra.CheckErr(calendarCmd.RegisterFlagCompletionFunc("user", completeFromServer(pb.CompletionKind_COMPLETION_KIND_USER)))
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/gensan0223/snulog/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendarRange(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	// 2025-01-15 は水曜日
+	now := time.Date(2025, 1, 15, 23, 30, 0, 0, tokyo)
+
+	from, to, err := calendarRange("", 2, now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 1, 5, 0, 0, 0, 0, tokyo), from, "先週の日曜日から")
+	assert.Equal(t, time.Date(2025, 1, 15, 0, 0, 0, 0, tokyo), to)
+
+	from, to, err = calendarRange("2024-02", 2, now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, tokyo), from)
+	assert.Equal(t, time.Date(2024, 2, 29, 0, 0, 0, 0, tokyo), to)
+
+	_, _, err = calendarRange("2024/02", 2, now)
+	assert.Error(t, err)
+	_, _, err = calendarRange("", 0, now)
+	assert.Error(t, err)
+}
+
+func TestCalendarSummary(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	days := []*pb.CalendarDay{
+		{Date: "2025-01-02", Count: 3, Mood: 2, Scored: 1},
+		{Date: "2025-01-03", Count: 1, Mood: -1, Scored: 2},
+	}
+	assert.Equal(t, "📅 2025-01-01 〜 2025-01-31（alice）: 2 日に 4 件、気分の平均 +0.0", calendarSummary("alice", from, to, days))
+	assert.Equal(t, "📅 2025-01-01 〜 2025-01-31（全員）: 0 日に 0 件", calendarSummary("", from, to, nil))
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/gensan0223/snulog/internal/tlsconfig"
+	"github.com/spf13/cobra"
+)
+
+var certsCmd = &cobra.Command{
+	Use:   "certs",
+	Short: "TLS 証明書を管理する",
+}
+
+var certsDevCmd = &cobra.Command{
+	Use:   "dev",
+	Short: "開発用の CA とサーバー・クライアント証明書を生成する",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, _ := cmd.Flags().GetString("dir")
+		hosts, _ := cmd.Flags().GetStringSlice("hosts")
+		clientName, _ := cmd.Flags().GetString("client-name")
+
+		files, err := tlsconfig.GenerateDevCertificates(dir, hosts, clientName)
+		if err != nil {
+			return fmt.Errorf("証明書の生成に失敗しました: %w", err)
+		}
+
+		fmt.Printf("✅ 開発用証明書を %s に生成しました\n\n", dir)
+		fmt.Println("サーバー:")
+		fmt.Printf("  SNULOG_TLS_CERT=%s\n", files.ServerCert)
+		fmt.Printf("  SNULOG_TLS_KEY=%s\n", files.ServerKey)
+		fmt.Printf("  SNULOG_TLS_CLIENT_CA=%s  # mTLS を使う場合\n\n", files.CACert)
+		fmt.Println("クライアント:")
+		fmt.Printf("  snulog --tls-ca %s --tls-cert %s --tls-key %s fetch\n", files.CACert, files.ClientCert, files.ClientKey)
+		fmt.Println("\n⚠️ この CA の秘密鍵は開発専用です。本番環境では使わないでください")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(certsCmd)
+	certsCmd.AddCommand(certsDevCmd)
+	certsDevCmd.Flags().String("dir", "certs", "Directory to write the certificates to")
+	certsDevCmd.Flags().StringSlice("hosts", []string{"localhost", "127.0.0.1"}, "Host names and IPs for the server certificate")
+	certsDevCmd.Flags().String("client-name", "snulog-client", "Common name of the client certificate")
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/gensan0223/snulog/internal/tlsconfig"
+	"github.com/gensan0223/snulog/internal/util"
+	pb "github.com/gensan0223/snulog/proto"
+	"google.golang.org/grpc"
+)
+
+// dialServer は設定の接続先と TLS 設定、snulog login で保存したトークンを反映して gRPC サーバーへのクライアント接続を作る。
+// RPC が成功したら outbox に溜まったログも送る
+func dialServer(config cliConfig) (*grpc.ClientConn, error) {
+	box, err := openOutbox()
+	if err != nil {
+		return nil, err
+	}
+	token, err := loadToken()
+	if err != nil {
+		return nil, err
+	}
+	return dial(config.Server, config.TLS, token, grpc.WithChainUnaryInterceptor(flushOutbox(box, config.Timeout)))
+}
+
+// dialNamedServer は servers.<名前> のサーバーに接続する。outbox はプロファイルのサーバーのものなので送らない
+func dialNamedServer(s serverConfig) (*grpc.ClientConn, error) {
+	token := ""
+	if s.Profile != "" {
+		var err error
+		if token, err = loadProfileToken(s.Profile); err != nil {
+			return nil, err
+		}
+	}
+	return dial(s.Server, s.TLS, token)
+}
+
+// dial は token が空でなければ各 RPC に付けて接続する
+func dial(server string, tls tlsconfig.ClientConfig, token string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	creds, err := tls.TransportCredentials()
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, grpc.WithTransportCredentials(creds))
+	if token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken(token)))
+	}
+	return grpc.NewClient(server, opts...)
+}
+
+// callServer はサーバーに接続し、設定のタイムアウト付きの context で fn を呼び出す
+func callServer(fn func(ctx context.Context, client pb.LogServiceClient) error) error {
+	config, err := currentConfig()
+	if err != nil {
+		return err
+	}
+	conn, err := dialServer(config)
+	if err != nil {
+		return err
+	}
+	defer util.CloseWithLog(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+	return fn(ctx, pb.NewLogServiceClient(conn))
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/compcache"
+	"github.com/gensan0223/snulog/internal/util"
+	pb "github.com/gensan0223/snulog/proto"
+	"github.com/spf13/cobra"
+)
+
+// completionTimeout は補完でサーバーに問い合わせる時間の上限。設定の timeout より短ければこちらを使う
+const completionTimeout = 2 * time.Second
+
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish|powershell]",
+	Short: "シェルの補完スクリプトを出力する",
+	Long: `シェルの補完スクリプトを標準出力に書き出す。
+ユーザー名・チーム・#タグ・課題の参照・ログ ID はサーバーに問い合わせて補完し、
+候補は 1 分間ローカルに保存する（snulog completion clear-cache で消せる）。
+
+bash:
+  source <(snulog completion bash)
+  # 常に使うには: snulog completion bash > /etc/bash_completion.d/snulog
+
+zsh:
+  snulog completion zsh > "${fpath[1]}/_snulog"
+
+fish:
+  snulog completion fish > ~/.config/fish/completions/snulog.fish
+
+PowerShell:
+  snulog completion powershell | Out-String | Invoke-Expression`,
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: []string{"bash", "zsh", "fish", "powershell"},
+	Run: func(cmd *cobra.Command, args []string) {
+		noDesc, _ := cmd.Flags().GetBool("no-descriptions")
+		out := cmd.OutOrStdout()
+		var err error
+		switch args[0] {
+		case "bash":
+			err = rootCmd.GenBashCompletionV2(out, !noDesc)
+		case "zsh":
+			if noDesc {
+				err = rootCmd.GenZshCompletionNoDesc(out)
+			} else {
+				err = rootCmd.GenZshCompletion(out)
+			}
+		case "fish":
+			err = rootCmd.GenFishCompletion(out, !noDesc)
+		case "powershell":
+			if noDesc {
+				err = rootCmd.GenPowerShellCompletion(out)
+			} else {
+				err = rootCmd.GenPowerShellCompletionWithDesc(out)
+			}
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "⛔補完スクリプトの出力に失敗: ", err)
+		}
+	},
+}
+
+var completionClearCacheCmd = &cobra.Command{
+	Use:   "clear-cache",
+	Short: "保存した補完の候補を消す",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cache, err := completionCache()
+		if err == nil {
+			err = cache.Clear()
+		}
+		if err != nil {
+			fmt.Println("⛔補完の候補の削除に失敗: ", err)
+			return
+		}
+		fmt.Println("✅補完の候補を削除しました")
+	},
+}
+
+// completionCache は補完の候補を保存するキャッシュ。Linux では ~/.cache/snulog/completion
+func completionCache() (*compcache.Cache, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	return compcache.New(filepath.Join(dir, "snulog", "completion"), compcache.DefaultTTL), nil
+}
+
+// fetchCompletions は key の候補をキャッシュから、なければサーバーから取得する。
+// 補完の出力を乱さないよう outbox は送らず、何も表示しない
+func fetchCompletions(key string, fetch func(ctx context.Context, client pb.LogServiceClient) (*pb.CompleteResponse, error)) []*pb.Completion {
+	config, err := currentConfig()
+	if err != nil {
+		return nil
+	}
+	token, err := loadToken()
+	if err != nil {
+		return nil
+	}
+	cache, err := completionCache()
+	if err != nil {
+		return nil
+	}
+	res, err := cache.Fetch(compcache.Key(config.Server, token, key), func() (*pb.CompleteResponse, error) {
+		conn, err := dial(config.Server, config.TLS, token)
+		if err != nil {
+			return nil, err
+		}
+		defer util.CloseWithLog(conn)
+
+		ctx, cancel := context.WithTimeout(context.Background(), min(config.Timeout, completionTimeout))
+		defer cancel()
+		return fetch(ctx, pb.NewLogServiceClient(conn))
+	})
+	if err != nil {
+		return nil
+	}
+	return res.Completions
+}
+
+// completeFromServer はサーバーに問い合わせて kind の候補を補完する。ログ ID はサーバーの並び（新しい順）のまま出す
+func completeFromServer(kinds ...pb.CompletionKind) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		directive := cobra.ShellCompDirectiveNoFileComp
+		var completions []*pb.Completion
+		for _, kind := range kinds {
+			if kind == pb.CompletionKind_COMPLETION_KIND_LOG_ID {
+				directive |= cobra.ShellCompDirectiveKeepOrder
+			}
+			completions = append(completions, fetchCompletions(kind.String(), func(ctx context.Context, client pb.LogServiceClient) (*pb.CompleteResponse, error) {
+				return client.Complete(ctx, &pb.CompleteRequest{Kind: kind})
+			})...)
+		}
+		return matchCompletions(completions, toComplete), directive
+	}
+}
+
+// completeFirstArg は 1 つ目の引数だけを fn で補完する
+func completeFirstArg(fn cobra.CompletionFunc) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return fn(cmd, args, toComplete)
+	}
+}
+
+// completeTemplates は設定のチームのテンプレート名を補完する
+func completeTemplates(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	config, err := currentConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	completions := fetchCompletions("templates:"+config.Team, func(ctx context.Context, client pb.LogServiceClient) (*pb.CompleteResponse, error) {
+		res, err := client.ListTemplates(ctx, &pb.ListTemplatesRequest{Team: config.Team})
+		if err != nil {
+			return nil, err
+		}
+		completions := &pb.CompleteResponse{}
+		for _, t := range res.Templates {
+			completions.Completions = append(completions.Completions, &pb.Completion{Value: t.Name, Description: formatTemplateFields(t.Fields)})
+		}
+		return completions, nil
+	})
+	return matchCompletions(completions, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeVisibility は --visibility の値を補完する
+func completeVisibility(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	names := make([]string, 0, len(visibilityValues))
+	for name := range visibilityValues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// matchCompletions は toComplete で始まる候補を説明付きで返す
+func matchCompletions(completions []*pb.Completion, toComplete string) []cobra.Completion {
+	var matched []cobra.Completion
+	for _, c := range completions {
+		if !strings.HasPrefix(c.Value, toComplete) {
+			continue
+		}
+		if c.Description == "" {
+			matched = append(matched, c.Value)
+		} else {
+			matched = append(matched, cobra.CompletionWithDesc(c.Value, c.Description))
+		}
+	}
+	return matched
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+	completionCmd.AddCommand(completionClearCacheCmd)
+	completionCmd.Flags().Bool("no-descriptions", false, "候補の説明を出さない")
+}
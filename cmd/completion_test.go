@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"testing"
+
+	pb "github.com/gensan0223/snulog/proto"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchCompletions(t *testing.T) {
+	completions := []*pb.Completion{
+		{Value: "alice", Description: "3 件"},
+		{Value: "bob"},
+		{Value: "albert", Description: "1 件"},
+	}
+	assert.Equal(t, []cobra.Completion{"alice\t3 件", "albert\t1 件"}, matchCompletions(completions, "al"))
+	assert.Equal(t, []cobra.Completion{"bob"}, matchCompletions(completions, "b"), "説明がなければ値だけ")
+	assert.Empty(t, matchCompletions(completions, "z"))
+}
+
+func TestCompleteFirstArg(t *testing.T) {
+	fn := completeFirstArg(cobra.FixedCompletions([]string{"alice"}, cobra.ShellCompDirectiveNoFileComp))
+	got, _ := fn(addCmd, nil, "")
+	assert.Equal(t, []cobra.Completion{"alice"}, got)
+	got, directive := fn(addCmd, []string{"alice"}, "")
+	assert.Empty(t, got, "2 つ目以降の引数は補完しない")
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+}
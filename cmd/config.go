@@ -0,0 +1,351 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/output"
+	"github.com/gensan0223/snulog/internal/tlsconfig"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// configDefaults は snulog config で扱えるキーと既定値。
+// 値はフラグ、SNULOG_* 環境変数（tls.ca なら SNULOG_TLS_CA）、設定ファイルのプロファイル、設定ファイルの順に優先する
+var configDefaults = map[string]any{
+	"server":          "localhost:50051",
+	"tls.enabled":     false,
+	"tls.ca":          "",
+	"tls.cert":        "",
+	"tls.key":         "",
+	"tls.server_name": "",
+	"team":            "default",
+	"user":            "",
+	"timezone":        "Local",
+	"output":          "table",
+	"timeout":         "5s",
+	"no_color":        false,
+	"no_emoji":        false,
+	"git.author":      "",
+	"note.remote":     false,
+}
+
+// cliConfig は解決済みの CLI の設定
+type cliConfig struct {
+	Profile  string
+	Server   string
+	TLS      tlsconfig.ClientConfig
+	Team     string
+	User     string
+	TimeZone *time.Location
+	Output   string
+	Timeout  time.Duration
+	NoColor  bool
+	NoEmoji  bool
+	// GitAuthor は snulog git suggest で読むコミットの作者。空ならリポジトリの user.email
+	GitAuthor string
+	// NoteRemote は snulog note のメモを手元ではなくサーバーに private のログとして保存するか
+	NoteRemote bool
+	// Servers は fetch --all で問い合わせる名前付きのサーバー。名前順
+	Servers []serverConfig
+}
+
+// serverConfig は設定ファイルの servers.<名前>。team と timeout を省略すると最上位の値を使う
+type serverConfig struct {
+	Name    string
+	Server  string
+	TLS     tlsconfig.ClientConfig
+	Team    string
+	Timeout time.Duration
+	// Profile はこのサーバーに snulog login したプロファイル。そのトークンを使い、省略すると匿名で接続する
+	Profile string
+}
+
+// setupConfig は既定値、SNULOG_* 環境変数、フラグを v に登録する。flagKeys はフラグ名から設定キーへの対応
+func setupConfig(v *viper.Viper, flags *pflag.FlagSet, flagKeys map[string]string) error {
+	for key, value := range configDefaults {
+		v.SetDefault(key, value)
+	}
+	v.SetEnvPrefix("snulog")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+	// profile は configDefaults に含めないので、環境変数を明示的に結び付ける
+	if err := v.BindEnv("profile"); err != nil {
+		return err
+	}
+	for flag, key := range flagKeys {
+		if err := v.BindPFlag(key, flags.Lookup(flag)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readConfig は設定ファイルを読み、選ばれたプロファイルの値をファイルの最上位の値に重ねる。
+// ファイルがなければ既定値と環境変数・フラグだけを使う
+func readConfig(v *viper.Viper, path string) error {
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	profile := v.GetString("profile")
+	if profile == "" {
+		return nil
+	}
+	settings, ok := v.Get("profiles." + profile).(map[string]any)
+	if !ok {
+		return fmt.Errorf("プロファイル %q が %s にありません", profile, path)
+	}
+	return v.MergeConfigMap(settings)
+}
+
+// resolveConfig は v の値を検証して cliConfig にする
+func resolveConfig(v *viper.Viper) (cliConfig, error) {
+	config := cliConfig{
+		Profile: v.GetString("profile"),
+		Server:  v.GetString("server"),
+		TLS: tlsconfig.ClientConfig{
+			Enabled:    v.GetBool("tls.enabled"),
+			CAFile:     v.GetString("tls.ca"),
+			CertFile:   v.GetString("tls.cert"),
+			KeyFile:    v.GetString("tls.key"),
+			ServerName: v.GetString("tls.server_name"),
+		},
+		Team:    v.GetString("team"),
+		User:    v.GetString("user"),
+		Output:  v.GetString("output"),
+		NoColor: v.GetBool("no_color"),
+		NoEmoji: v.GetBool("no_emoji"),
+
+		GitAuthor:  v.GetString("git.author"),
+		NoteRemote: v.GetBool("note.remote"),
+	}
+	var err error
+	if config.TimeZone, err = time.LoadLocation(v.GetString("timezone")); err != nil {
+		return config, fmt.Errorf("timezone: %w", err)
+	}
+	if config.Timeout, err = time.ParseDuration(v.GetString("timeout")); err != nil || config.Timeout <= 0 {
+		return config, fmt.Errorf("timeout は 5s のような正の時間で指定してください: %q", v.GetString("timeout"))
+	}
+	if _, _, err := output.ParseFormat(config.Output); err != nil {
+		return config, fmt.Errorf("output: %w", err)
+	}
+	if config.Server == "" {
+		return config, errors.New("server が設定されていません")
+	}
+	if config.Servers, err = resolveServers(v, config); err != nil {
+		return config, err
+	}
+	return config, nil
+}
+
+// resolveServers は servers.<名前> を名前順の serverConfig にする
+func resolveServers(v *viper.Viper, config cliConfig) ([]serverConfig, error) {
+	var names []string
+	for name := range v.GetStringMap("servers") {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	servers := make([]serverConfig, 0, len(names))
+	for _, name := range names {
+		sub := v.Sub("servers." + name)
+		if sub == nil {
+			return nil, fmt.Errorf("servers.%s は server などのキーを持つ表で指定してください", name)
+		}
+		s := serverConfig{
+			Name:   name,
+			Server: sub.GetString("server"),
+			TLS: tlsconfig.ClientConfig{
+				Enabled:    sub.GetBool("tls.enabled"),
+				CAFile:     sub.GetString("tls.ca"),
+				CertFile:   sub.GetString("tls.cert"),
+				KeyFile:    sub.GetString("tls.key"),
+				ServerName: sub.GetString("tls.server_name"),
+			},
+			Team:    config.Team,
+			Timeout: config.Timeout,
+			Profile: sub.GetString("profile"),
+		}
+		if s.Server == "" {
+			return nil, fmt.Errorf("servers.%s.server が設定されていません", name)
+		}
+		if sub.IsSet("team") {
+			s.Team = sub.GetString("team")
+		}
+		if sub.IsSet("timeout") {
+			timeout, err := time.ParseDuration(sub.GetString("timeout"))
+			if err != nil || timeout <= 0 {
+				return nil, fmt.Errorf("servers.%s.timeout は 5s のような正の時間で指定してください: %q", name, sub.GetString("timeout"))
+			}
+			s.Timeout = timeout
+		}
+		servers = append(servers, s)
+	}
+	return servers, nil
+}
+
+// validateConfigValue は snulog config set で書き込む前に値を確かめる
+func validateConfigValue(key, value string) error {
+	if key == "profile" {
+		return nil
+	}
+	if _, ok := configDefaults[key]; !ok {
+		return fmt.Errorf("不明なキーです: %s（%s）", key, strings.Join(configKeys(), ", "))
+	}
+	v := viper.New()
+	for k, def := range configDefaults {
+		v.SetDefault(k, def)
+	}
+	v.Set(key, value)
+	_, err := resolveConfig(v)
+	return err
+}
+
+// configKeys は設定できるキーを名前順に返す
+func configKeys() []string {
+	keys := make([]string, 0, len(configDefaults))
+	for key := range configDefaults {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// defaultConfigPath は --config を省略したときの設定ファイル
+func defaultConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".snulog.yaml"), nil
+}
+
+// writeConfigValue は設定ファイルの key を value にする。profile を指定するとそのプロファイルに書き込む
+func writeConfigValue(path, profile, key, value string) error {
+	file := viper.New()
+	file.SetConfigFile(path)
+	file.SetConfigType("yaml")
+	if err := file.ReadInConfig(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if profile != "" && key != "profile" {
+		key = "profiles." + profile + "." + key
+	}
+	file.Set(key, value)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return file.WriteConfigAs(path)
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "CLI の設定を表示・変更する",
+	Long: `設定ファイル（既定は ~/.snulog.yaml）の値を扱います。
+
+キー: server, tls.enabled, tls.ca, tls.cert, tls.key, tls.server_name,
+      team, user, timezone, output, timeout, no_color, no_emoji, git.author, note.remote, profile
+
+servers.<名前> には fetch --all で問い合わせるサーバーを書きます
+（server, team, timeout, profile, tls.*。profile はそのサーバーに login したプロファイル）。
+
+profiles.<名前> に書いた値は --profile <名前>（または SNULOG_PROFILE、設定ファイルの profile）で選んだときに
+最上位の値を上書きします。環境変数 SNULOG_<キー>（tls.ca なら SNULOG_TLS_CA）とフラグはさらに優先します。`,
+}
+
+var configViewCmd = &cobra.Command{
+	Use:          "view",
+	Short:        "解決済みの設定を表示する",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		settings := map[string]any{}
+		for _, key := range configKeys() {
+			settings[key] = viper.Get(key)
+		}
+		if profile := viper.GetString("profile"); profile != "" {
+			settings["profile"] = profile
+		}
+		out, err := yaml.Marshal(nestKeys(settings))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "# %s\n%s", viper.ConfigFileUsed(), out)
+		return nil
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:          "get <key>",
+	Short:        "解決済みの設定値を表示する",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, ok := configDefaults[args[0]]; !ok && args[0] != "profile" {
+			return fmt.Errorf("不明なキーです: %s（%s）", args[0], strings.Join(configKeys(), ", "))
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), viper.GetString(args[0]))
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:          "set <key> <value>",
+	Short:        "設定ファイルに値を書き込む（--profile を付けるとそのプロファイルに書き込む）",
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateConfigValue(args[0], args[1]); err != nil {
+			return err
+		}
+		profile, _ := cmd.Flags().GetString("profile")
+		path := viper.ConfigFileUsed()
+		if err := writeConfigValue(path, profile, args[0], args[1]); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "✅%s に %s = %s を書き込みました\n", path, args[0], args[1])
+		return nil
+	},
+}
+
+// nestKeys は "tls.ca" のようなキーを入れ子の map にする
+func nestKeys(flat map[string]any) map[string]any {
+	nested := map[string]any{}
+	for key, value := range flat {
+		parent, child, ok := strings.Cut(key, ".")
+		if !ok {
+			nested[key] = value
+			continue
+		}
+		section, _ := nested[parent].(map[string]any)
+		if section == nil {
+			section = map[string]any{}
+			nested[parent] = section
+		}
+		section[child] = value
+	}
+	return nested
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configViewCmd, configGetCmd, configSetCmd)
+	configGetCmd.ValidArgsFunction = completeFirstArg(completeConfigKeys)
+	configSetCmd.ValidArgsFunction = completeFirstArg(completeConfigKeys)
+}
+
+// completeConfigKeys は設定のキーを補完する
+func completeConfigKeys(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	return configKeys(), cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfigFile = `
+server: snulog.example.com:50051
+team: backend
+timeout: 10s
+tls:
+  enabled: true
+profiles:
+  home:
+    server: localhost:50051
+    tls:
+      enabled: false
+servers:
+  oss:
+    server: snulog.oss.example.org:443
+    team: maintainers
+    timeout: 2s
+    profile: oss
+    tls:
+      enabled: true
+  work:
+    server: snulog.work.example.com:50051
+`
+
+// newTestViper は root と同じフラグを持つ viper を作り、args をフラグとして解釈する
+func newTestViper(t *testing.T, args ...string) (*viper.Viper, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "snulog.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testConfigFile), 0o600))
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("profile", "", "")
+	flags.String("server", "localhost:50051", "")
+	flags.String("team", "default", "")
+	flags.String("timezone", "Local", "")
+	flags.String("timeout", "5s", "")
+	flags.Bool("no-color", false, "")
+	flags.Bool("no-emoji", false, "")
+	flags.Bool("tls", false, "")
+	flags.String("tls-ca", "", "")
+	flags.String("tls-cert", "", "")
+	flags.String("tls-key", "", "")
+	flags.String("tls-server-name", "", "")
+	require.NoError(t, flags.Parse(args))
+
+	v := viper.New()
+	require.NoError(t, setupConfig(v, flags, configFlags))
+	return v, path
+}
+
+func TestResolveConfig_Precedence(t *testing.T) {
+	v, path := newTestViper(t)
+	require.NoError(t, readConfig(v, path))
+	config, err := resolveConfig(v)
+	require.NoError(t, err)
+	assert.Equal(t, "snulog.example.com:50051", config.Server, "設定ファイルの値はフラグの既定値より優先する")
+	assert.Equal(t, "backend", config.Team)
+	assert.Equal(t, 10*time.Second, config.Timeout)
+	assert.True(t, config.TLS.Enabled)
+	assert.Equal(t, "table", config.Output)
+
+	t.Setenv("SNULOG_TEAM", "frontend")
+	t.Setenv("SNULOG_TLS_SERVER_NAME", "snulog.internal")
+	v, path = newTestViper(t, "--team", "infra")
+	require.NoError(t, readConfig(v, path))
+	config, err = resolveConfig(v)
+	require.NoError(t, err)
+	assert.Equal(t, "infra", config.Team, "フラグは環境変数より優先する")
+	assert.Equal(t, "snulog.internal", config.TLS.ServerName)
+}
+
+func TestResolveConfig_Profile(t *testing.T) {
+	v, path := newTestViper(t, "--profile", "home")
+	require.NoError(t, readConfig(v, path))
+	config, err := resolveConfig(v)
+	require.NoError(t, err)
+	assert.Equal(t, "home", config.Profile)
+	assert.Equal(t, "localhost:50051", config.Server)
+	assert.False(t, config.TLS.Enabled)
+	assert.Equal(t, "backend", config.Team, "プロファイルにない値は最上位の値を使う")
+
+	t.Setenv("SNULOG_PROFILE", "work")
+	v, path = newTestViper(t)
+	assert.Error(t, readConfig(v, path), "存在しないプロファイルはエラー")
+}
+
+func TestResolveConfig_Servers(t *testing.T) {
+	v, path := newTestViper(t)
+	require.NoError(t, readConfig(v, path))
+	config, err := resolveConfig(v)
+	require.NoError(t, err)
+	require.Len(t, config.Servers, 2)
+
+	oss, work := config.Servers[0], config.Servers[1]
+	assert.Equal(t, "oss", oss.Name)
+	assert.Equal(t, "maintainers", oss.Team)
+	assert.Equal(t, 2*time.Second, oss.Timeout)
+	assert.Equal(t, "oss", oss.Profile)
+	assert.True(t, oss.TLS.Enabled)
+	assert.Equal(t, "work", work.Name)
+	assert.Equal(t, "backend", work.Team, "省略したら最上位の team を使う")
+	assert.Equal(t, 10*time.Second, work.Timeout)
+	assert.Empty(t, work.Profile)
+	assert.False(t, work.TLS.Enabled, "最上位の TLS 設定は引き継がない")
+
+	v.Set("servers.broken.team", "x")
+	_, err = resolveConfig(v)
+	assert.Error(t, err, "server のないサーバーはエラー")
+}
+
+func TestResolveConfig_Validates(t *testing.T) {
+	assert.NoError(t, validateConfigValue("timezone", "Asia/Tokyo"))
+	assert.NoError(t, validateConfigValue("profile", "work"))
+	assert.Error(t, validateConfigValue("timezone", "Mars/Olympus"))
+	assert.Error(t, validateConfigValue("timeout", "soon"))
+	assert.Error(t, validateConfigValue("output", "xml"))
+	assert.NoError(t, validateConfigValue("output", "jsonl"))
+	assert.Error(t, validateConfigValue("colour", "blue"))
+}
+
+func TestWriteConfigValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "snulog.yaml")
+	require.NoError(t, writeConfigValue(path, "", "team", "backend"))
+	require.NoError(t, writeConfigValue(path, "work", "server", "snulog.example.com:443"))
+
+	v, _ := newTestViper(t, "--profile", "work")
+	require.NoError(t, readConfig(v, path))
+	config, err := resolveConfig(v)
+	require.NoError(t, err)
+	assert.Equal(t, "backend", config.Team)
+	assert.Equal(t, "snulog.example.com:443", config.Server)
+}
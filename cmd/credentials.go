@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// tokenPath は snulog login で保存したトークンの置き場所。接続先が違うのでプロファイルごとに分ける
+func tokenPath() (string, error) {
+	return profileTokenPath(viper.GetString("profile"))
+}
+
+// profileTokenPath は profile で login したトークンの置き場所。空ならプロファイルなし
+func profileTokenPath(profile string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	if profile != "" {
+		return filepath.Join(dir, "snulog", "tokens", filepath.Base(profile)), nil
+	}
+	return filepath.Join(dir, "snulog", "token"), nil
+}
+
+func saveToken(token string) error {
+	path, err := tokenPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(token+"\n"), 0o600)
+}
+
+// loadToken は保存済みのトークンを返す。未ログインなら空文字列
+func loadToken() (string, error) {
+	return loadProfileToken(viper.GetString("profile"))
+}
+
+func loadProfileToken(profile string) (string, error) {
+	path, err := profileTokenPath(profile)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func deleteToken() error {
+	path, err := tokenPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// bearerToken は保存済みのトークンを各 RPC の authorization メタデータに付ける
+type bearerToken string
+
+func (t bearerToken) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+// 平文接続の開発環境でも使えるようにする。本番では --tls を併用する
+func (t bearerToken) RequireTransportSecurity() bool {
+	return false
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	pb "github.com/gensan0223/snulog/proto"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/status"
+)
+
+var deleteCmd = &cobra.Command{
+	Use:               "delete <id>",
+	Short:             "ログを削除する（本人か管理者のみ）",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeFromServer(pb.CompletionKind_COMPLETION_KIND_LOG_ID),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			fmt.Println("⛔ログ ID は数字で指定してください: ", args[0])
+			return
+		}
+		err = callServer(func(ctx context.Context, client pb.LogServiceClient) error {
+			_, err := client.DeleteLog(ctx, &pb.DeleteLogRequest{Id: id})
+			return err
+		})
+		if err != nil {
+			fmt.Println("⛔ログの削除に失敗: ", status.Convert(err).Message())
+			return
+		}
+		fmt.Printf("✅ログ %d を削除しました\n", id)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(deleteCmd)
+}
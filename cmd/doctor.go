@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gensan0223/snulog/internal/doctor"
+	"github.com/gensan0223/snulog/internal/output"
+	"github.com/gensan0223/snulog/internal/version"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "サーバーへの接続を診断する",
+	Long: `接続先の名前解決、TCP の接続、TLS のハンドシェイク、gRPC のヘルスチェック、Ping RPC、
+保存したトークン、CLI とサーバーのバージョンの互換性、時計のずれを順に確かめる。
+ログの追加などサーバーに何かを書き込む RPC は呼ばない。失敗したチェックがあれば終了コード 1 で終わる。`,
+	Example: `  snulog doctor
+  snulog doctor -o json`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := currentConfig()
+		if err != nil {
+			return err
+		}
+		if err := checkOutput(cmd, config, doctorColumns); err != nil {
+			return err
+		}
+		tlsConfig, err := config.TLS.TLSConfig()
+		if err != nil {
+			return err
+		}
+		token, err := loadToken()
+		if err != nil {
+			return err
+		}
+
+		results := doctor.Run(context.Background(), doctor.Options{
+			Server:        config.Server,
+			TLS:           tlsConfig,
+			Token:         token,
+			Timeout:       config.Timeout,
+			ClientVersion: version.String(),
+			ClientAPI:     version.API,
+		})
+		if err := printRows(cmd, config, doctorColumns, results); err != nil {
+			return err
+		}
+		if !doctor.Healthy(results) {
+			return errors.New("失敗したチェックがあります")
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "✅%s に接続できます\n", config.Server)
+		return nil
+	},
+}
+
+var doctorColumns = []output.Column[doctor.Result]{
+	{Name: "check", Header: "CHECK", Emoji: "🩺", Value: func(r doctor.Result) any { return r.Check }},
+	{Name: "status", Header: "STATUS", Value: func(r doctor.Result) any { return string(r.Status) }},
+	{Name: "detail", Header: "DETAIL", Value: func(r doctor.Result) any { return r.Detail }},
+	{Name: "duration_ms", Header: "TIME (ms)", Value: func(r doctor.Result) any { return r.Duration.Milliseconds() }},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	addOutputFlags(doctorCmd)
+}
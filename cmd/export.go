@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gensan0223/snulog/internal/export"
+	"github.com/gensan0223/snulog/internal/util"
+	pb "github.com/gensan0223/snulog/proto"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/status"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "ログを CSV / JSON / Markdown / iCalendar で書き出す",
+	Long: `読めるログを古い順に書き出す。形式は --format か -o の拡張子（.csv, .json, .md, .ics）で決め、どちらもなければ CSV。
+markdown は日ごとのレポート、ics は 1 件を 1 つのチェックインの予定にしたカレンダー。
+CSV と JSON は snulog import でそのまま取り込める。--since と --until は YYYY-MM-DD か RFC3339 で、日付だけの --until はその日を含める。`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := currentConfig()
+		if err != nil {
+			fmt.Println("⛔設定の読み込みに失敗: ", err)
+			return
+		}
+		path, _ := cmd.Flags().GetString("output")
+		formatFlag, _ := cmd.Flags().GetString("format")
+		format, err := export.ParseFormat(formatFlag, path)
+		if err != nil {
+			fmt.Println("⛔", err)
+			return
+		}
+		sinceFlag, _ := cmd.Flags().GetString("since")
+		untilFlag, _ := cmd.Flags().GetString("until")
+		since, until, err := export.ParseRange(sinceFlag, untilFlag, config.TimeZone)
+		if err != nil {
+			fmt.Println("⛔", err)
+			return
+		}
+		user, _ := cmd.Flags().GetString("user")
+		query, _ := cmd.Flags().GetString("query")
+		req := &pb.ExportLogsRequest{Query: query, UserName: user, Since: since, Until: until}
+
+		out := cmd.OutOrStdout()
+		if path != "" {
+			file, err := os.Create(path)
+			if err != nil {
+				fmt.Println("⛔ファイルを作成できません: ", err)
+				return
+			}
+			defer util.CloseWithLog(file)
+			out = file
+		}
+		count, err := sendExport(config, req, out, format)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "⛔書き出しに失敗: ", status.Convert(err).Message())
+			return
+		}
+		// 標準出力に書き出したときは内容に混ざらないよう標準エラー出力に報告する
+		fmt.Fprintf(os.Stderr, "✅%d 件を書き出しました\n", count)
+	},
+}
+
+// sendExport は ExportLogs のストリームを受け取りながら out に書き出す。件数が多くても終わるまで待つよう、設定のタイムアウトは使わない
+func sendExport(config cliConfig, req *pb.ExportLogsRequest, out io.Writer, format export.Format) (int, error) {
+	conn, err := dialServer(config)
+	if err != nil {
+		return 0, err
+	}
+	defer util.CloseWithLog(conn)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := pb.NewLogServiceClient(conn).ExportLogs(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+	w, err := export.NewWriter(out, format, export.Options{TimeZone: config.TimeZone})
+	if err != nil {
+		return 0, err
+	}
+	return export.Copy(w, stream.Recv)
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().String("format", "", "形式 (csv, json, markdown, ics)。省略すると -o の拡張子で決める")
+	exportCmd.Flags().StringP("output", "o", "", "書き出し先のファイル（省略すると標準出力）")
+	exportCmd.Flags().String("since", "", "この日時以降のログ（YYYY-MM-DD か RFC3339）")
+	exportCmd.Flags().String("until", "", "この日時より前のログ（日付だけならその日を含める）")
+	exportCmd.Flags().StringP("user", "u", "", "投稿者で絞り込む")
+	exportCmd.Flags().StringP("query", "q", "", "status と feeling の部分一致で絞り込む")
+	cobra.CheckErr(exportCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"csv", "json", "markdown", "ics"}, cobra.ShellCompDirectiveNoFileComp)))
+	cobra.CheckErr(exportCmd.RegisterFlagCompletionFunc("user", completeFromServer(pb.CompletionKind_COMPLETION_KIND_USER)))
+	cobra.CheckErr(exportCmd.RegisterFlagCompletionFunc("query", completeFromServer(pb.CompletionKind_COMPLETION_KIND_TAG, pb.CompletionKind_COMPLETION_KIND_TICKET)))
+}
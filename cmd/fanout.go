@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/output"
+	"github.com/gensan0223/snulog/internal/util"
+	pb "github.com/gensan0223/snulog/proto"
+)
+
+// serverLog は fetch --all で取得したログと取得元のサーバーの名前
+type serverLog struct {
+	Server string
+	*pb.LogEntry
+}
+
+// serverFailure は fetch --all で取得できなかったサーバー
+type serverFailure struct {
+	Server serverConfig
+	Err    error
+}
+
+// fanOut は servers に最大 workers 台ずつ並行して fetch を呼び、サーバーごとの timeout で打ち切る。
+// 取得できたログは新しい順に並べ、失敗したサーバーは他の結果を捨てずに failures で返す
+func fanOut(ctx context.Context, servers []serverConfig, workers int, fetch func(context.Context, serverConfig) ([]*pb.LogEntry, error)) ([]serverLog, []serverFailure) {
+	type result struct {
+		logs []*pb.LogEntry
+		err  error
+	}
+	results := make([]result, len(servers))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range max(min(workers, len(servers)), 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				ctx, cancel := context.WithTimeout(ctx, servers[i].Timeout)
+				logs, err := fetch(ctx, servers[i])
+				cancel()
+				results[i] = result{logs: logs, err: err}
+			}
+		}()
+	}
+	for i := range servers {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var logs []serverLog
+	var failures []serverFailure
+	for i, r := range results {
+		if r.err != nil {
+			failures = append(failures, serverFailure{Server: servers[i], Err: r.err})
+			continue
+		}
+		for _, entry := range r.logs {
+			logs = append(logs, serverLog{Server: servers[i].Name, LogEntry: entry})
+		}
+	}
+	// サーバーごとにタイムゾーンが違っても時刻で比べる。同じ時刻ならサーバーの名前順
+	sort.SliceStable(logs, func(i, j int) bool {
+		return parseTimestamp(logs[i].Timestamp).After(parseTimestamp(logs[j].Timestamp))
+	})
+	return logs, failures
+}
+
+// parseTimestamp は RFC3339 の時刻を読む。読めなければゼロ値にして最も古い扱いにする
+func parseTimestamp(timestamp string) time.Time {
+	t, _ := time.Parse(time.RFC3339, timestamp)
+	return t
+}
+
+// fetchFromServer は servers.<名前> のサーバーからそのチームのログを取得する
+func fetchFromServer(ctx context.Context, s serverConfig) ([]*pb.LogEntry, error) {
+	conn, err := dialNamedServer(s)
+	if err != nil {
+		return nil, err
+	}
+	defer util.CloseWithLog(conn)
+	res, err := pb.NewLogServiceClient(conn).FetchLogs(ctx, &pb.FetchRequest{TeamId: s.Team})
+	if err != nil {
+		return nil, err
+	}
+	return res.Logs, nil
+}
+
+// serverLogColumns は fetch --all の列。先頭に取得元のサーバーを置き、残りはログの列と同じ
+func serverLogColumns(config cliConfig) []output.Column[serverLog] {
+	columns := []output.Column[serverLog]{
+		{Name: "server", Header: "SERVER", Emoji: "🌐", Value: func(l serverLog) any { return l.Server }},
+	}
+	for _, c := range logColumns(config) {
+		columns = append(columns, output.Column[serverLog]{
+			Name:   c.Name,
+			Header: c.Header,
+			Emoji:  c.Emoji,
+			Value:  func(l serverLog) any { return c.Value(l.LogEntry) },
+		})
+	}
+	return columns
+}
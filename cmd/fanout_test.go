@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gensan0223/snulog/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFanOut(t *testing.T) {
+	servers := []serverConfig{
+		{Name: "a", Timeout: time.Second},
+		{Name: "b", Timeout: time.Second},
+		{Name: "slow", Timeout: 20 * time.Millisecond},
+		{Name: "down", Timeout: time.Second},
+		{Name: "c", Timeout: time.Second},
+	}
+	logs := map[string][]*proto.LogEntry{
+		"a": {{UserName: "alice", Timestamp: "2025-01-01T09:00:00Z"}},
+		// タイムゾーンが違っても時刻で並べる（UTC では 2025-01-01T01:00:00Z）
+		"b": {{UserName: "bob", Timestamp: "2025-01-01T10:00:00+09:00"}, {UserName: "bea", Timestamp: "2025-01-02T00:00:00Z"}},
+		"c": {{UserName: "carol", Timestamp: "2025-01-01T05:00:00Z"}},
+	}
+
+	var running, peak atomic.Int32
+	result, failures := fanOut(context.Background(), servers, 2, func(ctx context.Context, s serverConfig) ([]*proto.LogEntry, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		switch s.Name {
+		case "slow":
+			<-ctx.Done()
+			return nil, ctx.Err()
+		case "down":
+			return nil, errors.New("connection refused")
+		}
+		time.Sleep(5 * time.Millisecond)
+		return logs[s.Name], nil
+	})
+
+	assert.LessOrEqual(t, peak.Load(), int32(2), "同時に問い合わせるのは workers 台まで")
+	var got []string
+	for _, l := range result {
+		got = append(got, l.Server+"/"+l.UserName)
+	}
+	assert.Equal(t, []string{"b/bea", "a/alice", "c/carol", "b/bob"}, got, "新しい順にまとめる")
+
+	require.Len(t, failures, 2, "失敗しても他のサーバーの結果は残す")
+	assert.Equal(t, "slow", failures[0].Server.Name)
+	assert.ErrorIs(t, failures[0].Err, context.DeadlineExceeded, "サーバーごとのタイムアウトで打ち切る")
+	assert.Equal(t, "down", failures[1].Server.Name)
+}
@@ -8,8 +8,7 @@ import (
 	"github.com/gensan0223/snulog/internal/util"
 	pb "github.com/gensan0223/snulog/proto"
 	"github.com/spf13/cobra"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 )
 
 // fetchCmd represents the fetch command
@@ -17,7 +16,22 @@ var fetchCmd = &cobra.Command{
 	Use:   "fetch",
 	Short: "チームメンバーの進捗と感情ログを取得する",
 	Run: func(cmd *cobra.Command, args []string) {
-		conn, err := grpc.NewClient("localhost:50051", grpc.WithTransportCredentials(insecure.NewCredentials()))
+		config, err := currentConfig()
+		if err != nil {
+			fmt.Println("⛔設定の読み込みに失敗: ", err)
+			return
+		}
+		if all, _ := cmd.Flags().GetBool("all"); all {
+			fetchAll(cmd, config)
+			return
+		}
+		columns := logColumns(config)
+		if err := checkOutput(cmd, config, columns); err != nil {
+			fmt.Println("⛔", err)
+			return
+		}
+
+		conn, err := dialServer(config)
 		if err != nil {
 			fmt.Println("⛔gRPC接続失敗: ", err)
 			return
@@ -26,22 +40,67 @@ var fetchCmd = &cobra.Command{
 
 		client := pb.NewLogServiceClient(conn)
 
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
 		defer cancel()
 
 		resp, err := client.FetchLogs(ctx, &pb.FetchRequest{
-			TeamId: "default",
+			TeamId: config.Team,
 		})
 		if err != nil {
 			fmt.Println("⛔データ取得失敗: ", err)
 			return
 		}
-		for _, log := range resp.Logs {
-			fmt.Printf("👤 %s\t📝 %s\t😀 %s\t🕒 %s\n", log.UserName, log.Status, log.Feeling, log.Timestamp)
+		if err := printRows(cmd, config, columns, resp.Logs); err != nil {
+			fmt.Println("⛔", err)
 		}
 	},
 }
 
+// fetchAll は設定の servers のすべてから並行してログを取得し、まとめて新しい順に表示する。
+// 取得できなかったサーバーは標準エラー出力に報告し、他のサーバーの結果は表示する
+func fetchAll(cmd *cobra.Command, config cliConfig) {
+	if len(config.Servers) == 0 {
+		fmt.Println("⛔--all には設定ファイルの servers にサーバーを登録してください（snulog config --help）")
+		return
+	}
+	columns := serverLogColumns(config)
+	if err := checkOutput(cmd, config, columns); err != nil {
+		fmt.Println("⛔", err)
+		return
+	}
+	workers, _ := cmd.Flags().GetInt("workers")
+	if workers < 1 {
+		fmt.Println("⛔--workers は 1 以上で指定してください")
+		return
+	}
+
+	logs, failures := fanOut(context.Background(), config.Servers, workers, fetchFromServer)
+	if len(failures) < len(config.Servers) {
+		if err := printRows(cmd, config, columns, logs); err != nil {
+			fmt.Println("⛔", err)
+			return
+		}
+	}
+	for _, f := range failures {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "⚠️%s (%s) から取得できませんでした: %s\n", f.Server.Name, f.Server.Server, status.Convert(f.Err).Message())
+	}
+	if len(failures) == len(config.Servers) {
+		fmt.Println("⛔すべてのサーバーで取得に失敗しました")
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(fetchCmd)
+	addOutputFlags(fetchCmd)
+	fetchCmd.Flags().Bool("all", false, "設定の servers のすべてから取得してまとめる")
+	fetchCmd.Flags().Int("workers", 4, "--all で同時に問い合わせるサーバーの数")
+}
+
+// localTime は RFC3339 の時刻を設定のタイムゾーンで表示する。解釈できなければそのまま返す
+func localTime(timestamp string, loc *time.Location) string {
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return timestamp
+	}
+	return t.In(loc).Format(time.RFC3339)
 }
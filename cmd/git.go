@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/gitlog"
+	"github.com/spf13/cobra"
+)
+
+var gitCmd = &cobra.Command{
+	Use:   "git",
+	Short: "git リポジトリのコミットからステータスを作る",
+}
+
+var gitSuggestCmd = &cobra.Command{
+	Use:   "suggest",
+	Short: "最近のコミットからステータスの候補を作る",
+	Long: `作者（設定の git.author、なければリポジトリの user.email）の最近のコミットの件名から、
+課題の参照（PROJ-123 や #123）を添えたステータスの候補を表示する。
+--post を付けると、確認してから snulog add と同じようにログを追加する。`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := currentConfig()
+		if err != nil {
+			fmt.Println("⛔設定の読み込みに失敗: ", err)
+			return
+		}
+		dir, _ := cmd.Flags().GetString("dir")
+		since, _ := cmd.Flags().GetDuration("since")
+		count, _ := cmd.Flags().GetInt("count")
+		author, _ := cmd.Flags().GetString("author")
+		post, _ := cmd.Flags().GetBool("post")
+
+		ctx := context.Background()
+		if author == "" {
+			author = config.GitAuthor
+		}
+		if author == "" {
+			if author, err = gitlog.Author(ctx, dir); err != nil {
+				fmt.Println("⛔コミットの作者が分かりません。snulog config set git.author <メールアドレス> で設定してください: ", err)
+				return
+			}
+		}
+		opts := gitlog.Options{Author: author, Max: count}
+		if since > 0 {
+			opts.Since = time.Now().Add(-since)
+		}
+		commits, err := gitlog.Log(ctx, dir, opts)
+		if err != nil {
+			fmt.Println("⛔コミットの読み込みに失敗: ", err)
+			return
+		}
+		if len(commits) == 0 {
+			fmt.Printf("💤%s のコミットはありません\n", author)
+			return
+		}
+
+		suggestion := gitlog.Suggest(commits)
+		for _, c := range commits {
+			fmt.Printf("  %.7s %s\n", c.Hash, c.Subject)
+		}
+		fmt.Println("💡", suggestion.Status)
+		if post {
+			postSuggestion(cmd, config, suggestion)
+		}
+	},
+}
+
+// postSuggestion はステータスと気分を確かめてからログを追加する
+func postSuggestion(cmd *cobra.Command, config cliConfig, suggestion gitlog.Suggestion) {
+	if config.User == "" {
+		fmt.Println("⛔user が設定されていません。snulog config set user <ユーザー名> で設定してください")
+		return
+	}
+	visibilityFlag, _ := cmd.Flags().GetString("visibility")
+	visibility, ok := visibilityValues[visibilityFlag]
+	if !ok {
+		fmt.Println("⛔公開範囲は team, managers, private のいずれかを指定してください")
+		return
+	}
+	feeling, _ := cmd.Flags().GetString("feeling")
+
+	reader := bufio.NewReader(cmd.InOrStdin())
+	status, err := prompt(reader, "ステータス（Enter で候補のまま）: ")
+	if err == nil && feeling == "" {
+		feeling, err = prompt(reader, "気分: ")
+	}
+	var answer string
+	if err == nil {
+		if status == "" {
+			status = suggestion.Status
+		}
+		fmt.Printf("user: %s\nstatus: %s\nfeeling: %s\n", config.User, status, feeling)
+		answer, err = prompt(reader, "このログを追加しますか？ [y/N]: ")
+	}
+	if err != nil {
+		fmt.Println("⛔入力の読み込みに失敗: ", err)
+		return
+	}
+	if !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+		fmt.Println("中止しました")
+		return
+	}
+
+	entry, err := newEntry(config, config.User, status, feeling, visibility)
+	if err != nil {
+		fmt.Println("⛔ログ追加失敗: ", err)
+		return
+	}
+	postEntry(config, entry)
+}
+
+// prompt は label を表示して 1 行読む。最後の行に改行がなくても読んだ分を返す
+func prompt(reader *bufio.Reader, label string) (string, error) {
+	fmt.Print(label)
+	line, err := reader.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+var gitHookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "git のフックを管理する",
+}
+
+var gitHookInstallCmd = &cobra.Command{
+	Use:   "install [post-commit|pre-push]",
+	Short: "コミットやプッシュのたびにステータスの候補を出すフックを入れる",
+	Long: `post-commit（既定）はコミットした 1 件から、pre-push は直近 24 時間のコミットから候補を出す。
+--post を付けると、候補を確認してログを追加するか端末で尋ねる。端末のない git クライアントからは尋ねない。
+フックは失敗してもコミットやプッシュを止めない。snulog 以外が作った同じ名前のフックは --force のときだけ置き換える。`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: gitlog.Hooks,
+	Run: func(cmd *cobra.Command, args []string) {
+		name := "post-commit"
+		if len(args) == 1 {
+			name = args[0]
+		}
+		dir, _ := cmd.Flags().GetString("dir")
+		post, _ := cmd.Flags().GetBool("post")
+		force, _ := cmd.Flags().GetBool("force")
+
+		path, err := gitlog.InstallHook(context.Background(), dir, name, hookCommand(name, post), force)
+		if errors.Is(err, gitlog.ErrForeignHook) {
+			fmt.Printf("⛔%s は snulog 以外が作ったフックです。置き換えるには --force を付けてください\n", path)
+			return
+		}
+		if err != nil {
+			fmt.Println("⛔フックのインストールに失敗: ", err)
+			return
+		}
+		fmt.Printf("✅%s をインストールしました: %s\n", name, path)
+	},
+}
+
+// hookCommand はフックで実行するコマンド。--post のときは端末から入力を読む
+func hookCommand(name string, post bool) string {
+	command := shellQuote(snulogPath()) + " git suggest"
+	if name == "post-commit" {
+		command += " --count 1"
+	}
+	if !post {
+		return command + " || true"
+	}
+	command += " --post"
+	return fmt.Sprintf("if (: < /dev/tty) 2>/dev/null; then\n  %s < /dev/tty || true\nfi", command)
+}
+
+// snulogPath はフックから呼ぶ snulog の実行ファイル。go run の一時ファイルなら PATH の snulog を使う
+func snulogPath() string {
+	path, err := os.Executable()
+	if err != nil || strings.HasPrefix(path, filepath.Clean(os.TempDir())+string(filepath.Separator)) {
+		return "snulog"
+	}
+	return path
+}
+
+// shellQuote は s を sh の 1 つの引数にする
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func init() {
+	rootCmd.AddCommand(gitCmd)
+	gitCmd.AddCommand(gitSuggestCmd, gitHookCmd)
+	gitHookCmd.AddCommand(gitHookInstallCmd)
+
+	gitSuggestCmd.Flags().String("dir", ".", "git リポジトリのディレクトリ")
+	gitSuggestCmd.Flags().Duration("since", 24*time.Hour, "この時間内のコミットを読む（0 なら期間で絞らない）")
+	gitSuggestCmd.Flags().Int("count", 0, "新しい順にこの件数だけ読む（0 なら上限なし）")
+	gitSuggestCmd.Flags().String("author", "", "コミットの作者（省略すると設定の git.author、なければ user.email）")
+	gitSuggestCmd.Flags().Bool("post", false, "確認してからログを追加する")
+	gitSuggestCmd.Flags().String("feeling", "", "--post で使う気分（省略すると尋ねる）")
+	gitSuggestCmd.Flags().String("visibility", "team", "--post で使う公開範囲 (team, managers, private)")
+	cobra.CheckErr(gitSuggestCmd.RegisterFlagCompletionFunc("visibility", completeVisibility))
+
+	gitHookInstallCmd.Flags().String("dir", ".", "git リポジトリのディレクトリ")
+	gitHookInstallCmd.Flags().Bool("post", false, "候補を確認してログを追加するか尋ねる")
+	gitHookInstallCmd.Flags().Bool("force", false, "snulog 以外が作った同じ名前のフックも置き換える")
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"bufio"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHookCommand(t *testing.T) {
+	command := hookCommand("post-commit", false)
+	assert.Contains(t, command, " git suggest --count 1 || true")
+
+	command = hookCommand("pre-push", true)
+	assert.NotContains(t, command, "--count")
+	assert.Contains(t, command, "--post < /dev/tty || true", "フックの標準入力ではなく端末から尋ねる")
+
+	// 生成したスクリプトは sh の構文として正しい
+	out, err := exec.Command("sh", "-n", "-c", command).CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+func TestShellQuote(t *testing.T) {
+	out, err := exec.Command("sh", "-c", "printf %s "+shellQuote("/opt/it's here/snulog")).Output()
+	require.NoError(t, err)
+	assert.Equal(t, "/opt/it's here/snulog", string(out))
+}
+
+func TestPrompt(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader(" fix bug \ny"))
+	line, err := prompt(reader, "")
+	require.NoError(t, err)
+	assert.Equal(t, "fix bug", line)
+	line, err = prompt(reader, "")
+	require.NoError(t, err)
+	assert.Equal(t, "y", line, "最後の行に改行がなくても読む")
+	_, err = prompt(reader, "")
+	assert.Error(t, err)
+}
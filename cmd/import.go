@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/gensan0223/snulog/internal/importer"
+	"github.com/gensan0223/snulog/internal/util"
+	pb "github.com/gensan0223/snulog/proto"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/status"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "CSV / JSON / JSONL のログをまとめて取り込む（組織の管理者用）",
+	Long: `表計算ソフトなどに残していた過去のログを取り込む。形式は --format か拡張子（.csv, .json, .jsonl）で決める。
+
+CSV は 1 行目を見出しとして、user（または user_name）, status, feeling, timestamp, visibility の列を読む。
+見出しが違う場合は --column timestamp=日付 のように対応付ける。JSON はオブジェクトの配列、JSONL は 1 行 1 オブジェクト。
+ファイルの名前が snulog のユーザー名と違う場合は --map-user 山田=yamada のように対応付ける。
+投稿者は組織の既存のユーザーでなければならない。同じ内容の行は取り込み直しても二重には追加されない。`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := currentConfig()
+		if err != nil {
+			fmt.Println("⛔設定の読み込みに失敗: ", err)
+			return
+		}
+		formatFlag, _ := cmd.Flags().GetString("format")
+		format, err := importer.ParseFormat(formatFlag, args[0])
+		if err != nil {
+			fmt.Println("⛔", err)
+			return
+		}
+		columns, _ := cmd.Flags().GetStringToString("column")
+		users, _ := cmd.Flags().GetStringToString("map-user")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		file, err := os.Open(args[0])
+		if err != nil {
+			fmt.Println("⛔ファイルを開けません: ", err)
+			return
+		}
+		defer util.CloseWithLog(file)
+		rows, rowErrors, err := importer.Read(file, format, importer.Options{Columns: columns, Users: users, TimeZone: config.TimeZone})
+		if err != nil {
+			fmt.Println("⛔ファイルを読み取れません: ", err)
+			return
+		}
+
+		res := &pb.ImportLogsResponse{DryRun: dryRun}
+		if len(rows) > 0 {
+			if res, err = sendImport(config, rows, dryRun); err != nil {
+				fmt.Println("⛔取り込みに失敗: ", status.Convert(err).Message())
+				return
+			}
+		}
+		for _, e := range res.Errors {
+			rowErrors = append(rowErrors, importer.RowError{Line: int(e.Row), Message: e.Message})
+		}
+		sort.SliceStable(rowErrors, func(i, j int) bool { return rowErrors[i].Line < rowErrors[j].Line })
+		for _, e := range rowErrors {
+			fmt.Printf("⛔%d 行目: %s\n", e.Line, e.Message)
+		}
+
+		if res.DryRun {
+			fmt.Printf("🔍dry-run: %d 件を取り込めます（エラー %d 件）\n", res.Imported, len(rowErrors))
+			return
+		}
+		fmt.Printf("✅%d 件を取り込みました（取り込み済み %d 件、エラー %d 件）\n", res.Imported, res.Duplicates, len(rowErrors))
+	},
+}
+
+// sendImport は rows を ImportLogs のストリームで送る。件数が多くても終わるまで待つよう、設定のタイムアウトは使わない
+func sendImport(config cliConfig, rows []importer.Row, dryRun bool) (*pb.ImportLogsResponse, error) {
+	conn, err := dialServer(config)
+	if err != nil {
+		return nil, err
+	}
+	defer util.CloseWithLog(conn)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := pb.NewLogServiceClient(conn).ImportLogs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i, row := range rows {
+		req := &pb.ImportLogsRequest{Entry: row.Entry, Row: int32(row.Line), DryRun: dryRun && i == 0}
+		if err := stream.Send(req); err != nil {
+			// サーバーがストリームを閉じた理由は CloseAndRecv で受け取る
+			break
+		}
+	}
+	return stream.CloseAndRecv()
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().String("format", "", "ファイルの形式 (csv, json, jsonl)。省略すると拡張子で決める")
+	importCmd.Flags().StringToString("column", nil, "項目と見出しの対応（例: timestamp=日付,user=名前）。項目は user, status, feeling, timestamp, visibility")
+	importCmd.Flags().StringToString("map-user", nil, "ファイルの名前と snulog のユーザー名の対応（例: 山田=yamada）")
+	importCmd.Flags().Bool("dry-run", false, "検証だけして取り込まない")
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gensan0223/snulog/internal/util"
+	pb "github.com/gensan0223/snulog/proto"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// loginCmd はサーバーにログインし、以降のコマンドで使うトークンを保存する
+var loginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "サーバーにログインしてトークンを保存する",
+	Run: func(cmd *cobra.Command, args []string) {
+		username, _ := cmd.Flags().GetString("user")
+		totpCode, _ := cmd.Flags().GetString("totp")
+
+		config, err := currentConfig()
+		if err != nil {
+			fmt.Println("⛔設定の読み込みに失敗: ", err)
+			return
+		}
+		if username == "" {
+			username = config.User
+		}
+
+		reader := bufio.NewReader(os.Stdin)
+		if username == "" {
+			fmt.Print("ユーザー名: ")
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				fmt.Println("⛔入力の読み込みに失敗: ", err)
+				return
+			}
+			username = strings.TrimSpace(line)
+		}
+		password, err := readPassword(reader, "パスワード: ")
+		if err != nil {
+			fmt.Println("⛔入力の読み込みに失敗: ", err)
+			return
+		}
+
+		conn, err := dialServer(config)
+		if err != nil {
+			fmt.Println("⛔gRPC接続失敗: ", err)
+			return
+		}
+		defer util.CloseWithLog(conn)
+
+		client := pb.NewLogServiceClient(conn)
+		req := &pb.LoginRequest{UserName: username, Password: password, TotpCode: totpCode}
+
+		ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+		defer cancel()
+
+		res, err := client.Login(ctx, req)
+		if status.Code(err) == codes.FailedPrecondition && totpCode == "" {
+			// 2 段階認証が有効なユーザーにはコードを尋ねてやり直す
+			fmt.Print("確認コード（またはリカバリーコード）: ")
+			line, readErr := reader.ReadString('\n')
+			if readErr != nil {
+				fmt.Println("⛔入力の読み込みに失敗: ", readErr)
+				return
+			}
+			req.TotpCode = strings.TrimSpace(line)
+			res, err = client.Login(ctx, req)
+		}
+		if err != nil {
+			fmt.Println("⛔ログイン失敗: ", status.Convert(err).Message())
+			return
+		}
+
+		if err := saveToken(res.Token); err != nil {
+			fmt.Println("⛔トークンの保存に失敗: ", err)
+			return
+		}
+		fmt.Printf("✅%s としてログインしました（有効期限: %s）\n", username, res.ExpiresAt)
+	},
+}
+
+// logoutCmd は保存済みのトークンを削除する
+var logoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "保存済みのトークンを削除する",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := deleteToken(); err != nil {
+			fmt.Println("⛔トークンの削除に失敗: ", err)
+			return
+		}
+		fmt.Println("✅ログアウトしました")
+	},
+}
+
+// readPassword は端末ならエコーせずに、パイプなら 1 行そのまま読む
+func readPassword(reader *bufio.Reader, prompt string) (string, error) {
+	fmt.Print(prompt)
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		password, err := term.ReadPassword(fd)
+		fmt.Println()
+		return string(password), err
+	}
+	line, err := reader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func init() {
+	rootCmd.AddCommand(loginCmd)
+	rootCmd.AddCommand(logoutCmd)
+	loginCmd.Flags().StringP("user", "u", "", "ユーザー名（省略すると入力を求める）")
+	loginCmd.Flags().String("totp", "", "2 段階認証の確認コード")
+}
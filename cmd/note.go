@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/notes"
+	"github.com/gensan0223/snulog/internal/output"
+	pb "github.com/gensan0223/snulog/proto"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc/status"
+)
+
+var noteCmd = &cobra.Command{
+	Use:   "note [<text>...]",
+	Short: "あとで snulog publish でまとめる自分だけのメモを書く",
+	Long: `時刻付きのメモを保存する。メモは誰にも見えず、snulog publish で 1 日分をまとめて 1 件のログにする。
+メモは手元（設定ディレクトリの snulog/notes）に保存する。--remote（または設定の note.remote）を付けると
+サーバーに本人だけが読めるメモとして保存し、別の端末からも publish できる（ログの一覧や集計には出ない）。サーバーへの保存には snulog login が必要。
+引数を省略すると今日のメモを表示する。`,
+	Example: `  snulog note "API のレビューで認可の抜けを見つけた"
+  snulog note --remote CI が遅いので調べる
+  snulog note`,
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := currentConfig()
+		if err != nil {
+			fmt.Println("⛔設定の読み込みに失敗: ", err)
+			return
+		}
+		store, err := openNotes()
+		if err != nil {
+			fmt.Println("⛔", err)
+			return
+		}
+		now := time.Now().In(config.TimeZone)
+
+		if len(args) == 0 {
+			if err := checkOutput(cmd, config, noteColumns(config)); err != nil {
+				fmt.Println("⛔", err)
+				return
+			}
+			local, remote, err := dayNotes(config, store, now)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "⚠️メモの一部を取得できませんでした: ", status.Convert(err).Message())
+			}
+			if err := printRows(cmd, config, noteColumns(config), mergeNotes(local, remote)); err != nil {
+				fmt.Println("⛔", err)
+			}
+			return
+		}
+
+		text := strings.Join(args, " ")
+		remote := config.NoteRemote
+		if cmd.Flags().Changed("remote") {
+			remote, _ = cmd.Flags().GetBool("remote")
+		}
+		if !remote {
+			if err := store.Add(notes.Note{Text: text, Time: now}); err != nil {
+				fmt.Println("⛔メモの保存に失敗: ", err)
+				return
+			}
+			fmt.Printf("📝メモを保存しました (%s)\n", now.Format("15:04"))
+			return
+		}
+
+		entry, err := newEntry(config, config.User, text, notes.Feeling, pb.Visibility_VISIBILITY_PRIVATE)
+		if err == nil {
+			entry.Note = true
+			err = callServer(func(ctx context.Context, client pb.LogServiceClient) error {
+				_, err := client.AddLogs(ctx, entry)
+				return err
+			})
+		}
+		if err != nil {
+			fmt.Println("⛔メモの保存に失敗: ", status.Convert(err).Message())
+			return
+		}
+		fmt.Printf("📝メモをサーバーに保存しました (%s)\n", now.Format("15:04"))
+	},
+}
+
+// noteColumns はメモの列。サーバーのメモはログ ID を表示する
+func noteColumns(config cliConfig) []output.Column[notes.Note] {
+	return []output.Column[notes.Note]{
+		{Name: "time", Header: "TIME", Emoji: "🕒", Value: func(n notes.Note) any { return n.Time.In(config.TimeZone).Format("15:04") }},
+		{Name: "text", Header: "NOTE", Emoji: "📝", Value: func(n notes.Note) any { return n.Text }},
+		{Name: "stored", Header: "STORED", Value: func(n notes.Note) any {
+			if n.ID == 0 {
+				return "local"
+			}
+			return fmt.Sprintf("server (#%d)", n.ID)
+		}},
+	}
+}
+
+// openNotes はメモの置き場所を返す。outbox と同じくプロファイルごとに分ける
+func openNotes() (*notes.Store, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	if profile := viper.GetString("profile"); profile != "" {
+		return notes.New(filepath.Join(dir, "snulog", "notes", "profiles", filepath.Base(profile))), nil
+	}
+	return notes.New(filepath.Join(dir, "snulog", "notes")), nil
+}
+
+// dayNotes は day の日付の手元のメモと、ログインしていればサーバーのメモを返す。
+// サーバーのメモを取得できなければ手元のメモとエラーを返す
+func dayNotes(config cliConfig, store *notes.Store, day time.Time) ([]notes.Note, []notes.Note, error) {
+	local, err := store.List(day)
+	if err != nil {
+		return nil, nil, err
+	}
+	token, err := loadToken()
+	if err != nil || token == "" {
+		return local, nil, err
+	}
+
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	var remote []notes.Note
+	err = callServer(func(ctx context.Context, client pb.LogServiceClient) error {
+		res, err := client.SearchLogs(ctx, &pb.SearchLogsRequest{
+			Since: start.Format(time.RFC3339),
+			Until: start.AddDate(0, 0, 1).Format(time.RFC3339),
+			Limit: 1000,
+			Notes: true,
+		})
+		if err != nil {
+			return err
+		}
+		// メモは private なので本人にしか見えず、自分のメモだけが返る
+		for _, entry := range res.Logs {
+			if !entry.Note {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339, entry.Timestamp)
+			if err != nil {
+				continue
+			}
+			remote = append(remote, notes.Note{Text: entry.Status, Time: t, ID: entry.Id})
+		}
+		return nil
+	})
+	return local, remote, err
+}
+
+// mergeNotes は手元とサーバーのメモを時刻順に並べる
+func mergeNotes(local, remote []notes.Note) []notes.Note {
+	merged := append(append([]notes.Note{}, local...), remote...)
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Time.Before(merged[j].Time)
+	})
+	return merged
+}
+
+func init() {
+	rootCmd.AddCommand(noteCmd)
+	addOutputFlags(noteCmd)
+	noteCmd.Flags().Bool("remote", false, "サーバーに private のログとして保存する（省略すると設定の note.remote）")
+}
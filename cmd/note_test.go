@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/notes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeNotes(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2025, 1, 2, hour, 0, 0, 0, time.UTC) }
+	local := []notes.Note{{Text: "a", Time: at(9)}, {Text: "c", Time: at(15)}}
+	remote := []notes.Note{{Text: "b", Time: at(11), ID: 7}}
+
+	merged := mergeNotes(local, remote)
+	assert.Equal(t, []string{"a", "b", "c"}, []string{merged[0].Text, merged[1].Text, merged[2].Text})
+	assert.Equal(t, "a", local[0].Text, "元のスライスは並べ替えない")
+	assert.Empty(t, mergeNotes(nil, nil))
+}
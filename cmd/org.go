@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/gensan0223/snulog/internal/output"
+	pb "github.com/gensan0223/snulog/proto"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/status"
+)
+
+// orgCmd は組織を管理する（インスタンス管理者用）
+var orgCmd = &cobra.Command{
+	Use:   "org",
+	Short: "組織を管理する（インスタンス管理者用）",
+}
+
+var orgCreateCmd = &cobra.Command{
+	Use:   "create <slug>",
+	Short: "組織を作成する",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name, _ := cmd.Flags().GetString("name")
+		err := callServer(func(ctx context.Context, client pb.LogServiceClient) error {
+			org, err := client.CreateOrganization(ctx, &pb.CreateOrganizationRequest{Slug: args[0], Name: name})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("✅組織を作成しました: %s (%s)\n", org.Slug, org.Name)
+			return nil
+		})
+		if err != nil {
+			fmt.Println("⛔組織の作成に失敗: ", status.Convert(err).Message())
+		}
+	},
+}
+
+var orgListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "組織の一覧を表示する",
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := currentConfig()
+		if err == nil {
+			err = checkOutput(cmd, config, orgColumns)
+		}
+		if err != nil {
+			fmt.Println("⛔", err)
+			return
+		}
+		err = callServer(func(ctx context.Context, client pb.LogServiceClient) error {
+			res, err := client.ListOrganizations(ctx, &pb.ListOrganizationsRequest{})
+			if err != nil {
+				return err
+			}
+			return printRows(cmd, config, orgColumns, res.Organizations)
+		})
+		if err != nil {
+			fmt.Println("⛔組織の取得に失敗: ", status.Convert(err).Message())
+		}
+	},
+}
+
+var orgColumns = []output.Column[*pb.Organization]{
+	{Name: "id", Header: "ID", Value: func(o *pb.Organization) any { return o.Id }},
+	{Name: "slug", Header: "SLUG", Emoji: "🏢", Value: func(o *pb.Organization) any { return o.Slug }},
+	{Name: "name", Header: "NAME", Value: func(o *pb.Organization) any { return o.Name }},
+}
+
+// teamCmd はログイン中のユーザーの組織のチームを管理する
+var teamCmd = &cobra.Command{
+	Use:   "team",
+	Short: "自分の組織のチームを管理する",
+}
+
+var teamCreateCmd = &cobra.Command{
+	Use:   "create <slug>",
+	Short: "チームを作成する（組織の管理者用）",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name, _ := cmd.Flags().GetString("name")
+		err := callServer(func(ctx context.Context, client pb.LogServiceClient) error {
+			team, err := client.CreateTeam(ctx, &pb.CreateTeamRequest{Slug: args[0], Name: name})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("✅チームを作成しました: %s (%s)\n", team.Slug, team.Name)
+			return nil
+		})
+		if err != nil {
+			fmt.Println("⛔チームの作成に失敗: ", status.Convert(err).Message())
+		}
+	},
+}
+
+var teamListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "チームの一覧を表示する",
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := currentConfig()
+		if err == nil {
+			err = checkOutput(cmd, config, teamColumns)
+		}
+		if err != nil {
+			fmt.Println("⛔", err)
+			return
+		}
+		err = callServer(func(ctx context.Context, client pb.LogServiceClient) error {
+			res, err := client.ListTeams(ctx, &pb.ListTeamsRequest{})
+			if err != nil {
+				return err
+			}
+			return printRows(cmd, config, teamColumns, res.Teams)
+		})
+		if err != nil {
+			fmt.Println("⛔チームの取得に失敗: ", status.Convert(err).Message())
+		}
+	},
+}
+
+var teamColumns = []output.Column[*pb.Team]{
+	{Name: "id", Header: "ID", Value: func(t *pb.Team) any { return t.Id }},
+	{Name: "slug", Header: "SLUG", Emoji: "👥", Value: func(t *pb.Team) any { return t.Slug }},
+	{Name: "name", Header: "NAME", Value: func(t *pb.Team) any { return t.Name }},
+}
+
+// userCmd はユーザーを管理する
+var userCmd = &cobra.Command{
+	Use:   "user",
+	Short: "ユーザーを管理する",
+}
+
+var userCreateCmd = &cobra.Command{
+	Use:   "create <username>",
+	Short: "ユーザーを作成する（組織の管理者用）",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		email, _ := cmd.Flags().GetString("email")
+		role, _ := cmd.Flags().GetString("role")
+		org, _ := cmd.Flags().GetString("org")
+
+		password, err := readPassword(bufio.NewReader(os.Stdin), "初期パスワード: ")
+		if err != nil {
+			fmt.Println("⛔入力の読み込みに失敗: ", err)
+			return
+		}
+
+		err = callServer(func(ctx context.Context, client pb.LogServiceClient) error {
+			res, err := client.CreateUser(ctx, &pb.CreateUserRequest{
+				UserName: args[0],
+				Password: password,
+				Email:    email,
+				Role:     role,
+				OrgSlug:  org,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("✅ユーザーを作成しました: %s (組織: %s, ロール: %s)\n", res.UserName, res.OrgSlug, res.Role)
+			return nil
+		})
+		if err != nil {
+			fmt.Println("⛔ユーザーの作成に失敗: ", status.Convert(err).Message())
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(orgCmd)
+	orgCmd.AddCommand(orgCreateCmd, orgListCmd)
+	orgCreateCmd.Flags().String("name", "", "表示名（省略すると slug）")
+	addOutputFlags(orgListCmd)
+
+	rootCmd.AddCommand(teamCmd)
+	teamCmd.AddCommand(teamCreateCmd, teamListCmd)
+	teamCreateCmd.Flags().String("name", "", "表示名（省略すると slug）")
+	addOutputFlags(teamListCmd)
+
+	rootCmd.AddCommand(userCmd)
+	userCmd.AddCommand(userCreateCmd)
+	userCreateCmd.Flags().String("email", "", "パスワードの再設定に使うメールアドレス")
+	userCreateCmd.Flags().String("role", "member", "ロール (member, manager, admin)")
+	userCreateCmd.Flags().String("org", "", "作成先の組織の slug（省略すると自分の組織。他の組織はインスタンス管理者のみ）")
+}
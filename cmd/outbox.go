@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/outbox"
+	pb "github.com/gensan0223/snulog/proto"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+)
+
+// outboxLog は自動で送った outbox の結果の出力先。画面を占有する tui では捨てる
+var outboxLog io.Writer = os.Stderr
+
+// openOutbox はサーバーに届かなかったログの置き場所を返す。トークンと同じくプロファイルごとに分ける
+func openOutbox() (*outbox.Outbox, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	if profile := viper.GetString("profile"); profile != "" {
+		return outbox.New(filepath.Join(dir, "snulog", "outboxes", filepath.Base(profile))), nil
+	}
+	return outbox.New(filepath.Join(dir, "snulog", "outbox")), nil
+}
+
+type flushingKey struct{}
+
+// flushOutbox は RPC が成功したら、溜まっているログを同じ接続で送る。
+// 送るための AddLogs と snulog sync の呼び出しではもう一度送らない
+func flushOutbox(box *outbox.Outbox, timeout time.Duration) grpc.UnaryClientInterceptor {
+	var flushing atomic.Bool
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if err != nil || ctx.Value(flushingKey{}) != nil || !flushing.CompareAndSwap(false, true) {
+			return err
+		}
+		defer flushing.Store(false)
+
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+		defer cancel()
+		result, flushErr := sendOutbox(ctx, box, pb.NewLogServiceClient(cc))
+		if result.Sent > 0 || len(result.Rejected) > 0 {
+			printFlushResult(outboxLog, box, result, flushErr)
+		}
+		return nil
+	}
+}
+
+// sendOutbox は溜まっているログを client で送る
+func sendOutbox(ctx context.Context, box *outbox.Outbox, client pb.LogServiceClient) (outbox.Result, error) {
+	ctx = context.WithValue(ctx, flushingKey{}, true)
+	return box.Flush(ctx, func(ctx context.Context, entry *pb.LogEntry) (*pb.AddResponse, error) {
+		return client.AddLogs(ctx, entry)
+	})
+}
+
+func printFlushResult(w io.Writer, box *outbox.Outbox, result outbox.Result, err error) {
+	if result.Sent > 0 {
+		_, _ = fmt.Fprintf(w, "📤outbox のログを %d 件送信しました\n", result.Sent)
+	}
+	for _, rejected := range result.Rejected {
+		_, _ = fmt.Fprintf(w, "⛔受け付けられなかったログを %s に移しました（%s）: %v\n",
+			filepath.Join(box.Dir(), outbox.RejectedDir), rejected.Entry.Timestamp, rejected.Err)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(w, "📥%d 件が送信待ちです: %v\n", result.Remaining, err)
+	}
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/outbox"
+	"github.com/gensan0223/snulog/internal/util"
+	"github.com/gensan0223/snulog/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type outboxLogServer struct {
+	proto.UnimplementedLogServiceServer
+	mutex sync.Mutex
+	keys  []string
+}
+
+func (s *outboxLogServer) AddLogs(ctx context.Context, entry *proto.LogEntry) (*proto.AddResponse, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, key := range s.keys {
+		if key == entry.IdempotencyKey {
+			return &proto.AddResponse{Duplicate: true}, nil
+		}
+	}
+	s.keys = append(s.keys, entry.IdempotencyKey)
+	return &proto.AddResponse{}, nil
+}
+
+func (s *outboxLogServer) FetchLogs(ctx context.Context, req *proto.FetchRequest) (*proto.FetchResponse, error) {
+	return &proto.FetchResponse{}, nil
+}
+
+func TestFlushOutbox_SendsQueuedLogsAfterSuccessfulCall(t *testing.T) {
+	server := &outboxLogServer{}
+	listener := bufconn.Listen(bufSize)
+	s := grpc.NewServer()
+	proto.RegisterLogServiceServer(s, server)
+	go func() { _ = s.Serve(listener) }()
+	t.Cleanup(s.Stop)
+
+	box := outbox.New(t.TempDir())
+	for _, key := range []string{"k1", "k2"} {
+		require.NoError(t, box.Add(&proto.LogEntry{UserName: "alice", Status: "offline", IdempotencyKey: key}))
+	}
+	var log bytes.Buffer
+	previous := outboxLog
+	outboxLog = &log
+	t.Cleanup(func() { outboxLog = previous })
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(flushOutbox(box, time.Second)))
+	require.NoError(t, err)
+	defer util.CloseWithLog(conn)
+
+	_, err = proto.NewLogServiceClient(conn).FetchLogs(context.Background(), &proto.FetchRequest{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"k1", "k2"}, server.keys, "送るための AddLogs からは送り直さない")
+	items, err := box.List()
+	require.NoError(t, err)
+	assert.Empty(t, items)
+	assert.Contains(t, log.String(), "2 件送信")
+
+	// 送信済みのログがもう一度届いても二重には追加されない
+	require.NoError(t, box.Add(&proto.LogEntry{UserName: "alice", Status: "offline", IdempotencyKey: "k1"}))
+	result, err := sendOutbox(context.Background(), box, proto.NewLogServiceClient(conn))
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Duplicates)
+	assert.Len(t, server.keys, 2)
+}
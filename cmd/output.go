@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/gensan0223/snulog/internal/output"
+	pb "github.com/gensan0223/snulog/proto"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// addOutputFlags は読み取り系コマンドに -o と --columns を追加する
+func addOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("output", "o", "", "出力形式 (table, json, jsonl, yaml, csv, template=<Go のテンプレート>)。省略すると設定の output")
+	cmd.Flags().StringSlice("columns", nil, "出力する列と順序（カンマ区切り）")
+}
+
+// outputOptions は -o / --columns と設定から出力の指定を作る。色は端末に出すときだけ付ける
+func outputOptions(cmd *cobra.Command, config cliConfig) (output.Options, error) {
+	value, _ := cmd.Flags().GetString("output")
+	if value == "" {
+		value = config.Output
+	}
+	format, tmpl, err := output.ParseFormat(value)
+	if err != nil {
+		return output.Options{}, err
+	}
+	columns, _ := cmd.Flags().GetStringSlice("columns")
+
+	color := !config.NoColor && os.Getenv("NO_COLOR") == ""
+	if f, ok := cmd.OutOrStdout().(*os.File); !ok || !term.IsTerminal(int(f.Fd())) {
+		color = false
+	}
+	return output.Options{
+		Format:   format,
+		Template: tmpl,
+		Columns:  columns,
+		Color:    color,
+		Emoji:    !config.NoEmoji,
+	}, nil
+}
+
+// checkOutput は RPC を呼ぶ前に -o と --columns を確かめる
+func checkOutput[T any](cmd *cobra.Command, config cliConfig, columns []output.Column[T]) error {
+	opts, err := outputOptions(cmd, config)
+	if err != nil {
+		return err
+	}
+	_, err = output.SelectColumns(columns, opts.Columns)
+	return err
+}
+
+// printRows は読み取り系コマンドの結果を -o の形式で標準出力に書き出す
+func printRows[T any](cmd *cobra.Command, config cliConfig, columns []output.Column[T], rows []T) error {
+	opts, err := outputOptions(cmd, config)
+	if err != nil {
+		return err
+	}
+	return output.Write(cmd.OutOrStdout(), opts, columns, rows)
+}
+
+// logColumns はログの列。時刻は設定のタイムゾーンで表示する
+func logColumns(config cliConfig) []output.Column[*pb.LogEntry] {
+	return []output.Column[*pb.LogEntry]{
+		{Name: "id", Header: "ID", Value: func(e *pb.LogEntry) any { return e.Id }},
+		{Name: "user", Header: "USER", Emoji: "👤", Value: func(e *pb.LogEntry) any { return e.UserName }},
+		{Name: "status", Header: "STATUS", Emoji: "📝", Value: func(e *pb.LogEntry) any { return e.Status }},
+		{Name: "feeling", Header: "FEELING", Emoji: "😀", Value: func(e *pb.LogEntry) any { return e.Feeling }},
+		{Name: "timestamp", Header: "TIME", Emoji: "🕒", Value: func(e *pb.LogEntry) any { return localTime(e.Timestamp, config.TimeZone) }},
+		{Name: "visibility", Header: "VISIBILITY", Value: func(e *pb.LogEntry) any { return visibilityName(e.Visibility) }},
+	}
+}
+
+// visibilityName は visibilityValues の逆引き
+func visibilityName(visibility pb.Visibility) string {
+	for name, value := range visibilityValues {
+		if value == visibility {
+			return name
+		}
+	}
+	return "team"
+}
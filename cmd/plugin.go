@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/gensan0223/snulog/internal/output"
+	"github.com/gensan0223/snulog/internal/plugin"
+	"github.com/gensan0223/snulog/sdk"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var pluginCmd = &cobra.Command{
+	Use:   "plugin",
+	Short: "PATH にある snulog-<名前> のプラグインを扱う",
+	Long: `PATH にある snulog-<名前> の実行ファイルは snulog <名前> で実行できる（git と同じ仕組み）。
+組み込みのコマンドと同じ名前のプラグインは使わず、同じ名前が複数あれば PATH の先のものを使う。
+プラグインには解決済みの設定を次の環境変数で渡す。Go で書くなら github.com/gensan0223/snulog/sdk の sdk.Connect で
+ログイン済みの LogServiceClient を作れる。
+
+  ` + sdk.EnvServer + `, ` + sdk.EnvToken + `, ` + sdk.EnvProfile + `, ` + sdk.EnvTeam + `, ` + sdk.EnvUser + `,
+  ` + sdk.EnvTimeout + `, ` + sdk.EnvTimeZone + `, SNULOG_TLS_*（enabled, ca, cert, key, server_name）, ` + sdk.EnvBinary,
+}
+
+var pluginListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "見つかったプラグインの一覧を表示する",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := currentConfig()
+		if err == nil {
+			err = checkOutput(cmd, config, pluginColumns)
+		}
+		if err != nil {
+			fmt.Println("⛔", err)
+			return
+		}
+		if err := printRows(cmd, config, pluginColumns, pluginRows(plugin.Find(os.Getenv("PATH")))); err != nil {
+			fmt.Println("⛔", err)
+		}
+	},
+}
+
+// pluginRow は plugin list の 1 行。使われない同じ名前の実行ファイルも 1 行にする
+type pluginRow struct {
+	Name   string
+	Path   string
+	Status string
+}
+
+var pluginColumns = []output.Column[pluginRow]{
+	{Name: "name", Header: "NAME", Emoji: "🧩", Value: func(r pluginRow) any { return r.Name }},
+	{Name: "path", Header: "PATH", Value: func(r pluginRow) any { return r.Path }},
+	{Name: "status", Header: "STATUS", Value: func(r pluginRow) any { return r.Status }},
+}
+
+func pluginRows(plugins []plugin.Plugin) []pluginRow {
+	var rows []pluginRow
+	for _, p := range plugins {
+		status := "ok"
+		if builtinCommand(p.Name) {
+			status = "ignored: same name as a built-in command"
+		}
+		rows = append(rows, pluginRow{Name: p.Name, Path: p.Path, Status: status})
+		for _, path := range p.Shadowed {
+			rows = append(rows, pluginRow{Name: p.Name, Path: path, Status: "shadowed by " + p.Path})
+		}
+	}
+	return rows
+}
+
+// builtinCommand は name が組み込みのコマンドかその別名か
+func builtinCommand(name string) bool {
+	for _, c := range rootCmd.Commands() {
+		if c.Annotations[pluginAnnotation] == "" && (c.Name() == name || c.HasAlias(name)) {
+			return true
+		}
+	}
+	// help と completion は Execute のときに cobra が足す
+	return name == "help" || name == "completion"
+}
+
+// pluginAnnotation はプラグインのコマンドに付ける印。値は実行ファイルのパス
+const pluginAnnotation = "snulog.plugin"
+
+// registerPlugins はプラグインをサブコマンドにする。ヘルプと補完に名前が出る。
+// フラグは解釈せずにそのままプラグインに渡す
+func registerPlugins(plugins []plugin.Plugin) {
+	for _, p := range plugins {
+		if builtinCommand(p.Name) {
+			continue
+		}
+		rootCmd.AddCommand(&cobra.Command{
+			Use:                p.Name,
+			Short:              "プラグイン (" + p.Path + ")",
+			Annotations:        map[string]string{pluginAnnotation: p.Path},
+			DisableFlagParsing: true,
+			Run: func(cmd *cobra.Command, args []string) {
+				os.Exit(runPlugin(p, os.Args[1:]))
+			},
+		})
+	}
+}
+
+// runPlugin は snulog の引数 args のうちプラグインの名前より前のフラグで設定を解決し、
+// 後ろの引数でプラグインを実行して終了コードを返す
+func runPlugin(p plugin.Plugin, args []string) int {
+	before, after, ok := splitPluginArgs(rootCmd.PersistentFlags(), args, p.Name)
+	if !ok {
+		before, after = nil, args
+	}
+	if err := rootCmd.PersistentFlags().Parse(before); err != nil {
+		fmt.Fprintln(os.Stderr, "⛔", err)
+		return 1
+	}
+	initConfig()
+	config, err := currentConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "⛔設定の読み込みに失敗: ", err)
+		return 1
+	}
+	token, err := loadToken()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "⛔トークンの読み込みに失敗: ", err)
+		return 1
+	}
+	binary, _ := os.Executable()
+
+	c := exec.Command(p.Path, after...)
+	c.Env = append(os.Environ(), pluginEnv(config, token, binary).Environ()...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	err = c.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⛔プラグイン %s を実行できません: %v\n", p.Path, err)
+		return 1
+	}
+	return 0
+}
+
+// pluginEnv はプラグインに渡す設定
+func pluginEnv(config cliConfig, token, binary string) sdk.Env {
+	return sdk.Env{
+		Server:   config.Server,
+		Token:    token,
+		Profile:  config.Profile,
+		Team:     config.Team,
+		User:     config.User,
+		Timeout:  config.Timeout,
+		TimeZone: config.TimeZone,
+		TLS: sdk.TLS{
+			Enabled:    config.TLS.Enabled,
+			CA:         config.TLS.CAFile,
+			Cert:       config.TLS.CertFile,
+			Key:        config.TLS.KeyFile,
+			ServerName: config.TLS.ServerName,
+		},
+		Binary: binary,
+	}
+}
+
+// splitPluginArgs は args をプラグインの名前より前の snulog のフラグと、名前より後ろのプラグインの引数に分ける。
+// 値を取るフラグ（--profile work など）の値は名前と見なさない
+func splitPluginArgs(flags *pflag.FlagSet, args []string, name string) ([]string, []string, bool) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == name {
+			return args[:i], args[i+1:], true
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "--" || strings.Contains(arg, "=") {
+			continue
+		}
+		var f *pflag.Flag
+		if long, ok := strings.CutPrefix(arg, "--"); ok {
+			f = flags.Lookup(long)
+		} else if len(arg) == 2 {
+			f = flags.ShorthandLookup(arg[1:])
+		}
+		if f != nil && f.NoOptDefVal == "" {
+			i++
+		}
+	}
+	return nil, nil, false
+}
+
+func init() {
+	rootCmd.AddCommand(pluginCmd)
+	pluginCmd.AddCommand(pluginListCmd)
+	addOutputFlags(pluginListCmd)
+}
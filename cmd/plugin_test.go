@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/gensan0223/snulog/internal/plugin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitPluginArgs(t *testing.T) {
+	flags := rootCmd.PersistentFlags()
+	tests := []struct {
+		args          []string
+		before, after []string
+		ok            bool
+	}{
+		{[]string{"oncall-handoff", "--to", "bob"}, []string{}, []string{"--to", "bob"}, true},
+		{[]string{"--profile", "work", "--no-color", "oncall-handoff", "-v"}, []string{"--profile", "work", "--no-color"}, []string{"-v"}, true},
+		// フラグの値がプラグインと同じ名前でも名前とは見なさない
+		{[]string{"--team", "oncall-handoff", "oncall-handoff"}, []string{"--team", "oncall-handoff"}, []string{}, true},
+		{[]string{"--server=localhost:1", "oncall-handoff"}, []string{"--server=localhost:1"}, []string{}, true},
+		{[]string{"report"}, nil, nil, false},
+	}
+	for _, tt := range tests {
+		before, after, ok := splitPluginArgs(flags, tt.args, "oncall-handoff")
+		assert.Equal(t, tt.ok, ok, tt.args)
+		assert.Equal(t, tt.before, before, tt.args)
+		assert.Equal(t, tt.after, after, tt.args)
+	}
+}
+
+func TestPluginRows(t *testing.T) {
+	rows := pluginRows([]plugin.Plugin{
+		{Name: "add", Path: "/bin/snulog-add"},
+		{Name: "oncall-handoff", Path: "/usr/local/bin/snulog-oncall-handoff", Shadowed: []string{"/usr/bin/snulog-oncall-handoff"}},
+	})
+	assert.Equal(t, []pluginRow{
+		{Name: "add", Path: "/bin/snulog-add", Status: "ignored: same name as a built-in command"},
+		{Name: "oncall-handoff", Path: "/usr/local/bin/snulog-oncall-handoff", Status: "ok"},
+		{Name: "oncall-handoff", Path: "/usr/bin/snulog-oncall-handoff", Status: "shadowed by /usr/local/bin/snulog-oncall-handoff"},
+	}, rows)
+}
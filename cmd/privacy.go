@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gensan0223/snulog/internal/usecase"
+	pb "github.com/gensan0223/snulog/proto"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// 書き出しの zip は既定の受信サイズ（4MB）を超えることがある
+const maxExportSize = 256 << 20
+
+var userExportCmd = &cobra.Command{
+	Use:   "export [username]",
+	Short: "自分のデータを zip に書き出す（他のユーザーは組織の管理者のみ）",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		req := &pb.ExportMyDataRequest{}
+		if len(args) == 1 {
+			req.UserName = args[0]
+		}
+
+		err := callServer(func(ctx context.Context, client pb.LogServiceClient) error {
+			res, err := client.ExportMyData(ctx, req, grpc.MaxCallRecvMsgSize(maxExportSize))
+			if err != nil {
+				return err
+			}
+			if output == "" {
+				output = res.FileName
+			}
+			if err := os.WriteFile(output, res.Archive, 0o600); err != nil {
+				return err
+			}
+			fmt.Printf("✅データを書き出しました: %s\n", output)
+			return nil
+		})
+		if err != nil {
+			fmt.Println("⛔データの書き出しに失敗: ", status.Convert(err).Message())
+		}
+	},
+}
+
+var userEraseCmd = &cobra.Command{
+	Use:   "erase <username>",
+	Short: "ユーザーを消去する（組織の管理者用）",
+	Long: `ユーザーを削除し、ログと監査ログを仮名化または削除します。
+pseudonymize はログの投稿者を仮名に置き換えて本文を消し、気分と日時をチームの集計のために残します。
+delete はログと監査ログを削除します。--mode を省略するとサーバーの既定（SNULOG_ERASURE_MODE）に従います。`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		modeValue, _ := cmd.Flags().GetString("mode")
+		yes, _ := cmd.Flags().GetBool("yes")
+		mode, err := usecase.ParseErasureMode(modeValue)
+		if err != nil {
+			fmt.Println("⛔", err)
+			return
+		}
+
+		if !yes {
+			// 元に戻せないので、ユーザー名の入力で確認する
+			fmt.Printf("%s を消去します。元に戻せません。確認のためユーザー名を入力してください: ", args[0])
+			line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil || strings.TrimSpace(line) != args[0] {
+				fmt.Println("中止しました")
+				return
+			}
+		}
+
+		err = callServer(func(ctx context.Context, client pb.LogServiceClient) error {
+			res, err := client.EraseUser(ctx, &pb.EraseUserRequest{UserName: args[0], Mode: mode})
+			if err != nil {
+				return err
+			}
+			if res.Mode == pb.ErasureMode_ERASURE_MODE_PSEUDONYMIZE {
+				fmt.Printf("✅%s を %s に仮名化しました（ログ %d 件、監査ログ %d 件）\n", args[0], res.Pseudonym, res.LogsAffected, res.AuditEventsAffected)
+			} else {
+				fmt.Printf("✅%s を削除しました（ログ %d 件、監査ログ %d 件）\n", args[0], res.LogsAffected, res.AuditEventsAffected)
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Println("⛔ユーザーの消去に失敗: ", status.Convert(err).Message())
+		}
+	},
+}
+
+func init() {
+	userCmd.AddCommand(userExportCmd, userEraseCmd)
+	userExportCmd.Flags().StringP("output", "o", "", "書き出し先のファイル（省略するとサーバーが付けた名前）")
+	userEraseCmd.Flags().String("mode", "", "消去の方法 (pseudonymize, delete)。省略するとサーバーの既定")
+	userEraseCmd.Flags().Bool("yes", false, "確認せずに消去する")
+	userExportCmd.ValidArgsFunction = completeFirstArg(completeFromServer(pb.CompletionKind_COMPLETION_KIND_USER))
+	userEraseCmd.ValidArgsFunction = completeFirstArg(completeFromServer(pb.CompletionKind_COMPLETION_KIND_USER))
+	cobra.CheckErr(userEraseCmd.RegisterFlagCompletionFunc("mode", cobra.FixedCompletions([]string{"pseudonymize", "delete"}, cobra.ShellCompDirectiveNoFileComp)))
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/draft"
+	"github.com/gensan0223/snulog/internal/notes"
+	pb "github.com/gensan0223/snulog/proto"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/status"
+)
+
+var publishCmd = &cobra.Command{
+	Use:   "publish",
+	Short: "今日のメモをエディタでまとめて 1 件のログとして投稿する",
+	Long: `snulog note で書いた 1 日分のメモ（手元のメモと、ログインしていればサーバーのメモ）を時刻順に並べ、
+$VISUAL か $EDITOR で開く。まとめた内容を 1 件のログとして投稿し、投稿したメモを消す。
+ステータスを空のまま保存すると中止し、メモは残す。`,
+	Example: `  snulog publish --feeling "🙂"
+  snulog publish --date 2025-01-02 --visibility managers
+  snulog publish --no-edit --feeling "😊"`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := currentConfig()
+		if err != nil {
+			fmt.Println("⛔設定の読み込みに失敗: ", err)
+			return
+		}
+		day := time.Now().In(config.TimeZone)
+		if date, _ := cmd.Flags().GetString("date"); date != "" {
+			if day, err = time.ParseInLocation(time.DateOnly, date, config.TimeZone); err != nil {
+				fmt.Printf("⛔--date は YYYY-MM-DD で指定してください: %q\n", date)
+				return
+			}
+		}
+		user, _ := cmd.Flags().GetString("user")
+		if user == "" {
+			user = config.User
+		}
+		if user == "" {
+			fmt.Println("⛔user が設定されていません。--user を付けるか snulog config set user <ユーザー名> で設定してください")
+			return
+		}
+		store, err := openNotes()
+		if err != nil {
+			fmt.Println("⛔", err)
+			return
+		}
+		local, remote, err := dayNotes(config, store, day)
+		if err != nil {
+			// サーバーのメモを落としたまま投稿しないよう、サーバーに保存する設定なら中止する
+			if config.NoteRemote || local == nil {
+				fmt.Println("⛔メモの取得に失敗: ", status.Convert(err).Message())
+				return
+			}
+			fmt.Println("⚠️サーバーのメモを取得できなかったため、手元のメモだけをまとめます: ", status.Convert(err).Message())
+		}
+		all := mergeNotes(local, remote)
+		if len(all) == 0 {
+			fmt.Printf("📭%s のメモはありません。snulog note <メモ> で書けます\n", day.Format(time.DateOnly))
+			return
+		}
+
+		visibilityFlag, _ := cmd.Flags().GetString("visibility")
+		d := draft.Draft{Status: notes.Summary(all, config.TimeZone), Visibility: visibilityFlag}
+		d.Feeling, _ = cmd.Flags().GetString("feeling")
+		if noEdit, _ := cmd.Flags().GetBool("no-edit"); !noEdit {
+			content, err := draft.Edit(draft.Editor(), draft.Render(d, nil))
+			if err == nil {
+				d, err = draft.Parse(content, nil)
+			}
+			if err != nil {
+				fmt.Println("⛔編集したログの読み込みに失敗: ", err)
+				return
+			}
+			if d.Empty() {
+				fmt.Println("中止しました。メモは残しています")
+				return
+			}
+			if d.Visibility == "" {
+				d.Visibility = visibilityFlag
+			}
+		}
+		text, err := d.Text(nil)
+		if err != nil {
+			fmt.Println("⛔ログ追加失敗: ", err)
+			return
+		}
+		visibility, ok := visibilityValues[d.Visibility]
+		if !ok {
+			fmt.Println("⛔公開範囲は team, managers, private のいずれかを指定してください")
+			return
+		}
+		entry, err := newEntry(config, user, text, d.Feeling, visibility)
+		if err != nil {
+			fmt.Println("⛔ログ追加失敗: ", err)
+			return
+		}
+		if !postEntry(config, entry) {
+			fmt.Println("メモは残しています")
+			return
+		}
+		clearNotes(store, day, len(local), remote)
+	},
+}
+
+// clearNotes は投稿したメモを消す。手元のメモは publish の間に書き足されたものを残す
+func clearNotes(store *notes.Store, day time.Time, local int, remote []notes.Note) {
+	if err := store.Drop(day, local); err != nil {
+		fmt.Println("⚠️手元のメモを消せませんでした: ", err)
+	}
+	for _, note := range remote {
+		err := callServer(func(ctx context.Context, client pb.LogServiceClient) error {
+			_, err := client.DeleteLog(ctx, &pb.DeleteLogRequest{Id: note.ID})
+			return err
+		})
+		if err != nil {
+			fmt.Printf("⚠️サーバーのメモ #%d を消せませんでした。snulog delete %d で消せます: %s\n", note.ID, note.ID, status.Convert(err).Message())
+		}
+	}
+	fmt.Printf("🧹投稿したメモを %d 件片付けました\n", local+len(remote))
+}
+
+func init() {
+	rootCmd.AddCommand(publishCmd)
+	publishCmd.Flags().String("date", "", "まとめる日（YYYY-MM-DD）。省略すると今日")
+	publishCmd.Flags().String("user", "", "ユーザー名（省略すると設定の user）")
+	publishCmd.Flags().String("feeling", "", "気分。エディタの front matter でも書ける")
+	publishCmd.Flags().String("visibility", "team", "公開範囲 (team, managers, private)。team 以外は snulog login が必要")
+	publishCmd.Flags().Bool("no-edit", false, "エディタを開かずにメモをそのまま投稿する")
+	cobra.CheckErr(publishCmd.RegisterFlagCompletionFunc("user", completeFromServer(pb.CompletionKind_COMPLETION_KIND_USER)))
+	cobra.CheckErr(publishCmd.RegisterFlagCompletionFunc("visibility", completeVisibility))
+}
@@ -22,73 +22,94 @@ THE SOFTWARE.
 package cmd
 
 import (
-	"fmt"
 	"os"
 
+	"github.com/gensan0223/snulog/internal/plugin"
+	"github.com/gensan0223/snulog/internal/version"
+	pb "github.com/gensan0223/snulog/proto"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var cfgFile string
 
+// configErr は設定ファイルの読み込みの失敗。サーバーに接続するコマンドで報告する
+var configErr error
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "snulog",
-	Short: "A brief description of your application",
-	Long: `A longer description that spans multiple lines and likely contains
-examples and usage of using your application. For example:
+	Short: "チームの進捗と気分を記録する CLI",
+	Long: `snulog はチームの進捗（ステータス）と気分を記録・共有する CLI です。
 
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
-	// Uncomment the following line if your bare application
-	// has an action associated with it:
-	// Run: func(cmd *cobra.Command, args []string) { },
+接続先や既定値は ~/.snulog.yaml、SNULOG_* 環境変数、フラグで設定できます（snulog config を参照）。`,
+	Version: version.String(),
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
+	registerPlugins(plugin.Find(os.Getenv("PATH")))
 	err := rootCmd.Execute()
 	if err != nil {
 		os.Exit(1)
 	}
 }
 
+// configFlags は永続フラグと設定キーの対応
+var configFlags = map[string]string{
+	"profile":         "profile",
+	"server":          "server",
+	"team":            "team",
+	"timezone":        "timezone",
+	"timeout":         "timeout",
+	"no-color":        "no_color",
+	"no-emoji":        "no_emoji",
+	"tls":             "tls.enabled",
+	"tls-ca":          "tls.ca",
+	"tls-cert":        "tls.cert",
+	"tls-key":         "tls.key",
+	"tls-server-name": "tls.server_name",
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
 
-	// Here you will define your flags and configuration settings.
-	// Cobra supports persistent flags, which, if defined here,
-	// will be global for your application.
-
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.snulog.yaml)")
-
-	// Cobra also supports local flags, which will only run
-	// when this action is called directly.
-	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	flags := rootCmd.PersistentFlags()
+	flags.StringVar(&cfgFile, "config", "", "config file (default is $HOME/.snulog.yaml)")
+	flags.String("profile", "", "設定ファイルの profiles から使うプロファイル")
+	flags.String("server", "localhost:50051", "gRPC サーバーのアドレス")
+	flags.String("team", "default", "既定のチーム")
+	flags.String("timezone", "Local", "時刻の表示に使うタイムゾーン")
+	flags.String("timeout", "5s", "RPC のタイムアウト")
+	flags.Bool("no-color", false, "色を付けない（NO_COLOR 環境変数でも無効になる）")
+	flags.Bool("no-emoji", false, "表の見出しの絵文字を付けない（CI のログ向け）")
+	flags.Bool("tls", false, "Connect to the gRPC server over TLS using the system trust store")
+	flags.String("tls-ca", "", "CA bundle used to verify the gRPC server (enables TLS)")
+	flags.String("tls-cert", "", "Client certificate for mutual TLS")
+	flags.String("tls-key", "", "Client private key for mutual TLS")
+	flags.String("tls-server-name", "", "Override the server name checked against the certificate")
+	cobra.CheckErr(setupConfig(viper.GetViper(), flags, configFlags))
+	cobra.CheckErr(rootCmd.RegisterFlagCompletionFunc("team", completeFromServer(pb.CompletionKind_COMPLETION_KIND_TEAM)))
 }
 
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
-	if cfgFile != "" {
-		// Use config file from the flag.
-		viper.SetConfigFile(cfgFile)
-	} else {
-		// Find home directory.
-		home, err := os.UserHomeDir()
-		cobra.CheckErr(err)
-
-		// Search config in home directory with name ".snulog" (without extension).
-		viper.AddConfigPath(home)
-		viper.SetConfigType("yaml")
-		viper.SetConfigName(".snulog")
+	path := cfgFile
+	if path == "" {
+		var err error
+		if path, err = defaultConfigPath(); err != nil {
+			configErr = err
+			return
+		}
 	}
+	configErr = readConfig(viper.GetViper(), path)
+}
 
-	viper.AutomaticEnv() // read in environment variables that match
-
-	// If a config file is found, read it in.
-	if err := viper.ReadInConfig(); err == nil {
-		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
+// currentConfig は解決済みの設定を返す
+func currentConfig() (cliConfig, error) {
+	if configErr != nil {
+		return cliConfig{}, configErr
 	}
+	return resolveConfig(viper.GetViper())
 }
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/gensan0223/snulog/proto"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/status"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "サーバーに届かなかったログ（outbox）を送信する",
+	Long: `snulog add がサーバーに接続できなかったときに保存したログを、保存した順に送信する。
+送信待ちのログは、次に別のコマンドでサーバーにつながったときにも自動で送信される。
+サーバーが受け付けなかったログは outbox の rejected ディレクトリに移す。`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		box, err := openOutbox()
+		if err != nil {
+			fmt.Println("⛔", err)
+			return
+		}
+
+		if list, _ := cmd.Flags().GetBool("list"); list {
+			config, err := currentConfig()
+			if err == nil {
+				err = checkOutput(cmd, config, logColumns(config))
+			}
+			if err != nil {
+				fmt.Println("⛔", err)
+				return
+			}
+			items, err := box.List()
+			if err != nil {
+				fmt.Println("⛔送信待ちのログの読み込みに失敗: ", err)
+				return
+			}
+			entries := make([]*pb.LogEntry, len(items))
+			for i, item := range items {
+				entries[i] = item.Entry
+			}
+			if err := printRows(cmd, config, logColumns(config), entries); err != nil {
+				fmt.Println("⛔", err)
+			}
+			return
+		}
+
+		items, err := box.List()
+		if err != nil {
+			fmt.Println("⛔送信待ちのログの読み込みに失敗: ", err)
+			return
+		}
+		if len(items) == 0 {
+			fmt.Println("✅送信待ちのログはありません")
+			return
+		}
+		err = callServer(func(ctx context.Context, client pb.LogServiceClient) error {
+			result, err := sendOutbox(ctx, box, client)
+			printFlushResult(cmd.OutOrStdout(), box, result, nil)
+			return err
+		})
+		if err != nil {
+			fmt.Println("⛔送信に失敗。送信待ちのログは残っています: ", status.Convert(err).Message())
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.Flags().Bool("list", false, "送信せずに送信待ちのログを表示する")
+	addOutputFlags(syncCmd)
+}
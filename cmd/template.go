@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gensan0223/snulog/internal/output"
+	pb "github.com/gensan0223/snulog/proto"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/status"
+)
+
+// standupFields は template set で --field を省略したときの項目
+var standupFields = []*pb.TemplateField{
+	{Key: "yesterday", Label: "昨日やったこと"},
+	{Key: "today", Label: "今日やること"},
+	{Key: "blockers", Label: "ブロッカー", Optional: true},
+}
+
+// templateCmd は snulog add --template で使うチームのテンプレートを管理する
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "snulog add --template で使うチームのテンプレートを管理する",
+}
+
+var templateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "チームのテンプレートの一覧を表示する",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := currentConfig()
+		if err == nil {
+			err = checkOutput(cmd, config, templateColumns)
+		}
+		if err != nil {
+			fmt.Println("⛔", err)
+			return
+		}
+		err = callServer(func(ctx context.Context, client pb.LogServiceClient) error {
+			res, err := client.ListTemplates(ctx, &pb.ListTemplatesRequest{Team: config.Team})
+			if err != nil {
+				return err
+			}
+			return printRows(cmd, config, templateColumns, res.Templates)
+		})
+		if err != nil {
+			fmt.Println("⛔テンプレートの取得に失敗: ", status.Convert(err).Message())
+		}
+	},
+}
+
+var templateColumns = []output.Column[*pb.Template]{
+	{Name: "name", Header: "NAME", Emoji: "📋", Value: func(t *pb.Template) any { return t.Name }},
+	{Name: "fields", Header: "FIELDS", Value: func(t *pb.Template) any { return formatTemplateFields(t.Fields) }},
+	{Name: "updated_by", Header: "UPDATED BY", Value: func(t *pb.Template) any { return t.UpdatedBy }},
+	{Name: "updated_at", Header: "UPDATED AT", Value: func(t *pb.Template) any { return t.UpdatedAt }},
+}
+
+var templateSetCmd = &cobra.Command{
+	Use:   "set <name>",
+	Short: "チームのテンプレートを作成・更新する（マネージャー用）",
+	Long: `--field key=見出し で尋ねる項目を順に指定する。key の末尾に ? を付けると省略できる項目になる。
+--field を省略すると yesterday（昨日やったこと）、today（今日やること）、blockers?（ブロッカー）の項目にする。`,
+	Example: `  snulog template set standup
+  snulog template set retro --field good=よかったこと --field improve=改善したいこと --field note?=メモ`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := currentConfig()
+		if err != nil {
+			fmt.Println("⛔設定の読み込みに失敗: ", err)
+			return
+		}
+		values, _ := cmd.Flags().GetStringArray("field")
+		fields, err := parseTemplateFields(values)
+		if err != nil {
+			fmt.Println("⛔", err)
+			return
+		}
+		err = callServer(func(ctx context.Context, client pb.LogServiceClient) error {
+			t, err := client.SaveTemplate(ctx, &pb.Template{Team: config.Team, Name: args[0], Fields: fields})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("✅テンプレートを保存しました: %s/%s (%s)\n", t.Team, t.Name, formatTemplateFields(t.Fields))
+			return nil
+		})
+		if err != nil {
+			fmt.Println("⛔テンプレートの保存に失敗: ", status.Convert(err).Message())
+		}
+	},
+}
+
+var templateDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "チームのテンプレートを削除する（マネージャー用）",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := currentConfig()
+		if err != nil {
+			fmt.Println("⛔設定の読み込みに失敗: ", err)
+			return
+		}
+		err = callServer(func(ctx context.Context, client pb.LogServiceClient) error {
+			_, err := client.DeleteTemplate(ctx, &pb.DeleteTemplateRequest{Team: config.Team, Name: args[0]})
+			return err
+		})
+		if err != nil {
+			fmt.Println("⛔テンプレートの削除に失敗: ", status.Convert(err).Message())
+			return
+		}
+		fmt.Printf("✅テンプレートを削除しました: %s/%s\n", config.Team, args[0])
+	},
+}
+
+// parseTemplateFields は --field key=見出し を項目にする。key の末尾の ? は省略できる項目の印
+func parseTemplateFields(values []string) ([]*pb.TemplateField, error) {
+	if len(values) == 0 {
+		return standupFields, nil
+	}
+	fields := make([]*pb.TemplateField, 0, len(values))
+	for _, value := range values {
+		key, label, _ := strings.Cut(value, "=")
+		key, optional := strings.CutSuffix(key, "?")
+		if key == "" {
+			return nil, fmt.Errorf("--field は key=見出し の形で指定してください: %q", value)
+		}
+		fields = append(fields, &pb.TemplateField{Key: key, Label: label, Optional: optional})
+	}
+	return fields, nil
+}
+
+// formatTemplateFields は項目を --field と同じ key?=見出し の形で並べる
+func formatTemplateFields(fields []*pb.TemplateField) string {
+	parts := make([]string, 0, len(fields))
+	for _, f := range fields {
+		key := f.Key
+		if f.Optional {
+			key += "?"
+		}
+		parts = append(parts, key+"="+f.Label)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func init() {
+	rootCmd.AddCommand(templateCmd)
+	templateCmd.AddCommand(templateListCmd, templateSetCmd, templateDeleteCmd)
+
+	addOutputFlags(templateListCmd)
+	templateSetCmd.ValidArgsFunction = completeFirstArg(completeTemplates)
+	templateDeleteCmd.ValidArgsFunction = completeFirstArg(completeTemplates)
+	templateSetCmd.Flags().StringArray("field", nil, "尋ねる項目（key=見出し、繰り返し指定できる。key? で省略できる項目）")
+}
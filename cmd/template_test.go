@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTemplateFields(t *testing.T) {
+	fields, err := parseTemplateFields(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "yesterday=昨日やったこと, today=今日やること, blockers?=ブロッカー", formatTemplateFields(fields))
+
+	fields, err = parseTemplateFields([]string{"good=よかったこと", "note?=メモ", "plain"})
+	require.NoError(t, err)
+	require.Len(t, fields, 3)
+	assert.False(t, fields[0].Optional)
+	assert.True(t, fields[1].Optional)
+	assert.Equal(t, "note", fields[1].Key)
+	assert.Equal(t, "", fields[2].Label, "見出しを省略するとサーバーが key を見出しにする")
+
+	_, err = parseTemplateFields([]string{"=見出し"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gensan0223/snulog/internal/tui"
+	"github.com/gensan0223/snulog/internal/util"
+	pb "github.com/gensan0223/snulog/proto"
+	"github.com/spf13/cobra"
+)
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "チームの状況と気分をターミナルのダッシュボードで見る",
+	Long: `メンバーごとの最新の状況と気分、ログの履歴を一画面に表示する。
+サーバーが WatchLogs に対応していれば追加と削除をすぐに反映し、対応していなければ --interval ごとに取り直す。
+
+a でログを追加、f でチーム・ユーザー・日付の絞り込み、r で取り直し、q で終了。`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := currentConfig()
+		if err != nil {
+			fmt.Println("⛔設定の読み込みに失敗: ", err)
+			return
+		}
+		interval, _ := cmd.Flags().GetDuration("interval")
+		// 画面を崩さないよう、自動で送った outbox の結果は出さない
+		outboxLog = io.Discard
+
+		conn, err := dialServer(config)
+		if err != nil {
+			fmt.Println("⛔gRPC接続失敗: ", err)
+			return
+		}
+		defer util.CloseWithLog(conn)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		model := tui.New(ctx, pb.NewLogServiceClient(conn), tui.Options{
+			User:         config.User,
+			TimeZone:     config.TimeZone,
+			Timeout:      config.Timeout,
+			PollInterval: interval,
+		})
+		program := tea.NewProgram(model, tea.WithAltScreen())
+		_, err = program.Run()
+		// 終了時に WatchLogs の受信を止める
+		cancel()
+		if err != nil {
+			fmt.Println("⛔", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+	tuiCmd.Flags().Duration("interval", 30*time.Second, "WatchLogs が使えないときにログを取り直す間隔")
+}
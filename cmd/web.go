@@ -1,13 +1,19 @@
 package cmd
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"time"
 
+	"github.com/gensan0223/snulog/internal/auth"
 	"github.com/gensan0223/snulog/internal/handler"
+	"github.com/gensan0223/snulog/internal/mail"
+	"github.com/gensan0223/snulog/internal/util"
+	"github.com/gensan0223/snulog/web"
 	_ "github.com/lib/pq"
 	"github.com/spf13/cobra"
 )
@@ -17,7 +23,15 @@ var webCmd = &cobra.Command{
 	Short: "Start the web server",
 	Run: func(cmd *cobra.Command, args []string) {
 		port, _ := cmd.Flags().GetString("port")
-		grpcAddr, _ := cmd.Flags().GetString("grpc-addr")
+		config, err := currentConfig()
+		if err != nil {
+			log.Fatalf("Failed to load config: %v", err)
+		}
+		// --grpc-addr を省略したら設定の server に接続する
+		grpcAddr := config.Server
+		if cmd.Flags().Changed("grpc-addr") {
+			grpcAddr, _ = cmd.Flags().GetString("grpc-addr")
+		}
 
 		// データベース接続
 		dsn := os.Getenv("DATABASE_URL")
@@ -35,10 +49,39 @@ var webCmd = &cobra.Command{
 			}
 		}()
 
-		webHandler := handler.NewWebHandler(grpcAddr, db)
+		requiredRoles, _ := cmd.Flags().GetStringSlice("require-2fa-roles")
+		opts := []handler.Option{
+			handler.WithTwoFactorPolicy(auth.NewTwoFactorPolicy(requiredRoles...)),
+		}
+		if issuer, _ := cmd.Flags().GetString("oidc-issuer"); issuer != "" {
+			provider, err := newOIDCProvider(cmd, issuer)
+			if err != nil {
+				log.Fatalf("Failed to configure OIDC: %v", err)
+			}
+			opts = append(opts, handler.WithOIDC(provider))
+		}
+
+		mailer, closeMailer, err := newMailer(cmd)
+		if err != nil {
+			log.Fatalf("Failed to configure mailer: %v", err)
+		}
+		defer closeMailer()
+		baseURL, _ := cmd.Flags().GetString("base-url")
+		if baseURL == "" {
+			baseURL = "http://localhost:" + port
+		}
+		opts = append(opts, handler.WithMailer(mailer, baseURL))
+
+		creds, err := config.TLS.TransportCredentials()
+		if err != nil {
+			log.Fatalf("Failed to configure gRPC TLS: %v", err)
+		}
+		opts = append(opts, handler.WithTransportCredentials(creds))
+
+		webHandler := handler.NewWebHandler(grpcAddr, db, opts...)
 
 		// Static files
-		http.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("web/static/"))))
+		http.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.FS(web.Static()))))
 
 		// Routes
 		http.HandleFunc("/", webHandler.ServeIndex)
@@ -52,7 +95,54 @@ var webCmd = &cobra.Command{
 				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			}
 		})
+		http.HandleFunc("/login/2fa", func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				webHandler.ServeTwoFactor(w, r)
+			case http.MethodPost:
+				webHandler.HandleTwoFactor(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		})
+		http.HandleFunc("/2fa/setup", func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				webHandler.ServeTwoFactorSetup(w, r)
+			case http.MethodPost:
+				webHandler.HandleTwoFactorSetup(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		})
+		http.HandleFunc("/profile", webHandler.ServeProfile)
+		http.HandleFunc("/profile/password", webHandler.HandleChangePassword)
+		http.HandleFunc("/profile/email", webHandler.HandleUpdateEmail)
+		http.HandleFunc("/password/forgot", func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				webHandler.ServeForgotPassword(w, r)
+			case http.MethodPost:
+				webHandler.HandleForgotPassword(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		})
+		http.HandleFunc("/password/reset", func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				webHandler.ServeResetPassword(w, r)
+			case http.MethodPost:
+				webHandler.HandleResetPassword(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		})
+		http.HandleFunc("/auth/oidc/login", webHandler.HandleOIDCLogin)
+		http.HandleFunc("/auth/oidc/callback", webHandler.HandleOIDCCallback)
 		http.HandleFunc("/logout", webHandler.HandleLogout)
+		http.HandleFunc("/api/export", webHandler.ExportLogs)
+		http.HandleFunc("/admin/audit", webHandler.ServeAdminAudit)
 		http.HandleFunc("/api/logs", func(w http.ResponseWriter, r *http.Request) {
 			switch r.Method {
 			case http.MethodPost:
@@ -68,14 +158,73 @@ var webCmd = &cobra.Command{
 		fmt.Printf("📡 Connecting to gRPC server at %s\n", grpcAddr)
 		fmt.Printf("🔐 Login page: http://localhost:%s/login\n", port)
 
-		if err := http.ListenAndServe(":"+port, nil); err != nil {
+		if err := http.ListenAndServe(":"+port, handler.SecurityHeaders(webHandler.CSRFMiddleware(http.DefaultServeMux))); err != nil {
 			log.Fatal("Failed to start web server:", err)
 		}
 	},
 }
 
+func newOIDCProvider(cmd *cobra.Command, issuer string) (*auth.OIDCProvider, error) {
+	clientID, _ := cmd.Flags().GetString("oidc-client-id")
+	redirectURL, _ := cmd.Flags().GetString("oidc-redirect-url")
+	usernameClaim, _ := cmd.Flags().GetString("oidc-username-claim")
+	autoProvision, _ := cmd.Flags().GetBool("oidc-auto-provision")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return auth.NewOIDCProvider(ctx, auth.OIDCConfig{
+		IssuerURL:     issuer,
+		ClientID:      clientID,
+		ClientSecret:  os.Getenv("SNULOG_OIDC_CLIENT_SECRET"),
+		RedirectURL:   redirectURL,
+		UsernameClaim: usernameClaim,
+		AutoProvision: autoProvision,
+	})
+}
+
+// newMailer は --mailer の指定に応じてパスワードリセット用の Mailer を作る
+func newMailer(cmd *cobra.Command) (mail.Mailer, func(), error) {
+	kind, _ := cmd.Flags().GetString("mailer")
+	switch kind {
+	case "stdout":
+		return mail.NewStdoutMailer(), func() {}, nil
+	case "file":
+		path, _ := cmd.Flags().GetString("mailer-file")
+		mailer, f, err := mail.NewFileMailer(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return mailer, func() { util.CloseWithLog(f) }, nil
+	case "smtp":
+		addr, _ := cmd.Flags().GetString("smtp-addr")
+		from, _ := cmd.Flags().GetString("smtp-from")
+		username, _ := cmd.Flags().GetString("smtp-username")
+		return mail.NewSMTPMailer(mail.SMTPConfig{
+			Addr:     addr,
+			From:     from,
+			Username: username,
+			Password: os.Getenv("SNULOG_SMTP_PASSWORD"),
+		}), func() {}, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown mailer %q (stdout, file, smtp)", kind)
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(webCmd)
 	webCmd.Flags().StringP("port", "p", "8080", "Port to run the web server on")
-	webCmd.Flags().StringP("grpc-addr", "g", "localhost:50051", "gRPC server address")
+	webCmd.Flags().StringP("grpc-addr", "g", "localhost:50051", "gRPC server address (default: the server config value)")
+	webCmd.Flags().String("base-url", "", "Public URL used in password reset links (default http://localhost:<port>)")
+	webCmd.Flags().String("mailer", "stdout", "Mailer for password reset mails: stdout, file or smtp")
+	webCmd.Flags().String("mailer-file", "mail.log", "File the file mailer appends to")
+	webCmd.Flags().String("smtp-addr", "localhost:587", "SMTP server address (password is read from SNULOG_SMTP_PASSWORD)")
+	webCmd.Flags().String("smtp-from", "snulog@localhost", "From address for outgoing mail")
+	webCmd.Flags().String("smtp-username", "", "SMTP username")
+	webCmd.Flags().StringSlice("require-2fa-roles", nil, "Roles that must use TOTP two-factor authentication (e.g. admin,manager)")
+	webCmd.Flags().String("oidc-issuer", "", "OIDC issuer URL (enables company IdP login)")
+	webCmd.Flags().String("oidc-client-id", "snulog", "OIDC client ID (secret is read from SNULOG_OIDC_CLIENT_SECRET)")
+	webCmd.Flags().String("oidc-redirect-url", "http://localhost:8080/auth/oidc/callback", "OIDC redirect URL registered at the IdP")
+	webCmd.Flags().String("oidc-username-claim", "preferred_username", "ID token claim used as the username of auto-provisioned users (email must be verified)")
+	webCmd.Flags().Bool("oidc-auto-provision", false, "Create users linked to the IdP subject on first OIDC login")
 }
@@ -0,0 +1,3 @@
+ALTER TABLE users ADD COLUMN oidc_subject VARCHAR(255);
+
+CREATE UNIQUE INDEX idx_users_oidc_subject ON users(oidc_subject);
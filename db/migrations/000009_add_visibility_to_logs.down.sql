@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_logs_timestamp;
+DROP INDEX IF EXISTS idx_logs_user_name;
+ALTER TABLE logs DROP COLUMN visibility;
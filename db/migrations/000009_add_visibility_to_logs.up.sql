@@ -0,0 +1,7 @@
+-- team: チーム全員 / managers: 本人とマネージャー・管理者 / private: 本人のみ
+ALTER TABLE logs
+    ADD COLUMN visibility VARCHAR(16) NOT NULL DEFAULT 'team'
+    CHECK (visibility IN ('team', 'managers', 'private'));
+
+CREATE INDEX idx_logs_user_name ON logs(user_name);
+CREATE INDEX idx_logs_timestamp ON logs(timestamp);
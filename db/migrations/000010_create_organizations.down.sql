@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_audit_events_org_id_occurred_at;
+DROP INDEX IF EXISTS idx_logs_org_id_timestamp;
+DROP INDEX IF EXISTS idx_users_org_id;
+
+ALTER TABLE audit_events DROP COLUMN IF EXISTS org_id;
+ALTER TABLE logs DROP COLUMN IF EXISTS org_id;
+ALTER TABLE users DROP COLUMN IF EXISTS org_id;
+
+DROP TABLE IF EXISTS teams;
+DROP TABLE IF EXISTS organizations;
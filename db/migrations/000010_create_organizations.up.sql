@@ -0,0 +1,30 @@
+CREATE TABLE organizations (
+    id SERIAL PRIMARY KEY,
+    slug VARCHAR(64) UNIQUE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 既存のデータはすべて既定の組織に属する
+INSERT INTO organizations (id, slug, name) VALUES (1, 'default', 'Default');
+SELECT setval('organizations_id_seq', (SELECT MAX(id) FROM organizations));
+
+CREATE TABLE teams (
+    id SERIAL PRIMARY KEY,
+    org_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    slug VARCHAR(64) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (org_id, slug)
+);
+
+INSERT INTO teams (org_id, slug, name) VALUES (1, 'default', 'Default');
+
+-- ユーザー名はログイン時に組織を特定するため、インスタンス全体で一意のままにする
+ALTER TABLE users ADD COLUMN org_id INTEGER NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE logs ADD COLUMN org_id INTEGER NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE audit_events ADD COLUMN org_id INTEGER NOT NULL DEFAULT 1 REFERENCES organizations(id);
+
+CREATE INDEX idx_users_org_id ON users(org_id);
+CREATE INDEX idx_logs_org_id_timestamp ON logs(org_id, timestamp);
+CREATE INDEX idx_audit_events_org_id_occurred_at ON audit_events(org_id, occurred_at);
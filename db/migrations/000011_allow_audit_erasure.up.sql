@@ -0,0 +1,16 @@
+-- 個人データの消去（snulog.audit_erasure = 'on'）では、利用者に紐づくイベントの仮名化と削除を許可する。
+-- 発生日時とアクションは書き換えられない
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' AND (current_setting('snulog.audit_retention', true) = 'on'
+                             OR current_setting('snulog.audit_erasure', true) = 'on') THEN
+        RETURN OLD;
+    END IF;
+    IF TG_OP = 'UPDATE' AND current_setting('snulog.audit_erasure', true) = 'on'
+       AND NEW.id = OLD.id AND NEW.org_id = OLD.org_id
+       AND NEW.occurred_at = OLD.occurred_at AND NEW.action = OLD.action THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_logs_org_id_idempotency_key;
+
+ALTER TABLE logs DROP COLUMN IF EXISTS idempotency_key;
@@ -0,0 +1,4 @@
+-- オフラインで溜めたログの再送が二重に追加されないよう、クライアントが生成したキーを組織ごとに一意にする
+ALTER TABLE logs ADD COLUMN idempotency_key VARCHAR(64);
+
+CREATE UNIQUE INDEX idx_logs_org_id_idempotency_key ON logs(org_id, idempotency_key) WHERE idempotency_key IS NOT NULL;
@@ -0,0 +1,12 @@
+-- チームで使う add の入力テンプレート（例: 昨日・今日・ブロッカー）
+CREATE TABLE templates (
+    id SERIAL PRIMARY KEY,
+    org_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    team VARCHAR(64) NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    -- [{"key": "yesterday", "label": "昨日やったこと", "optional": false}, ...]
+    fields JSONB NOT NULL,
+    updated_by VARCHAR(255) NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (org_id, team, name)
+);
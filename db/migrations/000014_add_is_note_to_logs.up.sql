@@ -0,0 +1,2 @@
+-- snulog note --remote のメモ。ログの集計や書き出しからは除く
+ALTER TABLE logs ADD COLUMN is_note BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,121 @@
+// Package audit は書き込み操作とセキュリティイベントを audit_events に記録する
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/repository"
+)
+
+// 記録するアクション
+const (
+	ActionLogCreate            = "log.create"
+	ActionLogDelete            = "log.delete"
+	ActionLoginSuccess         = "auth.login.success"
+	ActionLoginFailure         = "auth.login.failure"
+	ActionLoginBlocked         = "auth.login.blocked"
+	ActionLogout               = "auth.logout"
+	ActionTwoFactorEnabled     = "auth.2fa.enabled"
+	ActionTwoFactorFailure     = "auth.2fa.failure"
+	ActionRecoveryCodeUsed     = "auth.2fa.recovery_code_used"
+	ActionPasswordChanged      = "auth.password.changed"
+	ActionPasswordResetRequest = "auth.password.reset_requested"
+	ActionPasswordReset        = "auth.password.reset"
+	ActionEmailChanged         = "auth.email.changed"
+	ActionUserCreated          = "admin.user.created"
+	ActionOrgCreated           = "admin.org.created"
+	ActionTeamCreated          = "admin.team.created"
+	ActionUserDataExported     = "privacy.data.exported"
+	ActionUserErased           = "admin.user.erased"
+	ActionLogsImported         = "admin.logs.imported"
+	ActionTemplateSaved        = "team.template.saved"
+	ActionTemplateDeleted      = "team.template.deleted"
+)
+
+type Event struct {
+	Actor  string
+	Action string
+	Target string
+	// Source は Web なら接続元 IP、gRPC ならピアのアドレス
+	Source string
+	Before any
+	After  any
+	// OrgID は記録先の組織。0 なら context の組織（ログイン前など context に無い場合に指定する）
+	OrgID int64
+}
+
+type Recorder interface {
+	Record(ctx context.Context, event Event) error
+}
+
+type repositoryRecorder struct {
+	repo repository.AuditRepository
+	now  func() time.Time
+}
+
+func NewRecorder(repo repository.AuditRepository) Recorder {
+	return &repositoryRecorder{repo: repo, now: time.Now}
+}
+
+func (r *repositoryRecorder) Record(ctx context.Context, event Event) error {
+	before, err := marshal(event.Before)
+	if err != nil {
+		return err
+	}
+	after, err := marshal(event.After)
+	if err != nil {
+		return err
+	}
+
+	if event.Source == "" {
+		event.Source = SourceFromContext(ctx)
+	}
+	return r.repo.Append(ctx, &repository.AuditEvent{
+		OrgID:      event.OrgID,
+		OccurredAt: r.now().UTC(),
+		Actor:      event.Actor,
+		Action:     event.Action,
+		Target:     event.Target,
+		Source:     event.Source,
+		Before:     before,
+		After:      after,
+	})
+}
+
+type nopRecorder struct{}
+
+// Nop は監査ログを記録しない Recorder（テストや監査が不要な構成用）
+var Nop Recorder = nopRecorder{}
+
+func (nopRecorder) Record(ctx context.Context, event Event) error { return nil }
+
+// RecordOrLog は記録の失敗で本来の処理を失敗させたくない場合に使う
+func RecordOrLog(ctx context.Context, recorder Recorder, event Event) {
+	if recorder == nil {
+		return
+	}
+	if err := recorder.Record(ctx, event); err != nil {
+		log.Printf("audit: failed to record %s by %q: %v", event.Action, event.Actor, err)
+	}
+}
+
+func marshal(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+type sourceKey struct{}
+
+func WithSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, sourceKey{}, source)
+}
+
+func SourceFromContext(ctx context.Context) string {
+	source, _ := ctx.Value(sourceKey{}).(string)
+	return source
+}
@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder_Record(t *testing.T) {
+	repo := repository.NewInMemoryAuditRepository()
+	recorder := NewRecorder(repo)
+
+	ctx := WithSource(context.Background(), "10.0.0.1:5555")
+	err := recorder.Record(ctx, Event{
+		Actor:  "alice",
+		Action: ActionEmailChanged,
+		Target: "user:alice",
+		Before: map[string]string{"email": "alice@example.com"},
+		After:  map[string]string{"email": "alice@example.org"},
+	})
+	require.NoError(t, err)
+
+	events, err := repo.List(context.Background(), repository.AuditFilter{})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	event := events[0]
+	assert.Equal(t, "alice", event.Actor)
+	assert.Equal(t, "10.0.0.1:5555", event.Source, "Source 未指定なら context の接続元を使う")
+	assert.JSONEq(t, `{"email":"alice@example.com"}`, string(event.Before))
+	assert.JSONEq(t, `{"email":"alice@example.org"}`, string(event.After))
+	assert.False(t, event.OccurredAt.IsZero())
+}
+
+func TestRecorder_NilValuesAreStoredAsNull(t *testing.T) {
+	repo := repository.NewInMemoryAuditRepository()
+	require.NoError(t, NewRecorder(repo).Record(context.Background(), Event{Actor: "alice", Action: ActionLogout}))
+
+	events, err := repo.List(context.Background(), repository.AuditFilter{})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Nil(t, events[0].Before)
+	assert.Nil(t, events[0].After)
+}
+
+func TestPurge_DeletesOnlyExpiredEvents(t *testing.T) {
+	repo := repository.NewInMemoryAuditRepository()
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	require.NoError(t, repo.Append(ctx, &repository.AuditEvent{OccurredAt: now.Add(-48 * time.Hour), Action: "old"}))
+	require.NoError(t, repo.Append(ctx, &repository.AuditEvent{OccurredAt: now, Action: "new", After: json.RawMessage(`{}`)}))
+
+	purge(ctx, repo, 24*time.Hour)
+
+	events, err := repo.List(ctx, repository.AuditFilter{})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "new", events[0].Action)
+}
+
+func TestRunRetention_Disabled(t *testing.T) {
+	repo := repository.NewInMemoryAuditRepository()
+	ctx := context.Background()
+	require.NoError(t, repo.Append(ctx, &repository.AuditEvent{OccurredAt: time.Unix(0, 0), Action: "old"}))
+
+	// 保持期間 0 は無期限なので、すぐに戻り何も削除しない
+	RunRetention(ctx, repo, 0, time.Millisecond)
+
+	events, err := repo.List(ctx, repository.AuditFilter{})
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+}
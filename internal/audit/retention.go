@@ -0,0 +1,39 @@
+package audit
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/repository"
+)
+
+// RunRetention は retention より古いイベントを interval ごとに削除する。ctx がキャンセルされるまで戻らない
+func RunRetention(ctx context.Context, repo repository.AuditRepository, retention, interval time.Duration) {
+	if retention <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purge(ctx, repo, retention)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func purge(ctx context.Context, repo repository.AuditRepository, retention time.Duration) {
+	deleted, err := repo.DeleteBefore(ctx, time.Now().UTC().Add(-retention))
+	if err != nil {
+		log.Printf("audit: retention purge failed: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("audit: purged %d events older than %s", deleted, retention)
+	}
+}
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/gensan0223/snulog/internal/audit"
+)
+
+// SetRecorder はログインやパスワード変更などのセキュリティイベントの記録先を設定する
+func (a *AuthService) SetRecorder(recorder audit.Recorder) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.recorder = recorder
+}
+
+// RecordEvent はリクエストの接続元を Source として監査ログに記録する
+func (a *AuthService) RecordEvent(r *http.Request, event audit.Event) {
+	a.mutex.RLock()
+	recorder := a.recorder
+	a.mutex.RUnlock()
+
+	if event.Source == "" {
+		event.Source = ClientIP(r)
+	}
+	audit.RecordOrLog(r.Context(), recorder, event)
+}
+
+// ClientIP はリクエストの接続元 IP を返す。X-Forwarded-For は偽装できるため参照しない
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -7,22 +7,29 @@ import (
 	"sync"
 	"time"
 
+	"github.com/gensan0223/snulog/internal/audit"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type Session struct {
 	Username  string
+	CSRFToken string
 	CreatedAt time.Time
 }
 
 type AuthService struct {
-	sessions map[string]*Session
-	mutex    sync.RWMutex
+	sessions      map[string]*Session
+	pendingLogins map[string]*PendingLogin
+	enrollments   map[string]*enrollment
+	recorder      audit.Recorder
+	mutex         sync.RWMutex
 }
 
 func NewAuthService() *AuthService {
 	return &AuthService{
-		sessions: make(map[string]*Session),
+		sessions:      make(map[string]*Session),
+		pendingLogins: make(map[string]*PendingLogin),
+		enrollments:   make(map[string]*enrollment),
 	}
 }
 
@@ -37,6 +44,10 @@ func (a *AuthService) CheckPassword(password, hash string) bool {
 }
 
 func (a *AuthService) GenerateSessionToken() (string, error) {
+	return randomToken()
+}
+
+func randomToken() (string, error) {
 	bytes := make([]byte, 32)
 	if _, err := rand.Read(bytes); err != nil {
 		return "", err
@@ -49,12 +60,17 @@ func (a *AuthService) CreateSession(username string) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	csrfToken, err := randomToken()
+	if err != nil {
+		return "", err
+	}
 
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
 	a.sessions[token] = &Session{
 		Username:  username,
+		CSRFToken: csrfToken,
 		CreatedAt: time.Now(),
 	}
 
@@ -62,8 +78,9 @@ func (a *AuthService) CreateSession(username string) (string, error) {
 }
 
 func (a *AuthService) GetSession(token string) (*Session, bool) {
-	a.mutex.RLock()
-	defer a.mutex.RUnlock()
+	// 期限切れのセッションを削除するため書き込みロックを取る
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
 
 	session, exists := a.sessions[token]
 	if !exists {
@@ -3,6 +3,7 @@ package auth
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
@@ -162,3 +163,39 @@ func TestAuthService_GetSessionFromRequest(t *testing.T) {
 		t.Error("Expected no session for request without cookie")
 	}
 }
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(RecoveryCodeCount)
+	if err != nil {
+		t.Fatalf("Failed to generate recovery codes: %v", err)
+	}
+	if len(codes) != RecoveryCodeCount {
+		t.Fatalf("Expected %d codes, got %d", RecoveryCodeCount, len(codes))
+	}
+
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		if len(code) != 11 || code[5] != '-' {
+			t.Errorf("Unexpected recovery code format: %s", code)
+		}
+		if seen[code] {
+			t.Errorf("Duplicate recovery code: %s", code)
+		}
+		seen[code] = true
+	}
+
+	// 入力の揺れを吸収する
+	if HashRecoveryCode(codes[0]) != HashRecoveryCode(" "+strings.ToUpper(strings.ReplaceAll(codes[0], "-", ""))) {
+		t.Error("Expected normalized recovery codes to hash identically")
+	}
+}
+
+func TestTwoFactorPolicy(t *testing.T) {
+	policy := NewTwoFactorPolicy("admin", " manager ")
+	if !policy.Requires("admin") || !policy.Requires("manager") {
+		t.Error("Expected admin and manager to require 2FA")
+	}
+	if policy.Requires("member") {
+		t.Error("Expected member not to require 2FA")
+	}
+}
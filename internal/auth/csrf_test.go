@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func okHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func TestCSRFMiddleware_AllowsSafeMethods(t *testing.T) {
+	auth := NewAuthService()
+	handler := auth.CSRFMiddleware(okHandler())
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/logs", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+}
+
+func TestCSRFMiddleware_SessionToken(t *testing.T) {
+	auth := NewAuthService()
+	handler := auth.CSRFMiddleware(okHandler())
+
+	token, err := auth.CreateSession("testuser")
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	session, _ := auth.GetSession(token)
+
+	tests := []struct {
+		name     string
+		header   string
+		form     string
+		expected int
+	}{
+		{"ヘッダーのトークン", session.CSRFToken, "", http.StatusOK},
+		{"フォームのトークン", "", session.CSRFToken, http.StatusOK},
+		{"トークンなし", "", "", http.StatusForbidden},
+		{"不正なトークン", "wrong", "", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			if tt.form != "" {
+				form.Set(CSRFFormField, tt.form)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/api/logs", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.AddCookie(&http.Cookie{Name: "session_token", Value: token})
+			if tt.header != "" {
+				req.Header.Set(CSRFHeader, tt.header)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d", tt.expected, w.Code)
+			}
+		})
+	}
+}
+
+func TestCSRFMiddleware_AnonymousDoubleSubmit(t *testing.T) {
+	auth := NewAuthService()
+	handler := auth.CSRFMiddleware(okHandler())
+
+	// ログインページ表示時にトークンとクッキーが発行される
+	w := httptest.NewRecorder()
+	token, err := auth.CSRFToken(w, httptest.NewRequest(http.MethodGet, "/login", nil))
+	if err != nil {
+		t.Fatalf("Failed to issue CSRF token: %v", err)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value != token {
+		t.Fatalf("Expected CSRF cookie with issued token")
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/login", nil)
+	req.AddCookie(cookies[0])
+	req.Header.Set(CSRFHeader, token)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+
+	// クッキーがなければ拒否される
+	req = httptest.NewRequest(http.MethodPost, "/login", nil)
+	req.Header.Set(CSRFHeader, token)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
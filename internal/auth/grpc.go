@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/audit"
+	"github.com/gensan0223/snulog/internal/repository"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const authorizationHeader = "authorization"
+
+// GRPCAuthenticator は authorization メタデータのトークンを検証し、呼び出し元を context に載せる。
+// トークンがない呼び出しは匿名として通し、権限の判定は各 RPC に任せる
+type GRPCAuthenticator struct {
+	signer *TokenSigner
+	users  repository.UserRepository
+}
+
+func NewGRPCAuthenticator(signer *TokenSigner, users repository.UserRepository) *GRPCAuthenticator {
+	return &GRPCAuthenticator{signer: signer, users: users}
+}
+
+func (a *GRPCAuthenticator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := a.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (a *GRPCAuthenticator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authenticate(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticatedStream は呼び出し元を載せた context をストリームの handler に渡す
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (a *GRPCAuthenticator) authenticate(ctx context.Context) (context.Context, error) {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		ctx = audit.WithSource(ctx, p.Addr.String())
+	}
+
+	token := bearerToken(ctx)
+	if token == "" {
+		return ctx, nil
+	}
+
+	username, issuedAt, err := a.signer.Verify(token)
+	if err != nil {
+		log.Printf("security: rejected grpc token: %v", err)
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	user, err := a.users.GetUserByUsername(username)
+	if err != nil {
+		log.Printf("security: grpc token for unknown user %q: %v", username, err)
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	// パスワードの変更・リセットより前に発行されたトークンは使えない。iat は秒単位なので変更時刻も秒に切り捨てて比べる
+	if issuedAt.Before(user.PasswordChangedAt.Truncate(time.Second)) {
+		log.Printf("security: grpc token for %q was issued before the password change", username)
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	return WithIdentity(ctx, Identity{UserID: user.ID, Username: user.Username, Role: user.Role, OrgID: user.OrgID}), nil
+}
+
+func bearerToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	for _, value := range md.Get(authorizationHeader) {
+		if token, ok := strings.CutPrefix(value, "Bearer "); ok {
+			return token
+		}
+	}
+	return ""
+}
+
+// OutgoingContext は gRPC クライアントの呼び出しにトークンを付与する
+func OutgoingContext(ctx context.Context, token string) context.Context {
+	if token == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, authorizationHeader, "Bearer "+token)
+}
@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/repository"
+	"github.com/gensan0223/snulog/internal/tenant"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type stubUserRepository struct {
+	repository.UserRepository
+	users map[string]*repository.User
+}
+
+func (r *stubUserRepository) GetUserByUsername(username string) (*repository.User, error) {
+	user, ok := r.users[username]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+func invokeWithToken(t *testing.T, a *GRPCAuthenticator, token string) (Identity, bool, error) {
+	t.Helper()
+	ctx := context.Background()
+	if token != "" {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(authorizationHeader, "Bearer "+token))
+	}
+
+	var identity Identity
+	var found bool
+	_, err := a.UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/logs.LogService/AddLogs"},
+		func(ctx context.Context, req any) (any, error) {
+			identity, found = IdentityFromContext(ctx)
+			return nil, nil
+		})
+	return identity, found, err
+}
+
+func TestGRPCAuthenticator(t *testing.T) {
+	signer := NewTokenSigner([]byte("secret"))
+	users := &stubUserRepository{users: map[string]*repository.User{
+		"admin": {ID: 1, Username: "admin", Role: repository.RoleAdmin, OrgID: 7},
+	}}
+	a := NewGRPCAuthenticator(signer, users)
+
+	t.Run("valid token", func(t *testing.T) {
+		token, _ := signer.Issue("admin", time.Minute)
+		identity, found, err := invokeWithToken(t, a, token)
+		if err != nil || !found {
+			t.Fatalf("Expected identity, got found=%v err=%v", found, err)
+		}
+		if identity.Role != repository.RoleAdmin || identity.UserID != 1 || identity.OrgID != 7 {
+			t.Errorf("Unexpected identity: %+v", identity)
+		}
+	})
+
+	t.Run("anonymous", func(t *testing.T) {
+		_, found, err := invokeWithToken(t, a, "")
+		if err != nil || found {
+			t.Errorf("トークンなしは匿名で通るべき: found=%v err=%v", found, err)
+		}
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		_, _, err := invokeWithToken(t, a, "garbage.token")
+		if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("Expected Unauthenticated, got %v", err)
+		}
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		token, _ := signer.Issue("ghost", time.Minute)
+		_, _, err := invokeWithToken(t, a, token)
+		if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("Expected Unauthenticated, got %v", err)
+		}
+	})
+
+	t.Run("issued before password change", func(t *testing.T) {
+		issuedAt := time.Now()
+		signer.now = func() time.Time { return issuedAt }
+		defer func() { signer.now = time.Now }()
+
+		token, _ := signer.Issue("admin", time.Hour)
+		users.users["admin"].PasswordChangedAt = issuedAt.Add(time.Minute).UTC()
+		defer func() { users.users["admin"].PasswordChangedAt = time.Time{} }()
+
+		signer.now = func() time.Time { return issuedAt.Add(2 * time.Minute) }
+		_, _, err := invokeWithToken(t, a, token)
+		if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("Expected Unauthenticated, got %v", err)
+		}
+
+		// 変更後に発行し直したトークンは使える
+		token, _ = signer.Issue("admin", time.Hour)
+		if _, found, err := invokeWithToken(t, a, token); err != nil || !found {
+			t.Errorf("Expected identity, got found=%v err=%v", found, err)
+		}
+	})
+}
+
+type stubServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *stubServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestGRPCAuthenticator_Stream(t *testing.T) {
+	signer := NewTokenSigner([]byte("secret"))
+	users := &stubUserRepository{users: map[string]*repository.User{
+		"alice": {ID: 2, Username: "alice", Role: repository.RoleMember, OrgID: 3},
+	}}
+	a := NewGRPCAuthenticator(signer, users)
+	info := &grpc.StreamServerInfo{FullMethod: "/logs.LogService/WatchLogs", IsServerStream: true}
+
+	token, _ := signer.Issue("alice", time.Minute)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(authorizationHeader, "Bearer "+token))
+	var identity Identity
+	var found bool
+	var orgID int64
+	err := a.StreamServerInterceptor()(nil, &stubServerStream{ctx: ctx}, info, func(srv any, ss grpc.ServerStream) error {
+		identity, found = IdentityFromContext(ss.Context())
+		orgID = tenant.OrgID(ss.Context())
+		return nil
+	})
+	if err != nil || !found || identity.Username != "alice" || orgID != 3 {
+		t.Errorf("Expected alice of org 3 on the stream context, got %+v org=%d found=%v err=%v", identity, orgID, found, err)
+	}
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(authorizationHeader, "Bearer garbage.token"))
+	err = a.StreamServerInterceptor()(nil, &stubServerStream{ctx: ctx}, info, func(srv any, ss grpc.ServerStream) error {
+		t.Error("handler should not be called")
+		return nil
+	})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated, got %v", err)
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	ctx := WithIdentity(context.Background(), Identity{Username: "alice", Role: repository.RoleMember})
+	if _, err := RequireRole(ctx, repository.RoleAdmin); err != ErrPermissionDenied {
+		t.Errorf("member should not pass admin check: %v", err)
+	}
+	if _, err := RequireRole(ctx, repository.RoleMember, repository.RoleAdmin); err != nil {
+		t.Errorf("member should pass: %v", err)
+	}
+	if _, err := RequireRole(context.Background(), repository.RoleMember); err != ErrPermissionDenied {
+		t.Errorf("anonymous should be denied: %v", err)
+	}
+}
+
+func TestWithIdentity_SetsTenant(t *testing.T) {
+	ctx := WithIdentity(context.Background(), Identity{Username: "alice", OrgID: 7})
+	if got := tenant.OrgID(ctx); got != 7 {
+		t.Errorf("Expected org 7, got %d", got)
+	}
+
+	ctx = WithIdentity(context.Background(), Identity{Username: "alice"})
+	if got := tenant.OrgID(ctx); got != tenant.DefaultOrgID {
+		t.Errorf("組織が未設定なら既定の組織として扱う: got %d", got)
+	}
+	if identity, _ := IdentityFromContext(ctx); identity.OrgID != tenant.DefaultOrgID {
+		t.Errorf("Expected default org in identity, got %d", identity.OrgID)
+	}
+}
+
+func TestIdentity_IsSystemAdmin(t *testing.T) {
+	tests := []struct {
+		identity Identity
+		want     bool
+	}{
+		{Identity{Role: repository.RoleAdmin, OrgID: tenant.DefaultOrgID}, true},
+		{Identity{Role: repository.RoleAdmin, OrgID: 2}, false},
+		{Identity{Role: repository.RoleManager, OrgID: tenant.DefaultOrgID}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.identity.IsSystemAdmin(); got != tt.want {
+			t.Errorf("IsSystemAdmin(%+v) = %v, want %v", tt.identity, got, tt.want)
+		}
+	}
+}
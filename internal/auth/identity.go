@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/gensan0223/snulog/internal/repository"
+	"github.com/gensan0223/snulog/internal/tenant"
+)
+
+var ErrPermissionDenied = errors.New("auth: permission denied")
+
+// Identity は gRPC 呼び出し元として認証されたユーザー
+type Identity struct {
+	UserID   int
+	Username string
+	Role     string
+	// OrgID は所属する組織。0 なら tenant.DefaultOrgID として扱う
+	OrgID int64
+}
+
+// IsSystemAdmin は既定の組織の管理者か。インスタンス全体の組織を管理できる
+func (i Identity) IsSystemAdmin() bool {
+	return i.Role == repository.RoleAdmin && (i.OrgID == 0 || i.OrgID == tenant.DefaultOrgID)
+}
+
+type identityKey struct{}
+
+// WithIdentity は呼び出し元と、その組織を tenant として context に載せる
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	if identity.OrgID == 0 {
+		identity.OrgID = tenant.DefaultOrgID
+	}
+	ctx = tenant.WithOrg(ctx, identity.OrgID)
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
+
+// RequireRole は呼び出し元が指定したロールのいずれかを持つことを確認する
+func RequireRole(ctx context.Context, roles ...string) (Identity, error) {
+	identity, ok := IdentityFromContext(ctx)
+	if !ok || !slices.Contains(roles, identity.Role) {
+		return identity, ErrPermissionDenied
+	}
+	return identity, nil
+}
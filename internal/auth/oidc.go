@@ -0,0 +1,215 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// 認可リクエストの有効期限（IdP でのログイン操作にかかる時間の上限）
+const oidcStateTTL = 10 * time.Minute
+
+// oidcStateCookie は認可リクエストを始めたブラウザを覚えておく Cookie。
+// コールバックの state と一致しなければ、他人が始めたログインとして拒否する
+const oidcStateCookie = "oidc_state"
+
+var (
+	ErrOIDCInvalidState    = errors.New("oidc: unknown or expired state")
+	ErrOIDCMissingClaim    = errors.New("oidc: username claim is missing")
+	ErrOIDCMissingIDToken  = errors.New("oidc: token response has no id_token")
+	ErrOIDCUnverifiedEmail = errors.New("oidc: email is not verified by the IdP")
+)
+
+// OIDCIdentity は ID トークンで確認できた IdP のユーザー。
+// Subject（sub クレーム）は IdP の中で変わらない ID で、users.oidc_subject と照合する
+type OIDCIdentity struct {
+	Subject  string
+	Username string
+}
+
+type OIDCConfig struct {
+	IssuerURL     string
+	ClientID      string
+	ClientSecret  string
+	RedirectURL   string
+	Scopes        []string
+	UsernameClaim string
+	AutoProvision bool
+}
+
+type oidcPending struct {
+	verifier  string
+	nonce     string
+	createdAt time.Time
+}
+
+type OIDCProvider struct {
+	config   OIDCConfig
+	oauth2   oauth2.Config
+	verifier *oidc.IDTokenVerifier
+	pending  map[string]*oidcPending
+	mutex    sync.Mutex
+}
+
+// NewOIDCProvider は discovery で IdP のエンドポイントと JWKS を取得する
+func NewOIDCProvider(ctx context.Context, cfg OIDCConfig) (*OIDCProvider, error) {
+	provider, err := oidc.NewProvider(ctx, cfg.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+
+	if cfg.UsernameClaim == "" {
+		cfg.UsernameClaim = "preferred_username"
+	}
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"profile", "email"}
+	}
+
+	return &OIDCProvider{
+		config: cfg,
+		oauth2: oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       append([]string{oidc.ScopeOpenID}, scopes...),
+		},
+		verifier: provider.Verifier(&oidc.Config{ClientID: cfg.ClientID}),
+		pending:  make(map[string]*oidcPending),
+	}, nil
+}
+
+func (p *OIDCProvider) AutoProvision() bool {
+	return p.config.AutoProvision
+}
+
+// AuthCodeURL は PKCE の code_verifier と nonce を生成し、IdP の認可 URL と state を返す
+func (p *OIDCProvider) AuthCodeURL() (string, string, error) {
+	state, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.cleanupLocked()
+	p.pending[state] = &oidcPending{
+		verifier:  verifier,
+		nonce:     nonce,
+		createdAt: time.Now(),
+	}
+
+	url := p.oauth2.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier))
+	return url, state, nil
+}
+
+// SetStateCookie は state をブラウザに保存する。IdP からのリダイレクトでも送られるよう SameSite=Lax にする
+func (p *OIDCProvider) SetStateCookie(w http.ResponseWriter, state string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     "/auth/oidc",
+		MaxAge:   int(oidcStateTTL.Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// CheckStateCookie はコールバックの state がこのブラウザで始めた認可リクエストのものかを確認し、Cookie を消す
+func (p *OIDCProvider) CheckStateCookie(w http.ResponseWriter, r *http.Request, state string) error {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    "",
+		Path:     "/auth/oidc",
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+	cookie, err := r.Cookie(oidcStateCookie)
+	if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		return ErrOIDCInvalidState
+	}
+	return nil
+}
+
+// Exchange は認可コードをトークンに交換し、ID トークンを検証して IdP のユーザーを返す
+func (p *OIDCProvider) Exchange(ctx context.Context, state, code string) (*OIDCIdentity, error) {
+	p.mutex.Lock()
+	pending, exists := p.pending[state]
+	delete(p.pending, state)
+	p.mutex.Unlock()
+
+	if !exists || time.Since(pending.createdAt) > oidcStateTTL {
+		return nil, ErrOIDCInvalidState
+	}
+
+	token, err := p.oauth2.Exchange(ctx, code, oauth2.VerifierOption(pending.verifier))
+	if err != nil {
+		return nil, fmt.Errorf("oidc token exchange: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, ErrOIDCMissingIDToken
+	}
+
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("oidc id_token verification: %w", err)
+	}
+	if idToken.Nonce != pending.nonce {
+		return nil, errors.New("oidc: nonce mismatch")
+	}
+
+	var claims map[string]any
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("oidc claims: %w", err)
+	}
+
+	if idToken.Subject == "" {
+		return nil, errors.New("oidc: sub claim is missing")
+	}
+	username, err := usernameFromClaims(claims, p.config.UsernameClaim)
+	if err != nil {
+		return nil, err
+	}
+	return &OIDCIdentity{Subject: idToken.Subject, Username: username}, nil
+}
+
+func (p *OIDCProvider) cleanupLocked() {
+	for state, pending := range p.pending {
+		if time.Since(pending.createdAt) > oidcStateTTL {
+			delete(p.pending, state)
+		}
+	}
+}
+
+// usernameFromClaims はクレームからユーザー名を取り出す。email クレームは IdP が確認済みのものだけを、
+// ドメインを含めたまま小文字にして使う（@ 以前だけでは別のドメインの同じ名前と区別できない）
+func usernameFromClaims(claims map[string]any, claim string) (string, error) {
+	value, ok := claims[claim].(string)
+	if !ok || value == "" {
+		return "", ErrOIDCMissingClaim
+	}
+	if claim == "email" {
+		if verified, _ := claims["email_verified"].(bool); !verified {
+			return "", ErrOIDCUnverifiedEmail
+		}
+		value = strings.ToLower(value)
+	}
+	return value, nil
+}
@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gensan0223/snulog/internal/auth/oidctest"
+)
+
+// authorize は認可 URL にアクセスし、IdP からリダイレクトされた code と state を返す
+func authorize(t *testing.T, authURL string) (string, string) {
+	t.Helper()
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("Failed to call authorize endpoint: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("Invalid redirect location: %v", err)
+	}
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+func newTestOIDCProvider(t *testing.T, idp *oidctest.IdP, claim string) *OIDCProvider {
+	t.Helper()
+
+	provider, err := NewOIDCProvider(context.Background(), OIDCConfig{
+		IssuerURL:     idp.URL,
+		ClientID:      "snulog",
+		ClientSecret:  "secret",
+		RedirectURL:   "http://localhost:8080/auth/oidc/callback",
+		UsernameClaim: claim,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create OIDC provider: %v", err)
+	}
+	return provider
+}
+
+func TestOIDCProvider_Exchange(t *testing.T) {
+	idp := oidctest.NewIdP(map[string]any{"preferred_username": "alice"})
+	defer idp.Close()
+
+	provider := newTestOIDCProvider(t, idp, "")
+
+	authURL, state, err := provider.AuthCodeURL()
+	if err != nil {
+		t.Fatalf("Failed to build auth URL: %v", err)
+	}
+
+	code, returnedState := authorize(t, authURL)
+	if returnedState != state {
+		t.Fatalf("Expected state %s, got %s", state, returnedState)
+	}
+
+	identity, err := provider.Exchange(context.Background(), state, code)
+	if err != nil {
+		t.Fatalf("Failed to exchange code: %v", err)
+	}
+	if identity.Username != "alice" || identity.Subject != "test-subject" {
+		t.Errorf("Expected alice (test-subject), got %+v", identity)
+	}
+
+	// 同じ state は再利用できない
+	if _, err := provider.Exchange(context.Background(), state, code); !errors.Is(err, ErrOIDCInvalidState) {
+		t.Errorf("Expected ErrOIDCInvalidState on replay, got %v", err)
+	}
+}
+
+func TestOIDCProvider_EmailClaim(t *testing.T) {
+	idp := oidctest.NewIdP(map[string]any{"email": "Bob@Example.com", "email_verified": true})
+	defer idp.Close()
+
+	provider := newTestOIDCProvider(t, idp, "email")
+
+	authURL, state, err := provider.AuthCodeURL()
+	if err != nil {
+		t.Fatalf("Failed to build auth URL: %v", err)
+	}
+	code, _ := authorize(t, authURL)
+
+	identity, err := provider.Exchange(context.Background(), state, code)
+	if err != nil {
+		t.Fatalf("Failed to exchange code: %v", err)
+	}
+	// @ 以前だけにすると別のドメインの bob と区別できない
+	if identity.Username != "bob@example.com" {
+		t.Errorf("Expected username bob@example.com, got %s", identity.Username)
+	}
+}
+
+func TestOIDCProvider_UnverifiedEmail(t *testing.T) {
+	for _, claims := range []map[string]any{
+		{"email": "alice@example.com"},
+		{"email": "alice@example.com", "email_verified": false},
+		{"email": "alice@example.com", "email_verified": "true"},
+	} {
+		idp := oidctest.NewIdP(claims)
+		provider := newTestOIDCProvider(t, idp, "email")
+
+		authURL, state, err := provider.AuthCodeURL()
+		if err != nil {
+			t.Fatalf("Failed to build auth URL: %v", err)
+		}
+		code, _ := authorize(t, authURL)
+
+		if _, err := provider.Exchange(context.Background(), state, code); !errors.Is(err, ErrOIDCUnverifiedEmail) {
+			t.Errorf("%v: expected ErrOIDCUnverifiedEmail, got %v", claims, err)
+		}
+		idp.Close()
+	}
+}
+
+func TestOIDCProvider_MissingClaim(t *testing.T) {
+	idp := oidctest.NewIdP(map[string]any{"email": "bob@example.com"})
+	defer idp.Close()
+
+	provider := newTestOIDCProvider(t, idp, "")
+
+	authURL, state, err := provider.AuthCodeURL()
+	if err != nil {
+		t.Fatalf("Failed to build auth URL: %v", err)
+	}
+	code, _ := authorize(t, authURL)
+
+	if _, err := provider.Exchange(context.Background(), state, code); !errors.Is(err, ErrOIDCMissingClaim) {
+		t.Errorf("Expected ErrOIDCMissingClaim, got %v", err)
+	}
+}
+
+func TestOIDCProvider_RejectsUnknownSigningKey(t *testing.T) {
+	idp := oidctest.NewIdP(map[string]any{"preferred_username": "alice"})
+	defer idp.Close()
+	idp.ForeignKey = true
+
+	provider := newTestOIDCProvider(t, idp, "")
+
+	authURL, state, err := provider.AuthCodeURL()
+	if err != nil {
+		t.Fatalf("Failed to build auth URL: %v", err)
+	}
+	code, _ := authorize(t, authURL)
+
+	if _, err := provider.Exchange(context.Background(), state, code); err == nil {
+		t.Error("Expected ID token signed with an unknown key to be rejected")
+	}
+}
+
+func TestOIDCProvider_UnknownState(t *testing.T) {
+	idp := oidctest.NewIdP(map[string]any{"preferred_username": "alice"})
+	defer idp.Close()
+
+	provider := newTestOIDCProvider(t, idp, "")
+
+	if _, err := provider.Exchange(context.Background(), "unknown", "code"); !errors.Is(err, ErrOIDCInvalidState) {
+		t.Errorf("Expected ErrOIDCInvalidState, got %v", err)
+	}
+}
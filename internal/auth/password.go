@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// PasswordResetTTL はパスワードリセット用リンクの有効期限
+const PasswordResetTTL = time.Hour
+
+// PasswordPolicyError はパスワードがポリシーを満たさない理由を表す（画面にそのまま表示する）
+type PasswordPolicyError struct {
+	Reason string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return e.Reason
+}
+
+type PasswordPolicy struct {
+	MinLength int
+}
+
+var DefaultPasswordPolicy = PasswordPolicy{MinLength: 12}
+
+// 初期データやよく使われる推測しやすいパスワード
+var commonPasswords = map[string]bool{
+	"password":     true,
+	"password1":    true,
+	"password123":  true,
+	"123456789012": true,
+	"qwertyuiop12": true,
+	"snulog":       true,
+	"snulog123456": true,
+}
+
+func (p PasswordPolicy) Validate(username, password string) error {
+	if len([]rune(password)) < p.MinLength {
+		return &PasswordPolicyError{Reason: "パスワードは" + strconv.Itoa(p.MinLength) + "文字以上にしてください"}
+	}
+
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	if !hasLetter || !hasDigit {
+		return &PasswordPolicyError{Reason: "パスワードには英字と数字の両方を含めてください"}
+	}
+
+	lower := strings.ToLower(password)
+	if commonPasswords[lower] {
+		return &PasswordPolicyError{Reason: "推測されやすいパスワードは使用できません"}
+	}
+	if username != "" && strings.Contains(lower, strings.ToLower(username)) {
+		return &PasswordPolicyError{Reason: "パスワードにユーザー名を含めないでください"}
+	}
+	return nil
+}
+
+// GeneratePasswordResetToken はメールで送るトークンと、DB に保存するハッシュを返す
+func GeneratePasswordResetToken() (string, string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	return token, HashPasswordResetToken(token), nil
+}
+
+func HashPasswordResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// DeleteUserSessions はパスワード変更時などにユーザーのすべてのセッションを無効にする
+func (a *AuthService) DeleteUserSessions(username string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	for token, session := range a.sessions {
+		if session.Username == username {
+			delete(a.sessions, token)
+		}
+	}
+	for token, pending := range a.pendingLogins {
+		if pending.Username == username {
+			delete(a.pendingLogins, token)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		valid    bool
+	}{
+		{"十分な長さと文字種", "correct horse 42", true},
+		{"短すぎる", "abc123", false},
+		{"数字なし", "correcthorsebattery", false},
+		{"英字なし", "123456789012345", false},
+		{"初期パスワード", "password", false},
+		{"ユーザー名を含む", "Alice-2025-spring", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := DefaultPasswordPolicy.Validate("alice", tt.password)
+			if tt.valid && err != nil {
+				t.Errorf("Expected %q to be valid, got %v", tt.password, err)
+			}
+			if !tt.valid {
+				var policyErr *PasswordPolicyError
+				if !errors.As(err, &policyErr) {
+					t.Errorf("Expected PasswordPolicyError for %q, got %v", tt.password, err)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthService_DeleteUserSessions(t *testing.T) {
+	auth := NewAuthService()
+
+	alice1, _ := auth.CreateSession("alice")
+	alice2, _ := auth.CreateSession("alice")
+	bob, _ := auth.CreateSession("bob")
+
+	auth.DeleteUserSessions("alice")
+
+	if _, ok := auth.GetSession(alice1); ok {
+		t.Error("Expected alice session 1 to be deleted")
+	}
+	if _, ok := auth.GetSession(alice2); ok {
+		t.Error("Expected alice session 2 to be deleted")
+	}
+	if _, ok := auth.GetSession(bob); !ok {
+		t.Error("Expected bob session to remain")
+	}
+}
+
+func TestPasswordResetToken(t *testing.T) {
+	token, hash, err := GeneratePasswordResetToken()
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	if token == hash {
+		t.Error("Expected stored hash to differ from token")
+	}
+	if HashPasswordResetToken(token) != hash {
+		t.Error("Expected hash to be reproducible")
+	}
+}
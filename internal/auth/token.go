@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ServiceTokenTTL は Web サーバーが gRPC 呼び出しごとに発行するトークンの有効期限
+const ServiceTokenTTL = time.Minute
+
+// CLITokenTTL は Login RPC で CLI に発行するトークンの有効期限
+const CLITokenTTL = 30 * 24 * time.Hour
+
+var (
+	ErrInvalidToken = errors.New("auth: invalid token")
+	ErrExpiredToken = errors.New("auth: token expired")
+)
+
+type tokenClaims struct {
+	Subject   string `json:"sub"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// TokenSigner は gRPC 呼び出しに使う HMAC 署名付きトークンを発行・検証する
+type TokenSigner struct {
+	secret []byte
+	now    func() time.Time
+}
+
+func NewTokenSigner(secret []byte) *TokenSigner {
+	return &TokenSigner{secret: secret, now: time.Now}
+}
+
+// TokenSignerFromEnv は SNULOG_TOKEN_SECRET を使う。未設定の場合は起動しない。
+// SNULOG_DEV=true のときだけ、プロセスごとにランダムな鍵を生成して起動する
+// （Web サーバーと gRPC サーバーを別プロセスで動かす場合は鍵が一致しないため、SNULOG_TOKEN_SECRET を設定する）
+func TokenSignerFromEnv() *TokenSigner {
+	if secret := os.Getenv("SNULOG_TOKEN_SECRET"); secret != "" {
+		return NewTokenSigner([]byte(secret))
+	}
+
+	dev, _ := strconv.ParseBool(os.Getenv("SNULOG_DEV"))
+	if !dev {
+		log.Fatal("SNULOG_TOKEN_SECRET is not set (set SNULOG_DEV=true to use a random per-process secret for development)")
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatalf("failed to generate token secret: %v", err)
+	}
+	log.Printf("warn: SNULOG_TOKEN_SECRET is not set, using a random per-process secret (SNULOG_DEV=true)")
+	return NewTokenSigner(secret)
+}
+
+func (s *TokenSigner) Issue(username string, ttl time.Duration) (string, error) {
+	now := s.now()
+	payload, err := json.Marshal(tokenClaims{
+		Subject:   username,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + s.sign(encoded), nil
+}
+
+// Verify は署名と有効期限を確認してユーザー名と発行日時を返す
+func (s *TokenSigner) Verify(token string) (string, time.Time, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.sign(encoded))) {
+		return "", time.Time{}, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", time.Time{}, ErrInvalidToken
+	}
+	var claims tokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Subject == "" {
+		return "", time.Time{}, ErrInvalidToken
+	}
+	if s.now().Unix() >= claims.ExpiresAt {
+		return "", time.Time{}, ErrExpiredToken
+	}
+	return claims.Subject, time.Unix(claims.IssuedAt, 0), nil
+}
+
+func (s *TokenSigner) sign(encoded string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
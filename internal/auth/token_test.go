@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenSigner_IssueAndVerify(t *testing.T) {
+	signer := NewTokenSigner([]byte("secret"))
+	now := time.Unix(1700000000, 0)
+	signer.now = func() time.Time { return now }
+
+	token, err := signer.Issue("alice", time.Minute)
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+
+	username, issuedAt, err := signer.Verify(token)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if username != "alice" {
+		t.Errorf("Expected alice, got %q", username)
+	}
+	if !issuedAt.Equal(now) {
+		t.Errorf("Expected issued at %v, got %v", now, issuedAt)
+	}
+}
+
+func TestTokenSigner_RejectsTamperedToken(t *testing.T) {
+	signer := NewTokenSigner([]byte("secret"))
+	token, _ := signer.Issue("alice", time.Minute)
+
+	other := NewTokenSigner([]byte("other-secret"))
+	if _, _, err := other.Verify(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("別の鍵で署名されたトークンは拒否されるべき: %v", err)
+	}
+
+	// alice の署名のまま中身だけ admin に書き換える
+	forged, _ := signer.Issue("admin", time.Minute)
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+	_, signature, _ := strings.Cut(token, ".")
+	if _, _, err := signer.Verify(forgedPayload + "." + signature); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("署名を差し替えたトークンは拒否されるべき: %v", err)
+	}
+
+	for _, bad := range []string{"", "no-dot", "abc.def"} {
+		if _, _, err := signer.Verify(bad); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Verify(%q) should fail with ErrInvalidToken, got %v", bad, err)
+		}
+	}
+}
+
+func TestTokenSigner_Expired(t *testing.T) {
+	signer := NewTokenSigner([]byte("secret"))
+	now := time.Now()
+	signer.now = func() time.Time { return now }
+
+	token, _ := signer.Issue("alice", time.Minute)
+
+	signer.now = func() time.Time { return now.Add(2 * time.Minute) }
+	if _, _, err := signer.Verify(token); !errors.Is(err, ErrExpiredToken) {
+		t.Errorf("Expected ErrExpiredToken, got %v", err)
+	}
+}
+
+func TestTokenSignerFromEnv_DevSecret(t *testing.T) {
+	t.Setenv("SNULOG_TOKEN_SECRET", "")
+	t.Setenv("SNULOG_DEV", "true")
+
+	// 開発用の鍵はプロセスごとにランダムで、別の署名器のトークンは通らない
+	a, b := TokenSignerFromEnv(), TokenSignerFromEnv()
+	token, _ := a.Issue("alice", time.Minute)
+	if _, _, err := a.Verify(token); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if _, _, err := b.Verify(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken with another random secret, got %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// RFC 4226 Appendix D
+func TestHOTP_RFC4226Vectors(t *testing.T) {
+	key := []byte("12345678901234567890")
+	expected := []string{
+		"755224", "287082", "359152", "969429", "338314",
+		"254676", "287922", "162583", "399871", "520489",
+	}
+
+	for counter, want := range expected {
+		if got := HOTP(key, uint64(counter), 6, SHA1); got != want {
+			t.Errorf("counter %d: expected %s, got %s", counter, want, got)
+		}
+	}
+}
+
+// RFC 6238 Appendix B
+func TestGenerate_RFC6238Vectors(t *testing.T) {
+	seeds := map[Algorithm]string{
+		SHA1:   "12345678901234567890",
+		SHA256: "12345678901234567890123456789012",
+		SHA512: "1234567890123456789012345678901234567890123456789012345678901234",
+	}
+
+	tests := []struct {
+		unix      int64
+		algorithm Algorithm
+		expected  string
+	}{
+		{59, SHA1, "94287082"},
+		{59, SHA256, "46119246"},
+		{59, SHA512, "90693936"},
+		{1111111109, SHA1, "07081804"},
+		{1111111109, SHA256, "68084774"},
+		{1111111109, SHA512, "25091201"},
+		{1111111111, SHA1, "14050471"},
+		{1111111111, SHA256, "67062674"},
+		{1111111111, SHA512, "99943326"},
+		{1234567890, SHA1, "89005924"},
+		{1234567890, SHA256, "91819424"},
+		{1234567890, SHA512, "93441116"},
+		{2000000000, SHA1, "69279037"},
+		{2000000000, SHA256, "90698825"},
+		{2000000000, SHA512, "38618901"},
+		{20000000000, SHA1, "65353130"},
+		{20000000000, SHA256, "77737706"},
+		{20000000000, SHA512, "47863826"},
+	}
+
+	for _, tt := range tests {
+		secret := base32.StdEncoding.EncodeToString([]byte(seeds[tt.algorithm]))
+		opts := Options{Digits: 8, Period: 30 * time.Second, Algorithm: tt.algorithm}
+
+		got, err := Generate(secret, time.Unix(tt.unix, 0), opts)
+		if err != nil {
+			t.Fatalf("Generate failed: %v", err)
+		}
+		if got != tt.expected {
+			t.Errorf("%s at %d: expected %s, got %s", tt.algorithm, tt.unix, tt.expected, got)
+		}
+	}
+}
+
+func TestValidate_Skew(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("Failed to generate secret: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+
+	code, err := Generate(secret, now, DefaultOptions)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	if !Validate(code, secret, now, DefaultOptions) {
+		t.Error("Expected current code to be valid")
+	}
+	if !Validate(code, secret, now.Add(30*time.Second), DefaultOptions) {
+		t.Error("Expected code from previous step to be valid")
+	}
+	if Validate(code, secret, now.Add(90*time.Second), DefaultOptions) {
+		t.Error("Expected code outside skew to be invalid")
+	}
+	if Validate("12345", secret, now, DefaultOptions) {
+		t.Error("Expected code with wrong length to be invalid")
+	}
+	if Validate(code, "not base32!", now, DefaultOptions) {
+		t.Error("Expected invalid secret to fail validation")
+	}
+}
+
+func TestKeyURI(t *testing.T) {
+	uri := KeyURI("snulog", "alice", "JBSWY3DPEHPK3PXP", DefaultOptions)
+	expected := "otpauth://totp/snulog:alice?algorithm=SHA1&digits=6&issuer=snulog&period=30&secret=JBSWY3DPEHPK3PXP"
+	if uri != expected {
+		t.Errorf("Expected %s, got %s", expected, uri)
+	}
+}
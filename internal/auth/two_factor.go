@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/auth/totp"
+)
+
+const (
+	pendingLoginCookie = "mfa_token"
+	pendingLoginTTL    = 5 * time.Minute
+	enrollmentTTL      = 10 * time.Minute
+	RecoveryCodeCount  = 10
+)
+
+// PendingLogin はパスワード認証済みで 2 段階目の認証を待っている状態
+type PendingLogin struct {
+	Username  string
+	CreatedAt time.Time
+}
+
+type enrollment struct {
+	secret    string
+	createdAt time.Time
+}
+
+// TwoFactorPolicy は 2 段階認証を必須にするロールを表す
+type TwoFactorPolicy struct {
+	requiredRoles map[string]bool
+}
+
+func NewTwoFactorPolicy(roles ...string) TwoFactorPolicy {
+	policy := TwoFactorPolicy{requiredRoles: make(map[string]bool)}
+	for _, role := range roles {
+		if role = strings.TrimSpace(role); role != "" {
+			policy.requiredRoles[role] = true
+		}
+	}
+	return policy
+}
+
+func (p TwoFactorPolicy) Requires(role string) bool {
+	return p.requiredRoles[role]
+}
+
+func (a *AuthService) CreatePendingLogin(username string) (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.pendingLogins[token] = &PendingLogin{
+		Username:  username,
+		CreatedAt: time.Now(),
+	}
+	return token, nil
+}
+
+func (a *AuthService) GetPendingLoginFromRequest(r *http.Request) (*PendingLogin, bool) {
+	cookie, err := r.Cookie(pendingLoginCookie)
+	if err != nil {
+		return nil, false
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	pending, exists := a.pendingLogins[cookie.Value]
+	if !exists {
+		return nil, false
+	}
+	if time.Since(pending.CreatedAt) > pendingLoginTTL {
+		delete(a.pendingLogins, cookie.Value)
+		return nil, false
+	}
+	return pending, true
+}
+
+// CompletePendingLogin は 2 段階目の認証が済んだ保留ログインを破棄する
+func (a *AuthService) CompletePendingLogin(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(pendingLoginCookie); err == nil {
+		a.mutex.Lock()
+		delete(a.pendingLogins, cookie.Value)
+		a.mutex.Unlock()
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     pendingLoginCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+}
+
+func (a *AuthService) SetPendingLoginCookie(w http.ResponseWriter, token string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     pendingLoginCookie,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int(pendingLoginTTL.Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// BeginTOTPEnrollment は登録途中の共有鍵を生成して保持する。確認コードの検証が済むまで有効にはならない
+func (a *AuthService) BeginTOTPEnrollment(username string) (string, error) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", err
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.enrollments[username] = &enrollment{
+		secret:    secret,
+		createdAt: time.Now(),
+	}
+	return secret, nil
+}
+
+func (a *AuthService) TOTPEnrollmentSecret(username string) (string, bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	e, exists := a.enrollments[username]
+	if !exists || time.Since(e.createdAt) > enrollmentTTL {
+		delete(a.enrollments, username)
+		return "", false
+	}
+	return e.secret, true
+}
+
+func (a *AuthService) FinishTOTPEnrollment(username string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	delete(a.enrollments, username)
+}
+
+// GenerateRecoveryCodes は xxxxx-xxxxx 形式のリカバリーコードを生成する
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	codes := make([]string, n)
+	for i := range codes {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(encoding.EncodeToString(b))[:10]
+		codes[i] = code[:5] + "-" + code[5:]
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode は入力の揺れ（大文字小文字、ハイフン、空白）を正規化してハッシュする
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,108 @@
+// Package compcache はシェルの補完でサーバーから取得した候補を手元のディレクトリにしばらく保存する。
+// タブを押すたびにサーバーへ問い合わせないので補完が速く、サーバーに届かないときは古い候補で補う
+package compcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	pb "github.com/gensan0223/snulog/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// DefaultTTL は候補をサーバーに問い合わせ直すまでの時間
+const DefaultTTL = time.Minute
+
+type Cache struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time
+}
+
+func New(dir string, ttl time.Duration) *Cache {
+	return &Cache{dir: dir, ttl: ttl, now: time.Now}
+}
+
+// Key は接続先や認証情報などの parts から、ファイル名に使えるキーを作る。トークンをそのまま書かないようハッシュにする
+func Key(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:12])
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// load は保存した候補と、それが ttl 以内に保存されたかを返す
+func (c *Cache) load(key string) (*pb.CompleteResponse, bool, error) {
+	info, err := os.Stat(c.path(key))
+	if err != nil {
+		return nil, false, err
+	}
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false, err
+	}
+	res := &pb.CompleteResponse{}
+	if err := protojson.Unmarshal(data, res); err != nil {
+		return nil, false, err
+	}
+	return res, c.now().Sub(info.ModTime()) < c.ttl, nil
+}
+
+// Put は候補を保存する。書きかけのファイルを読まないよう、別名で書いてから置き換える
+func (c *Cache) Put(key string, res *pb.CompleteResponse) error {
+	data, err := protojson.Marshal(res)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(key))
+}
+
+// Fetch は ttl 以内に保存した候補があればそれを、なければ fetch の結果を保存して返す。
+// fetch が失敗したときは古い候補があればそれを返す
+func (c *Cache) Fetch(key string, fetch func() (*pb.CompleteResponse, error)) (*pb.CompleteResponse, error) {
+	cached, fresh, err := c.load(key)
+	if err == nil && fresh {
+		return cached, nil
+	}
+	res, fetchErr := fetch()
+	if fetchErr != nil {
+		if cached != nil {
+			return cached, nil
+		}
+		return nil, fetchErr
+	}
+	// 保存に失敗しても候補は返す。次の補完で問い合わせ直すだけ
+	_ = c.Put(key, res)
+	return res, nil
+}
+
+// Clear は保存したすべての候補を消す
+func (c *Cache) Clear() error {
+	err := os.RemoveAll(c.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,65 @@
+package compcache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/gensan0223/snulog/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func response(values ...string) *pb.CompleteResponse {
+	res := &pb.CompleteResponse{}
+	for _, value := range values {
+		res.Completions = append(res.Completions, &pb.Completion{Value: value})
+	}
+	return res
+}
+
+func TestFetch(t *testing.T) {
+	cache := New(t.TempDir(), time.Minute)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	key := Key("localhost:50051", "token", "user")
+
+	calls := 0
+	fetch := func() (*pb.CompleteResponse, error) {
+		calls++
+		return response("alice", "bob"), nil
+	}
+	res, err := cache.Fetch(key, fetch)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(response("alice", "bob"), res))
+
+	res, err = cache.Fetch(key, fetch)
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls, "ttl 以内は問い合わせない")
+	assert.Len(t, res.Completions, 2)
+
+	now = now.Add(2 * time.Minute)
+	_, err = cache.Fetch(key, fetch)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls, "ttl を過ぎたら問い合わせ直す")
+
+	now = now.Add(2 * time.Minute)
+	res, err = cache.Fetch(key, func() (*pb.CompleteResponse, error) { return nil, errors.New("unavailable") })
+	require.NoError(t, err, "サーバーに届かなければ古い候補を使う")
+	assert.Len(t, res.Completions, 2)
+
+	_, err = cache.Fetch(Key("other"), func() (*pb.CompleteResponse, error) { return nil, errors.New("unavailable") })
+	assert.Error(t, err)
+
+	require.NoError(t, cache.Clear())
+	_, err = cache.Fetch(key, fetch)
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, Key("a", "b"), Key("a", "b"))
+	assert.NotEqual(t, Key("a", "b"), Key("ab"))
+	assert.NotContains(t, Key("secret-token"), "secret")
+}
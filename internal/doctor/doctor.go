@@ -0,0 +1,305 @@
+// Package doctor は snulog の CLI からサーバーまでの経路を順に確かめる。
+// 名前解決、TCP、TLS のハンドシェイク、gRPC のヘルスチェック、Ping RPC、トークン、バージョン、時刻のずれを調べ、
+// ログを書き込むなどの副作用のある RPC は呼ばない
+package doctor
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/auth"
+	"github.com/gensan0223/snulog/internal/util"
+	"github.com/gensan0223/snulog/internal/version"
+	pb "github.com/gensan0223/snulog/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+type Status string
+
+const (
+	OK   Status = "ok"
+	Warn Status = "warn"
+	Fail Status = "fail"
+	// Skip は前のチェックが失敗したなどの理由で実行しなかった
+	Skip Status = "skip"
+)
+
+// チェックの名前
+const (
+	CheckDNS     = "dns"
+	CheckTCP     = "tcp"
+	CheckTLS     = "tls"
+	CheckHealth  = "health"
+	CheckPing    = "ping"
+	CheckAuth    = "auth"
+	CheckVersion = "version"
+	CheckClock   = "clock"
+)
+
+const (
+	// 証明書の期限がこれより近ければ警告する
+	certExpiryWarning = 14 * 24 * time.Hour
+	// 時刻のずれがこれ以上なら警告、clockSkewFail 以上なら失敗にする。ログの時刻は CLI の時計で付ける
+	clockSkewWarn = 2 * time.Second
+	clockSkewFail = time.Minute
+)
+
+// Result は 1 つのチェックの結果
+type Result struct {
+	Check    string
+	Status   Status
+	Detail   string
+	Duration time.Duration
+}
+
+type Options struct {
+	// Server は host:port。dns:/// のようなスキームが付いていてもよい
+	Server string
+	// TLS が nil なら平文で接続する
+	TLS *tls.Config
+	// Token は snulog login で保存したトークン。空なら未ログイン
+	Token string
+	// Timeout は 1 つのチェックにかける時間の上限
+	Timeout       time.Duration
+	ClientVersion string
+	ClientAPI     int32
+	Resolver      *net.Resolver
+	Now           func() time.Time
+}
+
+// runner はチェックの結果を集める
+type runner struct {
+	opts    Options
+	results []Result
+	// failed は後のチェックを実行できない失敗があったか
+	failed bool
+}
+
+// check は fn を実行して結果を記録する。前に失敗があれば実行せずに skip にする
+func (r *runner) check(ctx context.Context, name string, fn func(ctx context.Context) (Status, string)) Status {
+	if r.failed {
+		r.results = append(r.results, Result{Check: name, Status: Skip, Detail: "skipped after an earlier failure"})
+		return Skip
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.opts.Timeout)
+	defer cancel()
+	start := r.opts.Now()
+	s, detail := fn(ctx)
+	r.results = append(r.results, Result{Check: name, Status: s, Detail: detail, Duration: r.opts.Now().Sub(start)})
+	return s
+}
+
+// Run はすべてのチェックを順に実行して結果を返す。接続できないなど後のチェックができない失敗があれば、残りは skip にする
+func Run(ctx context.Context, opts Options) []Result {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	if opts.Resolver == nil {
+		opts.Resolver = net.DefaultResolver
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	r := &runner{opts: opts}
+	address := hostPort(opts.Server)
+	host, _, err := net.SplitHostPort(address)
+
+	if r.check(ctx, CheckDNS, func(ctx context.Context) (Status, string) {
+		if err != nil {
+			return Fail, fmt.Sprintf("invalid server address %q: %v", opts.Server, err)
+		}
+		if net.ParseIP(host) != nil {
+			return OK, host + " is an IP address"
+		}
+		addrs, err := opts.Resolver.LookupHost(ctx, host)
+		if err != nil {
+			return Fail, err.Error()
+		}
+		return OK, host + " → " + strings.Join(addrs, ", ")
+	}) == Fail {
+		r.failed = true
+	}
+
+	if r.check(ctx, CheckTCP, func(ctx context.Context) (Status, string) {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return Fail, err.Error()
+		}
+		defer util.CloseWithLog(conn)
+		return OK, "connected to " + conn.RemoteAddr().String()
+	}) == Fail {
+		r.failed = true
+	}
+
+	if r.check(ctx, CheckTLS, func(ctx context.Context) (Status, string) {
+		if opts.TLS == nil {
+			return Skip, "plaintext connection (TLS is not configured)"
+		}
+		return checkTLS(ctx, address, host, opts.TLS)
+	}) == Fail {
+		r.failed = true
+	}
+
+	var creds credentials.TransportCredentials = insecure.NewCredentials()
+	if opts.TLS != nil {
+		creds = credentials.NewTLS(opts.TLS)
+	}
+	conn, connErr := grpc.NewClient(address, grpc.WithTransportCredentials(creds))
+	if connErr == nil {
+		defer util.CloseWithLog(conn)
+	}
+
+	if r.check(ctx, CheckHealth, func(ctx context.Context) (Status, string) {
+		if connErr != nil {
+			return Fail, connErr.Error()
+		}
+		res, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: pb.LogService_ServiceDesc.ServiceName})
+		if status.Code(err) == codes.Unimplemented {
+			return Warn, "the server does not expose the gRPC health service (older server?)"
+		}
+		if err != nil {
+			return Fail, status.Convert(err).Message()
+		}
+		if res.Status != healthpb.HealthCheckResponse_SERVING {
+			return Fail, "service is " + res.Status.String()
+		}
+		return OK, pb.LogService_ServiceDesc.ServiceName + " is SERVING"
+	}) == Fail && connErr != nil {
+		r.failed = true
+	}
+
+	client := pb.NewLogServiceClient(conn)
+	req := &pb.PingRequest{ClientVersion: opts.ClientVersion, ClientApi: opts.ClientAPI}
+	var ping *pb.PingResponse
+	var sent time.Time
+	var rtt time.Duration
+	if r.check(ctx, CheckPing, func(ctx context.Context) (Status, string) {
+		sent = opts.Now()
+		res, err := client.Ping(ctx, req)
+		rtt = opts.Now().Sub(sent)
+		if status.Code(err) == codes.Unimplemented {
+			return Fail, "the server does not support Ping; upgrade the server"
+		}
+		if err != nil {
+			return Fail, status.Convert(err).Message()
+		}
+		ping = res
+		return OK, fmt.Sprintf("round trip %s", rtt.Round(time.Millisecond))
+	}) == Fail {
+		r.failed = true
+	}
+
+	r.check(ctx, CheckAuth, func(ctx context.Context) (Status, string) {
+		if opts.Token == "" {
+			return Warn, "not logged in; run snulog login to read non-team logs"
+		}
+		res, err := client.Ping(auth.OutgoingContext(ctx, opts.Token), req)
+		if status.Code(err) == codes.Unauthenticated {
+			return Fail, "the saved token is invalid or expired; run snulog login"
+		}
+		if err != nil {
+			return Fail, status.Convert(err).Message()
+		}
+		if res.UserName == "" {
+			return Fail, "the server ignored the token"
+		}
+		return OK, fmt.Sprintf("logged in as %s (%s)", res.UserName, res.Role)
+	})
+
+	r.check(ctx, CheckVersion, func(context.Context) (Status, string) {
+		detail := fmt.Sprintf("client %s (API %d), server %s (API %d)", opts.ClientVersion, opts.ClientAPI, ping.ServerVersion, ping.Api)
+		if err := version.Check(opts.ClientAPI, ping.Api, ping.MinClientApi); err != nil {
+			if opts.ClientAPI > ping.Api {
+				return Warn, detail + ": " + err.Error()
+			}
+			return Fail, detail + ": " + err.Error()
+		}
+		return OK, detail
+	})
+
+	r.check(ctx, CheckClock, func(context.Context) (Status, string) {
+		serverTime, err := time.Parse(time.RFC3339Nano, ping.ServerTime)
+		if err != nil {
+			return Warn, fmt.Sprintf("the server time %q cannot be parsed", ping.ServerTime)
+		}
+		// サーバーが時刻を読んだのは往復のおよそ中間
+		skew := serverTime.Sub(sent.Add(rtt / 2))
+		return clockStatus(skew), describeSkew(skew)
+	})
+	return r.results
+}
+
+// checkTLS はハンドシェイクをして、TLS のバージョンと証明書の期限を確かめる
+func checkTLS(ctx context.Context, address, host string, config *tls.Config) (Status, string) {
+	config = config.Clone()
+	if config.ServerName == "" {
+		config.ServerName = host
+	}
+	dialer := tls.Dialer{Config: config}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return Fail, err.Error()
+	}
+	defer util.CloseWithLog(conn)
+
+	state := conn.(*tls.Conn).ConnectionState()
+	detail := tls.VersionName(state.Version)
+	if len(state.PeerCertificates) == 0 {
+		return OK, detail
+	}
+	cert := state.PeerCertificates[0]
+	detail += fmt.Sprintf(", certificate %s expires %s", cert.Subject.CommonName, cert.NotAfter.Format(time.DateOnly))
+	if time.Until(cert.NotAfter) < certExpiryWarning {
+		return Warn, detail + " (soon)"
+	}
+	return OK, detail
+}
+
+func clockStatus(skew time.Duration) Status {
+	switch skew = skew.Abs(); {
+	case skew >= clockSkewFail:
+		return Fail
+	case skew >= clockSkewWarn:
+		return Warn
+	}
+	return OK
+}
+
+func describeSkew(skew time.Duration) string {
+	rounded := skew.Abs().Round(time.Millisecond)
+	switch {
+	case rounded == 0:
+		return "in sync with the server"
+	case skew > 0:
+		return fmt.Sprintf("the client clock is %s behind the server", rounded)
+	}
+	return fmt.Sprintf("the client clock is %s ahead of the server", rounded)
+}
+
+// hostPort は gRPC の接続先から dns:/// などのスキームを取り除く
+func hostPort(server string) string {
+	if _, rest, ok := strings.Cut(server, ":///"); ok {
+		return rest
+	}
+	return server
+}
+
+// Healthy は失敗したチェックがないかを返す
+func Healthy(results []Result) bool {
+	for _, result := range results {
+		if result.Status == Fail {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,147 @@
+package doctor
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/version"
+	pb "github.com/gensan0223/snulog/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type pingServer struct {
+	pb.UnimplementedLogServiceServer
+	// skew はサーバーの時計の進み
+	skew time.Duration
+	api  int32
+}
+
+func (s *pingServer) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingResponse, error) {
+	res := &pb.PingResponse{
+		ServerVersion: "v1.0.0",
+		Api:           s.api,
+		MinClientApi:  1,
+		ServerTime:    time.Now().Add(s.skew).Format(time.RFC3339Nano),
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	switch tokens := md.Get("authorization"); {
+	case len(tokens) == 0:
+	case tokens[0] == "Bearer good":
+		res.UserName, res.Role = "alice", "manager"
+	default:
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	return res, nil
+}
+
+// startServer は 127.0.0.1 の空いているポートでサーバーを起動してアドレスを返す
+func startServer(t *testing.T, srv *pingServer, withHealth bool) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := grpc.NewServer()
+	pb.RegisterLogServiceServer(s, srv)
+	if withHealth {
+		h := health.NewServer()
+		h.SetServingStatus(pb.LogService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+		healthpb.RegisterHealthServer(s, h)
+	}
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+	return lis.Addr().String()
+}
+
+func statuses(results []Result) map[string]Status {
+	m := map[string]Status{}
+	for _, r := range results {
+		m[r.Check] = r.Status
+	}
+	return m
+}
+
+func run(address, token string) []Result {
+	return Run(context.Background(), Options{
+		Server:        address,
+		Token:         token,
+		Timeout:       2 * time.Second,
+		ClientVersion: "dev",
+		ClientAPI:     version.API,
+	})
+}
+
+func TestRun_Healthy(t *testing.T) {
+	address := startServer(t, &pingServer{api: version.API}, true)
+	results := run(address, "good")
+
+	names := make([]string, len(results))
+	for i, r := range results {
+		names[i] = r.Check
+	}
+	assert.Equal(t, []string{CheckDNS, CheckTCP, CheckTLS, CheckHealth, CheckPing, CheckAuth, CheckVersion, CheckClock}, names)
+	assert.Equal(t, map[string]Status{
+		CheckDNS: OK, CheckTCP: OK, CheckTLS: Skip, CheckHealth: OK, CheckPing: OK,
+		CheckAuth: OK, CheckVersion: OK, CheckClock: OK,
+	}, statuses(results))
+	assert.Equal(t, "logged in as alice (manager)", results[5].Detail)
+	assert.True(t, Healthy(results))
+}
+
+func TestRun_Problems(t *testing.T) {
+	address := startServer(t, &pingServer{api: version.API, skew: 10 * time.Minute}, false)
+
+	results := statuses(run(address, "expired"))
+	assert.Equal(t, Warn, results[CheckHealth], "ヘルスサービスのない古いサーバー")
+	assert.Equal(t, Fail, results[CheckAuth])
+	assert.Equal(t, Fail, results[CheckClock], "10 分のずれ")
+
+	assert.Equal(t, Warn, statuses(run(address, ""))[CheckAuth], "未ログイン")
+
+	old := startServer(t, &pingServer{api: version.API}, true)
+	results = statuses(Run(context.Background(), Options{Server: old, Timeout: time.Second, ClientAPI: version.API + 1}))
+	assert.Equal(t, Warn, results[CheckVersion], "CLI のほうが新しい")
+}
+
+func TestRun_Unreachable(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := lis.Addr().String()
+	require.NoError(t, lis.Close())
+
+	results := run(address, "")
+	got := statuses(results)
+	assert.Equal(t, OK, got[CheckDNS])
+	assert.Equal(t, Fail, got[CheckTCP])
+	for _, name := range []string{CheckTLS, CheckHealth, CheckPing, CheckAuth, CheckVersion, CheckClock} {
+		assert.Equal(t, Skip, got[name], name)
+	}
+	assert.False(t, Healthy(results))
+
+	assert.Equal(t, Fail, statuses(run("no-port", ""))[CheckDNS])
+}
+
+func TestRun_TLSAgainstPlaintext(t *testing.T) {
+	address := startServer(t, &pingServer{api: version.API}, true)
+	results := statuses(Run(context.Background(), Options{Server: address, TLS: &tls.Config{MinVersion: tls.VersionTLS12}, Timeout: time.Second}))
+	assert.Equal(t, Fail, results[CheckTLS])
+	assert.Equal(t, Skip, results[CheckPing])
+}
+
+func TestDescribeSkew(t *testing.T) {
+	assert.Equal(t, "in sync with the server", describeSkew(0))
+	assert.Equal(t, "the client clock is 1.5s behind the server", describeSkew(1500*time.Millisecond))
+	assert.Equal(t, "the client clock is 3s ahead of the server", describeSkew(-3*time.Second))
+	assert.Equal(t, OK, clockStatus(time.Second))
+	assert.Equal(t, Warn, clockStatus(-5*time.Second))
+	assert.Equal(t, Fail, clockStatus(2*time.Minute))
+	assert.Equal(t, "localhost:50051", hostPort("dns:///localhost:50051"))
+}
@@ -0,0 +1,257 @@
+// Package draft は snulog add で入力するログの下書きを扱う。
+// チームのテンプレートの項目からステータスを組み立て、$EDITOR で編集する YAML front matter 付きのファイルを読み書きする
+package draft
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	pb "github.com/gensan0223/snulog/proto"
+	"gopkg.in/yaml.v3"
+)
+
+// BlockerLabel はテンプレートにブロッカーの項目がないときに --blocker をステータスに添える見出し
+const BlockerLabel = "ブロッカー"
+
+// blockerKeys は --blocker を入れるテンプレートの項目のキー
+var blockerKeys = []string{"blockers", "blocker"}
+
+// Draft は入力途中のログ。テンプレートを使うときは Status の代わりに Answers に項目ごとの答えを入れる
+type Draft struct {
+	Feeling    string
+	Visibility string
+	Blocker    string
+	Status     string
+	Answers    map[string]string
+}
+
+// Text は下書きからステータスを作る。テンプレートがあれば各項目を「見出し: 答え」の行にし、
+// 答えのない任意の項目は省く。必須の項目やステータスが空ならエラーを返す
+func (d Draft) Text(fields []*pb.TemplateField) (string, error) {
+	blocker := strings.TrimSpace(d.Blocker)
+	if len(fields) == 0 {
+		status := strings.TrimSpace(d.Status)
+		if status == "" {
+			return "", errors.New("status is empty")
+		}
+		if blocker != "" {
+			status += "\n" + BlockerLabel + ": " + blocker
+		}
+		return status, nil
+	}
+
+	answers := map[string]string{}
+	for key, value := range d.Answers {
+		answers[key] = value
+	}
+	if key := BlockerField(fields); key != "" && blocker != "" && strings.TrimSpace(answers[key]) == "" {
+		answers[key] = blocker
+		blocker = ""
+	}
+	var lines []string
+	for _, f := range fields {
+		answer := strings.TrimSpace(answers[f.Key])
+		if answer == "" {
+			if f.Optional {
+				continue
+			}
+			return "", fmt.Errorf("%s is empty", f.Label)
+		}
+		lines = append(lines, f.Label+": "+answer)
+	}
+	if blocker != "" {
+		lines = append(lines, BlockerLabel+": "+blocker)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// Empty はステータスもテンプレートの答えも書かれていないか
+func (d Draft) Empty() bool {
+	if strings.TrimSpace(d.Status) != "" {
+		return false
+	}
+	for _, answer := range d.Answers {
+		if strings.TrimSpace(answer) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Missing はまだ答えのない項目を返す。テンプレートがなければ nil
+func (d Draft) Missing(fields []*pb.TemplateField) []*pb.TemplateField {
+	var missing []*pb.TemplateField
+	for _, f := range fields {
+		if strings.TrimSpace(d.Answers[f.Key]) == "" {
+			missing = append(missing, f)
+		}
+	}
+	return missing
+}
+
+// BlockerField は --blocker を入れる項目のキーを返す。なければ空文字列
+func BlockerField(fields []*pb.TemplateField) string {
+	for _, f := range fields {
+		for _, key := range blockerKeys {
+			if f.Key == key {
+				return f.Key
+			}
+		}
+	}
+	return ""
+}
+
+// frontMatter は編集用のファイルの先頭の YAML
+type frontMatter struct {
+	Feeling    string `yaml:"feeling"`
+	Visibility string `yaml:"visibility"`
+	// Blocker はテンプレートにブロッカーの項目があれば書かない
+	Blocker *string `yaml:"blocker,omitempty"`
+}
+
+const (
+	delimiter = "---"
+	// sectionPrefix はテンプレートの項目の見出し
+	sectionPrefix = "## "
+	optionalMark  = "（任意）"
+)
+
+// Render は d を $EDITOR で編集するファイルにする。本文はステータス、
+// テンプレートがあれば項目ごとの「## 見出し」の下に答えを書く形にする
+func Render(d Draft, fields []*pb.TemplateField) []byte {
+	var b bytes.Buffer
+	b.WriteString(delimiter + "\n")
+	b.WriteString("# feeling と visibility (team, managers, private) を書き、下にステータスを書いて保存してください。\n")
+	b.WriteString("# ステータスを空のまま保存すると中止します。\n")
+	fm := frontMatter{Feeling: d.Feeling, Visibility: d.Visibility}
+	if BlockerField(fields) == "" {
+		fm.Blocker = &d.Blocker
+	}
+	out, _ := yaml.Marshal(fm)
+	b.Write(out)
+	b.WriteString(delimiter + "\n")
+
+	if len(fields) == 0 {
+		b.WriteString(d.Status)
+		if d.Status != "" && !strings.HasSuffix(d.Status, "\n") {
+			b.WriteString("\n")
+		}
+		return b.Bytes()
+	}
+	for i, f := range fields {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(sectionPrefix + f.Label)
+		if f.Optional {
+			b.WriteString(optionalMark)
+		}
+		b.WriteString("\n")
+		answer := d.Answers[f.Key]
+		if answer == "" && f.Key == BlockerField(fields) {
+			answer = d.Blocker
+		}
+		if answer != "" {
+			b.WriteString(answer + "\n")
+		}
+	}
+	return b.Bytes()
+}
+
+// Parse は Render の形で編集されたファイルを読む。テンプレートがあれば本文を「## 見出し」ごとの答えにする
+func Parse(content []byte, fields []*pb.TemplateField) (Draft, error) {
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	rest, ok := strings.CutPrefix(text, delimiter+"\n")
+	if !ok {
+		return Draft{}, errors.New("front matter must start with ---")
+	}
+	head, body, ok := strings.Cut(rest, "\n"+delimiter+"\n")
+	if !ok {
+		if head, ok = strings.CutSuffix(rest, "\n"+delimiter); !ok {
+			return Draft{}, errors.New("front matter is not closed with ---")
+		}
+	}
+	var fm frontMatter
+	if err := yaml.Unmarshal([]byte(head), &fm); err != nil {
+		return Draft{}, fmt.Errorf("front matter: %w", err)
+	}
+	d := Draft{Feeling: strings.TrimSpace(fm.Feeling), Visibility: strings.TrimSpace(fm.Visibility)}
+	if fm.Blocker != nil {
+		d.Blocker = strings.TrimSpace(*fm.Blocker)
+	}
+	if len(fields) == 0 {
+		d.Status = strings.TrimSpace(body)
+		return d, nil
+	}
+
+	labels := map[string]string{}
+	for _, f := range fields {
+		labels[f.Label] = f.Key
+		labels[f.Label+optionalMark] = f.Key
+	}
+	d.Answers = map[string]string{}
+	var key string
+	var lines []string
+	flush := func() {
+		if key != "" {
+			d.Answers[key] = strings.Join(lines, "; ")
+		}
+		lines = nil
+	}
+	for _, line := range strings.Split(body, "\n") {
+		if heading, ok := strings.CutPrefix(line, sectionPrefix); ok {
+			if k, known := labels[strings.TrimSpace(heading)]; known {
+				flush()
+				key = k
+				continue
+			}
+		}
+		if line = strings.TrimSpace(line); line != "" && key != "" {
+			lines = append(lines, line)
+		}
+	}
+	flush()
+	return d, nil
+}
+
+// Editor は編集に使うコマンド。$VISUAL、$EDITOR の順に使い、どちらもなければ vi
+func Editor() string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(name)); editor != "" {
+			return editor
+		}
+	}
+	return "vi"
+}
+
+// Edit は content を一時ファイルに書いて editor で開き、保存された内容を返す。
+// editor は "code --wait" のように引数を含んでもよい
+func Edit(editor string, content []byte) ([]byte, error) {
+	file, err := os.CreateTemp("", "snulog-*.md")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = os.Remove(file.Name()) // Ignore remove errors
+	}()
+	if _, err := file.Write(content); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", file.Name())
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s: %w", editor, err)
+	}
+	return os.ReadFile(file.Name())
+}
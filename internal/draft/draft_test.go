@@ -0,0 +1,126 @@
+package draft
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/gensan0223/snulog/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var standup = []*pb.TemplateField{
+	{Key: "yesterday", Label: "昨日やったこと"},
+	{Key: "today", Label: "今日やること"},
+	{Key: "blockers", Label: "ブロッカー", Optional: true},
+}
+
+func TestText(t *testing.T) {
+	status, err := Draft{Status: " API のレビュー "}.Text(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "API のレビュー", status)
+
+	status, err = Draft{Status: "API のレビュー", Blocker: "権限待ち"}.Text(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "API のレビュー\nブロッカー: 権限待ち", status)
+
+	_, err = Draft{Blocker: "権限待ち"}.Text(nil)
+	assert.Error(t, err)
+
+	d := Draft{Answers: map[string]string{"yesterday": "ログイン画面", "today": "API"}}
+	status, err = d.Text(standup)
+	require.NoError(t, err)
+	assert.Equal(t, "昨日やったこと: ログイン画面\n今日やること: API", status, "empty optional fields are skipped")
+
+	d.Blocker = "権限待ち"
+	status, err = d.Text(standup)
+	require.NoError(t, err)
+	assert.Equal(t, "昨日やったこと: ログイン画面\n今日やること: API\nブロッカー: 権限待ち", status, "--blocker fills the blockers field")
+
+	_, err = Draft{Answers: map[string]string{"yesterday": "ログイン画面"}}.Text(standup)
+	assert.ErrorContains(t, err, "今日やること")
+
+	noBlocker := standup[:2]
+	status, err = Draft{Answers: map[string]string{"yesterday": "a", "today": "b"}, Blocker: "c"}.Text(noBlocker)
+	require.NoError(t, err)
+	assert.Equal(t, "昨日やったこと: a\n今日やること: b\nブロッカー: c", status)
+}
+
+func TestMissing(t *testing.T) {
+	d := Draft{Answers: map[string]string{"yesterday": "ログイン画面", "today": " "}}
+	missing := d.Missing(standup)
+	require.Len(t, missing, 2)
+	assert.Equal(t, "today", missing[0].Key)
+	assert.Equal(t, "blockers", missing[1].Key)
+	assert.Nil(t, d.Missing(nil))
+}
+
+func TestRenderParse(t *testing.T) {
+	d := Draft{Feeling: "😊", Visibility: "team", Blocker: "権限待ち", Status: "API のレビュー"}
+	content := Render(d, nil)
+	assert.Contains(t, string(content), "blocker: 権限待ち")
+
+	parsed, err := Parse(content, nil)
+	require.NoError(t, err)
+	assert.Equal(t, d, parsed)
+
+	edited := "---\nfeeling: \"🙂\"\nvisibility: private\nblocker: \"\"\n---\n\n1 行目\n2 行目\n"
+	parsed, err = Parse([]byte(edited), nil)
+	require.NoError(t, err)
+	assert.Equal(t, Draft{Feeling: "🙂", Visibility: "private", Status: "1 行目\n2 行目"}, parsed)
+
+	_, err = Parse([]byte("feeling: x\n"), nil)
+	assert.Error(t, err)
+	_, err = Parse([]byte("---\nfeeling: x\n"), nil)
+	assert.Error(t, err)
+}
+
+func TestRenderParseTemplate(t *testing.T) {
+	d := Draft{Visibility: "team", Blocker: "権限待ち", Answers: map[string]string{"yesterday": "ログイン画面"}}
+	content := string(Render(d, standup))
+	assert.NotContains(t, content, "blocker:", "the blockers section replaces the front matter key")
+	assert.Contains(t, content, "## 昨日やったこと\nログイン画面\n")
+	assert.Contains(t, content, "## ブロッカー（任意）\n権限待ち\n")
+
+	edited := "---\nfeeling: まあまあ\nvisibility: team\n---\n## 昨日やったこと\nログイン画面\n\n## 今日やること\n- API\n- レビュー\n\n## ブロッカー（任意）\n\n"
+	parsed, err := Parse([]byte(edited), standup)
+	require.NoError(t, err)
+	assert.Equal(t, "まあまあ", parsed.Feeling)
+	assert.Equal(t, map[string]string{"yesterday": "ログイン画面", "today": "- API; - レビュー", "blockers": ""}, parsed.Answers)
+	assert.False(t, parsed.Empty())
+
+	status, err := parsed.Text(standup)
+	require.NoError(t, err)
+	assert.Equal(t, "昨日やったこと: ログイン画面\n今日やること: - API; - レビュー", status)
+
+	parsed, err = Parse(Render(Draft{}, standup), standup)
+	require.NoError(t, err)
+	assert.True(t, parsed.Empty())
+}
+
+func TestEdit(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not installed")
+	}
+	script := filepath.Join(t.TempDir(), "editor.sh")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\necho 追記 >> \"$1\"\n"), 0o755))
+
+	out, err := Edit(script, []byte("本文\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "本文\n追記\n", string(out))
+
+	_, err = Edit("false", nil)
+	assert.Error(t, err)
+}
+
+func TestEditor(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+	assert.Equal(t, "vi", Editor())
+	t.Setenv("EDITOR", "nano")
+	assert.Equal(t, "nano", Editor())
+	t.Setenv("VISUAL", "code --wait")
+	assert.Equal(t, "code --wait", Editor())
+}
@@ -0,0 +1,404 @@
+// Package export はログを CSV / JSON / Markdown / iCalendar で書き出す。
+// ExportLogs のストリームから 1 件ずつ受け取って書くので、件数が多くても全件をメモリに載せない。
+// CSV と JSON の列（キー）は snulog import で読める名前にしてある
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/gensan0223/snulog/proto"
+)
+
+type Format string
+
+const (
+	CSV      Format = "csv"
+	JSON     Format = "json"
+	Markdown Format = "markdown"
+	ICS      Format = "ics"
+)
+
+// ParseFormat は --format の値を確かめる。空なら path の拡張子で決め、拡張子もなければ CSV にする
+func ParseFormat(value, path string) (Format, error) {
+	if value == "" {
+		value = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+		if value == "" {
+			return CSV, nil
+		}
+	}
+	switch value {
+	case "md":
+		return Markdown, nil
+	case "ical", "icalendar":
+		return ICS, nil
+	}
+	switch format := Format(value); format {
+	case CSV, JSON, Markdown, ICS:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown format %q (csv, json, markdown or ics)", value)
+}
+
+// ParseRange は since と until（RFC3339 か YYYY-MM-DD）を ExportLogsRequest の RFC3339 にする。
+// 日付だけの until はその日を含めるため翌日の 0 時にする。日付は loc のタイムゾーンで読む
+func ParseRange(since, until string, loc *time.Location) (string, string, error) {
+	from, err := parseDate(since, loc, false)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid since: %w", err)
+	}
+	to, err := parseDate(until, loc, true)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid until: %w", err)
+	}
+	return from, to, nil
+}
+
+func parseDate(value string, loc *time.Location, end bool) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.Format(time.RFC3339), nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return "", fmt.Errorf("%q is neither RFC3339 nor YYYY-MM-DD", value)
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t.Format(time.RFC3339), nil
+}
+
+// ContentType は HTTP で返すときの Content-Type
+func (f Format) ContentType() string {
+	switch f {
+	case JSON:
+		return "application/json; charset=utf-8"
+	case Markdown:
+		return "text/markdown; charset=utf-8"
+	case ICS:
+		return "text/calendar; charset=utf-8"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// Extension はファイル名の拡張子（. を含む）
+func (f Format) Extension() string {
+	if f == Markdown {
+		return ".md"
+	}
+	return "." + string(f)
+}
+
+type Options struct {
+	// TimeZone は Markdown の日付の区切りと時刻の表示に使う
+	TimeZone *time.Location
+	// Title は Markdown の見出しと iCalendar のカレンダー名
+	Title string
+	// Now は iCalendar の DTSTAMP に使う。ゼロ値なら現在時刻
+	Now time.Time
+}
+
+// Writer は 1 件ずつログを書き出す。Close で末尾（JSON の ] など）を書くので必ず呼ぶ
+type Writer interface {
+	Write(entry *pb.LogEntry) error
+	Close() error
+}
+
+// NewWriter は w に format で書き出す Writer を返す
+func NewWriter(w io.Writer, format Format, opts Options) (Writer, error) {
+	if opts.TimeZone == nil {
+		opts.TimeZone = time.Local
+	}
+	if opts.Title == "" {
+		opts.Title = "snulog"
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	switch format {
+	case CSV:
+		return newCSVWriter(w)
+	case JSON:
+		return &jsonWriter{w: w}, nil
+	case Markdown:
+		return &markdownWriter{w: w, opts: opts}, nil
+	case ICS:
+		return &icsWriter{w: w, opts: opts}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// Copy は recv が io.EOF を返すまで受け取ったログを w に書き、w を閉じて書いた件数を返す
+func Copy(w Writer, recv func() (*pb.LogEntry, error)) (int, error) {
+	count := 0
+	for {
+		entry, err := recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return count, err
+		}
+		if err := w.Write(entry); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, w.Close()
+}
+
+// visibilityName は import の visibility の列と同じ名前を返す
+func visibilityName(v pb.Visibility) string {
+	switch v {
+	case pb.Visibility_VISIBILITY_MANAGERS:
+		return "managers"
+	case pb.Visibility_VISIBILITY_PRIVATE:
+		return "private"
+	default:
+		return "team"
+	}
+}
+
+var csvHeader = []string{"id", "user_name", "status", "feeling", "timestamp", "visibility"}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	c := &csvWriter{w: csv.NewWriter(w)}
+	if err := c.w.Write(csvHeader); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *csvWriter) Write(entry *pb.LogEntry) error {
+	return c.w.Write([]string{
+		strconv.FormatInt(entry.Id, 10),
+		entry.UserName,
+		entry.Status,
+		entry.Feeling,
+		entry.Timestamp,
+		visibilityName(entry.Visibility),
+	})
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonEntry は JSON で書き出す 1 件。キーは csvHeader と同じ
+type jsonEntry struct {
+	ID         int64  `json:"id"`
+	UserName   string `json:"user_name"`
+	Status     string `json:"status"`
+	Feeling    string `json:"feeling"`
+	Timestamp  string `json:"timestamp"`
+	Visibility string `json:"visibility"`
+}
+
+// jsonWriter はオブジェクトの配列を 1 件ずつ書く
+type jsonWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (j *jsonWriter) Write(entry *pb.LogEntry) error {
+	data, err := json.Marshal(jsonEntry{
+		ID:         entry.Id,
+		UserName:   entry.UserName,
+		Status:     entry.Status,
+		Feeling:    entry.Feeling,
+		Timestamp:  entry.Timestamp,
+		Visibility: visibilityName(entry.Visibility),
+	})
+	if err != nil {
+		return err
+	}
+	prefix := ",\n  "
+	if j.count == 0 {
+		prefix = "[\n  "
+	}
+	j.count++
+	_, err = fmt.Fprintf(j.w, "%s%s", prefix, data)
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	if j.count == 0 {
+		_, err := io.WriteString(j.w, "[]\n")
+		return err
+	}
+	_, err := io.WriteString(j.w, "\n]\n")
+	return err
+}
+
+// markdownWriter は日ごとの見出しの下にログを並べたレポートを書く。ログは古い順に届く前提
+type markdownWriter struct {
+	w       io.Writer
+	opts    Options
+	day     string
+	count   int
+	feeling map[string]int
+	order   []string
+}
+
+var weekdays = []string{"日", "月", "火", "水", "木", "金", "土"}
+
+func (m *markdownWriter) Write(entry *pb.LogEntry) error {
+	var b strings.Builder
+	if m.count == 0 {
+		fmt.Fprintf(&b, "# %s レポート\n", m.opts.Title)
+		m.feeling = map[string]int{}
+	}
+	m.count++
+	if _, seen := m.feeling[entry.Feeling]; !seen {
+		m.order = append(m.order, entry.Feeling)
+	}
+	m.feeling[entry.Feeling]++
+
+	day, clock := "日付不明", ""
+	if t, err := time.Parse(time.RFC3339, entry.Timestamp); err == nil {
+		t = t.In(m.opts.TimeZone)
+		day = fmt.Sprintf("%s (%s)", t.Format("2006-01-02"), weekdays[t.Weekday()])
+		clock = t.Format("15:04") + " "
+	}
+	if day != m.day {
+		m.day = day
+		fmt.Fprintf(&b, "\n## %s\n\n", day)
+	}
+	fmt.Fprintf(&b, "- %s**%s** %s %s\n", clock, markdownEscape(entry.UserName), entry.Feeling, markdownEscape(entry.Status))
+	_, err := io.WriteString(m.w, b.String())
+	return err
+}
+
+func (m *markdownWriter) Close() error {
+	if m.count == 0 {
+		_, err := fmt.Fprintf(m.w, "# %s レポート\n\nログはありません。\n", m.opts.Title)
+		return err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n## 集計\n\n- ログ: %d 件\n", m.count)
+	for _, feeling := range m.order {
+		name := feeling
+		if name == "" {
+			name = "（なし）"
+		}
+		fmt.Fprintf(&b, "- %s: %d 件\n", name, m.feeling[feeling])
+	}
+	_, err := io.WriteString(m.w, b.String())
+	return err
+}
+
+// markdownEscape は本文が見出しや強調として解釈されないようにする
+func markdownEscape(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "#", `\#`, "[", `\[`, "]", `\]`, "<", `\<`).Replace(s)
+}
+
+// icsWriter はログ 1 件を 1 つのチェックインの予定として書く（RFC 5545）
+type icsWriter struct {
+	w       io.Writer
+	opts    Options
+	started bool
+}
+
+func (c *icsWriter) start() error {
+	if c.started {
+		return nil
+	}
+	c.started = true
+	return c.lines(
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//snulog//export//JA",
+		"CALSCALE:GREGORIAN",
+		"X-WR-CALNAME:"+icsText(c.opts.Title),
+	)
+}
+
+func (c *icsWriter) Write(entry *pb.LogEntry) error {
+	t, err := time.Parse(time.RFC3339, entry.Timestamp)
+	if err != nil {
+		// 時刻のないログは予定にできないので飛ばす
+		return nil
+	}
+	if err := c.start(); err != nil {
+		return err
+	}
+	summary := strings.TrimSpace(entry.Feeling + " " + entry.UserName + ": " + entry.Status)
+	return c.lines(
+		"BEGIN:VEVENT",
+		fmt.Sprintf("UID:log-%d@snulog", entry.Id),
+		"DTSTAMP:"+c.opts.Now.UTC().Format(icsTime),
+		"DTSTART:"+t.UTC().Format(icsTime),
+		"DURATION:PT15M",
+		"SUMMARY:"+icsText(summary),
+		"DESCRIPTION:"+icsText(entry.Status),
+		"CATEGORIES:"+icsText(entry.UserName),
+		"CLASS:"+icsClass(entry.Visibility),
+		"END:VEVENT",
+	)
+}
+
+func (c *icsWriter) Close() error {
+	if err := c.start(); err != nil {
+		return err
+	}
+	return c.lines("END:VCALENDAR")
+}
+
+const icsTime = "20060102T150405Z"
+
+// lines は各行を 75 オクテットで折り返し、CRLF で区切って書く
+func (c *icsWriter) lines(lines ...string) error {
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(icsFold(line))
+		b.WriteString("\r\n")
+	}
+	_, err := io.WriteString(c.w, b.String())
+	return err
+}
+
+// icsFold は 75 オクテットを超える行を、UTF-8 の文字の途中で切らずに折り返す
+func icsFold(line string) string {
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > 75 {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	return b.String()
+}
+
+// icsText は TEXT の値の \ ; , と改行をエスケープする
+func icsText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// icsClass は公開範囲を CLASS にする。team 以外は共有カレンダーで中身を見せない
+func icsClass(v pb.Visibility) string {
+	if v == pb.Visibility_VISIBILITY_TEAM || v == pb.Visibility_VISIBILITY_UNSPECIFIED {
+		return "PUBLIC"
+	}
+	return "PRIVATE"
+}
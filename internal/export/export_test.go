@@ -0,0 +1,171 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/importer"
+	pb "github.com/gensan0223/snulog/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var entries = []*pb.LogEntry{
+	{Id: 1, UserName: "alice", Status: "design review", Feeling: "😊", Timestamp: "2025-01-01T09:00:00Z"},
+	{Id: 2, UserName: "bob", Status: "fix, then *ship*", Feeling: "😫", Timestamp: "2025-01-01T18:30:00Z", Visibility: pb.Visibility_VISIBILITY_MANAGERS},
+	{Id: 3, UserName: "alice", Status: "retro", Feeling: "😊", Timestamp: "2025-01-02T10:00:00+09:00"},
+}
+
+func write(t *testing.T, format Format, entries []*pb.LogEntry) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, format, Options{
+		TimeZone: time.FixedZone("JST", 9*60*60),
+		Now:      time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	for _, entry := range entries {
+		require.NoError(t, w.Write(entry))
+	}
+	require.NoError(t, w.Close())
+	return buf.String()
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		value, path string
+		want        Format
+	}{
+		{"", "logs.csv", CSV},
+		{"", "report.md", Markdown},
+		{"", "checkins.ics", ICS},
+		{"", "", CSV},
+		{"json", "logs.txt", JSON},
+		{"ical", "", ICS},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.value, tt.path)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, got)
+	}
+
+	_, err := ParseFormat("", "logs.xlsx")
+	assert.Error(t, err)
+}
+
+func TestParseRange(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	since, until, err := ParseRange("2025-01-01", "2025-01-31", jst)
+	require.NoError(t, err)
+	assert.Equal(t, "2025-01-01T00:00:00+09:00", since)
+	assert.Equal(t, "2025-02-01T00:00:00+09:00", until, "日付だけの until はその日を含める")
+
+	since, until, err = ParseRange("2025-01-01T12:00:00Z", "", jst)
+	require.NoError(t, err)
+	assert.Equal(t, "2025-01-01T12:00:00Z", since)
+	assert.Empty(t, until)
+
+	_, _, err = ParseRange("", "last week", jst)
+	assert.Error(t, err)
+}
+
+func TestWriter_CSVAndJSONCanBeImported(t *testing.T) {
+	for _, format := range []Format{CSV, JSON} {
+		t.Run(string(format), func(t *testing.T) {
+			rows, rowErrors, err := importer.Read(strings.NewReader(write(t, format, entries)), importer.Format(format), importer.Options{})
+			require.NoError(t, err)
+			assert.Empty(t, rowErrors)
+			require.Len(t, rows, len(entries))
+			for i, row := range rows {
+				assert.Equal(t, entries[i].UserName, row.Entry.UserName)
+				assert.Equal(t, entries[i].Status, row.Entry.Status)
+				assert.Equal(t, entries[i].Timestamp, row.Entry.Timestamp)
+				assert.Equal(t, visibilityName(entries[i].Visibility), visibilityName(row.Entry.Visibility))
+			}
+		})
+	}
+}
+
+func TestWriter_EmptyJSON(t *testing.T) {
+	var logs []any
+	require.NoError(t, json.Unmarshal([]byte(write(t, JSON, nil)), &logs))
+	assert.Empty(t, logs)
+}
+
+func TestWriter_Markdown(t *testing.T) {
+	got := write(t, Markdown, entries)
+	assert.Equal(t, `# snulog レポート
+
+## 2025-01-01 (水)
+
+- 18:00 **alice** 😊 design review
+
+## 2025-01-02 (木)
+
+- 03:30 **bob** 😫 fix, then \*ship\*
+- 10:00 **alice** 😊 retro
+
+## 集計
+
+- ログ: 3 件
+- 😊: 2 件
+- 😫: 1 件
+`, got, "日付と時刻は指定のタイムゾーンで区切る")
+
+	assert.Contains(t, write(t, Markdown, nil), "ログはありません。")
+}
+
+func TestWriter_ICS(t *testing.T) {
+	got := write(t, ICS, entries)
+	lines := strings.Split(strings.TrimSuffix(got, "\r\n"), "\r\n")
+	assert.Equal(t, "BEGIN:VCALENDAR", lines[0])
+	assert.Equal(t, "END:VCALENDAR", lines[len(lines)-1])
+	assert.Equal(t, 3, strings.Count(got, "BEGIN:VEVENT"))
+	assert.Contains(t, got, "UID:log-2@snulog\r\nDTSTAMP:20250201T000000Z\r\nDTSTART:20250101T183000Z\r\n")
+	assert.Contains(t, got, `SUMMARY:😫 bob: fix\, then *ship*`)
+	assert.Contains(t, got, "CLASS:PRIVATE")
+	assert.Contains(t, got, "DTSTART:20250102T010000Z", "UTC で書く")
+	for _, line := range lines {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+
+	empty := write(t, ICS, nil)
+	assert.Equal(t, "BEGIN:VCALENDAR", strings.SplitN(empty, "\r\n", 2)[0])
+	assert.True(t, strings.HasSuffix(empty, "END:VCALENDAR\r\n"))
+}
+
+func TestICSFold(t *testing.T) {
+	folded := icsFold("SUMMARY:" + strings.Repeat("あ", 40))
+	for _, line := range strings.Split(folded, "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+	assert.Equal(t, "SUMMARY:"+strings.Repeat("あ", 40), strings.ReplaceAll(folded, "\r\n ", ""), "文字の途中で切らない")
+}
+
+func TestCopy(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, JSON, Options{})
+	require.NoError(t, err)
+	i := 0
+	count, err := Copy(w, func() (*pb.LogEntry, error) {
+		if i == len(entries) {
+			return nil, io.EOF
+		}
+		i++
+		return entries[i-1], nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.True(t, strings.HasSuffix(buf.String(), "\n]\n"), "最後に閉じる")
+
+	broken := errors.New("stream broken")
+	w, err = NewWriter(&buf, JSON, Options{})
+	require.NoError(t, err)
+	_, err = Copy(w, func() (*pb.LogEntry, error) { return nil, broken })
+	assert.ErrorIs(t, err, broken)
+}
@@ -0,0 +1,196 @@
+// Package gitlog はローカルの git リポジトリのコミットを読み、snulog のステータスの候補を作る
+package gitlog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Commit は git log の 1 件
+type Commit struct {
+	Hash    string
+	Time    time.Time
+	Subject string
+}
+
+type Options struct {
+	// Author は git log --author に渡す。名前かメールアドレスの一部
+	Author string
+	// Since より後のコミットだけを読む。ゼロ値なら期間で絞らない
+	Since time.Time
+	// Max は読むコミットの上限。0 なら上限なし
+	Max int
+}
+
+// git は dir で git を実行して標準出力を返す。失敗したら git のエラー出力をエラーにする
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], message)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return string(out), nil
+}
+
+// Author はリポジトリの user.email を返す。なければ user.name
+func Author(ctx context.Context, dir string) (string, error) {
+	for _, key := range []string{"user.email", "user.name"} {
+		// 未設定なら git config は 1 で終わるので、エラーは次のキーを試す
+		if value, err := git(ctx, dir, "config", "--get", key); err == nil && strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value), nil
+		}
+	}
+	return "", errors.New("neither user.email nor user.name is set in git config")
+}
+
+// HooksDir はリポジトリのフックのディレクトリ（core.hooksPath を考慮する）を返す
+func HooksDir(ctx context.Context, dir string) (string, error) {
+	out, err := git(ctx, dir, "rev-parse", "--path-format=absolute", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// Log は opts の作者のコミットを新しい順に返す。マージコミットは含めない
+func Log(ctx context.Context, dir string, opts Options) ([]Commit, error) {
+	args := []string{"log", "--no-merges", "--format=%H%x1f%aI%x1f%s%x1e"}
+	if opts.Author != "" {
+		args = append(args, "--author="+opts.Author)
+	}
+	if !opts.Since.IsZero() {
+		args = append(args, "--since="+opts.Since.Format(time.RFC3339))
+	}
+	if opts.Max > 0 {
+		args = append(args, "--max-count="+strconv.Itoa(opts.Max))
+	}
+	out, err := git(ctx, dir, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []Commit
+	for _, record := range strings.Split(out, "\x1e") {
+		fields := strings.Split(strings.TrimSpace(record), "\x1f")
+		if len(fields) != 3 {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("git log: %q: %w", fields[1], err)
+		}
+		commits = append(commits, Commit{Hash: fields[0], Time: t, Subject: fields[2]})
+	}
+	return commits, nil
+}
+
+// ticketPattern は課題の参照。PROJ-123 のような課題キーと #123 のような番号
+var ticketPattern = regexp.MustCompile(`\b[A-Z][A-Z0-9]+-[0-9]+\b|#[0-9]+\b`)
+
+// Tickets は text の課題の参照を現れた順に重複なく返す
+func Tickets(text string) []string {
+	var tickets []string
+	seen := map[string]bool{}
+	for _, ticket := range ticketPattern.FindAllString(text, -1) {
+		if !seen[ticket] {
+			seen[ticket] = true
+			tickets = append(tickets, ticket)
+		}
+	}
+	return tickets
+}
+
+// leftover は課題の参照を取り除いたあとに残る括弧や区切り
+var leftover = regexp.MustCompile(`[\[(]\s*[,\s]*\s*[\])]|^[\s:,/-]+|[\s:,/-]+$`)
+
+// summary は件名から課題の参照を取り除く（例: "[PROJ-1] Fix login (#2)" → "Fix login"）
+func summary(subject string) string {
+	s := ticketPattern.ReplaceAllString(subject, "")
+	s = leftover.ReplaceAllString(s, "")
+	s = leftover.ReplaceAllString(strings.TrimSpace(s), "")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Suggestion はコミットから作ったステータスの候補
+type Suggestion struct {
+	Status  string
+	Tickets []string
+}
+
+// Suggest は commits（新しい順）を古い順に並べた件名の要約と課題の参照からステータスを作る。
+// 同じ件名は 1 つにまとめる
+func Suggest(commits []Commit) Suggestion {
+	var parts []string
+	var tickets []string
+	seenPart := map[string]bool{}
+	seenTicket := map[string]bool{}
+	for i := len(commits) - 1; i >= 0; i-- {
+		for _, ticket := range Tickets(commits[i].Subject) {
+			if !seenTicket[ticket] {
+				seenTicket[ticket] = true
+				tickets = append(tickets, ticket)
+			}
+		}
+		part := summary(commits[i].Subject)
+		if part != "" && !seenPart[part] {
+			seenPart[part] = true
+			parts = append(parts, part)
+		}
+	}
+
+	status := strings.Join(parts, "; ")
+	if len(tickets) > 0 {
+		status = strings.TrimSpace(status + " (" + strings.Join(tickets, ", ") + ")")
+	}
+	return Suggestion{Status: status, Tickets: tickets}
+}
+
+// Hooks は snulog git hook install で入れられるフック
+var Hooks = []string{"post-commit", "pre-push"}
+
+// hookMarker は snulog が書いたフックの印。印のない既存のフックは上書きしない
+const hookMarker = "# installed by snulog git hook install"
+
+// ErrForeignHook は同じ名前のフックが snulog 以外で作られている
+var ErrForeignHook = errors.New("hook already exists and was not installed by snulog")
+
+// InstallHook はリポジトリのフック name に command を実行する sh のスクリプトを書き、書いたパスを返す。
+// snulog が書いたフックは置き換える。それ以外のフックは force のときだけ置き換える
+func InstallHook(ctx context.Context, dir, name, command string, force bool) (string, error) {
+	if !slices.Contains(Hooks, name) {
+		return "", fmt.Errorf("unknown hook %q (%s)", name, strings.Join(Hooks, ", "))
+	}
+	hooksDir, err := HooksDir(ctx, dir)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(hooksDir, name)
+	if current, err := os.ReadFile(path); err == nil && !force && !strings.Contains(string(current), hookMarker) {
+		return path, ErrForeignHook
+	}
+	if err := os.MkdirAll(hooksDir, 0o755); err != nil {
+		return "", err
+	}
+	script := "#!/bin/sh\n" + hookMarker + "\n" + command + "\n"
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		return "", err
+	}
+	// 既存のファイルを置き換えたときは WriteFile がパーミッションを変えない
+	return path, os.Chmod(path, 0o755)
+}
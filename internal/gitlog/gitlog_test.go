@@ -0,0 +1,122 @@
+package gitlog
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRepo は一時ディレクトリに git リポジトリを作る
+func newRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	run(t, dir, "init", "-q")
+	run(t, dir, "config", "user.email", "alice@example.com")
+	run(t, dir, "config", "user.name", "Alice")
+	return dir
+}
+
+func run(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	runWithEnv(t, dir, nil, args...)
+}
+
+func runWithEnv(t *testing.T, dir string, env []string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	// 利用者の設定（署名やフック）に影響されないようにする
+	cmd.Env = append(append(os.Environ(), "GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1"), env...)
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+// commit は author が at に書いた空のコミットを作る。--since はコミット日時で絞るので両方を at にする
+func commit(t *testing.T, dir, author, subject string, at time.Time) {
+	t.Helper()
+	date := at.Format(time.RFC3339)
+	runWithEnv(t, dir, []string{"GIT_AUTHOR_DATE=" + date, "GIT_COMMITTER_DATE=" + date},
+		"-c", "user.email="+author, "commit", "-q", "--allow-empty", "-m", subject)
+}
+
+func TestLog(t *testing.T) {
+	dir := newRepo(t)
+	now := time.Now()
+	commit(t, dir, "alice@example.com", "old work", now.Add(-72*time.Hour))
+	commit(t, dir, "alice@example.com", "[PROJ-1] Fix login", now.Add(-2*time.Hour))
+	commit(t, dir, "bob@example.com", "bob's change", now.Add(-time.Hour))
+	commit(t, dir, "alice@example.com", "Add export (#42)", now.Add(-30*time.Minute))
+
+	author, err := Author(context.Background(), dir)
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", author)
+
+	commits, err := Log(context.Background(), dir, Options{Author: author, Since: now.Add(-24 * time.Hour)})
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	assert.Equal(t, "Add export (#42)", commits[0].Subject, "新しい順")
+	assert.Len(t, commits[0].Hash, 40)
+
+	commits, err = Log(context.Background(), dir, Options{Author: author, Max: 1})
+	require.NoError(t, err)
+	assert.Len(t, commits, 1)
+
+	_, err = Log(context.Background(), t.TempDir(), Options{})
+	assert.Error(t, err, "リポジトリの外")
+}
+
+func TestTickets(t *testing.T) {
+	assert.Equal(t, []string{"PROJ-12", "#34", "AB2-7"}, Tickets("PROJ-12: fix #34 and AB2-7, again PROJ-12"))
+	assert.Empty(t, Tickets("utf-8 handling in x-2 mode"))
+}
+
+func TestSuggest(t *testing.T) {
+	commits := []Commit{
+		{Subject: "Add export (#42)"},
+		{Subject: "PROJ-1: fix login"},
+		{Subject: "[PROJ-1] Fix login"},
+		{Subject: "[PROJ-1] Fix login"},
+	}
+	got := Suggest(commits)
+	assert.Equal(t, "Fix login; fix login; Add export (PROJ-1, #42)", got.Status, "古い順で、同じ件名はまとめる")
+	assert.Equal(t, []string{"PROJ-1", "#42"}, got.Tickets)
+
+	assert.Empty(t, Suggest(nil).Status)
+}
+
+func TestInstallHook(t *testing.T) {
+	dir := newRepo(t)
+	ctx := context.Background()
+
+	path, err := InstallHook(ctx, dir, "post-commit", "snulog git suggest --post", false)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, ".git", "hooks", "post-commit"), path)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&0o100, "実行できる")
+
+	_, err = InstallHook(ctx, dir, "post-commit", "snulog git suggest --post --count 1", false)
+	assert.NoError(t, err, "snulog のフックは置き換える")
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "--count 1")
+
+	foreign := filepath.Join(dir, ".git", "hooks", "pre-push")
+	require.NoError(t, os.WriteFile(foreign, []byte("#!/bin/sh\nmake test\n"), 0o755))
+	_, err = InstallHook(ctx, dir, "pre-push", "snulog git suggest --post", false)
+	assert.ErrorIs(t, err, ErrForeignHook)
+	_, err = InstallHook(ctx, dir, "pre-push", "snulog git suggest --post", true)
+	assert.NoError(t, err)
+
+	_, err = InstallHook(ctx, dir, "pre-commit", "x", false)
+	assert.Error(t, err)
+}
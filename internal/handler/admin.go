@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/auth"
+	"github.com/gensan0223/snulog/internal/repository"
+	pb "github.com/gensan0223/snulog/proto"
+)
+
+// requireAdmin は管理者のセッションを確認する。管理者でなければレスポンスを書いて nil を返す
+func (h *WebHandler) requireAdmin(w http.ResponseWriter, r *http.Request) (*auth.Session, *repository.User) {
+	session, authenticated := h.authService.GetSessionFromRequest(r)
+	if !authenticated {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return nil, nil
+	}
+	user, err := h.userRepo.GetUserByUsername(session.Username)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, nil
+	}
+	if user.Role != repository.RoleAdmin {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return nil, nil
+	}
+	return session, user
+}
+
+func (h *WebHandler) ServeAdminAudit(w http.ResponseWriter, r *http.Request) {
+	session, admin := h.requireAdmin(w, r)
+	if admin == nil {
+		return
+	}
+
+	query := r.URL.Query()
+	req := &pb.ListAuditEventsRequest{
+		Actor:  query.Get("actor"),
+		Action: query.Get("action"),
+		Target: query.Get("target"),
+		Since:  dateToRFC3339(query.Get("since"), 0),
+		// 終了日はその日の終わりまでを含める
+		Until: dateToRFC3339(query.Get("until"), 1),
+	}
+
+	var events []*pb.AuditEvent
+	var listErr string
+	conn, err := h.dial()
+	if err != nil {
+		log.Printf("grpc: connect %s: %v", h.grpcAddr, err)
+		listErr = "サーバーに接続できませんでした"
+	} else {
+		defer func() {
+			_ = conn.Close() // Ignore close errors
+		}()
+		ctx, cancel, err := h.rpcContext(admin.Username)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		defer cancel()
+
+		resp, err := pb.NewLogServiceClient(conn).ListAuditEvents(ctx, req)
+		if err != nil {
+			listErr = rpcErrorMessage("監査ログの取得", err)
+		} else {
+			events = resp.Events
+		}
+	}
+
+	tmpl, err := parsePage("admin_audit.html")
+	if err != nil {
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		Username  string
+		CSRFToken string
+		Actor     string
+		Action    string
+		Target    string
+		Since     string
+		Until     string
+		Events    []*pb.AuditEvent
+		Error     string
+	}{
+		Username:  admin.Username,
+		CSRFToken: session.CSRFToken,
+		Actor:     req.Actor,
+		Action:    req.Action,
+		Target:    req.Target,
+		Since:     query.Get("since"),
+		Until:     query.Get("until"),
+		Events:    events,
+		Error:     listErr,
+	}
+
+	if err := tmpl.Execute(w, data); err != nil {
+		http.Error(w, "Template execution error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// dateToRFC3339 は日付入力（2006-01-02）を days 日後の 0 時の RFC3339 に変換する。不正な値は無視する
+func dateToRFC3339(value string, days int) string {
+	date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return ""
+	}
+	return date.AddDate(0, 0, days).Format(time.RFC3339)
+}
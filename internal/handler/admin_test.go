@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gensan0223/snulog/internal/auth"
+	"github.com/gensan0223/snulog/internal/repository"
+)
+
+func TestServeAdminAudit_RequiresAdmin(t *testing.T) {
+	users := newFakeUserRepository("root", "alice")
+	users.users["root"].Role = repository.RoleAdmin
+	h := &WebHandler{
+		authService: auth.NewAuthService(),
+		userRepo:    users,
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/audit", nil)
+	w := httptest.NewRecorder()
+	h.ServeAdminAudit(w, req)
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/login" {
+		t.Errorf("Expected redirect to login, got %d %s", w.Code, w.Header().Get("Location"))
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/audit", nil)
+	req.AddCookie(sessionCookie(t, h, "alice"))
+	w = httptest.NewRecorder()
+	h.ServeAdminAudit(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a member, got %d", w.Code)
+	}
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/export"
+	pb "github.com/gensan0223/snulog/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ExportLogs は /api/export?format=csv|json|markdown|ics&since=&until=&user=&q= でログを書き出す。
+// ExportLogs のストリームを受け取りながら書くので、範囲が広くても全件をメモリに載せない
+func (h *WebHandler) ExportLogs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	session, authenticated := h.authService.GetSessionFromRequest(r)
+	if !authenticated {
+		http.Error(w, "ログインが必要です", http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+	format, err := export.ParseFormat(query.Get("format"), "")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	since, until, err := export.ParseRange(query.Get("since"), query.Get("until"), time.Local)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	conn, err := h.dial()
+	if err != nil {
+		log.Printf("grpc: connect %s: %v", h.grpcAddr, err)
+		http.Error(w, "サーバーに接続できませんでした", http.StatusBadGateway)
+		return
+	}
+	defer func() {
+		_ = conn.Close() // Ignore close errors
+	}()
+
+	ctx, err := h.streamContext(r.Context(), session.Username)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	stream, err := pb.NewLogServiceClient(conn).ExportLogs(ctx, &pb.ExportLogsRequest{
+		Query:    query.Get("q"),
+		UserName: query.Get("user"),
+		Since:    since,
+		Until:    until,
+	})
+	// サーバーのエラーは最初の Recv で届く。ヘッダーを書く前に受け取り、ステータスコードで返す
+	var first *pb.LogEntry
+	if err == nil {
+		first, err = stream.Recv()
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, rpcErrorMessage("ログの書き出し", err), exportStatus(err))
+		return
+	}
+	firstErr := err
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="snulog-%s%s"`, time.Now().Format("20060102"), format.Extension()))
+	writer, err := export.NewWriter(w, format, export.Options{TimeZone: time.Local})
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	received := false
+	recv := func() (*pb.LogEntry, error) {
+		if !received {
+			received = true
+			return first, firstErr
+		}
+		return stream.Recv()
+	}
+	if _, err := export.Copy(writer, recv); err != nil {
+		// ヘッダーは送信済みなので、途中で切れたことはログにだけ残す
+		log.Printf("export: %s: %v", session.Username, err)
+	}
+}
+
+// exportStatus は書き出しを始める前の gRPC のエラーを HTTP のステータスコードにする
+func exportStatus(err error) int {
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	default:
+		return http.StatusBadGateway
+	}
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gensan0223/snulog/internal/auth"
+	pb "github.com/gensan0223/snulog/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type exportLogServer struct {
+	pb.UnimplementedLogServiceServer
+	req *pb.ExportLogsRequest
+}
+
+func (s *exportLogServer) ExportLogs(req *pb.ExportLogsRequest, stream grpc.ServerStreamingServer[pb.LogEntry]) error {
+	s.req = req
+	if req.UserName == "nobody" {
+		return status.Error(codes.PermissionDenied, "denied")
+	}
+	for _, entry := range []*pb.LogEntry{
+		{Id: 1, UserName: "alice", Status: "review", Feeling: "😊", Timestamp: "2025-01-01T09:00:00Z"},
+		{Id: 2, UserName: "bob", Status: "deploy", Feeling: "😫", Timestamp: "2025-01-02T09:00:00Z"},
+	} {
+		if err := stream.Send(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newExportTestHandler(t *testing.T) (*WebHandler, *exportLogServer) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	server := &exportLogServer{}
+	s := grpc.NewServer()
+	pb.RegisterLogServiceServer(s, server)
+	go func() { _ = s.Serve(listener) }()
+	t.Cleanup(s.Stop)
+
+	return &WebHandler{
+		grpcAddr:    listener.Addr().String(),
+		authService: auth.NewAuthService(),
+		userRepo:    newFakeUserRepository("alice"),
+		tokenSigner: auth.NewTokenSigner([]byte("test-secret")),
+	}, server
+}
+
+func getExport(h *WebHandler, query string, cookie *http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/export?"+query, nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	h.ExportLogs(w, req)
+	return w
+}
+
+func TestExportLogs(t *testing.T) {
+	h, server := newExportTestHandler(t)
+	cookie := sessionCookie(t, h, "alice")
+
+	w := getExport(h, "format=csv&user=bob&q=deploy&since=2025-01-01T00:00:00Z", cookie)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Type"); got != "text/csv; charset=utf-8" {
+		t.Errorf("Unexpected Content-Type %q", got)
+	}
+	if got := w.Header().Get("Content-Disposition"); !strings.HasPrefix(got, `attachment; filename="snulog-`) || !strings.HasSuffix(got, `.csv"`) {
+		t.Errorf("Unexpected Content-Disposition %q", got)
+	}
+	if lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n"); len(lines) != 3 || !strings.HasPrefix(lines[1], "1,alice,review") {
+		t.Errorf("Unexpected body %q", w.Body.String())
+	}
+	if server.req.UserName != "bob" || server.req.Query != "deploy" || server.req.Since != "2025-01-01T00:00:00Z" {
+		t.Errorf("Unexpected request %+v", server.req)
+	}
+
+	w = getExport(h, "format=ics", cookie)
+	if !strings.HasPrefix(w.Body.String(), "BEGIN:VCALENDAR\r\n") || w.Header().Get("Content-Type") != "text/calendar; charset=utf-8" {
+		t.Errorf("Unexpected ics response %q", w.Body.String())
+	}
+}
+
+func TestExportLogs_Rejected(t *testing.T) {
+	h, _ := newExportTestHandler(t)
+	cookie := sessionCookie(t, h, "alice")
+
+	tests := []struct {
+		name   string
+		query  string
+		cookie *http.Cookie
+		code   int
+	}{
+		{"not logged in", "format=csv", nil, http.StatusUnauthorized},
+		{"unknown format", "format=xlsx", cookie, http.StatusBadRequest},
+		{"invalid date", "since=yesterday", cookie, http.StatusBadRequest},
+		{"denied by server", "user=nobody", cookie, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := getExport(h, tt.query, tt.cookie)
+			if w.Code != tt.code {
+				t.Errorf("Expected %d, got %d: %s", tt.code, w.Code, w.Body.String())
+			}
+			if w.Header().Get("Content-Disposition") != "" {
+				t.Error("エラーをファイルとして返さない")
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/repository"
+	"github.com/gensan0223/snulog/internal/tenant"
+)
+
+type fakeResetToken struct {
+	userID    int
+	expiresAt time.Time
+	used      bool
+}
+
+type fakeUserRepository struct {
+	users         map[string]*repository.User
+	recoveryCodes map[string]bool
+	resetTokens   map[string]*fakeResetToken
+}
+
+func newFakeUserRepository(usernames ...string) *fakeUserRepository {
+	repo := &fakeUserRepository{
+		users:       make(map[string]*repository.User),
+		resetTokens: make(map[string]*fakeResetToken),
+	}
+	for _, name := range usernames {
+		repo.users[name] = &repository.User{ID: len(repo.users) + 1, OrgID: tenant.DefaultOrgID, Username: name, Role: repository.RoleMember}
+	}
+	return repo
+}
+
+func (r *fakeUserRepository) GetUserByUsername(username string) (*repository.User, error) {
+	user, exists := r.users[username]
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+func (r *fakeUserRepository) GetUserByOIDCSubject(subject string) (*repository.User, error) {
+	for _, user := range r.users {
+		if subject != "" && user.OIDCSubject == subject {
+			return user, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *fakeUserRepository) CreateUser(user *repository.User) error {
+	if user.Role == "" {
+		user.Role = repository.RoleMember
+	}
+	user.ID = len(r.users) + 1
+	r.users[user.Username] = user
+	return nil
+}
+
+// addUser は既定の組織にユーザーを作成する
+func (r *fakeUserRepository) addUser(username, passwordHash string) {
+	_ = r.CreateUser(&repository.User{OrgID: tenant.DefaultOrgID, Username: username, PasswordHash: passwordHash})
+}
+
+func (r *fakeUserRepository) EnableTOTP(userID int, secret string, recoveryCodeHashes []string) error {
+	for _, user := range r.users {
+		if user.ID == userID {
+			user.TOTPSecret = secret
+			user.TOTPEnabled = true
+		}
+	}
+	r.recoveryCodes = make(map[string]bool)
+	for _, hash := range recoveryCodeHashes {
+		r.recoveryCodes[hash] = true
+	}
+	return nil
+}
+
+func (r *fakeUserRepository) ConsumeRecoveryCode(userID int, codeHash string) (bool, error) {
+	if !r.recoveryCodes[codeHash] {
+		return false, nil
+	}
+	delete(r.recoveryCodes, codeHash)
+	return true, nil
+}
+
+func (r *fakeUserRepository) userByID(userID int) *repository.User {
+	for _, user := range r.users {
+		if user.ID == userID {
+			return user
+		}
+	}
+	return nil
+}
+
+func (r *fakeUserRepository) UpdatePassword(userID int, passwordHash string) error {
+	user := r.userByID(userID)
+	if user == nil {
+		return sql.ErrNoRows
+	}
+	user.PasswordHash = passwordHash
+	user.PasswordChangedAt = time.Now().UTC()
+	return nil
+}
+
+func (r *fakeUserRepository) UpdateEmail(userID int, email string) error {
+	user := r.userByID(userID)
+	if user == nil {
+		return sql.ErrNoRows
+	}
+	user.Email = email
+	return nil
+}
+
+func (r *fakeUserRepository) CreatePasswordResetToken(userID int, tokenHash string, expiresAt time.Time) error {
+	r.resetTokens[tokenHash] = &fakeResetToken{userID: userID, expiresAt: expiresAt}
+	return nil
+}
+
+func (r *fakeUserRepository) FindPasswordResetToken(tokenHash string) (*repository.User, error) {
+	token, exists := r.resetTokens[tokenHash]
+	if !exists || token.used || time.Now().After(token.expiresAt) {
+		return nil, sql.ErrNoRows
+	}
+	return r.userByID(token.userID), nil
+}
+
+func (r *fakeUserRepository) ResetPassword(tokenHash, passwordHash string) (*repository.User, error) {
+	user, err := r.FindPasswordResetToken(tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	r.resetTokens[tokenHash].used = true
+	user.PasswordHash = passwordHash
+	user.PasswordChangedAt = time.Now().UTC()
+	return user, nil
+}
+
+func (r *fakeUserRepository) DeleteUser(orgID int64, userID int) error {
+	user := r.userByID(userID)
+	if user == nil || user.OrgID != orgID {
+		return sql.ErrNoRows
+	}
+	delete(r.users, user.Username)
+	return nil
+}
@@ -0,0 +1,353 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/audit"
+	"github.com/gensan0223/snulog/internal/auth"
+	"github.com/gensan0223/snulog/internal/mail"
+	"github.com/gensan0223/snulog/internal/repository"
+)
+
+// WithMailer はパスワードリセットのメール送信に使う Mailer と、リンクに使う公開 URL を設定する
+func WithMailer(mailer mail.Mailer, baseURL string) Option {
+	return func(h *WebHandler) {
+		h.mailer = mailer
+		h.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+func (h *WebHandler) ServeProfile(w http.ResponseWriter, r *http.Request) {
+	session, authenticated := h.authService.GetSessionFromRequest(r)
+	if !authenticated {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	user, err := h.userRepo.GetUserByUsername(session.Username)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	tmpl, err := parsePage("profile.html")
+	if err != nil {
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		Username    string
+		Email       string
+		Role        string
+		TOTPEnabled bool
+		CSRFToken   string
+	}{
+		Username:    user.Username,
+		Email:       user.Email,
+		Role:        user.Role,
+		TOTPEnabled: user.TOTPEnabled,
+		CSRFToken:   session.CSRFToken,
+	}
+
+	if err := tmpl.Execute(w, data); err != nil {
+		http.Error(w, "Template execution error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *WebHandler) HandleChangePassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session, authenticated := h.authService.GetSessionFromRequest(r)
+	if !authenticated {
+		w.Header().Set("HX-Redirect", "/login")
+		return
+	}
+
+	user, err := h.userRepo.GetUserByUsername(session.Username)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if !h.checkCurrentPassword(w, r, user) {
+		return
+	}
+
+	newPassword := r.FormValue("new_password")
+	if newPassword != r.FormValue("confirm_password") {
+		writeError(w, "新しいパスワードが一致しません")
+		return
+	}
+	if err := h.setPassword(user.ID, user.Username, newPassword); err != nil {
+		h.writePasswordError(w, err)
+		return
+	}
+	log.Printf("security: password changed user=%q ip=%s", user.Username, auth.ClientIP(r))
+	h.authService.RecordEvent(r, audit.Event{Actor: user.Username, Action: audit.ActionPasswordChanged, Target: "user:" + user.Username, OrgID: user.OrgID})
+
+	// 他の端末のセッションはすべて無効にし、この端末には新しいセッションを発行する
+	token, err := h.authService.CreateSession(user.Username)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	h.authService.SetSessionCookie(w, token)
+	w.Header().Set("HX-Refresh", "true")
+	writeSuccess(w, "パスワードを変更しました")
+}
+
+// HandleUpdateEmail はリセット用リンクの送信先を変更する。セッションを奪っただけでは
+// 送信先を差し替えてアカウントを乗っ取れないよう、現在のパスワードを確認する
+func (h *WebHandler) HandleUpdateEmail(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session, authenticated := h.authService.GetSessionFromRequest(r)
+	if !authenticated {
+		w.Header().Set("HX-Redirect", "/login")
+		return
+	}
+
+	email := strings.TrimSpace(r.FormValue("email"))
+	if email != "" && !strings.Contains(email, "@") {
+		writeError(w, "メールアドレスの形式が正しくありません")
+		return
+	}
+
+	user, err := h.userRepo.GetUserByUsername(session.Username)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !h.checkCurrentPassword(w, r, user) {
+		return
+	}
+
+	previous := user.Email
+	if err := h.userRepo.UpdateEmail(user.ID, email); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	log.Printf("security: email changed user=%q ip=%s", user.Username, auth.ClientIP(r))
+	h.authService.RecordEvent(r, audit.Event{Actor: user.Username, Action: audit.ActionEmailChanged, Target: "user:" + user.Username, Before: map[string]any{"email": previous}, After: map[string]any{"email": email}, OrgID: user.OrgID})
+	writeSuccess(w, "メールアドレスを更新しました")
+}
+
+// checkCurrentPassword は current_password を確認する。ログインと同じリミッターでユーザーごとの失敗回数を数え、
+// 確認できなかった場合はエラーメッセージを書き込んで false を返す
+func (h *WebHandler) checkCurrentPassword(w http.ResponseWriter, r *http.Request, user *repository.User) bool {
+	ip := auth.ClientIP(r)
+	limitKeys := []string{"user:" + user.Username}
+	if h.limiter != nil {
+		allowed, retryAfter, err := h.limiter.Allow(r.Context(), limitKeys...)
+		if err != nil {
+			log.Printf("ratelimit: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return false
+		}
+		if !allowed {
+			log.Printf("security: password check blocked user=%q ip=%s retry_after=%s", user.Username, ip, retryAfter)
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			w.WriteHeader(http.StatusTooManyRequests)
+			writeError(w, "しばらくしてから再度お試しください")
+			return false
+		}
+	}
+
+	if !h.authService.CheckPassword(r.FormValue("current_password"), user.PasswordHash) {
+		log.Printf("security: password check failed user=%q ip=%s", user.Username, ip)
+		if h.limiter != nil {
+			if err := h.limiter.Record(r.Context(), limitKeys...); err != nil {
+				log.Printf("ratelimit: %v", err)
+			}
+		}
+		writeError(w, "現在のパスワードが間違っています")
+		return false
+	}
+	return true
+}
+
+func (h *WebHandler) ServeForgotPassword(w http.ResponseWriter, r *http.Request) {
+	h.servePasswordPage(w, r, "password_forgot.html", "")
+}
+
+// HandleForgotPassword はユーザーの存在有無にかかわらず同じ応答を返す
+func (h *WebHandler) HandleForgotPassword(w http.ResponseWriter, r *http.Request) {
+	username := strings.TrimSpace(r.FormValue("username"))
+	ip := auth.ClientIP(r)
+
+	if h.limiter != nil {
+		limitKeys := []string{"reset:" + ip}
+		allowed, _, err := h.limiter.Allow(r.Context(), limitKeys...)
+		if err != nil {
+			log.Printf("ratelimit: %v", err)
+		}
+		if !allowed {
+			log.Printf("security: password reset blocked ip=%s", ip)
+			renderPartial(w, "notice-message", "登録済みのメールアドレスにリセット用のリンクを送信しました")
+			return
+		}
+		if err := h.limiter.Record(r.Context(), limitKeys...); err != nil {
+			log.Printf("ratelimit: %v", err)
+		}
+	}
+
+	if user, err := h.sendPasswordReset(r.Context(), username); err != nil {
+		log.Printf("password reset for %q: %v", username, err)
+	} else {
+		log.Printf("security: password reset requested user=%q ip=%s", username, ip)
+		h.authService.RecordEvent(r, audit.Event{Actor: "anonymous", Action: audit.ActionPasswordResetRequest, Target: "user:" + username, OrgID: user.OrgID})
+	}
+	renderPartial(w, "notice-message", "登録済みのメールアドレスにリセット用のリンクを送信しました")
+}
+
+// sendPasswordReset はリセット用のリンクを送信し、送信先のユーザーを返す
+func (h *WebHandler) sendPasswordReset(ctx context.Context, username string) (*repository.User, error) {
+	if h.mailer == nil {
+		return nil, errors.New("mailer is not configured")
+	}
+
+	user, err := h.userRepo.GetUserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	if user.Email == "" {
+		return nil, errors.New("user has no email address")
+	}
+
+	token, tokenHash, err := auth.GeneratePasswordResetToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := h.userRepo.CreatePasswordResetToken(user.ID, tokenHash, time.Now().UTC().Add(auth.PasswordResetTTL)); err != nil {
+		return nil, err
+	}
+
+	link := h.baseURL + "/password/reset?token=" + url.QueryEscape(token)
+	return user, h.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Snulog パスワードの再設定",
+		Body: fmt.Sprintf("%s さん\n\n以下のリンクから新しいパスワードを設定してください（有効期限: %d分、1回のみ有効）。\n\n%s\n\n心当たりがない場合はこのメールを破棄してください。\n",
+			user.Username, int(auth.PasswordResetTTL.Minutes()), link),
+	})
+}
+
+func (h *WebHandler) ServeResetPassword(w http.ResponseWriter, r *http.Request) {
+	h.servePasswordPage(w, r, "password_reset.html", r.URL.Query().Get("token"))
+}
+
+func (h *WebHandler) HandleResetPassword(w http.ResponseWriter, r *http.Request) {
+	newPassword := r.FormValue("new_password")
+	if newPassword != r.FormValue("confirm_password") {
+		writeError(w, "新しいパスワードが一致しません")
+		return
+	}
+
+	// ポリシー違反でリンクが使えなくならないよう、トークンは確認だけしてパスワードと同時に使用済みにする
+	tokenHash := auth.HashPasswordResetToken(r.FormValue("token"))
+	user, err := h.userRepo.FindPasswordResetToken(tokenHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		log.Printf("security: invalid password reset token ip=%s", auth.ClientIP(r))
+		writeError(w, "リンクが無効か、有効期限が切れています")
+		return
+	}
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if err := auth.DefaultPasswordPolicy.Validate(user.Username, newPassword); err != nil {
+		h.writePasswordError(w, err)
+		return
+	}
+
+	hash, err := h.authService.HashPassword(newPassword)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	user, err = h.userRepo.ResetPassword(tokenHash, hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, "リンクが無効か、有効期限が切れています")
+		return
+	}
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	h.authService.DeleteUserSessions(user.Username)
+
+	log.Printf("security: password reset completed user=%q ip=%s", user.Username, auth.ClientIP(r))
+	h.authService.RecordEvent(r, audit.Event{Actor: user.Username, Action: audit.ActionPasswordReset, Target: "user:" + user.Username, OrgID: user.OrgID})
+	renderPartial(w, "password-reset-done", nil)
+}
+
+// setPassword はポリシーを確認してパスワードを更新し、既存のセッションをすべて無効にする
+func (h *WebHandler) setPassword(userID int, username, password string) error {
+	if err := auth.DefaultPasswordPolicy.Validate(username, password); err != nil {
+		return err
+	}
+	hash, err := h.authService.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	if err := h.userRepo.UpdatePassword(userID, hash); err != nil {
+		return err
+	}
+	h.authService.DeleteUserSessions(username)
+	return nil
+}
+
+func (h *WebHandler) writePasswordError(w http.ResponseWriter, err error) {
+	var policyErr *auth.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		writeError(w, policyErr.Reason)
+		return
+	}
+	log.Printf("password update: %v", err)
+	http.Error(w, "Internal server error", http.StatusInternalServerError)
+}
+
+func (h *WebHandler) servePasswordPage(w http.ResponseWriter, r *http.Request, name, token string) {
+	tmpl, err := parsePage(name)
+	if err != nil {
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		return
+	}
+
+	csrfToken, err := h.authService.CSRFToken(w, r)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		Token     string
+		CSRFToken string
+	}{
+		Token:     token,
+		CSRFToken: csrfToken,
+	}
+
+	if err := tmpl.Execute(w, data); err != nil {
+		http.Error(w, "Template execution error", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gensan0223/snulog/internal/audit"
+	"github.com/gensan0223/snulog/internal/auth"
+	"github.com/gensan0223/snulog/internal/mail"
+	"github.com/gensan0223/snulog/internal/ratelimit"
+	"github.com/gensan0223/snulog/internal/repository"
+)
+
+func newPasswordTestHandler(t *testing.T) (*WebHandler, *fakeUserRepository, *bytes.Buffer) {
+	t.Helper()
+
+	authService := auth.NewAuthService()
+	hash, err := authService.HashPassword("password")
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+	users := newFakeUserRepository()
+	users.addUser("alice", hash)
+	users.users["alice"].Email = "alice@example.com"
+
+	var outbox bytes.Buffer
+	h := &WebHandler{
+		authService: authService,
+		userRepo:    users,
+	}
+	WithMailer(mail.NewWriterMailer(&outbox), "http://snulog.test/")(h)
+	return h, users, &outbox
+}
+
+func TestHandleChangePassword(t *testing.T) {
+	h, users, _ := newPasswordTestHandler(t)
+	current := sessionCookie(t, h, "alice")
+	other := sessionCookie(t, h, "alice")
+
+	tests := []struct {
+		name    string
+		current string
+		next    string
+		confirm string
+		message string
+	}{
+		{"現在のパスワード誤り", "wrong", "correct horse 42", "correct horse 42", "現在のパスワードが間違っています"},
+		{"確認不一致", "password", "correct horse 42", "correct horse 43", "一致しません"},
+		{"短すぎる", "password", "short1", "short1", "12文字以上"},
+		{"数字なし", "password", "correcthorsebattery", "correcthorsebattery", "英字と数字"},
+		{"ユーザー名を含む", "password", "alice-is-great-1", "alice-is-great-1", "ユーザー名"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{"current_password": {tt.current}, "new_password": {tt.next}, "confirm_password": {tt.confirm}}
+			w := postForm(h.HandleChangePassword, "/profile/password", form, []*http.Cookie{current})
+			if !strings.Contains(w.Body.String(), tt.message) {
+				t.Errorf("Expected message %q, got %s", tt.message, w.Body.String())
+			}
+		})
+	}
+
+	form := url.Values{"current_password": {"password"}, "new_password": {"correct horse 42"}, "confirm_password": {"correct horse 42"}}
+	w := postForm(h.HandleChangePassword, "/profile/password", form, []*http.Cookie{current})
+	if !strings.Contains(w.Body.String(), "success-message") {
+		t.Fatalf("Expected success, got %s", w.Body.String())
+	}
+	if !h.authService.CheckPassword("correct horse 42", users.users["alice"].PasswordHash) {
+		t.Error("Expected password hash to be updated")
+	}
+	if _, ok := h.authService.GetSession(other.Value); ok {
+		t.Error("Expected other sessions to be invalidated")
+	}
+	if _, ok := h.authService.GetSession(current.Value); ok {
+		t.Error("Expected previous session token to be replaced")
+	}
+	if !hasSessionCookie(w) {
+		t.Error("Expected a fresh session cookie for the current browser")
+	}
+}
+
+func TestPasswordResetFlow(t *testing.T) {
+	h, users, outbox := newPasswordTestHandler(t)
+	existing := sessionCookie(t, h, "alice")
+
+	// 存在しないユーザーでも同じ応答を返し、メールは送らない
+	w := postForm(h.HandleForgotPassword, "/password/forgot", url.Values{"username": {"nobody"}}, nil)
+	unknownBody := w.Body.String()
+	if outbox.Len() != 0 {
+		t.Fatal("Expected no mail for unknown user")
+	}
+
+	w = postForm(h.HandleForgotPassword, "/password/forgot", url.Values{"username": {"alice"}}, nil)
+	if w.Body.String() != unknownBody {
+		t.Error("Expected identical response for known and unknown users")
+	}
+
+	match := regexp.MustCompile(`http://snulog\.test/password/reset\?token=(\S+)`).FindStringSubmatch(outbox.String())
+	if match == nil {
+		t.Fatalf("Expected reset link in mail, got: %s", outbox.String())
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatalf("Invalid token in link: %v", err)
+	}
+
+	reset := func(token, password string) string {
+		form := url.Values{"token": {token}, "new_password": {password}, "confirm_password": {password}}
+		return postForm(h.HandleResetPassword, "/password/reset", form, nil).Body.String()
+	}
+
+	if body := reset(token, "weak"); !strings.Contains(body, "12文字以上") {
+		t.Errorf("Expected policy error, got %s", body)
+	}
+	// ユーザー名を含むパスワードで拒否されても、リンクはそのまま使える
+	if body := reset(token, "alice-is-great-1"); !strings.Contains(body, "ユーザー名") {
+		t.Errorf("Expected username policy error, got %s", body)
+	}
+	if body := reset(token, "correct horse 42"); !strings.Contains(body, "success-message") {
+		t.Fatalf("Expected reset to succeed, got %s", body)
+	}
+	if !h.authService.CheckPassword("correct horse 42", users.users["alice"].PasswordHash) {
+		t.Error("Expected password to be reset")
+	}
+	if _, ok := h.authService.GetSession(existing.Value); ok {
+		t.Error("Expected existing sessions to be invalidated")
+	}
+
+	// トークンは一度しか使えない
+	if body := reset(token, "another horse 43"); !strings.Contains(body, "無効") {
+		t.Errorf("Expected reused token to be rejected, got %s", body)
+	}
+}
+
+func TestHandleUpdateEmail(t *testing.T) {
+	h, users, _ := newPasswordTestHandler(t)
+	h.limiter = ratelimit.NewLimiter("login", repository.NewInMemoryAttemptRepository(), ratelimit.DefaultLoginPolicy)
+	auditRepo := repository.NewInMemoryAuditRepository()
+	h.authService.SetRecorder(audit.NewRecorder(auditRepo))
+	current := sessionCookie(t, h, "alice")
+
+	update := func(email, password string) *httptest.ResponseRecorder {
+		form := url.Values{"email": {email}, "current_password": {password}}
+		return postForm(h.HandleUpdateEmail, "/profile/email", form, []*http.Cookie{current})
+	}
+
+	if w := update("mallory@example.com", "wrong"); !strings.Contains(w.Body.String(), "現在のパスワードが間違っています") {
+		t.Fatalf("Expected wrong password to be rejected, got %s", w.Body.String())
+	}
+	if users.users["alice"].Email != "alice@example.com" {
+		t.Fatal("Expected email to be unchanged")
+	}
+
+	if w := update("alice@example.org", "password"); !strings.Contains(w.Body.String(), "success-message") {
+		t.Fatalf("Expected success, got %s", w.Body.String())
+	}
+	if users.users["alice"].Email != "alice@example.org" {
+		t.Error("Expected email to be updated")
+	}
+	events, err := auditRepo.List(context.Background(), repository.AuditFilter{Action: audit.ActionEmailChanged})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(events) != 1 || string(events[0].Before) != `{"email":"alice@example.com"}` {
+		t.Errorf("Expected audit event with the old address, got %+v", events)
+	}
+
+	// 失敗を繰り返すとログインと同じようにロックされる
+	for i := 0; i < ratelimit.DefaultLoginPolicy.MaxAttempts; i++ {
+		update("mallory@example.com", "wrong")
+	}
+	if w := update("mallory@example.com", "password"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status 429, got %d", w.Code)
+	}
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"bytes"
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/gensan0223/snulog/web"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// htmx で差し替える HTML 断片。利用者が入力した文字列は必ずここを通してエスケープする
+var partials = template.Must(template.ParseFS(web.Templates, "templates/partials/*.html"))
+
+func parsePage(name string) (*template.Template, error) {
+	return template.ParseFS(web.Templates, "templates/"+name)
+}
+
+// renderPartial は断片を最後まで描画できた場合だけ書き出す
+func renderPartial(w http.ResponseWriter, name string, data any) {
+	var buf bytes.Buffer
+	if err := partials.ExecuteTemplate(&buf, name, data); err != nil {
+		log.Printf("render %s: %v", name, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if _, err := buf.WriteTo(w); err != nil {
+		log.Printf("write response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, message string) {
+	renderPartial(w, "error-message", message)
+}
+
+func writeSuccess(w http.ResponseWriter, message string) {
+	renderPartial(w, "success-message", message)
+}
+
+// rpcErrorMessage は gRPC のエラーを画面に出せる文言にする。詳細はサーバーのログにだけ残す
+func rpcErrorMessage(action string, err error) string {
+	log.Printf("grpc: %s: %v", action, err)
+	switch status.Code(err) {
+	case codes.PermissionDenied:
+		return action + "権限がありません"
+	case codes.NotFound:
+		return "対象が見つかりません"
+	case codes.Unauthenticated:
+		return "ログインが必要です"
+	case codes.ResourceExhausted:
+		return "リクエストが多すぎます。しばらくしてから再度お試しください"
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return "サーバーに接続できませんでした"
+	default:
+		return action + "に失敗しました"
+	}
+}
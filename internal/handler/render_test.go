@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pb "github.com/gensan0223/snulog/proto"
+	"github.com/gensan0223/snulog/web"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLogList_EscapesUserInput(t *testing.T) {
+	w := httptest.NewRecorder()
+	renderPartial(w, "log-list", logListData{
+		Logs: []*pb.LogEntry{{
+			Id:        1,
+			UserName:  `<img src=x onerror=alert(1)>`,
+			Status:    `<script>alert("xss")</script>`,
+			Feeling:   `"><svg onload=alert(1)>`,
+			Timestamp: "2025-01-01T09:00:00Z",
+		}},
+	})
+
+	body := w.Body.String()
+	for _, raw := range []string{"<script>", "<img", "<svg"} {
+		if strings.Contains(body, raw) {
+			t.Errorf("ユーザー入力がエスケープされていない: %q in %s", raw, body)
+		}
+	}
+	if !strings.Contains(body, "&lt;script&gt;") {
+		t.Errorf("Expected escaped status, got %s", body)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("Unexpected Content-Type %q", ct)
+	}
+}
+
+func TestLogList_Empty(t *testing.T) {
+	w := httptest.NewRecorder()
+	renderPartial(w, "log-list", logListData{})
+	if !strings.Contains(w.Body.String(), "まだログがありません") {
+		t.Errorf("Expected empty message, got %s", w.Body.String())
+	}
+}
+
+func TestLogList_VisibilityBadge(t *testing.T) {
+	w := httptest.NewRecorder()
+	renderPartial(w, "log-list", logListData{
+		Logs: []*pb.LogEntry{
+			{Id: 1, UserName: "alice", Status: "team", Visibility: pb.Visibility_VISIBILITY_TEAM},
+			{Id: 2, UserName: "alice", Status: "managers", Visibility: pb.Visibility_VISIBILITY_MANAGERS},
+			{Id: 3, UserName: "alice", Status: "private", Visibility: pb.Visibility_VISIBILITY_PRIVATE},
+		},
+	})
+
+	body := w.Body.String()
+	if strings.Count(body, "visibility-badge") != 2 {
+		t.Errorf("team 以外のログにだけバッジを表示する: %s", body)
+	}
+	for _, label := range []string{"🔒 自分のみ", "👔 マネージャーまで"} {
+		if !strings.Contains(body, label) {
+			t.Errorf("Expected badge %q, got %s", label, body)
+		}
+	}
+}
+
+func TestParseVisibility(t *testing.T) {
+	for value, want := range map[string]pb.Visibility{
+		"":         pb.Visibility_VISIBILITY_TEAM,
+		"team":     pb.Visibility_VISIBILITY_TEAM,
+		"managers": pb.Visibility_VISIBILITY_MANAGERS,
+		"private":  pb.Visibility_VISIBILITY_PRIVATE,
+	} {
+		got, ok := parseVisibility(value)
+		if !ok || got != want {
+			t.Errorf("parseVisibility(%q) = %v, %v; want %v", value, got, ok, want)
+		}
+	}
+	if _, ok := parseVisibility("everyone"); ok {
+		t.Error("未知の値は受け付けない")
+	}
+}
+
+func TestWriteError_Escapes(t *testing.T) {
+	w := httptest.NewRecorder()
+	writeError(w, `<script>alert(1)</script>`)
+	if strings.Contains(w.Body.String(), "<script>") {
+		t.Errorf("エラーメッセージがエスケープされていない: %s", w.Body.String())
+	}
+}
+
+func TestRPCErrorMessage_HidesDetails(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{status.Error(codes.PermissionDenied, "admin role required <b>"), "監査ログの取得権限がありません"},
+		{status.Error(codes.Unavailable, "dial tcp 10.0.0.5:50051: connection refused"), "サーバーに接続できませんでした"},
+		{errors.New("pq: relation \"audit_events\" does not exist"), "監査ログの取得に失敗しました"},
+	}
+	for _, tt := range tests {
+		if got := rpcErrorMessage("監査ログの取得", tt.err); got != tt.want {
+			t.Errorf("rpcErrorMessage(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	h := SecurityHeaders(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	csp := w.Header().Get("Content-Security-Policy")
+	if !strings.Contains(csp, "script-src 'self'") || strings.Contains(csp, "unpkg") || strings.Contains(csp, "unsafe-eval") {
+		t.Errorf("Unexpected CSP %q", csp)
+	}
+	if got := w.Header().Get("X-Frame-Options"); got != "DENY" {
+		t.Errorf("X-Frame-Options = %q", got)
+	}
+	if got := w.Header().Get("Referrer-Policy"); got == "" {
+		t.Error("Referrer-Policy is missing")
+	}
+}
+
+func TestPageTemplates_NoExternalScripts(t *testing.T) {
+	pages, err := fs.Glob(web.Templates, "templates/*.html")
+	if err != nil || len(pages) == 0 {
+		t.Fatalf("no templates found: %v", err)
+	}
+	for _, page := range pages {
+		content, err := fs.ReadFile(web.Templates, page)
+		if err != nil {
+			t.Fatalf("read %s: %v", page, err)
+		}
+		if strings.Contains(string(content), "unpkg.com") || strings.Contains(string(content), "hx-on") {
+			t.Errorf("%s: 外部スクリプトやインラインハンドラーは CSP で動かない", page)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package handler
+
+import (
+	"encoding/base64"
+	"html/template"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/audit"
+	"github.com/gensan0223/snulog/internal/auth"
+	"github.com/gensan0223/snulog/internal/auth/totp"
+	"github.com/gensan0223/snulog/internal/repository"
+	"github.com/skip2/go-qrcode"
+)
+
+const totpIssuer = "snulog"
+
+// WithTwoFactorPolicy は指定したロールのユーザーに 2 段階認証を必須にする
+func WithTwoFactorPolicy(policy auth.TwoFactorPolicy) Option {
+	return func(h *WebHandler) {
+		h.twoFactorPolicy = policy
+	}
+}
+
+// ServeTwoFactor はパスワード認証後の確認コード入力画面を表示する
+func (h *WebHandler) ServeTwoFactor(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.authService.GetPendingLoginFromRequest(r); !ok {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	tmpl, err := parsePage("two_factor.html")
+	if err != nil {
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		return
+	}
+
+	csrfToken, err := h.authService.CSRFToken(w, r)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		CSRFToken string
+	}{
+		CSRFToken: csrfToken,
+	}
+
+	if err := tmpl.Execute(w, data); err != nil {
+		http.Error(w, "Template execution error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *WebHandler) HandleTwoFactor(w http.ResponseWriter, r *http.Request) {
+	pending, ok := h.authService.GetPendingLoginFromRequest(r)
+	if !ok {
+		w.Header().Set("HX-Redirect", "/login")
+		return
+	}
+
+	ip := auth.ClientIP(r)
+	limitKeys := []string{"2fa:" + pending.Username}
+	if h.limiter != nil {
+		allowed, retryAfter, err := h.limiter.Allow(r.Context(), limitKeys...)
+		if err != nil {
+			log.Printf("ratelimit: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if !allowed {
+			log.Printf("security: 2fa blocked user=%q ip=%s retry_after=%s", pending.Username, ip, retryAfter)
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			w.WriteHeader(http.StatusTooManyRequests)
+			writeError(w, "ログインできません。しばらくしてから再度お試しください")
+			return
+		}
+	}
+
+	user, err := h.userRepo.GetUserByUsername(pending.Username)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	code := strings.TrimSpace(r.FormValue("code"))
+	verified := user.TOTPEnabled && totp.Validate(code, user.TOTPSecret, time.Now(), totp.DefaultOptions)
+	if !verified && code != "" {
+		// 認証アプリが使えない場合はリカバリーコードを受け付ける
+		verified, err = h.userRepo.ConsumeRecoveryCode(user.ID, auth.HashRecoveryCode(code))
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if verified {
+			log.Printf("security: recovery code used user=%q ip=%s", user.Username, ip)
+			h.authService.RecordEvent(r, audit.Event{Actor: user.Username, Action: audit.ActionRecoveryCodeUsed, Target: "user:" + user.Username, OrgID: user.OrgID})
+		}
+	}
+
+	if !verified {
+		log.Printf("security: 2fa failure user=%q ip=%s", user.Username, ip)
+		h.authService.RecordEvent(r, audit.Event{Actor: user.Username, Action: audit.ActionTwoFactorFailure, Target: "user:" + user.Username, OrgID: user.OrgID})
+		if h.limiter != nil {
+			if err := h.limiter.Record(r.Context(), limitKeys...); err != nil {
+				log.Printf("ratelimit: %v", err)
+			}
+		}
+		writeError(w, "確認コードが正しくありません")
+		return
+	}
+
+	if h.limiter != nil {
+		if err := h.limiter.Reset(r.Context(), limitKeys...); err != nil {
+			log.Printf("ratelimit: %v", err)
+		}
+	}
+
+	if err := h.completeLogin(w, r, user.Username); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	log.Printf("security: login success user=%q ip=%s 2fa=true", user.Username, ip)
+	h.authService.RecordEvent(r, audit.Event{Actor: user.Username, Action: audit.ActionLoginSuccess, Target: "user:" + user.Username, After: map[string]any{"2fa": true}, OrgID: user.OrgID})
+	w.Header().Set("HX-Redirect", "/")
+}
+
+// ServeTwoFactorSetup は認証アプリ登録用の QR コードを表示する。
+// ログイン済みのユーザーと、ポリシーで登録を求められた保留ログイン中のユーザーが使える
+func (h *WebHandler) ServeTwoFactorSetup(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := h.twoFactorSetupUser(r)
+	if !ok {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+	username := user.Username
+
+	secret, err := h.authService.BeginTOTPEnrollment(username)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	png, err := qrcode.Encode(totp.KeyURI(totpIssuer, username, secret, totp.DefaultOptions), qrcode.Medium, 256)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	tmpl, err := parsePage("two_factor_setup.html")
+	if err != nil {
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		return
+	}
+
+	csrfToken, err := h.authService.CSRFToken(w, r)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		Username    string
+		Secret      string
+		QRCode      template.URL
+		CSRFToken   string
+		TOTPEnabled bool
+	}{
+		Username:    username,
+		Secret:      secret,
+		QRCode:      template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(png)),
+		CSRFToken:   csrfToken,
+		TOTPEnabled: user.TOTPEnabled,
+	}
+
+	if err := tmpl.Execute(w, data); err != nil {
+		http.Error(w, "Template execution error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *WebHandler) HandleTwoFactorSetup(w http.ResponseWriter, r *http.Request) {
+	user, pending, ok := h.twoFactorSetupUser(r)
+	if !ok {
+		w.Header().Set("HX-Redirect", "/login")
+		return
+	}
+	username := user.Username
+
+	// 登録済みのユーザーが認証アプリを登録し直すときは、今の第 2 要素を確認する。
+	// セッションを奪っただけの第三者が自分の認証アプリに差し替えられないようにするため
+	if user.TOTPEnabled {
+		verified, err := h.verifyCurrentTwoFactor(w, r, user)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if !verified {
+			return
+		}
+	}
+
+	secret, ok := h.authService.TOTPEnrollmentSecret(username)
+	if !ok {
+		writeError(w, "登録の有効期限が切れました。ページを再読み込みしてください")
+		return
+	}
+
+	if !totp.Validate(strings.TrimSpace(r.FormValue("code")), secret, time.Now(), totp.DefaultOptions) {
+		writeError(w, "確認コードが正しくありません")
+		return
+	}
+
+	codes, err := auth.GenerateRecoveryCodes(auth.RecoveryCodeCount)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = auth.HashRecoveryCode(code)
+	}
+
+	if err := h.userRepo.EnableTOTP(user.ID, secret, hashes); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	h.authService.FinishTOTPEnrollment(username)
+	log.Printf("security: 2fa enabled user=%q ip=%s", username, auth.ClientIP(r))
+	h.authService.RecordEvent(r, audit.Event{Actor: username, Action: audit.ActionTwoFactorEnabled, Target: "user:" + username, OrgID: user.OrgID})
+
+	// ポリシーにより登録を求められていた場合は、ここでログインを完了する
+	if pending {
+		if err := h.completeLogin(w, r, username); err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	renderPartial(w, "recovery-codes", codes)
+}
+
+// twoFactorSetupUser は認証アプリを登録できるユーザーを返す。2 つ目の戻り値は保留ログインからの登録かどうか。
+// 保留ログインから登録できるのは、ポリシーで登録を求められていてまだ登録していないユーザーだけ
+// （登録済みのユーザーが登録し直してログインを完了できると、第 2 要素を確認せずにログインできてしまう）
+func (h *WebHandler) twoFactorSetupUser(r *http.Request) (*repository.User, bool, bool) {
+	if session, ok := h.authService.GetSessionFromRequest(r); ok {
+		user, err := h.userRepo.GetUserByUsername(session.Username)
+		if err != nil {
+			return nil, false, false
+		}
+		return user, false, true
+	}
+	if pending, ok := h.authService.GetPendingLoginFromRequest(r); ok {
+		user, err := h.userRepo.GetUserByUsername(pending.Username)
+		if err != nil || user.TOTPEnabled || !h.twoFactorPolicy.Requires(user.Role) {
+			return nil, false, false
+		}
+		return user, true, true
+	}
+	return nil, false, false
+}
+
+// verifyCurrentTwoFactor は current_code の TOTP コードかリカバリーコードを確認する。
+// 確認できなかった場合はエラーメッセージを書き込んで false を返す
+func (h *WebHandler) verifyCurrentTwoFactor(w http.ResponseWriter, r *http.Request, user *repository.User) (bool, error) {
+	ip := auth.ClientIP(r)
+	limitKeys := []string{"2fa:" + user.Username}
+	if h.limiter != nil {
+		allowed, retryAfter, err := h.limiter.Allow(r.Context(), limitKeys...)
+		if err != nil {
+			return false, err
+		}
+		if !allowed {
+			log.Printf("security: 2fa blocked user=%q ip=%s retry_after=%s", user.Username, ip, retryAfter)
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			w.WriteHeader(http.StatusTooManyRequests)
+			writeError(w, "しばらくしてから再度お試しください")
+			return false, nil
+		}
+	}
+
+	code := strings.TrimSpace(r.FormValue("current_code"))
+	verified := totp.Validate(code, user.TOTPSecret, time.Now(), totp.DefaultOptions)
+	if !verified && code != "" {
+		var err error
+		verified, err = h.userRepo.ConsumeRecoveryCode(user.ID, auth.HashRecoveryCode(code))
+		if err != nil {
+			return false, err
+		}
+		if verified {
+			log.Printf("security: recovery code used user=%q ip=%s", user.Username, ip)
+			h.authService.RecordEvent(r, audit.Event{Actor: user.Username, Action: audit.ActionRecoveryCodeUsed, Target: "user:" + user.Username, OrgID: user.OrgID})
+		}
+	}
+
+	if !verified {
+		log.Printf("security: 2fa failure user=%q ip=%s", user.Username, ip)
+		h.authService.RecordEvent(r, audit.Event{Actor: user.Username, Action: audit.ActionTwoFactorFailure, Target: "user:" + user.Username, OrgID: user.OrgID})
+		if h.limiter != nil {
+			if err := h.limiter.Record(r.Context(), limitKeys...); err != nil {
+				log.Printf("ratelimit: %v", err)
+			}
+		}
+		writeError(w, "現在の確認コードが正しくありません")
+		return false, nil
+	}
+
+	if h.limiter != nil {
+		if err := h.limiter.Reset(r.Context(), limitKeys...); err != nil {
+			log.Printf("ratelimit: %v", err)
+		}
+	}
+	return true, nil
+}
+
+func (h *WebHandler) completeLogin(w http.ResponseWriter, r *http.Request, username string) error {
+	token, err := h.authService.CreateSession(username)
+	if err != nil {
+		return err
+	}
+	h.authService.CompletePendingLogin(w, r)
+	h.authService.SetSessionCookie(w, token)
+	return nil
+}
@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/auth"
+	"github.com/gensan0223/snulog/internal/auth/totp"
+	"github.com/gensan0223/snulog/internal/repository"
+)
+
+func newTwoFactorTestHandler(t *testing.T, policy auth.TwoFactorPolicy) (*WebHandler, *fakeUserRepository) {
+	t.Helper()
+
+	authService := auth.NewAuthService()
+	hash, err := authService.HashPassword("password")
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+	users := newFakeUserRepository()
+	users.addUser("alice", hash)
+	users.addUser("admin", hash)
+	users.users["admin"].Role = repository.RoleAdmin
+
+	return &WebHandler{
+		authService:     authService,
+		userRepo:        users,
+		twoFactorPolicy: policy,
+	}, users
+}
+
+func sessionCookie(t *testing.T, h *WebHandler, username string) *http.Cookie {
+	t.Helper()
+	token, err := h.authService.CreateSession(username)
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	return &http.Cookie{Name: "session_token", Value: token}
+}
+
+func postForm(handler http.HandlerFunc, path string, form url.Values, cookies []*http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	handler(w, req)
+	return w
+}
+
+func TestTwoFactorLogin(t *testing.T) {
+	h, users := newTwoFactorTestHandler(t, auth.NewTwoFactorPolicy())
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatalf("Failed to generate secret: %v", err)
+	}
+	codes, err := auth.GenerateRecoveryCodes(2)
+	if err != nil {
+		t.Fatalf("Failed to generate recovery codes: %v", err)
+	}
+	_ = users.EnableTOTP(users.users["alice"].ID, secret, []string{auth.HashRecoveryCode(codes[0]), auth.HashRecoveryCode(codes[1])})
+
+	login := func() []*http.Cookie {
+		w := postForm(h.HandleLogin, "/login", url.Values{"username": {"alice"}, "password": {"password"}}, nil)
+		if w.Header().Get("HX-Redirect") != "/login/2fa" {
+			t.Fatalf("Expected redirect to /login/2fa, got %q", w.Header().Get("HX-Redirect"))
+		}
+		if hasSessionCookie(w) {
+			t.Fatal("Expected no session before second factor")
+		}
+		return w.Result().Cookies()
+	}
+
+	t.Run("TOTP コード", func(t *testing.T) {
+		pending := login()
+
+		w := postForm(h.HandleTwoFactor, "/login/2fa", url.Values{"code": {"000000"}}, pending)
+		if hasSessionCookie(w) || !strings.Contains(w.Body.String(), "error-message") {
+			t.Fatal("Expected wrong code to be rejected")
+		}
+
+		code, _ := totp.Generate(secret, time.Now(), totp.DefaultOptions)
+		w = postForm(h.HandleTwoFactor, "/login/2fa", url.Values{"code": {code}}, pending)
+		if !hasSessionCookie(w) || w.Header().Get("HX-Redirect") != "/" {
+			t.Fatal("Expected valid TOTP code to complete login")
+		}
+	})
+
+	t.Run("リカバリーコードは一度だけ使える", func(t *testing.T) {
+		pending := login()
+		w := postForm(h.HandleTwoFactor, "/login/2fa", url.Values{"code": {strings.ToUpper(codes[0])}}, pending)
+		if !hasSessionCookie(w) {
+			t.Fatal("Expected recovery code to complete login")
+		}
+
+		pending = login()
+		w = postForm(h.HandleTwoFactor, "/login/2fa", url.Values{"code": {codes[0]}}, pending)
+		if hasSessionCookie(w) {
+			t.Error("Expected used recovery code to be rejected")
+		}
+	})
+
+	t.Run("保留ログインなし", func(t *testing.T) {
+		w := postForm(h.HandleTwoFactor, "/login/2fa", url.Values{"code": {"123456"}}, nil)
+		if w.Header().Get("HX-Redirect") != "/login" || hasSessionCookie(w) {
+			t.Error("Expected redirect to login without pending login")
+		}
+	})
+}
+
+func TestTwoFactorPolicy_RequiresEnrollment(t *testing.T) {
+	h, users := newTwoFactorTestHandler(t, auth.NewTwoFactorPolicy(repository.RoleAdmin))
+
+	// ポリシー対象外のロールは通常どおりログインできる
+	w := postForm(h.HandleLogin, "/login", url.Values{"username": {"alice"}, "password": {"password"}}, nil)
+	if !hasSessionCookie(w) {
+		t.Fatal("Expected member to log in without 2FA")
+	}
+
+	w = postForm(h.HandleLogin, "/login", url.Values{"username": {"admin"}, "password": {"password"}}, nil)
+	if w.Header().Get("HX-Redirect") != "/2fa/setup" || hasSessionCookie(w) {
+		t.Fatalf("Expected admin to be sent to enrollment, got %q", w.Header().Get("HX-Redirect"))
+	}
+	pending := w.Result().Cookies()
+
+	secret, err := h.authService.BeginTOTPEnrollment("admin")
+	if err != nil {
+		t.Fatalf("Failed to begin enrollment: %v", err)
+	}
+	code, _ := totp.Generate(secret, time.Now(), totp.DefaultOptions)
+
+	w = postForm(h.HandleTwoFactorSetup, "/2fa/setup", url.Values{"code": {code}}, pending)
+	if !hasSessionCookie(w) {
+		t.Fatal("Expected enrollment to complete the pending login")
+	}
+	if !users.users["admin"].TOTPEnabled {
+		t.Error("Expected TOTP to be enabled")
+	}
+	if strings.Count(w.Body.String(), "<code>") != auth.RecoveryCodeCount {
+		t.Errorf("Expected %d recovery codes in response", auth.RecoveryCodeCount)
+	}
+}
+
+func TestTwoFactorSetup_PendingLoginOfTOTPUser(t *testing.T) {
+	h, users := newTwoFactorTestHandler(t, auth.NewTwoFactorPolicy(repository.RoleAdmin))
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatalf("Failed to generate secret: %v", err)
+	}
+	_ = users.EnableTOTP(users.users["admin"].ID, secret, nil)
+
+	w := postForm(h.HandleLogin, "/login", url.Values{"username": {"admin"}, "password": {"password"}}, nil)
+	if w.Header().Get("HX-Redirect") != "/login/2fa" {
+		t.Fatalf("Expected redirect to /login/2fa, got %q", w.Header().Get("HX-Redirect"))
+	}
+	pending := w.Result().Cookies()
+
+	// 登録済みのユーザーは保留ログインのまま登録画面に入れない
+	req := httptest.NewRequest(http.MethodGet, "/2fa/setup", nil)
+	for _, cookie := range pending {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeTwoFactorSetup(rec, req)
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/login" {
+		t.Fatalf("Expected redirect to /login, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+
+	// 自分の認証アプリを登録し直してログインを完了することもできない
+	attacker, err := h.authService.BeginTOTPEnrollment("admin")
+	if err != nil {
+		t.Fatalf("Failed to begin enrollment: %v", err)
+	}
+	code, _ := totp.Generate(attacker, time.Now(), totp.DefaultOptions)
+	w = postForm(h.HandleTwoFactorSetup, "/2fa/setup", url.Values{"code": {code}}, pending)
+	if hasSessionCookie(w) || w.Header().Get("HX-Redirect") != "/login" {
+		t.Fatal("Expected pending login of a TOTP user to be rejected")
+	}
+	if users.users["admin"].TOTPSecret != secret {
+		t.Error("Expected TOTP secret to be unchanged")
+	}
+}
+
+func TestTwoFactorSetup_ReenrollRequiresCurrentCode(t *testing.T) {
+	h, users := newTwoFactorTestHandler(t, auth.NewTwoFactorPolicy())
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatalf("Failed to generate secret: %v", err)
+	}
+	codes, err := auth.GenerateRecoveryCodes(1)
+	if err != nil {
+		t.Fatalf("Failed to generate recovery codes: %v", err)
+	}
+	_ = users.EnableTOTP(users.users["alice"].ID, secret, []string{auth.HashRecoveryCode(codes[0])})
+	session := []*http.Cookie{sessionCookie(t, h, "alice")}
+
+	enroll := func(current string) *httptest.ResponseRecorder {
+		next, err := h.authService.BeginTOTPEnrollment("alice")
+		if err != nil {
+			t.Fatalf("Failed to begin enrollment: %v", err)
+		}
+		code, _ := totp.Generate(next, time.Now(), totp.DefaultOptions)
+		return postForm(h.HandleTwoFactorSetup, "/2fa/setup", url.Values{"code": {code}, "current_code": {current}}, session)
+	}
+
+	for _, current := range []string{"", "000000"} {
+		w := enroll(current)
+		if !strings.Contains(w.Body.String(), "error-message") || users.users["alice"].TOTPSecret != secret {
+			t.Fatalf("Expected re-enrollment with current_code %q to be rejected", current)
+		}
+	}
+
+	current, _ := totp.Generate(secret, time.Now(), totp.DefaultOptions)
+	if w := enroll(current); strings.Count(w.Body.String(), "<code>") != auth.RecoveryCodeCount {
+		t.Fatal("Expected re-enrollment with the current TOTP code to succeed")
+	}
+	if users.users["alice"].TOTPSecret == secret {
+		t.Error("Expected TOTP secret to be replaced")
+	}
+}
@@ -3,15 +3,22 @@ package handler
 import (
 	"context"
 	"database/sql"
-	"fmt"
-	"html/template"
+	"errors"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/gensan0223/snulog/internal/audit"
 	"github.com/gensan0223/snulog/internal/auth"
+	"github.com/gensan0223/snulog/internal/mail"
+	"github.com/gensan0223/snulog/internal/ratelimit"
 	"github.com/gensan0223/snulog/internal/repository"
+	"github.com/gensan0223/snulog/internal/tenant"
 	pb "github.com/gensan0223/snulog/proto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
@@ -19,14 +26,66 @@ type WebHandler struct {
 	grpcAddr    string
 	authService *auth.AuthService
 	userRepo    repository.UserRepository
+	oidc        *auth.OIDCProvider
+	limiter     *ratelimit.Limiter
+
+	twoFactorPolicy auth.TwoFactorPolicy
+	mailer          mail.Mailer
+	baseURL         string
+	tokenSigner     *auth.TokenSigner
+	transportCreds  credentials.TransportCredentials
 }
 
-func NewWebHandler(grpcAddr string, db *sql.DB) *WebHandler {
-	return &WebHandler{
+type Option func(*WebHandler)
+
+// WithOIDC はフォームログインに加えて OIDC ログインを有効にする
+func WithOIDC(provider *auth.OIDCProvider) Option {
+	return func(h *WebHandler) {
+		h.oidc = provider
+	}
+}
+
+// WithLoginLimiter はログイン試行の制限に使う Limiter を差し替える
+func WithLoginLimiter(limiter *ratelimit.Limiter) Option {
+	return func(h *WebHandler) {
+		h.limiter = limiter
+	}
+}
+
+// WithTokenSigner は gRPC 呼び出しに付与するトークンの署名鍵を差し替える
+func WithTokenSigner(signer *auth.TokenSigner) Option {
+	return func(h *WebHandler) {
+		h.tokenSigner = signer
+	}
+}
+
+// WithTransportCredentials は gRPC サーバーへの接続に使う TLS 設定を指定する（既定は平文）
+func WithTransportCredentials(creds credentials.TransportCredentials) Option {
+	return func(h *WebHandler) {
+		h.transportCreds = creds
+	}
+}
+
+func NewWebHandler(grpcAddr string, db *sql.DB, opts ...Option) *WebHandler {
+	h := &WebHandler{
 		grpcAddr:    grpcAddr,
 		authService: auth.NewAuthService(),
 		userRepo:    repository.NewPostgresUserRepository(db),
+		limiter:     ratelimit.NewLimiter("login", repository.NewPostgresAttemptRepository(db), ratelimit.DefaultLoginPolicy),
+	}
+	h.authService.SetRecorder(audit.NewRecorder(repository.NewPostgresAuditRepository(db)))
+	for _, opt := range opts {
+		opt(h)
+	}
+	if h.tokenSigner == nil {
+		h.tokenSigner = auth.TokenSignerFromEnv()
 	}
+	return h
+}
+
+// CSRFMiddleware は POST などの状態変更リクエストに CSRF トークンを要求する
+func (h *WebHandler) CSRFMiddleware(next http.Handler) http.Handler {
+	return h.authService.CSRFMiddleware(next)
 }
 
 func (h *WebHandler) ServeIndex(w http.ResponseWriter, r *http.Request) {
@@ -36,16 +95,18 @@ func (h *WebHandler) ServeIndex(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tmpl, err := template.ParseFiles("web/templates/index.html")
+	tmpl, err := parsePage("index.html")
 	if err != nil {
 		http.Error(w, "Template error", http.StatusInternalServerError)
 		return
 	}
 
 	data := struct {
-		Username string
+		Username  string
+		CSRFToken string
 	}{
-		Username: session.Username,
+		Username:  session.Username,
+		CSRFToken: session.CSRFToken,
 	}
 
 	if err := tmpl.Execute(w, data); err != nil {
@@ -61,13 +122,29 @@ func (h *WebHandler) ServeLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tmpl, err := template.ParseFiles("web/templates/login.html")
+	tmpl, err := parsePage("login.html")
 	if err != nil {
 		http.Error(w, "Template error", http.StatusInternalServerError)
 		return
 	}
 
-	if err := tmpl.Execute(w, nil); err != nil {
+	csrfToken, err := h.authService.CSRFToken(w, r)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		OIDCEnabled bool
+		Error       string
+		CSRFToken   string
+	}{
+		OIDCEnabled: h.oidc != nil,
+		Error:       r.URL.Query().Get("error"),
+		CSRFToken:   csrfToken,
+	}
+
+	if err := tmpl.Execute(w, data); err != nil {
 		http.Error(w, "Template execution error", http.StatusInternalServerError)
 		return
 	}
@@ -83,44 +160,217 @@ func (h *WebHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	password := r.FormValue("password")
 
 	if username == "" || password == "" {
-		w.Header().Set("Content-Type", "text/html")
-		if _, err := fmt.Fprint(w, `<div class="error-message">ユーザー名とパスワードを入力してください</div>`); err != nil {
+		writeError(w, "ユーザー名とパスワードを入力してください")
+		return
+	}
+
+	ip := auth.ClientIP(r)
+	limitKeys := []string{"user:" + username, "ip:" + ip}
+	if h.limiter != nil {
+		allowed, retryAfter, err := h.limiter.Allow(r.Context(), limitKeys...)
+		if err != nil {
+			log.Printf("ratelimit: %v", err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if !allowed {
+			log.Printf("security: login blocked user=%q ip=%s retry_after=%s", username, ip, retryAfter)
+			h.authService.RecordEvent(r, audit.Event{Actor: "anonymous", Action: audit.ActionLoginBlocked, Target: "user:" + username})
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			w.WriteHeader(http.StatusTooManyRequests)
+			writeError(w, "ログインできません。しばらくしてから再度お試しください")
+			return
 		}
-		return
 	}
 
 	user, err := h.userRepo.GetUserByUsername(username)
-	if err != nil {
-		w.Header().Set("Content-Type", "text/html")
-		if _, writeErr := fmt.Fprint(w, `<div class="error-message">ユーザー名またはパスワードが間違っています</div>`); writeErr != nil {
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+	if err != nil || !h.authService.CheckPassword(password, user.PasswordHash) {
+		log.Printf("security: login failure user=%q ip=%s", username, ip)
+		event := audit.Event{Actor: "anonymous", Action: audit.ActionLoginFailure, Target: "user:" + username}
+		if user != nil {
+			event.OrgID = user.OrgID
+		}
+		h.authService.RecordEvent(r, event)
+		if h.limiter != nil {
+			if err := h.limiter.Record(r.Context(), limitKeys...); err != nil {
+				log.Printf("ratelimit: %v", err)
+			}
 		}
+		writeError(w, "ユーザー名またはパスワードが間違っています")
 		return
 	}
 
-	if !h.authService.CheckPassword(password, user.PasswordHash) {
-		w.Header().Set("Content-Type", "text/html")
-		if _, writeErr := fmt.Fprint(w, `<div class="error-message">ユーザー名またはパスワードが間違っています</div>`); writeErr != nil {
+	// IP の失敗回数は戻さない。自分のアカウントでのログインを挟んで他人のパスワードを試せてしまう
+	if h.limiter != nil {
+		if err := h.limiter.Reset(r.Context(), "user:"+username); err != nil {
+			log.Printf("ratelimit: %v", err)
+		}
+	}
+
+	// 2 段階認証が有効、またはロールで必須の場合は確認コードの入力へ進む
+	if user.TOTPEnabled || h.twoFactorPolicy.Requires(user.Role) {
+		pendingToken, err := h.authService.CreatePendingLogin(username)
+		if err != nil {
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		h.authService.SetPendingLoginCookie(w, pendingToken)
+		if user.TOTPEnabled {
+			w.Header().Set("HX-Redirect", "/login/2fa")
+		} else {
+			w.Header().Set("HX-Redirect", "/2fa/setup")
 		}
 		return
 	}
 
 	token, err := h.authService.CreateSession(username)
 	if err != nil {
-		w.Header().Set("Content-Type", "text/html")
-		if _, writeErr := fmt.Fprint(w, `<div class="error-message">ログインに失敗しました</div>`); writeErr != nil {
+		writeError(w, "ログインに失敗しました")
+		return
+	}
+
+	log.Printf("security: login success user=%q ip=%s", username, ip)
+	h.authService.RecordEvent(r, audit.Event{Actor: username, Action: audit.ActionLoginSuccess, Target: "user:" + username, OrgID: user.OrgID})
+	h.authService.SetSessionCookie(w, token)
+	w.Header().Set("HX-Redirect", "/")
+}
+
+func (h *WebHandler) HandleOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	if h.oidc == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	url, state, err := h.oidc.AuthCodeURL()
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	h.oidc.SetStateCookie(w, state)
+	http.Redirect(w, r, url, http.StatusFound)
+}
+
+func (h *WebHandler) HandleOIDCCallback(w http.ResponseWriter, r *http.Request) {
+	if h.oidc == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	query := r.URL.Query()
+	if errCode := query.Get("error"); errCode != "" {
+		log.Printf("oidc: authorization failed: %s %s", errCode, query.Get("error_description"))
+		http.Redirect(w, r, "/login?error=oidc", http.StatusSeeOther)
+		return
+	}
+
+	// 別のブラウザで始めた認可リクエストのコールバックは受け付けない（ログイン CSRF 対策）
+	state := query.Get("state")
+	if err := h.oidc.CheckStateCookie(w, r, state); err != nil {
+		log.Printf("security: oidc state does not match the browser ip=%s", auth.ClientIP(r))
+		http.Redirect(w, r, "/login?error=oidc", http.StatusSeeOther)
+		return
+	}
+
+	identity, err := h.oidc.Exchange(r.Context(), state, query.Get("code"))
+	if err != nil {
+		log.Printf("oidc: %v", err)
+		http.Redirect(w, r, "/login?error=oidc", http.StatusSeeOther)
+		return
+	}
+
+	user, err := h.ensureOIDCUser(identity)
+	if err != nil {
+		log.Printf("oidc: user %q: %v", identity.Username, err)
+		http.Redirect(w, r, "/login?error=oidc", http.StatusSeeOther)
+		return
+	}
+
+	// IdP の多要素認証とは別に、snulog で 2 段階認証を登録したユーザーやポリシーの対象には確認コードを求める
+	if user.TOTPEnabled || h.twoFactorPolicy.Requires(user.Role) {
+		pendingToken, err := h.authService.CreatePendingLogin(user.Username)
+		if err != nil {
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		h.authService.SetPendingLoginCookie(w, pendingToken)
+		if user.TOTPEnabled {
+			http.Redirect(w, r, "/login/2fa", http.StatusSeeOther)
+		} else {
+			http.Redirect(w, r, "/2fa/setup", http.StatusSeeOther)
 		}
 		return
 	}
 
+	token, err := h.authService.CreateSession(user.Username)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("security: login success user=%q ip=%s oidc=true", user.Username, auth.ClientIP(r))
+	h.authService.RecordEvent(r, audit.Event{Actor: user.Username, Action: audit.ActionLoginSuccess, Target: "user:" + user.Username, After: map[string]any{"oidc": true}, OrgID: user.OrgID})
 	h.authService.SetSessionCookie(w, token)
-	w.Header().Set("HX-Redirect", "/")
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// errOIDCAccountConflict は IdP のユーザー名が、IdP に結び付いていない既存のユーザーと重なったことを表す
+var errOIDCAccountConflict = errors.New("username belongs to a local account that is not linked to the IdP")
+
+// errOIDCUserNotProvisioned は自動作成が無効で、IdP のユーザーに対応するアカウントが無いことを表す
+var errOIDCUserNotProvisioned = errors.New("no account is linked to the IdP user and auto-provisioning is disabled")
+
+// ensureOIDCUser は IdP の sub に結び付いたユーザーを返し、なければ自動作成する。
+// 同じ名前のパスワードのユーザーがいても暗黙に結び付けない（IdP 側で名前を名乗れば乗っ取れてしまう）
+func (h *WebHandler) ensureOIDCUser(identity *auth.OIDCIdentity) (*repository.User, error) {
+	user, err := h.userRepo.GetUserByOIDCSubject(identity.Subject)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	if _, err := h.userRepo.GetUserByUsername(identity.Username); err == nil {
+		return nil, errOIDCAccountConflict
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if !h.oidc.AutoProvision() {
+		return nil, errOIDCUserNotProvisioned
+	}
+
+	// フォームログインできないよう、ランダムなパスワードのハッシュを設定する
+	password, err := h.authService.GenerateSessionToken()
+	if err != nil {
+		return nil, err
+	}
+	hash, err := h.authService.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+	// IdP から作成したユーザーは既定の組織に所属させる
+	user = &repository.User{OrgID: tenant.DefaultOrgID, Username: identity.Username, PasswordHash: hash, OIDCSubject: identity.Subject}
+	if err := h.userRepo.CreateUser(user); err != nil {
+		return nil, err
+	}
+	return user, nil
 }
 
 func (h *WebHandler) HandleLogout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if session, authenticated := h.authService.GetSessionFromRequest(r); authenticated {
+		event := audit.Event{Actor: session.Username, Action: audit.ActionLogout, Target: "user:" + session.Username}
+		if user, err := h.userRepo.GetUserByUsername(session.Username); err == nil {
+			event.OrgID = user.OrgID
+		}
+		h.authService.RecordEvent(r, event)
+	}
+
 	cookie, err := r.Cookie("session_token")
 	if err == nil {
 		h.authService.DeleteSession(cookie.Value)
@@ -138,10 +388,7 @@ func (h *WebHandler) AddLog(w http.ResponseWriter, r *http.Request) {
 
 	session, authenticated := h.authService.GetSessionFromRequest(r)
 	if !authenticated {
-		w.Header().Set("Content-Type", "text/html")
-		if _, writeErr := fmt.Fprint(w, `<div class="error-message">ログインが必要です</div>`); writeErr != nil {
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-		}
+		writeError(w, "ログインが必要です")
 		return
 	}
 
@@ -150,19 +397,20 @@ func (h *WebHandler) AddLog(w http.ResponseWriter, r *http.Request) {
 	feeling := r.FormValue("feeling")
 
 	if status == "" || feeling == "" {
-		w.Header().Set("Content-Type", "text/html")
-		if _, writeErr := fmt.Fprint(w, `<div class="error-message">すべてのフィールドを入力してください</div>`); writeErr != nil {
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-		}
+		writeError(w, "すべてのフィールドを入力してください")
 		return
 	}
 
-	conn, err := grpc.NewClient(h.grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	visibility, ok := parseVisibility(r.FormValue("visibility"))
+	if !ok {
+		writeError(w, "公開範囲が正しくありません")
+		return
+	}
+
+	conn, err := h.dial()
 	if err != nil {
-		w.Header().Set("Content-Type", "text/html")
-		if _, writeErr := fmt.Fprintf(w, `<div class="error-message">サーバー接続エラー: %v</div>`, err); writeErr != nil {
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-		}
+		log.Printf("grpc: connect %s: %v", h.grpcAddr, err)
+		writeError(w, "サーバーに接続できませんでした")
 		return
 	}
 	defer func() {
@@ -170,47 +418,56 @@ func (h *WebHandler) AddLog(w http.ResponseWriter, r *http.Request) {
 	}()
 
 	client := pb.NewLogServiceClient(conn)
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	ctx, cancel, err := h.rpcContext(session.Username)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
 	defer cancel()
 
 	entry := &pb.LogEntry{
-		UserName:  userName,
-		Status:    status,
-		Feeling:   feeling,
-		Timestamp: time.Now().Format(time.RFC3339),
+		UserName:   userName,
+		Status:     status,
+		Feeling:    feeling,
+		Timestamp:  time.Now().Format(time.RFC3339),
+		Visibility: visibility,
 	}
 
-	resp, err := client.AddLogs(ctx, entry)
-	if err != nil {
-		w.Header().Set("Content-Type", "text/html")
-		if _, writeErr := fmt.Fprintf(w, `<div class="error-message">ログの追加に失敗しました: %v</div>`, err); writeErr != nil {
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-		}
+	if _, err := client.AddLogs(ctx, entry); err != nil {
+		writeError(w, rpcErrorMessage("ログの追加", err))
 		return
 	}
 
-	w.Header().Set("Content-Type", "text/html")
-	if _, writeErr := fmt.Fprintf(w, `<div class="success-message">✅ ログが正常に追加されました: %s</div>`, resp.Message); writeErr != nil {
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	// 一覧を再読み込みさせる
+	w.Header().Set("HX-Trigger", "refresh")
+	writeSuccess(w, "ログが正常に追加されました")
+}
+
+// parseVisibility はフォームの公開範囲を変換する。未指定はチーム全員
+func parseVisibility(value string) (pb.Visibility, bool) {
+	switch value {
+	case "", "team":
+		return pb.Visibility_VISIBILITY_TEAM, true
+	case "managers":
+		return pb.Visibility_VISIBILITY_MANAGERS, true
+	case "private":
+		return pb.Visibility_VISIBILITY_PRIVATE, true
+	default:
+		return pb.Visibility_VISIBILITY_UNSPECIFIED, false
 	}
 }
 
 func (h *WebHandler) GetLogs(w http.ResponseWriter, r *http.Request) {
-	_, authenticated := h.authService.GetSessionFromRequest(r)
+	session, authenticated := h.authService.GetSessionFromRequest(r)
 	if !authenticated {
-		w.Header().Set("Content-Type", "text/html")
-		if _, writeErr := fmt.Fprint(w, `<div class="error-message">ログインが必要です</div>`); writeErr != nil {
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-		}
+		writeError(w, "ログインが必要です")
 		return
 	}
 
-	conn, err := grpc.NewClient(h.grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := h.dial()
 	if err != nil {
-		w.Header().Set("Content-Type", "text/html")
-		if _, writeErr := fmt.Fprintf(w, `<div class="error-message">サーバー接続エラー: %v</div>`, err); writeErr != nil {
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-		}
+		log.Printf("grpc: connect %s: %v", h.grpcAddr, err)
+		writeError(w, "サーバーに接続できませんでした")
 		return
 	}
 	defer func() {
@@ -218,38 +475,53 @@ func (h *WebHandler) GetLogs(w http.ResponseWriter, r *http.Request) {
 	}()
 
 	client := pb.NewLogServiceClient(conn)
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	ctx, cancel, err := h.rpcContext(session.Username)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
 	defer cancel()
 
 	resp, err := client.FetchLogs(ctx, &pb.FetchRequest{
 		TeamId: "default",
 	})
 	if err != nil {
-		w.Header().Set("Content-Type", "text/html")
-		if _, writeErr := fmt.Fprintf(w, `<div class="error-message">ログの取得に失敗しました: %v</div>`, err); writeErr != nil {
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-		}
+		writeError(w, rpcErrorMessage("ログの取得", err))
 		return
 	}
 
-	w.Header().Set("Content-Type", "text/html")
+	renderPartial(w, "log-list", logListData{Logs: resp.Logs})
+}
 
-	if len(resp.Logs) == 0 {
-		if _, writeErr := fmt.Fprint(w, `<p>まだログがありません</p>`); writeErr != nil {
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-		}
-		return
+// logListData は log-list テンプレートに渡す一覧
+type logListData struct {
+	Logs []*pb.LogEntry
+}
+
+func (h *WebHandler) dial() (*grpc.ClientConn, error) {
+	creds := h.transportCreds
+	if creds == nil {
+		creds = insecure.NewCredentials()
 	}
+	return grpc.NewClient(h.grpcAddr, grpc.WithTransportCredentials(creds))
+}
 
-	for _, log := range resp.Logs {
-		if _, writeErr := fmt.Fprintf(w, `
-			<div class="log-entry">
-				<strong>👤 %s</strong> - 📝 %s - 😀 %s
-				<div class="log-meta">🕒 %s</div>
-			</div>
-		`, log.UserName, log.Status, log.Feeling, log.Timestamp); writeErr != nil {
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-			return
-		}
+// rpcContext はセッションのユーザーとして gRPC を呼び出すための context を返す
+func (h *WebHandler) rpcContext(username string) (context.Context, context.CancelFunc, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	ctx, err := h.streamContext(ctx, username)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	return ctx, cancel, nil
+}
+
+// streamContext は rpcContext と違いタイムアウトを付けない。件数の多いストリームを parent（リクエスト）が終わるまで受け取る
+func (h *WebHandler) streamContext(parent context.Context, username string) (context.Context, error) {
+	token, err := h.tokenSigner.Issue(username, auth.ServiceTokenTTL)
+	if err != nil {
+		return nil, err
 	}
+	return auth.OutgoingContext(parent, token), nil
 }
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gensan0223/snulog/internal/auth"
+	"github.com/gensan0223/snulog/internal/ratelimit"
+	"github.com/gensan0223/snulog/internal/repository"
+)
+
+func postLogin(h *WebHandler, username, password string) *httptest.ResponseRecorder {
+	return postLoginFrom(h, "192.0.2.10", username, password)
+}
+
+func postLoginFrom(h *WebHandler, ip, username, password string) *httptest.ResponseRecorder {
+	form := url.Values{"username": {username}, "password": {password}}
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.RemoteAddr = ip + ":51234"
+
+	w := httptest.NewRecorder()
+	h.HandleLogin(w, req)
+	return w
+}
+
+func TestHandleLogin_RateLimit(t *testing.T) {
+	authService := auth.NewAuthService()
+	hash, err := authService.HashPassword("password")
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+	users := newFakeUserRepository()
+	users.addUser("alice", hash)
+
+	h := &WebHandler{
+		authService: authService,
+		userRepo:    users,
+		limiter:     ratelimit.NewLimiter("login", repository.NewInMemoryAttemptRepository(), ratelimit.DefaultLoginPolicy),
+	}
+
+	for i := 0; i < ratelimit.DefaultLoginPolicy.MaxAttempts; i++ {
+		w := postLogin(h, "alice", "wrong")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200 for failed attempt %d, got %d", i+1, w.Code)
+		}
+	}
+
+	// ロック中は正しいパスワードでもログインできない
+	w := postLogin(h, "alice", "password")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status 429, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("Expected Retry-After header")
+	}
+	if w.Header().Get("HX-Redirect") != "" {
+		t.Error("Expected no redirect while locked out")
+	}
+
+	// 未登録ユーザーでも同じ IP からの試行はブロックされる
+	w = postLogin(h, "nobody", "password")
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status 429 for same IP, got %d", w.Code)
+	}
+}
+
+func TestHandleLogin_SuccessResetsFailures(t *testing.T) {
+	authService := auth.NewAuthService()
+	hash, err := authService.HashPassword("password")
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+	users := newFakeUserRepository()
+	users.addUser("alice", hash)
+
+	h := &WebHandler{
+		authService: authService,
+		userRepo:    users,
+		limiter:     ratelimit.NewLimiter("login", repository.NewInMemoryAttemptRepository(), ratelimit.DefaultLoginPolicy),
+	}
+
+	for i := 0; i < ratelimit.DefaultLoginPolicy.MaxAttempts-1; i++ {
+		postLoginFrom(h, "192.0.2.10", "alice", "wrong")
+	}
+	if w := postLoginFrom(h, "192.0.2.11", "alice", "password"); w.Header().Get("HX-Redirect") != "/" {
+		t.Fatalf("Expected successful login")
+	}
+
+	// 成功後はユーザーの失敗回数がリセットされている
+	for i := 0; i < ratelimit.DefaultLoginPolicy.MaxAttempts-1; i++ {
+		postLoginFrom(h, "192.0.2.12", "alice", "wrong")
+	}
+	if w := postLoginFrom(h, "192.0.2.13", "alice", "password"); w.Header().Get("HX-Redirect") != "/" {
+		t.Error("Expected login to succeed after reset")
+	}
+}
+
+func TestHandleLogin_SuccessKeepsIPFailures(t *testing.T) {
+	authService := auth.NewAuthService()
+	hash, err := authService.HashPassword("password")
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+	users := newFakeUserRepository()
+	users.addUser("mallory", hash)
+
+	h := &WebHandler{
+		authService: authService,
+		userRepo:    users,
+		limiter:     ratelimit.NewLimiter("login", repository.NewInMemoryAttemptRepository(), ratelimit.DefaultLoginPolicy),
+	}
+
+	// 自分のアカウントでのログインを挟んでも、同じ IP から他人を試した回数は数え続ける
+	for i := 0; i < ratelimit.DefaultLoginPolicy.MaxAttempts; i++ {
+		postLogin(h, fmt.Sprintf("user%d", i), "guess")
+		if i < ratelimit.DefaultLoginPolicy.MaxAttempts-1 {
+			if w := postLogin(h, "mallory", "password"); w.Header().Get("HX-Redirect") != "/" {
+				t.Fatalf("Expected mallory to log in after %d guesses", i+1)
+			}
+		}
+	}
+	if w := postLogin(h, "alice", "guess"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status 429 for the sprayed IP, got %d", w.Code)
+	}
+}
@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gensan0223/snulog/internal/auth"
+	"github.com/gensan0223/snulog/internal/auth/oidctest"
+	"github.com/gensan0223/snulog/internal/repository"
+)
+
+func newOIDCTestHandler(t *testing.T, idp *oidctest.IdP, autoProvision bool, users *fakeUserRepository) *WebHandler {
+	t.Helper()
+
+	provider, err := auth.NewOIDCProvider(context.Background(), auth.OIDCConfig{
+		IssuerURL:     idp.URL,
+		ClientID:      "snulog",
+		RedirectURL:   "http://localhost:8080/auth/oidc/callback",
+		AutoProvision: autoProvision,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create OIDC provider: %v", err)
+	}
+	return &WebHandler{
+		authService: auth.NewAuthService(),
+		userRepo:    users,
+		oidc:        provider,
+	}
+}
+
+// runOIDCFlow はログイン開始から IdP を経由してコールバックまでを同じブラウザで実行する
+func runOIDCFlow(t *testing.T, h *WebHandler) *httptest.ResponseRecorder {
+	t.Helper()
+	return runOIDCFlowWithCookies(t, h, func(cookies []*http.Cookie) []*http.Cookie { return cookies })
+}
+
+// runOIDCFlowWithCookies はログイン開始で受け取った Cookie を browser で差し替えてからコールバックを呼ぶ
+func runOIDCFlowWithCookies(t *testing.T, h *WebHandler, browser func([]*http.Cookie) []*http.Cookie) *httptest.ResponseRecorder {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	h.HandleOIDCLogin(w, httptest.NewRequest(http.MethodGet, "/auth/oidc/login", nil))
+	if w.Code != http.StatusFound {
+		t.Fatalf("Expected status 302, got %d", w.Code)
+	}
+	cookies := browser(w.Result().Cookies())
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Get(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("Failed to call IdP: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("Invalid callback URL: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/oidc/callback?"+callback.RawQuery, nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	w = httptest.NewRecorder()
+	h.HandleOIDCCallback(w, req)
+	return w
+}
+
+func hasSessionCookie(w *httptest.ResponseRecorder) bool {
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == "session_token" && cookie.Value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func TestOIDCLogin_LinkedUser(t *testing.T) {
+	// IdP での名前が変わっても sub で同じユーザーとしてログインする
+	idp := oidctest.NewIdP(map[string]any{"sub": "alice-sub", "preferred_username": "alice.renamed"})
+	defer idp.Close()
+
+	users := newFakeUserRepository("alice")
+	users.users["alice"].OIDCSubject = "alice-sub"
+	h := newOIDCTestHandler(t, idp, false, users)
+	w := runOIDCFlow(t, h)
+
+	if w.Header().Get("Location") != "/" {
+		t.Errorf("Expected redirect to /, got %s", w.Header().Get("Location"))
+	}
+	if !hasSessionCookie(w) {
+		t.Error("Expected session cookie to be set")
+	}
+}
+
+func TestOIDCLogin_DoesNotLinkLocalAccount(t *testing.T) {
+	// IdP で alice を名乗っても、IdP に結び付いていないパスワードの alice にはならない
+	idp := oidctest.NewIdP(map[string]any{"sub": "attacker", "preferred_username": "alice"})
+	defer idp.Close()
+
+	users := newFakeUserRepository("alice")
+	users.users["alice"].Role = repository.RoleAdmin
+	h := newOIDCTestHandler(t, idp, true, users)
+	w := runOIDCFlow(t, h)
+
+	if w.Header().Get("Location") != "/login?error=oidc" {
+		t.Errorf("Expected redirect to login error, got %s", w.Header().Get("Location"))
+	}
+	if hasSessionCookie(w) {
+		t.Error("Expected no session cookie")
+	}
+	if users.users["alice"].OIDCSubject != "" {
+		t.Error("既存のユーザーが IdP に結び付けられてはいけない")
+	}
+}
+
+func TestOIDCLogin_RequiresLocalTOTP(t *testing.T) {
+	idp := oidctest.NewIdP(map[string]any{"sub": "alice-sub", "preferred_username": "alice"})
+	defer idp.Close()
+
+	users := newFakeUserRepository("alice")
+	users.users["alice"].OIDCSubject = "alice-sub"
+	users.users["alice"].TOTPEnabled = true
+	h := newOIDCTestHandler(t, idp, false, users)
+	w := runOIDCFlow(t, h)
+
+	if w.Header().Get("Location") != "/login/2fa" {
+		t.Errorf("Expected redirect to /login/2fa, got %s", w.Header().Get("Location"))
+	}
+	if hasSessionCookie(w) {
+		t.Error("確認コードの前にセッションを発行してはいけない")
+	}
+}
+
+func TestOIDCLogin_RejectsCallbackFromAnotherBrowser(t *testing.T) {
+	idp := oidctest.NewIdP(map[string]any{"sub": "alice-sub", "preferred_username": "alice"})
+	defer idp.Close()
+
+	users := newFakeUserRepository("alice")
+	users.users["alice"].OIDCSubject = "alice-sub"
+	h := newOIDCTestHandler(t, idp, false, users)
+
+	tests := []struct {
+		name    string
+		browser func([]*http.Cookie) []*http.Cookie
+	}{
+		{"no state cookie", func([]*http.Cookie) []*http.Cookie { return nil }},
+		{"state of another login", func([]*http.Cookie) []*http.Cookie {
+			return []*http.Cookie{{Name: "oidc_state", Value: "another-state"}}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := runOIDCFlowWithCookies(t, h, tt.browser)
+			if w.Header().Get("Location") != "/login?error=oidc" {
+				t.Errorf("Expected redirect to login error, got %s", w.Header().Get("Location"))
+			}
+			if hasSessionCookie(w) {
+				t.Error("Expected no session cookie")
+			}
+		})
+	}
+}
+
+func TestOIDCLogin_UnknownUserWithoutProvisioning(t *testing.T) {
+	idp := oidctest.NewIdP(map[string]any{"preferred_username": "carol"})
+	defer idp.Close()
+
+	users := newFakeUserRepository("alice")
+	h := newOIDCTestHandler(t, idp, false, users)
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+	w := runOIDCFlow(t, h)
+
+	if w.Header().Get("Location") != "/login?error=oidc" {
+		t.Errorf("Expected redirect to login error, got %s", w.Header().Get("Location"))
+	}
+	if hasSessionCookie(w) {
+		t.Error("Expected no session cookie")
+	}
+	if _, exists := users.users["carol"]; exists {
+		t.Error("Expected user not to be provisioned")
+	}
+	if !strings.Contains(logs.String(), errOIDCUserNotProvisioned.Error()) {
+		t.Errorf("Expected callback to log %q, got %q", errOIDCUserNotProvisioned, logs.String())
+	}
+
+	_, err := h.ensureOIDCUser(&auth.OIDCIdentity{Subject: "carol-sub", Username: "carol"})
+	if !errors.Is(err, errOIDCUserNotProvisioned) {
+		t.Errorf("Expected errOIDCUserNotProvisioned, got %v", err)
+	}
+}
+
+func TestOIDCLogin_AutoProvision(t *testing.T) {
+	idp := oidctest.NewIdP(map[string]any{"preferred_username": "carol"})
+	defer idp.Close()
+
+	users := newFakeUserRepository("alice")
+	h := newOIDCTestHandler(t, idp, true, users)
+	w := runOIDCFlow(t, h)
+
+	if !hasSessionCookie(w) {
+		t.Fatal("Expected session cookie to be set")
+	}
+	user, exists := users.users["carol"]
+	if !exists {
+		t.Fatal("Expected user to be provisioned")
+	}
+	if user.PasswordHash == "" {
+		t.Error("Expected provisioned user to have an unusable password hash")
+	}
+	if user.OIDCSubject != "test-subject" {
+		t.Errorf("Expected provisioned user to be linked to the IdP subject, got %q", user.OIDCSubject)
+	}
+}
+
+func TestOIDCLogin_Disabled(t *testing.T) {
+	h := &WebHandler{authService: auth.NewAuthService()}
+
+	w := httptest.NewRecorder()
+	h.HandleOIDCLogin(w, httptest.NewRequest(http.MethodGet, "/auth/oidc/login", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/gensan0223/snulog/internal/auth"
 )
 
 // TestHTTPMethodValidation tests HTTP method validation without database dependency
@@ -92,3 +94,16 @@ func TestHTMLResponseGeneration(t *testing.T) {
 		})
 	}
 }
+
+// TestHandleLogout_RequiresPost tests that logout cannot be triggered by GET
+func TestHandleLogout_RequiresPost(t *testing.T) {
+	h := &WebHandler{authService: auth.NewAuthService()}
+
+	req := httptest.NewRequest(http.MethodGet, "/logout", nil)
+	w := httptest.NewRecorder()
+	h.HandleLogout(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405, got %d", w.Code)
+	}
+}
@@ -0,0 +1,193 @@
+// Package heatmap は GitHub の contribution グラフのように、日ごとの投稿数や気分を曜日×週の格子にして端末に描く。
+// 色を使えないときは濃淡や高さの違う文字で描く
+package heatmap
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/mood"
+	"golang.org/x/text/width"
+)
+
+type Metric string
+
+const (
+	// Count は投稿数で濃くする
+	Count Metric = "count"
+	// Mood は気分の点数で赤から緑にする
+	Mood Metric = "mood"
+)
+
+// Day は 1 日の集計。Scored が 0 なら Mood は使わない
+type Day struct {
+	Count  int64
+	Mood   float64
+	Scored int64
+}
+
+type Options struct {
+	// From と To の日付（両端を含む）を描く。時刻は無視する
+	From, To time.Time
+	Metric   Metric
+	// Color が false なら ANSI の色を使わない
+	Color bool
+}
+
+// セルの種類。levels の添字
+const (
+	// empty はログのない日
+	empty = iota
+	// unscored はログはあるが気分を点数にできなかった日（Mood のみ）
+	unscored
+	firstLevel
+)
+
+type palette struct {
+	// glyphs は色を使わないときの文字、colors は 256 色の番号。どちらも empty, unscored, 各段階の順
+	glyphs []string
+	colors []int
+	// low と high は凡例の両端
+	low, high string
+}
+
+var palettes = map[Metric]palette{
+	Count: {
+		glyphs: []string{"·", "·", "░", "▒", "▓", "█"},
+		colors: []int{237, 237, 22, 28, 34, 40},
+		low:    "少", high: "多",
+	},
+	Mood: {
+		glyphs: []string{"·", "?", "▁", "▂", "▄", "▆", "█"},
+		colors: []int{237, 244, 196, 208, 220, 112, 34},
+		low:    "悪", high: "良",
+	},
+}
+
+var weekdays = []string{"日", "月", "火", "水", "木", "金", "土"}
+
+// Render は opts.From から opts.To までを日曜始まりの週ごとの列にして w に書く。days のキーは YYYY-MM-DD
+func Render(w io.Writer, days map[string]Day, opts Options) error {
+	p, ok := palettes[opts.Metric]
+	if !ok {
+		return fmt.Errorf("unknown metric %q", opts.Metric)
+	}
+	from := dateOnly(opts.From)
+	to := dateOnly(opts.To)
+	if to.Before(from) {
+		return fmt.Errorf("the end date %s is before the start date %s", to.Format(time.DateOnly), from.Format(time.DateOnly))
+	}
+	start := from.AddDate(0, 0, -int(from.Weekday()))
+	weeks := int(to.Sub(start).Hours()/24)/7 + 1
+
+	var maxCount int64
+	for date, day := range days {
+		if t, err := time.Parse(time.DateOnly, date); err == nil && !t.Before(from) && !t.After(to) {
+			maxCount = max(maxCount, day.Count)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("   " + monthHeader(start, from, to, weeks) + "\n")
+	for weekday := range 7 {
+		// GitHub と同じく月・水・金だけに曜日を書く
+		row := "   "
+		if weekday%2 == 1 {
+			row = weekdays[weekday] + " "
+		}
+		for week := range weeks {
+			date := start.AddDate(0, 0, week*7+weekday)
+			if date.Before(from) || date.After(to) {
+				row += "  "
+				continue
+			}
+			row += p.cell(level(opts.Metric, days[date.Format(time.DateOnly)], maxCount), opts.Color) + " "
+		}
+		b.WriteString(strings.TrimRight(row, " ") + "\n")
+	}
+	b.WriteString("\n   " + p.low + " ")
+	for i := firstLevel; i < len(p.glyphs); i++ {
+		b.WriteString(p.cell(i, opts.Color) + " ")
+	}
+	b.WriteString(p.high)
+	if opts.Metric == Mood {
+		b.WriteString("   " + p.cell(unscored, opts.Color) + " 気分を読み取れなかった日")
+	}
+	b.WriteString("\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// monthHeader は月が変わる週の列の上に「1月」のように月を書く。
+// 期間の始まりの月は途中からなので、次の月と重なるときは省く
+func monthHeader(start, from, to time.Time, weeks int) string {
+	type label struct {
+		pos  int
+		text string
+	}
+	var labels []label
+	for week := range weeks {
+		for weekday := range 7 {
+			date := start.AddDate(0, 0, week*7+weekday)
+			if !date.Before(from) && !date.After(to) && (date.Day() == 1 || date.Equal(from)) {
+				labels = append(labels, label{pos: week * 2, text: fmt.Sprintf("%d月", int(date.Month()))})
+				break
+			}
+		}
+	}
+
+	var b strings.Builder
+	col := 0
+	for i, l := range labels {
+		end := l.pos + displayWidth(l.text)
+		if l.pos < col || (i+1 < len(labels) && end >= labels[i+1].pos) {
+			continue
+		}
+		b.WriteString(strings.Repeat(" ", l.pos-col) + l.text)
+		col = end
+	}
+	return b.String()
+}
+
+// level は day を描くセルの種類を返す。投稿数は期間の最大に対する割合で 4 段階にする
+func level(metric Metric, day Day, maxCount int64) int {
+	if day.Count == 0 {
+		return empty
+	}
+	if metric == Count {
+		return firstLevel - 1 + int(min((day.Count*4+maxCount-1)/maxCount, 4))
+	}
+	if day.Scored == 0 {
+		return unscored
+	}
+	score := min(max(math.Round(day.Mood), mood.Min), mood.Max)
+	return firstLevel + int(score) - mood.Min
+}
+
+func (p palette) cell(i int, color bool) string {
+	if !color {
+		return p.glyphs[i]
+	}
+	return fmt.Sprintf("\x1b[38;5;%dm■\x1b[0m", p.colors[i])
+}
+
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// displayWidth は全角の文字を 2 として数えた幅
+func displayWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		switch width.LookupRune(r).Kind() {
+		case width.EastAsianWide, width.EastAsianFullwidth:
+			n += 2
+		default:
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,97 @@
+package heatmap
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var january = Options{
+	From: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	To:   time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC),
+}
+
+func render(t *testing.T, days map[string]Day, opts Options) string {
+	t.Helper()
+	var b strings.Builder
+	require.NoError(t, Render(&b, days, opts))
+	return b.String()
+}
+
+func TestRender_CountPlain(t *testing.T) {
+	opts := january
+	opts.Metric = Count
+	days := map[string]Day{
+		"2025-01-02": {Count: 4},
+		"2025-01-06": {Count: 1},
+		"2025-01-15": {Count: 2},
+		"2025-02-01": {Count: 100},
+	}
+	// 2025-01-01 は水曜日。範囲外の 2 月 1 日は最大の計算に含めない
+	assert.Equal(t, `   1月
+     · · · ·
+月   ░ · · ·
+     · · · ·
+水 · · ▒ · ·
+   █ · · · ·
+金 · · · · ·
+   · · · ·
+
+   少 ░ ▒ ▓ █ 多
+`, render(t, days, opts))
+}
+
+func TestRender_MoodPlain(t *testing.T) {
+	opts := january
+	opts.Metric = Mood
+	opts.To = time.Date(2025, 1, 7, 0, 0, 0, 0, time.UTC)
+	days := map[string]Day{
+		"2025-01-01": {Count: 1, Mood: -2, Scored: 1},
+		"2025-01-02": {Count: 3, Mood: 0.6, Scored: 2},
+		"2025-01-03": {Count: 2},
+		"2025-01-06": {Count: 1, Mood: 5, Scored: 1},
+	}
+	assert.Equal(t, `   1月
+     ·
+月   █
+     ·
+水 ▁
+   ▆
+金 ?
+   ·
+
+   悪 ▁ ▂ ▄ ▆ █ 良   ? 気分を読み取れなかった日
+`, render(t, days, opts))
+}
+
+func TestRender_MonthLabels(t *testing.T) {
+	opts := Options{
+		From:   time.Date(2024, 12, 20, 0, 0, 0, 0, time.UTC),
+		To:     time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC),
+		Metric: Count,
+	}
+	header, _, _ := strings.Cut(render(t, nil, opts), "\n")
+	assert.Equal(t, "       1月     2月", header, "途中から始まる 12 月は 1 月と重なるので省く")
+
+	opts.From = time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	header, _, _ = strings.Cut(render(t, nil, opts), "\n")
+	assert.Equal(t, "   12月    1月     2月", header)
+}
+
+func TestRender_Color(t *testing.T) {
+	opts := january
+	opts.Metric = Count
+	opts.Color = true
+	out := render(t, map[string]Day{"2025-01-02": {Count: 1}}, opts)
+	assert.Contains(t, out, "\x1b[38;5;40m■\x1b[0m")
+	assert.Contains(t, out, "\x1b[38;5;237m■\x1b[0m")
+	assert.NotContains(t, out, "█")
+}
+
+func TestRender_Errors(t *testing.T) {
+	assert.Error(t, Render(&strings.Builder{}, nil, Options{From: january.From, To: january.To, Metric: "steps"}))
+	assert.Error(t, Render(&strings.Builder{}, nil, Options{From: january.To, To: january.From, Metric: Count}))
+}
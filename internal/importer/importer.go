@@ -0,0 +1,317 @@
+// Package importer は snulog import で読むファイル（CSV / JSON / JSONL）をログの行にする。
+// 行ごとの内容から idempotency_key を作るので、同じファイルを取り込み直しても二重には追加されない
+package importer
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	pb "github.com/gensan0223/snulog/proto"
+)
+
+type Format string
+
+const (
+	CSV   Format = "csv"
+	JSON  Format = "json"
+	JSONL Format = "jsonl"
+)
+
+// Fields は取り込めるログの項目
+var Fields = []string{"user", "status", "feeling", "timestamp", "visibility"}
+
+// aliases は見出しやキーを省略したときに項目として探す名前
+var aliases = map[string][]string{
+	"user":       {"user", "user_name"},
+	"status":     {"status"},
+	"feeling":    {"feeling"},
+	"timestamp":  {"timestamp"},
+	"visibility": {"visibility"},
+}
+
+// timeLayouts は timestamp として読める形式。RFC3339 以外はタイムゾーンを Options.TimeZone とみなす
+var timeLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006/01/02 15:04:05",
+	"2006/01/02 15:04",
+	"2006-01-02",
+	"2006/01/02",
+}
+
+var visibilities = map[string]pb.Visibility{
+	"":         pb.Visibility_VISIBILITY_TEAM,
+	"team":     pb.Visibility_VISIBILITY_TEAM,
+	"managers": pb.Visibility_VISIBILITY_MANAGERS,
+	"private":  pb.Visibility_VISIBILITY_PRIVATE,
+}
+
+type Options struct {
+	// Columns は項目から CSV の見出し（JSON ならキー）への対応。省略した項目は項目と同じ名前を探す
+	Columns map[string]string
+	// Users はファイルに書かれた名前から snulog のユーザー名への対応
+	Users map[string]string
+	// TimeZone はタイムゾーンのない時刻に使う
+	TimeZone *time.Location
+}
+
+// Row は取り込む 1 行。Line は CSV と JSONL では行番号、JSON では配列の何番目か（1 始まり）
+type Row struct {
+	Line  int
+	Entry *pb.LogEntry
+}
+
+// RowError は読み取れなかった行
+type RowError struct {
+	Line    int
+	Message string
+}
+
+// ParseFormat は --format の値を確かめる。空なら path の拡張子で決める
+func ParseFormat(value, path string) (Format, error) {
+	if value == "" {
+		value = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+		if value == "ndjson" {
+			value = string(JSONL)
+		}
+	}
+	switch format := Format(value); format {
+	case CSV, JSON, JSONL:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown format %q (csv, json or jsonl)", value)
+}
+
+// Read は r を format として読み、取り込める行と読み取れなかった行を返す。
+// ファイル自体が読めないときや、必要な列がないときはエラーを返す
+func Read(r io.Reader, format Format, opts Options) ([]Row, []RowError, error) {
+	if opts.TimeZone == nil {
+		opts.TimeZone = time.Local
+	}
+	for field := range opts.Columns {
+		if _, ok := aliases[field]; !ok {
+			return nil, nil, fmt.Errorf("unknown field %q (%s)", field, strings.Join(Fields, ", "))
+		}
+	}
+
+	var records []record
+	var err error
+	switch format {
+	case CSV:
+		records, err = readCSV(r, opts)
+	case JSON:
+		records, err = readJSON(r)
+	case JSONL:
+		records, err = readJSONL(r)
+	default:
+		err = fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rows []Row
+	var rowErrors []RowError
+	for _, rec := range records {
+		if rec.err != nil {
+			rowErrors = append(rowErrors, RowError{Line: rec.line, Message: rec.err.Error()})
+			continue
+		}
+		entry, err := opts.entry(rec.values)
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Line: rec.line, Message: err.Error()})
+			continue
+		}
+		rows = append(rows, Row{Line: rec.line, Entry: entry})
+	}
+	return rows, rowErrors, nil
+}
+
+// record はファイルの 1 行。values は見出し（キー）から値
+type record struct {
+	line   int
+	values map[string]string
+	err    error
+}
+
+// keys は field を探す見出し（キー）の候補
+func (o Options) keys(field string) []string {
+	if column, ok := o.Columns[field]; ok {
+		return []string{column}
+	}
+	return aliases[field]
+}
+
+func (o Options) value(values map[string]string, field string) string {
+	for _, key := range o.keys(field) {
+		if v, ok := values[key]; ok {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+func (o Options) entry(values map[string]string) (*pb.LogEntry, error) {
+	user := o.value(values, "user")
+	if mapped, ok := o.Users[user]; ok {
+		user = mapped
+	}
+	if user == "" {
+		return nil, errors.New("user is empty")
+	}
+	status := o.value(values, "status")
+	if status == "" {
+		return nil, errors.New("status is empty")
+	}
+	timestamp, err := o.parseTime(o.value(values, "timestamp"))
+	if err != nil {
+		return nil, err
+	}
+	visibility, ok := visibilities[strings.ToLower(o.value(values, "visibility"))]
+	if !ok {
+		return nil, fmt.Errorf("visibility must be team, managers or private: %q", o.value(values, "visibility"))
+	}
+
+	entry := &pb.LogEntry{
+		UserName:   user,
+		Status:     status,
+		Feeling:    o.value(values, "feeling"),
+		Timestamp:  timestamp,
+		Visibility: visibility,
+	}
+	entry.IdempotencyKey = idempotencyKey(entry)
+	return entry, nil
+}
+
+func (o Options) parseTime(value string) (string, error) {
+	if value == "" {
+		return "", errors.New("timestamp is empty")
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.Format(time.RFC3339), nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, o.TimeZone); err == nil {
+			return t.Format(time.RFC3339), nil
+		}
+	}
+	return "", fmt.Errorf("cannot read timestamp %q", value)
+}
+
+// idempotencyKey は行の内容から作るキー。同じ内容の行は同じキーになる
+func idempotencyKey(entry *pb.LogEntry) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{entry.UserName, entry.Timestamp, entry.Status, entry.Feeling}, "\x00")))
+	return "import-" + hex.EncodeToString(sum[:16])
+}
+
+func readCSV(r io.Reader, opts Options) ([]record, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read header: %w", err)
+	}
+	// 表計算ソフトが付ける BOM を外す
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+	for _, field := range []string{"user", "status", "timestamp"} {
+		if !hasAny(header, opts.keys(field)) {
+			return nil, fmt.Errorf("no column for %s (header: %s); use --column %s=<header>", field, strings.Join(header, ", "), field)
+		}
+	}
+
+	var records []record
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, err
+			}
+			records = append(records, record{line: parseErr.StartLine, err: parseErr.Err})
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		values := map[string]string{}
+		for i, name := range header {
+			if i < len(fields) {
+				values[name] = fields[i]
+			}
+		}
+		records = append(records, record{line: line, values: values})
+	}
+	return records, nil
+}
+
+func hasAny(header, names []string) bool {
+	for _, h := range header {
+		for _, name := range names {
+			if h == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func readJSON(r io.Reader) ([]record, error) {
+	var objects []map[string]any
+	if err := json.NewDecoder(r).Decode(&objects); err != nil {
+		return nil, fmt.Errorf("expected an array of objects: %w", err)
+	}
+	records := make([]record, len(objects))
+	for i, object := range objects {
+		records[i] = record{line: i + 1, values: stringValues(object)}
+	}
+	return records, nil
+}
+
+func readJSONL(r io.Reader) ([]record, error) {
+	var records []record
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var object map[string]any
+		if err := json.Unmarshal([]byte(text), &object); err != nil {
+			records = append(records, record{line: line, err: err})
+			continue
+		}
+		records = append(records, record{line: line, values: stringValues(object)})
+	}
+	return records, scanner.Err()
+}
+
+// stringValues は JSON のオブジェクトの値を文字列にする。null は空文字列
+func stringValues(object map[string]any) map[string]string {
+	values := map[string]string{}
+	for key, value := range object {
+		switch v := value.(type) {
+		case nil:
+			values[key] = ""
+		case string:
+			values[key] = v
+		default:
+			values[key] = fmt.Sprint(v)
+		}
+	}
+	return values
+}
@@ -0,0 +1,98 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/gensan0223/snulog/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var tokyo = time.FixedZone("JST", 9*60*60)
+
+func TestRead_CSV(t *testing.T) {
+	file := "\ufeff日付,名前,やったこと,気分\n" +
+		"2024/04/01 09:30,山田,スプリント計画,😊\n" +
+		"2024-04-02,鈴木,\"レビュー, 修正\",😐\n" +
+		"2024-04-03,山田,,😫\n" +
+		"昨日,山田,振り返り,🙂\n"
+	rows, rowErrors, err := Read(strings.NewReader(file), CSV, Options{
+		Columns:  map[string]string{"timestamp": "日付", "user": "名前", "status": "やったこと", "feeling": "気分"},
+		Users:    map[string]string{"山田": "yamada"},
+		TimeZone: tokyo,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, rows, 2)
+	assert.Equal(t, 2, rows[0].Line)
+	assert.Equal(t, "yamada", rows[0].Entry.UserName, "--map-user で既存のユーザーに対応付ける")
+	assert.Equal(t, "2024-04-01T09:30:00+09:00", rows[0].Entry.Timestamp)
+	assert.Equal(t, pb.Visibility_VISIBILITY_TEAM, rows[0].Entry.Visibility)
+	assert.Equal(t, "鈴木", rows[1].Entry.UserName, "対応のない名前はそのまま")
+	assert.Equal(t, "レビュー, 修正", rows[1].Entry.Status)
+
+	assert.Equal(t, []RowError{
+		{Line: 4, Message: "status is empty"},
+		{Line: 5, Message: `cannot read timestamp "昨日"`},
+	}, rowErrors)
+
+	_, _, err = Read(strings.NewReader(file), CSV, Options{})
+	assert.ErrorContains(t, err, "--column", "列が見つからなければ対応付けを促す")
+}
+
+func TestRead_JSONAndJSONL(t *testing.T) {
+	jsonFile := `[
+		{"user_name": "alice", "status": "deploy", "feeling": "🔥", "timestamp": "2024-04-01T09:00:00Z", "visibility": "managers"},
+		{"user": "bob", "status": "oncall", "timestamp": "2024-04-01T10:00:00Z", "visibility": "secret"}
+	]`
+	rows, rowErrors, err := Read(strings.NewReader(jsonFile), JSON, Options{})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, pb.Visibility_VISIBILITY_MANAGERS, rows[0].Entry.Visibility)
+	assert.Equal(t, []RowError{{Line: 2, Message: `visibility must be team, managers or private: "secret"`}}, rowErrors)
+
+	jsonlFile := `{"user": "alice", "status": "deploy", "feeling": "🔥", "timestamp": "2024-04-01T09:00:00Z"}
+
+{"user": "bob", "status": 
+{"user": "bob", "status": "oncall", "timestamp": "2024-04-01T10:00:00Z"}
+`
+	rows, rowErrors, err = Read(strings.NewReader(jsonlFile), JSONL, Options{})
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, []int{1, 4}, []int{rows[0].Line, rows[1].Line})
+	require.Len(t, rowErrors, 1)
+	assert.Equal(t, 3, rowErrors[0].Line)
+
+	_, _, err = Read(strings.NewReader(`{"user": "alice"}`), JSON, Options{})
+	assert.Error(t, err, "JSON は配列")
+}
+
+func TestRead_IdempotencyKey(t *testing.T) {
+	file := `[
+		{"user": "alice", "status": "deploy", "timestamp": "2024-04-01T09:00:00Z"},
+		{"user": "alice", "status": "deploy", "timestamp": "2024-04-01T09:00:00Z"},
+		{"user": "alice", "status": "deploy", "timestamp": "2024-04-01T09:00:01Z"}
+	]`
+	rows, _, err := Read(strings.NewReader(file), JSON, Options{})
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, rows[0].Entry.IdempotencyKey, rows[1].Entry.IdempotencyKey, "同じ内容は同じキー")
+	assert.NotEqual(t, rows[0].Entry.IdempotencyKey, rows[2].Entry.IdempotencyKey)
+	assert.LessOrEqual(t, len(rows[0].Entry.IdempotencyKey), 64)
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("", "notes/2024.CSV")
+	require.NoError(t, err)
+	assert.Equal(t, CSV, format)
+	format, err = ParseFormat("", "notes.ndjson")
+	require.NoError(t, err)
+	assert.Equal(t, JSONL, format)
+	format, err = ParseFormat("json", "notes.txt")
+	require.NoError(t, err)
+	assert.Equal(t, JSON, format)
+	_, err = ParseFormat("", "notes.xlsx")
+	assert.Error(t, err)
+}
@@ -0,0 +1,76 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriterMailer_Send(t *testing.T) {
+	var buf bytes.Buffer
+	mailer := NewWriterMailer(&buf)
+
+	err := mailer.Send(context.Background(), Message{
+		To:      "alice@example.com",
+		Subject: "パスワードの再設定",
+		Body:    "リンク: http://localhost:8080/password/reset?token=abc",
+	})
+	if err != nil {
+		t.Fatalf("Failed to send mail: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "To: alice@example.com\r\n") {
+		t.Errorf("Expected To header, got: %s", out)
+	}
+	if !strings.Contains(out, "Subject: =?UTF-8?b?") {
+		t.Errorf("Expected MIME encoded subject, got: %s", out)
+	}
+	if !strings.Contains(out, "token=abc") {
+		t.Errorf("Expected body to be written, got: %s", out)
+	}
+}
+
+func TestWriterMailer_StripsHeaderInjection(t *testing.T) {
+	var buf bytes.Buffer
+	mailer := NewWriterMailer(&buf)
+
+	err := mailer.Send(context.Background(), Message{
+		To:      "alice@example.com\r\nBcc: attacker@example.com",
+		Subject: "hello",
+	})
+	if err != nil {
+		t.Fatalf("Failed to send mail: %v", err)
+	}
+	if strings.Contains(buf.String(), "\r\nBcc:") {
+		t.Errorf("Expected header injection to be stripped, got: %s", buf.String())
+	}
+}
+
+func TestFileMailer_Appends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.log")
+	mailer, f, err := NewFileMailer(path)
+	if err != nil {
+		t.Fatalf("Failed to open file mailer: %v", err)
+	}
+
+	for _, to := range []string{"alice@example.com", "bob@example.com"} {
+		if err := mailer.Send(context.Background(), Message{To: to, Subject: "hi", Body: "body"}); err != nil {
+			t.Fatalf("Failed to send mail: %v", err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Failed to close file: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read mail file: %v", err)
+	}
+	if strings.Count(string(content), "From: ") != 2 {
+		t.Errorf("Expected two messages in file, got: %s", content)
+	}
+}
@@ -0,0 +1,65 @@
+// Package mood は自由に書かれた気分（絵文字や言葉）を -2（とても悪い）から 2（とても良い）の点数にする。
+// 気分の書き方はチームごとに違うので、よく使われる絵文字と言葉だけを見て、わからないものは点数にしない
+package mood
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	Min = -2
+	Max = 2
+)
+
+type term struct {
+	word  string
+	score int
+}
+
+// lexicon は気分に含まれていれば点数にする絵文字と言葉。言葉は小文字で比べる。
+// 「絶好調」の「好調」や unhappy の happy のように、長い言葉の一部は長い言葉として数えるため長い順に並べる
+var lexicon = byLength([]term{
+	{"😄", 2}, {"😁", 2}, {"😆", 2}, {"🤩", 2}, {"🥳", 2}, {"😍", 2}, {"🎉", 2}, {"💪", 2}, {"✨", 2},
+	{"最高", 2}, {"絶好調", 2}, {"great", 2}, {"awesome", 2}, {"excellent", 2},
+
+	{"😊", 1}, {"🙂", 1}, {"😀", 1}, {"😃", 1}, {"☺", 1}, {"😌", 1}, {"👍", 1},
+	{"好調", 1}, {"元気", 1}, {"楽しい", 1}, {"嬉しい", 1}, {"うれしい", 1}, {"good", 1}, {"happy", 1}, {"fine", 1},
+
+	{"😐", 0}, {"😶", 0}, {"😑", 0}, {"🤔", 0},
+	{"まあまあ", 0}, {"普通", 0}, {"ふつう", 0}, {"so-so", 0}, {"meh", 0},
+
+	{"😕", -1}, {"🙁", -1}, {"😟", -1}, {"😓", -1}, {"😥", -1}, {"😴", -1}, {"🥱", -1},
+	{"疲れ", -1}, {"眠い", -1}, {"微妙", -1}, {"不安", -1}, {"tired", -1}, {"sleepy", -1}, {"bad", -1}, {"unhappy", -1},
+
+	{"😞", -2}, {"😢", -2}, {"😭", -2}, {"😫", -2}, {"😩", -2}, {"😡", -2}, {"😠", -2}, {"🤯", -2}, {"😱", -2},
+	{"最悪", -2}, {"絶不調", -2}, {"つらい", -2}, {"辛い", -2}, {"しんどい", -2}, {"awful", -2}, {"terrible", -2}, {"exhausted", -2},
+})
+
+func byLength(terms []term) []term {
+	sort.SliceStable(terms, func(i, j int) bool {
+		return utf8.RuneCountInString(terms[i].word) > utf8.RuneCountInString(terms[j].word)
+	})
+	return terms
+}
+
+// Score は feeling の点数を返す。知っている絵文字や言葉が複数あれば平均し、1 つもなければ false を返す
+func Score(feeling string) (float64, bool) {
+	rest := strings.ToLower(feeling)
+	total, n := 0, 0
+	for _, t := range lexicon {
+		count := strings.Count(rest, t.word)
+		if count == 0 {
+			continue
+		}
+		// 数えた言葉は取り除き、その一部の短い言葉として数え直さない
+		rest = strings.ReplaceAll(rest, t.word, " ")
+		total += t.score * count
+		n += count
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return float64(total) / float64(n), true
+}
@@ -0,0 +1,35 @@
+package mood
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		feeling string
+		score   float64
+		ok      bool
+	}{
+		{"😄", 2, true},
+		{"😊 元気", 1, true},
+		{"絶好調", 2, true},
+		{"絶不調", -2, true},
+		{"Great!", 2, true},
+		{"unhappy", -1, true},
+		{"😴 眠い", -1, true},
+		{"😄😢", 0, true},
+		{"😊😊😢", 0, true},
+		{"まあまあ", 0, true},
+		{"🍣", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.feeling, func(t *testing.T) {
+			score, ok := Score(tt.feeling)
+			assert.Equal(t, tt.ok, ok)
+			assert.InDelta(t, tt.score, score, 1e-9)
+		})
+	}
+}
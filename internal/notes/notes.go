@@ -0,0 +1,137 @@
+// Package notes は snulog note で書いたメモを手元のディレクトリに日ごとに保存する。
+// メモは YYYY-MM-DD.jsonl に 1 行ずつ追記し、snulog publish で 1 件のログにまとめたら消す
+package notes
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/util"
+)
+
+// Feeling はサーバーに保存したメモ（LogEntry.Note）に付ける気分
+const Feeling = "📝"
+
+// Note は 1 件のメモ。ID はサーバーに保存したメモのログ ID で、手元のメモでは 0
+type Note struct {
+	Text string    `json:"text"`
+	Time time.Time `json:"time"`
+	ID   int64     `json:"-"`
+}
+
+type Store struct {
+	dir string
+}
+
+func New(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// path は day の日付（day のタイムゾーンでの日付）のファイル
+func (s *Store) path(day time.Time) string {
+	return filepath.Join(s.dir, day.Format(time.DateOnly)+".jsonl")
+}
+
+// Add は note を note.Time の日付のファイルに追記する
+func (s *Store) Add(note Note) error {
+	if strings.TrimSpace(note.Text) == "" {
+		return errors.New("note is empty")
+	}
+	line, err := json.Marshal(note)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(s.path(note.Time), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	// 1 行を 1 回で書くので、同時に追記しても行は混ざらない
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// List は day の日付のメモを書いた順に返す。なければ空
+func (s *Store) List(day time.Time) ([]Note, error) {
+	file, err := os.Open(s.path(day))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer util.CloseWithLog(file)
+
+	var notes []Note
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1<<20)
+	for n := 1; scanner.Scan(); n++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var note Note
+		if err := json.Unmarshal(scanner.Bytes(), &note); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", s.path(day), n, err)
+		}
+		notes = append(notes, note)
+	}
+	return notes, scanner.Err()
+}
+
+// Drop は day の日付のメモを先頭から n 件消す。publish の間に書き足されたメモは残す
+func (s *Store) Drop(day time.Time, n int) error {
+	notes, err := s.List(day)
+	if err != nil {
+		return err
+	}
+	if n >= len(notes) {
+		err := os.Remove(s.path(day))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	// 書きかけのファイルを読まないよう、別名で書いてから置き換える
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	encoder := json.NewEncoder(tmp)
+	for _, note := range notes[n:] {
+		if err := encoder.Encode(note); err != nil {
+			_ = tmp.Close()
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(day))
+}
+
+// Summary はメモを「- 15:04 本文」の行にする。publish で編集する下書きの本文
+func Summary(notes []Note, loc *time.Location) string {
+	lines := make([]string, 0, len(notes))
+	for _, note := range notes {
+		text := strings.Join(strings.Fields(note.Text), " ")
+		lines = append(lines, "- "+note.Time.In(loc).Format("15:04")+" "+text)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,58 @@
+package notes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_AddListDrop(t *testing.T) {
+	store := New(filepath.Join(t.TempDir(), "notes"))
+	tokyo := time.FixedZone("JST", 9*60*60)
+	day := time.Date(2025, 1, 2, 0, 0, 0, 0, tokyo)
+
+	notes, err := store.List(day)
+	require.NoError(t, err)
+	assert.Empty(t, notes)
+
+	for i, text := range []string{"API のレビュー", "CI が遅い", "設計の相談"} {
+		require.NoError(t, store.Add(Note{Text: text, Time: day.Add(time.Duration(9+i) * time.Hour)}))
+	}
+	// UTC では 1 月 1 日でも、書いたときのタイムゾーンの日付に入れる
+	require.NoError(t, store.Add(Note{Text: "翌日", Time: time.Date(2025, 1, 3, 8, 0, 0, 0, tokyo)}))
+	assert.Error(t, store.Add(Note{Text: "  ", Time: day}))
+
+	notes, err = store.List(day)
+	require.NoError(t, err)
+	require.Len(t, notes, 3)
+	assert.Equal(t, "API のレビュー", notes[0].Text)
+	assert.True(t, notes[0].Time.Equal(day.Add(9*time.Hour)))
+
+	require.NoError(t, store.Drop(day, 2))
+	notes, err = store.List(day)
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	assert.Equal(t, "設計の相談", notes[0].Text, "publish の後に書いたメモは残す")
+
+	require.NoError(t, store.Drop(day, 1))
+	_, err = os.Stat(filepath.Join(store.Dir(), "2025-01-02.jsonl"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	require.NoError(t, store.Drop(day, 1), "メモがなくてもエラーにしない")
+
+	notes, err = store.List(day.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	assert.Len(t, notes, 1)
+}
+
+func TestSummary(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	notes := []Note{
+		{Text: "API の\nレビュー", Time: time.Date(2025, 1, 2, 0, 5, 0, 0, time.UTC)},
+		{Text: "設計の相談", Time: time.Date(2025, 1, 2, 13, 30, 0, 0, tokyo)},
+	}
+	assert.Equal(t, "- 09:05 API の レビュー\n- 13:30 設計の相談", Summary(notes, tokyo))
+}
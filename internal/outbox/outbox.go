@@ -0,0 +1,210 @@
+// Package outbox はサーバーに届かなかったログを手元のディレクトリに溜め、つながったときに送り直す。
+// 1 件を 1 ファイルに書くので、途中で落ちても溜めたログは壊れない。再送が重なっても
+// サーバーは idempotency_key で二重に追加しない
+package outbox
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	pb "github.com/gensan0223/snulog/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// RejectedDir はサーバーに受け付けられなかったログを移すサブディレクトリ
+const RejectedDir = "rejected"
+
+type Outbox struct {
+	dir string
+}
+
+func New(dir string) *Outbox {
+	return &Outbox{dir: dir}
+}
+
+func (o *Outbox) Dir() string {
+	return o.dir
+}
+
+// Item は送信待ちの 1 件
+type Item struct {
+	Entry    *pb.LogEntry
+	QueuedAt time.Time
+	file     string
+}
+
+// NewKey は idempotency_key に使う乱数の文字列を返す
+func NewKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Add は entry を送信待ちにする。再送を見分けられるよう entry.IdempotencyKey が必要
+func (o *Outbox) Add(entry *pb.LogEntry) error {
+	if entry.IdempotencyKey == "" || strings.ContainsAny(entry.IdempotencyKey, `/\.`) {
+		return fmt.Errorf("invalid idempotency key %q", entry.IdempotencyKey)
+	}
+	data, err := protojson.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(o.dir, 0o700); err != nil {
+		return err
+	}
+
+	// ファイル名の時刻で溜めた順に送る。書きかけのファイルを読まないよう、別名で書いてから置き換える
+	name := fmt.Sprintf("%020d-%s.json", time.Now().UnixNano(), entry.IdempotencyKey)
+	tmp, err := os.CreateTemp(o.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(o.dir, name))
+}
+
+// List は送信待ちのログを溜めた順に返す。ディレクトリがなければ空
+func (o *Outbox) List() ([]*Item, error) {
+	files, err := os.ReadDir(o.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*Item
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(o.dir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			// 他のプロセスが送信済み
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var entry pb.LogEntry
+		if err := protojson.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		item := &Item{Entry: &entry, file: name}
+		var nanos int64
+		if _, err := fmt.Sscanf(name, "%d-", &nanos); err == nil {
+			item.QueuedAt = time.Unix(0, nanos)
+		}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].file < items[j].file })
+	return items, nil
+}
+
+func (o *Outbox) remove(item *Item) error {
+	err := os.Remove(filepath.Join(o.dir, item.file))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (o *Outbox) reject(item *Item) error {
+	dir := filepath.Join(o.dir, RejectedDir)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	err := os.Rename(filepath.Join(o.dir, item.file), filepath.Join(dir, item.file))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Result は Flush の結果
+type Result struct {
+	Sent int
+	// Duplicates は Sent のうち、前の送信が届いていて追加済みだったもの
+	Duplicates int
+	Rejected   []Rejection
+	// Remaining は送れずに残ったもの
+	Remaining int
+}
+
+// Rejection はサーバーが受け付けず RejectedDir に移したログ
+type Rejection struct {
+	Entry *pb.LogEntry
+	Err   error
+}
+
+// Flush は溜めた順に send で送る。送れたものは消し、受け付けられなかったものは RejectedDir に移す。
+// つながらないなどで送れなかったら、そこで止めて残りを次に回し、そのエラーを返す
+func (o *Outbox) Flush(ctx context.Context, send func(context.Context, *pb.LogEntry) (*pb.AddResponse, error)) (Result, error) {
+	var result Result
+	items, err := o.List()
+	if err != nil {
+		return result, err
+	}
+
+	for i, item := range items {
+		res, err := send(ctx, item.Entry)
+		if err != nil && !Permanent(err) {
+			result.Remaining = len(items) - i
+			return result, err
+		}
+		if err != nil {
+			result.Rejected = append(result.Rejected, Rejection{Entry: item.Entry, Err: err})
+			if err := o.reject(item); err != nil {
+				return result, err
+			}
+			continue
+		}
+
+		result.Sent++
+		if res.GetDuplicate() {
+			result.Duplicates++
+		}
+		if err := o.remove(item); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// Offline はサーバーに届かなかった、または届いたか分からないエラーか。add はこのときログを溜める
+func Offline(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
+
+// Permanent は送り直しても受け付けられないエラーか。ログイン切れやつながらないエラーは送り直せる
+func Permanent(err error) bool {
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.PermissionDenied, codes.FailedPrecondition, codes.AlreadyExists, codes.OutOfRange:
+		return true
+	}
+	return false
+}
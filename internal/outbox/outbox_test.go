@@ -0,0 +1,99 @@
+package outbox
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/gensan0223/snulog/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func queue(t *testing.T, box *Outbox, statuses ...string) {
+	t.Helper()
+	for _, s := range statuses {
+		key, err := NewKey()
+		require.NoError(t, err)
+		require.NoError(t, box.Add(&pb.LogEntry{UserName: "alice", Status: s, Feeling: "😊", IdempotencyKey: key}))
+	}
+}
+
+func queued(t *testing.T, box *Outbox) []string {
+	t.Helper()
+	items, err := box.List()
+	require.NoError(t, err)
+	var result []string
+	for _, item := range items {
+		result = append(result, item.Entry.Status)
+	}
+	return result
+}
+
+func TestOutbox_AddAndList(t *testing.T) {
+	box := New(filepath.Join(t.TempDir(), "outbox"))
+	assert.Empty(t, queued(t, box), "ディレクトリがなければ空")
+
+	queue(t, box, "first", "second", "third")
+	assert.Equal(t, []string{"first", "second", "third"}, queued(t, box), "溜めた順に返す")
+
+	items, err := box.List()
+	require.NoError(t, err)
+	assert.False(t, items[0].QueuedAt.IsZero())
+	assert.Len(t, items[0].Entry.IdempotencyKey, 32)
+
+	info, err := os.Stat(box.Dir())
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+
+	assert.Error(t, box.Add(&pb.LogEntry{Status: "no key"}))
+	assert.Error(t, box.Add(&pb.LogEntry{Status: "bad key", IdempotencyKey: "../x"}))
+}
+
+func TestOutbox_Flush(t *testing.T) {
+	box := New(t.TempDir())
+	queue(t, box, "sent", "duplicate", "invalid", "offline", "later")
+
+	var sent []string
+	result, err := box.Flush(context.Background(), func(ctx context.Context, entry *pb.LogEntry) (*pb.AddResponse, error) {
+		sent = append(sent, entry.Status)
+		switch entry.Status {
+		case "duplicate":
+			return &pb.AddResponse{Duplicate: true}, nil
+		case "invalid":
+			return nil, status.Error(codes.InvalidArgument, "unknown visibility")
+		case "offline":
+			return nil, status.Error(codes.Unavailable, "connection refused")
+		}
+		return &pb.AddResponse{}, nil
+	})
+
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, []string{"sent", "duplicate", "invalid", "offline"}, sent, "つながらなくなったら止める")
+	assert.Equal(t, 2, result.Sent)
+	assert.Equal(t, 1, result.Duplicates)
+	assert.Equal(t, 2, result.Remaining)
+	if assert.Len(t, result.Rejected, 1) {
+		assert.Equal(t, "invalid", result.Rejected[0].Entry.Status)
+	}
+	assert.Equal(t, []string{"offline", "later"}, queued(t, box))
+	assert.Equal(t, []string{"invalid"}, queued(t, New(filepath.Join(box.Dir(), RejectedDir))), "受け付けられなかったログは別の場所に残す")
+
+	result, err = box.Flush(context.Background(), func(ctx context.Context, entry *pb.LogEntry) (*pb.AddResponse, error) {
+		return &pb.AddResponse{}, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Sent)
+	assert.Empty(t, queued(t, box))
+}
+
+func TestOffline(t *testing.T) {
+	assert.True(t, Offline(status.Error(codes.Unavailable, "")))
+	assert.True(t, Offline(status.Error(codes.DeadlineExceeded, "")))
+	assert.False(t, Offline(status.Error(codes.Unauthenticated, "")))
+	assert.False(t, Permanent(status.Error(codes.Unauthenticated, "")), "ログインし直せば送れる")
+	assert.True(t, Permanent(status.Error(codes.PermissionDenied, "")))
+}
@@ -0,0 +1,279 @@
+// Package output は CLI の読み取り系コマンドの結果を table / json / jsonl / yaml / csv / template で書き出す
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"golang.org/x/text/width"
+	"gopkg.in/yaml.v3"
+)
+
+type Format string
+
+const (
+	Table    Format = "table"
+	JSON     Format = "json"
+	JSONL    Format = "jsonl"
+	YAML     Format = "yaml"
+	CSV      Format = "csv"
+	Template Format = "template"
+)
+
+// Formats は -o に指定できる形式。template は template=<Go のテンプレート> の形で指定する
+var Formats = []Format{Table, JSON, JSONL, YAML, CSV, Template}
+
+// Column は 1 列の定義。Value の値は json / yaml ではそのままの型で、table / csv では文字列にして書き出す
+type Column[T any] struct {
+	// Name は --columns と json / yaml / csv のキーに使う
+	Name   string
+	Header string
+	// Emoji は table の見出しの飾り。--no-emoji で外す
+	Emoji string
+	Value func(row T) any
+}
+
+type Options struct {
+	Format   Format
+	Template string
+	// Columns は書き出す列と順序。空ならすべての列
+	Columns []string
+	Color   bool
+	Emoji   bool
+}
+
+// ParseFormat は -o の値を解釈する（例: json, template={{.UserName}}）
+func ParseFormat(value string) (Format, string, error) {
+	name, tmpl, hasTemplate := strings.Cut(value, "=")
+	format := Format(name)
+	if !slices.Contains(Formats, format) {
+		return "", "", fmt.Errorf("unknown output format %q (table, json, jsonl, yaml, csv, template=...)", value)
+	}
+	if format == Template && (!hasTemplate || tmpl == "") {
+		return "", "", fmt.Errorf("template には -o template='{{.UserName}}' のようにテンプレートを指定してください")
+	}
+	if format != Template && hasTemplate {
+		return "", "", fmt.Errorf("unknown output format %q", value)
+	}
+	return format, tmpl, nil
+}
+
+// SelectColumns は names の順に列を選ぶ。空ならすべての列を返す
+func SelectColumns[T any](columns []Column[T], names []string) ([]Column[T], error) {
+	if len(names) == 0 {
+		return columns, nil
+	}
+	selected := make([]Column[T], 0, len(names))
+	for _, name := range names {
+		i := slices.IndexFunc(columns, func(c Column[T]) bool { return c.Name == name })
+		if i < 0 {
+			available := make([]string, len(columns))
+			for j, c := range columns {
+				available[j] = c.Name
+			}
+			return nil, fmt.Errorf("unknown column %q (%s)", name, strings.Join(available, ", "))
+		}
+		selected = append(selected, columns[i])
+	}
+	return selected, nil
+}
+
+// Write は rows を opts の形式で w に書き出す。template は列ではなく行そのもの（T）に適用する
+func Write[T any](w io.Writer, opts Options, columns []Column[T], rows []T) error {
+	columns, err := SelectColumns(columns, opts.Columns)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case Table, "":
+		return writeTable(w, opts, columns, rows)
+	case JSON:
+		objects := make([]orderedObject, len(rows))
+		for i, row := range rows {
+			objects[i] = toObject(columns, row)
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(objects)
+	case JSONL:
+		enc := json.NewEncoder(w)
+		for _, row := range rows {
+			if err := enc.Encode(toObject(columns, row)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case YAML:
+		doc := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, row := range rows {
+			node, err := toObject(columns, row).yamlNode()
+			if err != nil {
+				return err
+			}
+			doc.Content = append(doc.Content, node)
+		}
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+		return enc.Close()
+	case CSV:
+		cw := csv.NewWriter(w)
+		header := make([]string, len(columns))
+		for i, c := range columns {
+			header[i] = c.Name
+		}
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if err := cw.Write(cells(columns, row)); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case Template:
+		tmpl, err := template.New("output").Option("missingkey=error").Parse(opts.Template)
+		if err != nil {
+			return fmt.Errorf("template: %w", err)
+		}
+		for _, row := range rows {
+			if err := tmpl.Execute(w, row); err != nil {
+				return fmt.Errorf("template: %w", err)
+			}
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q", opts.Format)
+}
+
+const (
+	bold  = "\x1b[1m"
+	reset = "\x1b[0m"
+)
+
+// writeTable は表示幅（全角や絵文字は 2 桁）で列を揃える。色は見出しだけに付ける
+func writeTable[T any](w io.Writer, opts Options, columns []Column[T], rows []T) error {
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.Header
+		if opts.Emoji && c.Emoji != "" {
+			header[i] = c.Emoji + " " + c.Header
+		}
+	}
+	lines := [][]string{header}
+	for _, row := range rows {
+		lines = append(lines, cells(columns, row))
+	}
+
+	widths := make([]int, len(columns))
+	for _, line := range lines {
+		for i, cell := range line {
+			widths[i] = max(widths[i], displayWidth(cell))
+		}
+	}
+
+	var buf bytes.Buffer
+	for n, line := range lines {
+		for i, cell := range line {
+			if n == 0 && opts.Color {
+				cell = bold + cell + reset
+			}
+			buf.WriteString(cell)
+			if i < len(line)-1 {
+				buf.WriteString(strings.Repeat(" ", widths[i]-displayWidth(line[i])+2))
+			}
+		}
+		buf.WriteByte('\n')
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func cells[T any](columns []Column[T], row T) []string {
+	values := make([]string, len(columns))
+	for i, c := range columns {
+		values[i] = fmt.Sprint(c.Value(row))
+	}
+	return values
+}
+
+// displayWidth は端末での表示幅を返す。改行やタブは 1 桁として数える
+func displayWidth(s string) int {
+	n := 0
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		s = s[size:]
+		switch width.LookupRune(r).Kind() {
+		case width.EastAsianWide, width.EastAsianFullwidth:
+			n += 2
+		default:
+			n++
+		}
+	}
+	return n
+}
+
+// orderedObject は列の順序を保ったまま json / yaml にするためのキーと値の組
+type orderedObject []keyValue
+
+type keyValue struct {
+	key   string
+	value any
+}
+
+func toObject[T any](columns []Column[T], row T) orderedObject {
+	object := make(orderedObject, len(columns))
+	for i, c := range columns {
+		object[i] = keyValue{key: c.Name, value: c.Value(row)}
+	}
+	return object
+}
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, kv := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(kv.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(kv.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (o orderedObject) yamlNode() (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, kv := range o {
+		value := &yaml.Node{}
+		if err := value.Encode(kv.value); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: kv.key}, value)
+	}
+	return node, nil
+}
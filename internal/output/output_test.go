@@ -0,0 +1,113 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type entry struct {
+	UserName string
+	Status   string
+	Count    int
+}
+
+var columns = []Column[entry]{
+	{Name: "user", Header: "USER", Emoji: "👤", Value: func(e entry) any { return e.UserName }},
+	{Name: "status", Header: "STATUS", Value: func(e entry) any { return e.Status }},
+	{Name: "count", Header: "COUNT", Value: func(e entry) any { return e.Count }},
+}
+
+var rows = []entry{
+	{UserName: "alice", Status: "レビュー中", Count: 3},
+	{UserName: "bob", Status: `fix "quotes", commas`, Count: 0},
+}
+
+func write(t *testing.T, opts Options) string {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, opts, columns, rows))
+	return buf.String()
+}
+
+func TestWrite_Table(t *testing.T) {
+	out := write(t, Options{Format: Table, Emoji: true})
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	require.Len(t, lines, 3)
+	assert.True(t, strings.HasPrefix(lines[0], "👤 USER"))
+	// 全角の列も表示幅で揃える
+	assert.Equal(t, displayWidth(lines[1][:strings.Index(lines[1], "3")]), displayWidth(lines[0][:strings.Index(lines[0], "COUNT")]))
+
+	out = write(t, Options{Format: Table})
+	assert.NotContains(t, out, "👤", "--no-emoji では見出しの絵文字を付けない")
+	assert.NotContains(t, out, "\x1b[")
+
+	out = write(t, Options{Format: Table, Color: true})
+	assert.Contains(t, out, bold+"USER"+reset)
+}
+
+func TestWrite_StructuredFormats(t *testing.T) {
+	assert.Equal(t, `[
+  {
+    "user": "alice",
+    "status": "レビュー中",
+    "count": 3
+  },
+  {
+    "user": "bob",
+    "status": "fix \"quotes\", commas",
+    "count": 0
+  }
+]
+`, write(t, Options{Format: JSON}))
+
+	assert.Equal(t, `{"user":"alice","status":"レビュー中","count":3}
+{"user":"bob","status":"fix \"quotes\", commas","count":0}
+`, write(t, Options{Format: JSONL}))
+
+	assert.Equal(t, `- user: alice
+  status: レビュー中
+  count: 3
+- user: bob
+  status: fix "quotes", commas
+  count: 0
+`, write(t, Options{Format: YAML}))
+
+	assert.Equal(t, `user,status,count
+alice,レビュー中,3
+bob,"fix ""quotes"", commas",0
+`, write(t, Options{Format: CSV}))
+}
+
+func TestWrite_Columns(t *testing.T) {
+	assert.Equal(t, "count,user\n3,alice\n0,bob\n", write(t, Options{Format: CSV, Columns: []string{"count", "user"}}))
+
+	err := Write(&bytes.Buffer{}, Options{Format: CSV, Columns: []string{"mood"}}, columns, rows)
+	assert.ErrorContains(t, err, "user, status, count")
+}
+
+func TestWrite_Template(t *testing.T) {
+	assert.Equal(t, "alice=3\nbob=0\n", write(t, Options{Format: Template, Template: "{{.UserName}}={{.Count}}"}))
+
+	err := Write(&bytes.Buffer{}, Options{Format: Template, Template: "{{.Mood}}"}, columns, rows)
+	assert.Error(t, err)
+}
+
+func TestParseFormat(t *testing.T) {
+	format, tmpl, err := ParseFormat("template={{.UserName}}")
+	require.NoError(t, err)
+	assert.Equal(t, Template, format)
+	assert.Equal(t, "{{.UserName}}", tmpl)
+
+	format, _, err = ParseFormat("jsonl")
+	require.NoError(t, err)
+	assert.Equal(t, JSONL, format)
+
+	for _, value := range []string{"xml", "template", "template=", "json=x"} {
+		_, _, err := ParseFormat(value)
+		assert.Error(t, err, value)
+	}
+}
@@ -0,0 +1,90 @@
+// Package plugin は PATH にある snulog-<名前> の実行ファイルを探す。
+// git と同じく、snulog <名前> で組み込みのコマンドがなければそのプラグインを実行する
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Prefix はプラグインの実行ファイル名の接頭辞
+const Prefix = "snulog-"
+
+// Plugin は見つかったプラグイン。同じ名前が PATH の複数のディレクトリにあれば先のものを使う
+type Plugin struct {
+	Name string
+	Path string
+	// Shadowed は PATH の後ろにあって使われない同じ名前の実行ファイル
+	Shadowed []string
+}
+
+// Find は path（PATH の形式）のディレクトリからプラグインを探し、名前順に返す。
+// 読めないディレクトリと、今いるディレクトリのファイルを実行しないよう空や相対パスのディレクトリは飛ばす
+func Find(path string) []Plugin {
+	found := map[string]*Plugin{}
+	seen := map[string]bool{}
+	for _, dir := range filepath.SplitList(path) {
+		if !filepath.IsAbs(dir) || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := pluginName(entry.Name())
+			if !ok {
+				continue
+			}
+			full := filepath.Join(dir, entry.Name())
+			if !executable(full) {
+				continue
+			}
+			if p, ok := found[name]; ok {
+				p.Shadowed = append(p.Shadowed, full)
+				continue
+			}
+			found[name] = &Plugin{Name: name, Path: full}
+		}
+	}
+
+	plugins := make([]Plugin, 0, len(found))
+	for _, p := range found {
+		plugins = append(plugins, *p)
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins
+}
+
+// pluginName はファイル名からプラグインの名前を取り出す。Windows では .exe などの拡張子を除く
+func pluginName(file string) (string, bool) {
+	name, ok := strings.CutPrefix(file, Prefix)
+	if !ok {
+		return "", false
+	}
+	if runtime.GOOS == "windows" {
+		ext := strings.ToLower(filepath.Ext(name))
+		if ext != ".exe" && ext != ".bat" && ext != ".cmd" {
+			return "", false
+		}
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	// snulog-foo.sh のような名前も snulog foo.sh ではなくそのまま使う。空や隠しファイルは除く
+	if name == "" || strings.HasPrefix(name, ".") {
+		return "", false
+	}
+	return name, true
+}
+
+// executable は path が実行できる通常のファイル（シンボリックリンクならその先）か
+func executable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode().Perm()&0o111 != 0
+}
@@ -0,0 +1,42 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, dir, name string, mode os.FileMode) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"), mode))
+	return path
+}
+
+func TestFind(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("実行権限のビットで判定する環境だけで確かめる")
+	}
+	first, second := t.TempDir(), t.TempDir()
+	handoff := writeFile(t, first, "snulog-oncall-handoff", 0o755)
+	shadowed := writeFile(t, second, "snulog-oncall-handoff", 0o755)
+	report := writeFile(t, second, "snulog-report", 0o700)
+	writeFile(t, first, "snulog-notes.txt", 0o644)
+	writeFile(t, first, "snulog-", 0o755)
+	writeFile(t, first, "other-tool", 0o755)
+	require.NoError(t, os.Mkdir(filepath.Join(first, "snulog-dir"), 0o755))
+
+	path := strings.Join([]string{first, filepath.Join(first, "missing"), second, first}, string(os.PathListSeparator))
+	assert.Equal(t, []Plugin{
+		{Name: "oncall-handoff", Path: handoff, Shadowed: []string{shadowed}},
+		{Name: "report", Path: report},
+	}, Find(path))
+
+	t.Chdir(first)
+	assert.Empty(t, Find(strings.Join([]string{"", ".", filepath.Join(first, "missing")}, string(os.PathListSeparator))), "相対パスのディレクトリは探さない")
+}
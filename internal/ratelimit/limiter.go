@@ -0,0 +1,137 @@
+package ratelimit
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/repository"
+)
+
+// Policy は Window 内に MaxAttempts 回の試行があった時点でキーをロックする。
+// ロック時間は BaseLockout から始まり、ロックのたびに倍になる（上限 MaxLockout）
+type Policy struct {
+	MaxAttempts int
+	Window      time.Duration
+	BaseLockout time.Duration
+	MaxLockout  time.Duration
+}
+
+var (
+	// DefaultLoginPolicy はログイン失敗に対するポリシー
+	DefaultLoginPolicy = Policy{
+		MaxAttempts: 5,
+		Window:      15 * time.Minute,
+		BaseLockout: 30 * time.Second,
+		MaxLockout:  time.Hour,
+	}
+	// DefaultRPCPolicy は書き込み系 RPC の呼び出し回数に対するポリシー
+	DefaultRPCPolicy = Policy{
+		MaxAttempts: 60,
+		Window:      time.Minute,
+		BaseLockout: 10 * time.Second,
+		MaxLockout:  10 * time.Minute,
+	}
+)
+
+type Limiter struct {
+	name   string
+	policy Policy
+	store  repository.AttemptRepository
+	mutex  sync.Mutex
+	now    func() time.Time
+}
+
+// NewLimiter は name をキーの名前空間として使う。同じストアを複数の Limiter で共有できる
+func NewLimiter(name string, store repository.AttemptRepository, policy Policy) *Limiter {
+	return &Limiter{
+		name:   name,
+		policy: policy,
+		store:  store,
+		now:    time.Now,
+	}
+}
+
+// Allow はいずれかのキーがロック中であれば false と解除までの時間を返す
+func (l *Limiter) Allow(ctx context.Context, keys ...string) (bool, time.Duration, error) {
+	now := l.now().UTC()
+	var retryAfter time.Duration
+	for _, key := range keys {
+		attempt, err := l.store.GetAttempt(ctx, l.key(key))
+		if err != nil {
+			return false, 0, err
+		}
+		if attempt != nil && attempt.LockedUntil.After(now) {
+			if wait := attempt.LockedUntil.Sub(now); wait > retryAfter {
+				retryAfter = wait
+			}
+		}
+	}
+	return retryAfter == 0, retryAfter, nil
+}
+
+// Record はキーごとに試行を 1 回記録し、しきい値に達したらロックする
+func (l *Limiter) Record(ctx context.Context, keys ...string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// auth_attempts の時刻はタイムゾーンなしの TIMESTAMP なので UTC で保存する
+	now := l.now().UTC()
+	for _, key := range keys {
+		attempt, err := l.store.GetAttempt(ctx, l.key(key))
+		if err != nil {
+			return err
+		}
+		if attempt == nil {
+			attempt = &repository.Attempt{Key: l.key(key), WindowStart: now}
+		}
+		if now.Sub(attempt.WindowStart) > l.policy.Window {
+			attempt.Failures = 0
+			attempt.WindowStart = now
+		}
+
+		attempt.Failures++
+		if attempt.Failures >= l.policy.MaxAttempts {
+			attempt.Lockouts++
+			lockout := l.lockout(attempt.Lockouts)
+			attempt.LockedUntil = now.Add(lockout)
+			attempt.Failures = 0
+			attempt.WindowStart = now
+			log.Printf("security: %s locked out key=%q lockouts=%d duration=%s", l.name, key, attempt.Lockouts, lockout)
+		}
+
+		if err := l.store.SaveAttempt(ctx, attempt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reset はログイン成功時などにキーの記録を消す
+func (l *Limiter) Reset(ctx context.Context, keys ...string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	for _, key := range keys {
+		if err := l.store.DeleteAttempt(ctx, l.key(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *Limiter) key(key string) string {
+	return l.name + ":" + key
+}
+
+func (l *Limiter) lockout(lockouts int) time.Duration {
+	lockout := l.policy.BaseLockout
+	for i := 1; i < lockouts; i++ {
+		lockout *= 2
+		if lockout >= l.policy.MaxLockout {
+			return l.policy.MaxLockout
+		}
+	}
+	return lockout
+}
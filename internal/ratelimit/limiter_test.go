@@ -0,0 +1,203 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/auth"
+	"github.com/gensan0223/snulog/internal/repository"
+	"github.com/gensan0223/snulog/proto"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+var testPolicy = Policy{
+	MaxAttempts: 3,
+	Window:      time.Minute,
+	BaseLockout: 10 * time.Second,
+	MaxLockout:  30 * time.Second,
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func newTestLimiter(store repository.AttemptRepository) (*Limiter, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)}
+	l := NewLimiter("login", store, testPolicy)
+	l.now = clock.Now
+	return l, clock
+}
+
+func TestLimiter_LocksAfterMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	l, _ := newTestLimiter(repository.NewInMemoryAttemptRepository())
+
+	for i := 0; i < testPolicy.MaxAttempts-1; i++ {
+		assert.NoError(t, l.Record(ctx, "user:alice"))
+	}
+	allowed, _, err := l.Allow(ctx, "user:alice")
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	assert.NoError(t, l.Record(ctx, "user:alice"))
+	allowed, retryAfter, err := l.Allow(ctx, "user:alice")
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, 10*time.Second, retryAfter)
+
+	// 他のキーには影響しない
+	allowed, _, err = l.Allow(ctx, "user:bob")
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestLimiter_ExponentialLockout(t *testing.T) {
+	ctx := context.Background()
+	l, clock := newTestLimiter(repository.NewInMemoryAttemptRepository())
+
+	expected := []time.Duration{10 * time.Second, 20 * time.Second, 30 * time.Second, 30 * time.Second}
+	for _, want := range expected {
+		for i := 0; i < testPolicy.MaxAttempts; i++ {
+			assert.NoError(t, l.Record(ctx, "ip:10.0.0.1"))
+		}
+		_, retryAfter, err := l.Allow(ctx, "ip:10.0.0.1")
+		assert.NoError(t, err)
+		assert.Equal(t, want, retryAfter)
+
+		clock.now = clock.now.Add(retryAfter)
+	}
+}
+
+func TestLimiter_WindowExpiry(t *testing.T) {
+	ctx := context.Background()
+	l, clock := newTestLimiter(repository.NewInMemoryAttemptRepository())
+
+	assert.NoError(t, l.Record(ctx, "user:alice"))
+	assert.NoError(t, l.Record(ctx, "user:alice"))
+	clock.now = clock.now.Add(2 * time.Minute)
+	assert.NoError(t, l.Record(ctx, "user:alice"))
+
+	allowed, _, err := l.Allow(ctx, "user:alice")
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestLimiter_SurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewInMemoryAttemptRepository()
+
+	l, _ := newTestLimiter(store)
+	for i := 0; i < testPolicy.MaxAttempts; i++ {
+		assert.NoError(t, l.Record(ctx, "user:alice"))
+	}
+
+	// 同じストアを使う新しい Limiter でもロックが維持される
+	restarted, _ := newTestLimiter(store)
+	allowed, _, err := restarted.Allow(ctx, "user:alice")
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+
+	assert.NoError(t, restarted.Reset(ctx, "user:alice"))
+	allowed, _, err = restarted.Allow(ctx, "user:alice")
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+// savedAttempts は保存された試行を記録する
+type savedAttempts struct {
+	repository.AttemptRepository
+	saved []repository.Attempt
+}
+
+func (s *savedAttempts) SaveAttempt(ctx context.Context, attempt *repository.Attempt) error {
+	s.saved = append(s.saved, *attempt)
+	return s.AttemptRepository.SaveAttempt(ctx, attempt)
+}
+
+func TestLimiter_RecordsUTC(t *testing.T) {
+	ctx := context.Background()
+	store := &savedAttempts{AttemptRepository: repository.NewInMemoryAttemptRepository()}
+	l, clock := newTestLimiter(store)
+	clock.now = clock.now.In(time.FixedZone("JST", 9*60*60))
+
+	for i := 0; i < testPolicy.MaxAttempts; i++ {
+		assert.NoError(t, l.Record(ctx, "user:alice"))
+	}
+
+	assert.Len(t, store.saved, testPolicy.MaxAttempts)
+	for _, attempt := range store.saved {
+		assert.Equal(t, time.UTC, attempt.WindowStart.Location())
+	}
+	locked := store.saved[len(store.saved)-1]
+	assert.Equal(t, time.UTC, locked.LockedUntil.Location())
+	assert.Equal(t, time.Date(2025, 1, 1, 9, 0, 10, 0, time.UTC), locked.LockedUntil)
+
+	allowed, retryAfter, err := l.Allow(ctx, "user:alice")
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, 10*time.Second, retryAfter)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	l, _ := newTestLimiter(repository.NewInMemoryAttemptRepository())
+	interceptor := l.UnaryServerInterceptor(proto.LogService_AddLogs_FullMethodName)
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 40000},
+	})
+	handler := func(ctx context.Context, req any) (any, error) {
+		return &proto.AddResponse{Message: "ok"}, nil
+	}
+	entry := &proto.LogEntry{UserName: "alice"}
+
+	addInfo := &grpc.UnaryServerInfo{FullMethod: proto.LogService_AddLogs_FullMethodName}
+	for i := 0; i < testPolicy.MaxAttempts; i++ {
+		_, err := interceptor(ctx, entry, addInfo, handler)
+		assert.NoError(t, err)
+	}
+
+	_, err := interceptor(ctx, entry, addInfo, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// 対象外のメソッドは制限されない
+	fetchInfo := &grpc.UnaryServerInfo{FullMethod: proto.LogService_FetchLogs_FullMethodName}
+	_, err = interceptor(ctx, &proto.FetchRequest{}, fetchInfo, handler)
+	assert.NoError(t, err)
+}
+
+func TestUnaryServerInterceptor_KeysOnCaller(t *testing.T) {
+	l, _ := newTestLimiter(repository.NewInMemoryAttemptRepository())
+	interceptor := l.UnaryServerInterceptor(proto.LogService_AddLogs_FullMethodName)
+
+	// Web サーバー経由の呼び出しはすべて同じ IP から届く
+	webServer := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("192.0.2.10"), Port: 40000},
+	})
+	as := func(username string) context.Context {
+		return auth.WithIdentity(webServer, auth.Identity{Username: username, Role: repository.RoleMember})
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return &proto.AddResponse{Message: "ok"}, nil
+	}
+	addInfo := &grpc.UnaryServerInfo{FullMethod: proto.LogService_AddLogs_FullMethodName}
+
+	// mallory が user_name に alice を書いて送り続けても、数えられるのは mallory
+	for i := 0; i < testPolicy.MaxAttempts; i++ {
+		_, err := interceptor(as("mallory"), &proto.LogEntry{UserName: "alice"}, addInfo, handler)
+		assert.NoError(t, err)
+	}
+	_, err := interceptor(as("mallory"), &proto.LogEntry{UserName: "alice"}, addInfo, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// 同じ IP からでも alice は制限されない
+	_, err = interceptor(as("alice"), &proto.LogEntry{UserName: "alice"}, addInfo, handler)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Attempt はレート制限のキー（ユーザー名や IP）ごとの試行状況
+type Attempt struct {
+	Key         string
+	Failures    int
+	Lockouts    int
+	WindowStart time.Time
+	LockedUntil time.Time
+}
+
+type AttemptRepository interface {
+	GetAttempt(ctx context.Context, key string) (*Attempt, error)
+	SaveAttempt(ctx context.Context, attempt *Attempt) error
+	DeleteAttempt(ctx context.Context, key string) error
+}
+
+type postgresAttemptRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresAttemptRepository(db *sql.DB) AttemptRepository {
+	return &postgresAttemptRepository{db: db}
+}
+
+// GetAttempt は記録がない場合 nil を返す
+func (r *postgresAttemptRepository) GetAttempt(ctx context.Context, key string) (*Attempt, error) {
+	attempt := &Attempt{Key: key}
+	var lockedUntil sql.NullTime
+	err := r.db.QueryRowContext(ctx, `
+        SELECT failures, lockouts, window_start, locked_until FROM auth_attempts WHERE key = $1
+        `, key).Scan(&attempt.Failures, &attempt.Lockouts, &attempt.WindowStart, &lockedUntil)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	attempt.LockedUntil = lockedUntil.Time
+	return attempt, nil
+}
+
+// SaveAttempt は時刻を UTC に揃えて保存する。列はタイムゾーンなしの TIMESTAMP で、
+// ローカル時刻のまま渡すとオフセットが捨てられてずれる
+func (r *postgresAttemptRepository) SaveAttempt(ctx context.Context, attempt *Attempt) error {
+	var lockedUntil sql.NullTime
+	if !attempt.LockedUntil.IsZero() {
+		lockedUntil = sql.NullTime{Time: attempt.LockedUntil.UTC(), Valid: true}
+	}
+	_, err := r.db.ExecContext(ctx, `
+        INSERT INTO auth_attempts (key, failures, lockouts, window_start, locked_until, updated_at)
+        VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
+        ON CONFLICT (key) DO UPDATE SET
+            failures = EXCLUDED.failures,
+            lockouts = EXCLUDED.lockouts,
+            window_start = EXCLUDED.window_start,
+            locked_until = EXCLUDED.locked_until,
+            updated_at = CURRENT_TIMESTAMP
+        `, attempt.Key, attempt.Failures, attempt.Lockouts, attempt.WindowStart.UTC(), lockedUntil)
+	return err
+}
+
+func (r *postgresAttemptRepository) DeleteAttempt(ctx context.Context, key string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM auth_attempts WHERE key = $1", key)
+	return err
+}
+
+type InMemoryAttemptRepository struct {
+	attempts map[string]Attempt
+	mutex    sync.Mutex
+}
+
+func NewInMemoryAttemptRepository() *InMemoryAttemptRepository {
+	return &InMemoryAttemptRepository{
+		attempts: make(map[string]Attempt),
+	}
+}
+
+func (r *InMemoryAttemptRepository) GetAttempt(ctx context.Context, key string) (*Attempt, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	attempt, exists := r.attempts[key]
+	if !exists {
+		return nil, nil
+	}
+	return &attempt, nil
+}
+
+func (r *InMemoryAttemptRepository) SaveAttempt(ctx context.Context, attempt *Attempt) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.attempts[attempt.Key] = *attempt
+	return nil
+}
+
+func (r *InMemoryAttemptRepository) DeleteAttempt(ctx context.Context, key string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.attempts, key)
+	return nil
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresAttemptRepository_SaveAttemptStoresUTC(t *testing.T) {
+	recorder := &queryRecorder{}
+	db := sql.OpenDB(recorder)
+	t.Cleanup(func() { _ = db.Close() })
+	attempts := NewPostgresAttemptRepository(db)
+
+	// TIMESTAMP 列はオフセットを捨てるので、ローカル時刻のままだと JST では 9 時間ずれる
+	jst := time.FixedZone("JST", 9*60*60)
+	windowStart := time.Date(2025, 1, 1, 18, 0, 0, 0, jst)
+	lockedUntil := windowStart.Add(10 * time.Second)
+	require.NoError(t, attempts.SaveAttempt(context.Background(), &Attempt{
+		Key:         "login:user:alice",
+		Failures:    0,
+		Lockouts:    1,
+		WindowStart: windowStart,
+		LockedUntil: lockedUntil,
+	}))
+
+	queries := recorder.take()
+	require.Len(t, queries, 1)
+	args := queries[0].args
+	require.Len(t, args, 5)
+	assert.Equal(t, time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC), args[3])
+	assert.Equal(t, time.Date(2025, 1, 1, 9, 0, 10, 0, time.UTC), args[4])
+}
+
+func TestPostgresAttemptRepository_SaveAttemptWithoutLock(t *testing.T) {
+	recorder := &queryRecorder{}
+	db := sql.OpenDB(recorder)
+	t.Cleanup(func() { _ = db.Close() })
+	attempts := NewPostgresAttemptRepository(db)
+
+	require.NoError(t, attempts.SaveAttempt(context.Background(), &Attempt{
+		Key:         "login:user:alice",
+		Failures:    1,
+		WindowStart: time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC),
+	}))
+
+	queries := recorder.take()
+	require.Len(t, queries, 1)
+	assert.Nil(t, queries[0].args[4])
+}
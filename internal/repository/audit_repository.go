@@ -0,0 +1,324 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/tenant"
+	"github.com/gensan0223/snulog/internal/util"
+)
+
+type AuditEvent struct {
+	ID int64
+	// OrgID が 0 なら Append 時に context の組織を使う
+	OrgID      int64
+	OccurredAt time.Time
+	Actor      string
+	Action     string
+	Target     string
+	Source     string
+	Before     json.RawMessage
+	After      json.RawMessage
+}
+
+type AuditFilter struct {
+	Actor  string
+	Action string
+	Target string
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+}
+
+func (f AuditFilter) matches(e *AuditEvent) bool {
+	switch {
+	case f.Actor != "" && e.Actor != f.Actor:
+		return false
+	case f.Action != "" && e.Action != f.Action:
+		return false
+	case f.Target != "" && e.Target != f.Target:
+		return false
+	case !f.Since.IsZero() && e.OccurredAt.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && !e.OccurredAt.Before(f.Until):
+		return false
+	}
+	return true
+}
+
+// concerns はイベントが username の個人データを含むか（本人の操作、本人が対象、本人のログの内容）を返す
+func (e *AuditEvent) concerns(username string) bool {
+	return e.Actor == username || e.Target == "user:"+username ||
+		payloadUser(e.Before) == username || payloadUser(e.After) == username
+}
+
+// payloadUser はログの内容を記録したペイロードの投稿者を返す
+func payloadUser(raw json.RawMessage) string {
+	var payload struct {
+		UserName string `json:"user_name"`
+	}
+	if len(raw) == 0 || json.Unmarshal(raw, &payload) != nil {
+		return ""
+	}
+	return payload.UserName
+}
+
+// concernsClause は AuditEvent.concerns と同じ条件の SQL。$1 は組織、$2 はユーザー名
+const concernsClause = `org_id = $1 AND (actor = $2 OR target = 'user:' || $2
+        OR before->>'user_name' = $2 OR after->>'user_name' = $2)`
+
+// AuditRepository は追記専用。削除は保持期間を過ぎたイベントと、個人データの消去に対してのみ行う。
+// DeleteBefore 以外は context の組織のイベントだけを扱い、DeleteBefore は保持期間の処理として全組織を対象にする
+type AuditRepository interface {
+	Append(ctx context.Context, event *AuditEvent) error
+	List(ctx context.Context, filter AuditFilter) ([]*AuditEvent, error)
+	DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error)
+	// ListByUser は username の個人データを含むイベントを古い順に返す
+	ListByUser(ctx context.Context, username string) ([]*AuditEvent, error)
+	// PseudonymizeUser は username を pseudonym に置き換え、本人の接続元とログの内容を消す。
+	// 発生日時とアクションは残す
+	PseudonymizeUser(ctx context.Context, username, pseudonym string) (int64, error)
+	DeleteByUser(ctx context.Context, username string) (int64, error)
+}
+
+type postgresAuditRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresAuditRepository(db *sql.DB) AuditRepository {
+	return &postgresAuditRepository{db: db}
+}
+
+func (r *postgresAuditRepository) Append(ctx context.Context, event *AuditEvent) error {
+	if event.OrgID == 0 {
+		event.OrgID = tenant.OrgID(ctx)
+	}
+	return r.db.QueryRowContext(ctx, `
+        INSERT INTO audit_events (org_id, occurred_at, actor, action, target, source, before, after)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING id
+        `, event.OrgID, event.OccurredAt, event.Actor, event.Action, event.Target, event.Source,
+		nullJSON(event.Before), nullJSON(event.After)).Scan(&event.ID)
+}
+
+func (r *postgresAuditRepository) List(ctx context.Context, filter AuditFilter) ([]*AuditEvent, error) {
+	var conditions []string
+	var args []any
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	add("org_id = $%d", tenant.OrgID(ctx))
+	if filter.Actor != "" {
+		add("actor = $%d", filter.Actor)
+	}
+	if filter.Action != "" {
+		add("action = $%d", filter.Action)
+	}
+	if filter.Target != "" {
+		add("target = $%d", filter.Target)
+	}
+	if !filter.Since.IsZero() {
+		add("occurred_at >= $%d", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		add("occurred_at < $%d", filter.Until)
+	}
+
+	query := "SELECT id, org_id, occurred_at, actor, action, target, source, before, after FROM audit_events WHERE " +
+		strings.Join(conditions, " AND ")
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" ORDER BY occurred_at DESC, id DESC LIMIT $%d", len(args))
+
+	return r.queryEvents(ctx, query, args...)
+}
+
+func (r *postgresAuditRepository) ListByUser(ctx context.Context, username string) ([]*AuditEvent, error) {
+	return r.queryEvents(ctx, "SELECT id, org_id, occurred_at, actor, action, target, source, before, after FROM audit_events WHERE "+
+		concernsClause+" ORDER BY occurred_at, id", tenant.OrgID(ctx), username)
+}
+
+func (r *postgresAuditRepository) queryEvents(ctx context.Context, query string, args ...any) ([]*AuditEvent, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer util.CloseWithLog(rows)
+
+	var events []*AuditEvent
+	for rows.Next() {
+		var e AuditEvent
+		var before, after []byte
+		if err := rows.Scan(&e.ID, &e.OrgID, &e.OccurredAt, &e.Actor, &e.Action, &e.Target, &e.Source, &before, &after); err != nil {
+			return nil, err
+		}
+		e.Before, e.After = before, after
+		events = append(events, &e)
+	}
+	return events, rows.Err()
+}
+
+// DeleteBefore はトリガーの追記専用チェックを保持期間の削除に限って解除する
+func (r *postgresAuditRepository) DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	return r.execUnlocked(ctx, "snulog.audit_retention", "DELETE FROM audit_events WHERE occurred_at < $1", cutoff)
+}
+
+func (r *postgresAuditRepository) PseudonymizeUser(ctx context.Context, username, pseudonym string) (int64, error) {
+	return r.execUnlocked(ctx, "snulog.audit_erasure", `
+        UPDATE audit_events SET
+            actor = CASE WHEN actor = $2 THEN $3 ELSE actor END,
+            source = CASE WHEN actor = $2 THEN '' ELSE source END,
+            target = CASE WHEN target = 'user:' || $2 THEN 'user:' || $3 ELSE target END,
+            before = CASE WHEN before->>'user_name' = $2 THEN NULL ELSE before END,
+            after = CASE WHEN after->>'user_name' = $2 THEN NULL ELSE after END
+        WHERE `+concernsClause, tenant.OrgID(ctx), username, pseudonym)
+}
+
+func (r *postgresAuditRepository) DeleteByUser(ctx context.Context, username string) (int64, error) {
+	return r.execUnlocked(ctx, "snulog.audit_erasure", "DELETE FROM audit_events WHERE "+concernsClause, tenant.OrgID(ctx), username)
+}
+
+// execUnlocked はトランザクションの間だけ setting を有効にして、トリガーの追記専用チェックを解除する
+func (r *postgresAuditRepository) execUnlocked(ctx context.Context, setting, query string, args ...any) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback() // Commit 後は ErrTxDone になるだけ
+	}()
+
+	if _, err := tx.ExecContext(ctx, "SET LOCAL "+setting+" = 'on'"); err != nil {
+		return 0, err
+	}
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
+}
+
+func nullJSON(raw json.RawMessage) any {
+	if len(raw) == 0 {
+		return nil
+	}
+	return []byte(raw)
+}
+
+type InMemoryAuditRepository struct {
+	events []*AuditEvent
+	mutex  sync.Mutex
+}
+
+func NewInMemoryAuditRepository() *InMemoryAuditRepository {
+	return &InMemoryAuditRepository{}
+}
+
+func (r *InMemoryAuditRepository) Append(ctx context.Context, event *AuditEvent) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	event.ID = int64(len(r.events) + 1)
+	if event.OrgID == 0 {
+		event.OrgID = tenant.OrgID(ctx)
+	}
+	stored := *event
+	r.events = append(r.events, &stored)
+	return nil
+}
+
+func (r *InMemoryAuditRepository) List(ctx context.Context, filter AuditFilter) ([]*AuditEvent, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var events []*AuditEvent
+	for i := len(r.events) - 1; i >= 0; i-- {
+		if r.events[i].OrgID == tenant.OrgID(ctx) && filter.matches(r.events[i]) {
+			e := *r.events[i]
+			events = append(events, &e)
+			if filter.Limit > 0 && len(events) == filter.Limit {
+				break
+			}
+		}
+	}
+	return events, nil
+}
+
+func (r *InMemoryAuditRepository) DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var kept []*AuditEvent
+	for _, e := range r.events {
+		if !e.OccurredAt.Before(cutoff) {
+			kept = append(kept, e)
+		}
+	}
+	deleted := int64(len(r.events) - len(kept))
+	r.events = kept
+	return deleted, nil
+}
+
+func (r *InMemoryAuditRepository) ListByUser(ctx context.Context, username string) ([]*AuditEvent, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var events []*AuditEvent
+	for _, e := range r.events {
+		if e.OrgID == tenant.OrgID(ctx) && e.concerns(username) {
+			copied := *e
+			events = append(events, &copied)
+		}
+	}
+	return events, nil
+}
+
+func (r *InMemoryAuditRepository) PseudonymizeUser(ctx context.Context, username, pseudonym string) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var n int64
+	for _, e := range r.events {
+		if e.OrgID != tenant.OrgID(ctx) || !e.concerns(username) {
+			continue
+		}
+		if e.Actor == username {
+			e.Actor, e.Source = pseudonym, ""
+		}
+		if e.Target == "user:"+username {
+			e.Target = "user:" + pseudonym
+		}
+		if payloadUser(e.Before) == username {
+			e.Before = nil
+		}
+		if payloadUser(e.After) == username {
+			e.After = nil
+		}
+		n++
+	}
+	return n, nil
+}
+
+func (r *InMemoryAuditRepository) DeleteByUser(ctx context.Context, username string) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var kept []*AuditEvent
+	for _, e := range r.events {
+		if e.OrgID != tenant.OrgID(ctx) || !e.concerns(username) {
+			kept = append(kept, e)
+		}
+	}
+	deleted := int64(len(r.events) - len(kept))
+	r.events = kept
+	return deleted, nil
+}
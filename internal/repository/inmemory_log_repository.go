@@ -2,25 +2,243 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"sort"
+	"time"
 
+	"github.com/gensan0223/snulog/internal/tenant"
 	"github.com/gensan0223/snulog/proto"
 )
 
 type InMemoryLogRepository struct {
 	logs []*proto.LogEntry
+	// orgs はログ ID ごとの組織
+	orgs map[int64]int64
+	// keys は組織ごとの idempotency_key とログ ID
+	keys   map[int64]map[string]int64
+	nextID int64
 }
 
 func NewInMemoryLogRepository() *InMemoryLogRepository {
 	return &InMemoryLogRepository{
 		logs: []*proto.LogEntry{},
+		orgs: map[int64]int64{},
+		keys: map[int64]map[string]int64{},
 	}
 }
 
+// inOrg は context の組織のログかを返す
+func (r *InMemoryLogRepository) inOrg(ctx context.Context, entry *proto.LogEntry) bool {
+	return r.orgs[entry.Id] == tenant.OrgID(ctx)
+}
+
 func (r *InMemoryLogRepository) Save(ctx context.Context, entry *proto.LogEntry) error {
+	orgID := tenant.OrgID(ctx)
+	if entry.IdempotencyKey != "" {
+		if id, ok := r.keys[orgID][entry.IdempotencyKey]; ok {
+			entry.Id = id
+			return ErrDuplicateLog
+		}
+	}
+
+	r.nextID++
+	entry.Id = r.nextID
+	entry.Visibility = NormalizeVisibility(entry.Visibility)
+	r.orgs[entry.Id] = orgID
 	r.logs = append(r.logs, entry)
+	if entry.IdempotencyKey != "" {
+		if r.keys[orgID] == nil {
+			r.keys[orgID] = map[string]int64{}
+		}
+		r.keys[orgID][entry.IdempotencyKey] = entry.Id
+	}
 	return nil
 }
 
-func (r *InMemoryLogRepository) FindAll(ctx context.Context) ([]*proto.LogEntry, error) {
-	return r.logs, nil
+func (r *InMemoryLogRepository) SaveBatch(ctx context.Context, entries []*proto.LogEntry) (int, error) {
+	inserted := 0
+	for _, entry := range entries {
+		err := r.Save(ctx, entry)
+		if errors.Is(err, ErrDuplicateLog) {
+			entry.Id = 0
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		inserted++
+	}
+	return inserted, nil
+}
+
+func (r *InMemoryLogRepository) FindAll(ctx context.Context, viewer Viewer) ([]*proto.LogEntry, error) {
+	return r.Search(ctx, viewer, LogQuery{})
+}
+
+func (r *InMemoryLogRepository) FindByID(ctx context.Context, id int64, viewer Viewer) (*proto.LogEntry, error) {
+	for _, entry := range r.logs {
+		if entry.Id == id && r.inOrg(ctx, entry) && viewer.CanSee(entry) {
+			return entry, nil
+		}
+	}
+	return nil, ErrLogNotFound
+}
+
+func (r *InMemoryLogRepository) Search(ctx context.Context, viewer Viewer, query LogQuery) ([]*proto.LogEntry, error) {
+	logs := []*proto.LogEntry{}
+	for _, entry := range r.logs {
+		if r.inOrg(ctx, entry) && viewer.CanSee(entry) && query.matches(entry) {
+			logs = append(logs, entry)
+		}
+	}
+	// Postgres と同じく新しい順にする
+	sort.SliceStable(logs, func(i, j int) bool {
+		return logTime(logs[i]).After(logTime(logs[j]))
+	})
+	if query.Limit > 0 && len(logs) > query.Limit {
+		logs = logs[:query.Limit]
+	}
+	return logs, nil
+}
+
+func (r *InMemoryLogRepository) Each(ctx context.Context, viewer Viewer, query LogQuery, fn func(*proto.LogEntry) error) error {
+	query.Limit = 0
+	logs, err := r.Search(ctx, viewer, query)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(logs, func(i, j int) bool {
+		a, b := logTime(logs[i]), logTime(logs[j])
+		if a.Equal(b) {
+			return logs[i].Id < logs[j].Id
+		}
+		return a.Before(b)
+	})
+	for _, entry := range logs {
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *InMemoryLogRepository) MoodStats(ctx context.Context, viewer Viewer, since, until time.Time) (*proto.MoodStatsResponse, error) {
+	team := map[string]int64{}
+	byUser := map[[2]string]int64{}
+	for _, entry := range r.logs {
+		if !r.inOrg(ctx, entry) || entry.Note || !inRange(entry, since, until) {
+			continue
+		}
+		team[entry.Feeling]++
+		if viewer.CanSee(entry) {
+			byUser[[2]string{entry.UserName, entry.Feeling}]++
+		}
+	}
+
+	stats := &proto.MoodStatsResponse{}
+	for feeling, count := range team {
+		stats.Team = append(stats.Team, &proto.MoodCount{Feeling: feeling, Count: count})
+	}
+	for key, count := range byUser {
+		stats.ByUser = append(stats.ByUser, &proto.UserMoodCount{UserName: key[0], Feeling: key[1], Count: count})
+	}
+	sort.Slice(stats.Team, func(i, j int) bool {
+		if stats.Team[i].Count != stats.Team[j].Count {
+			return stats.Team[i].Count > stats.Team[j].Count
+		}
+		return stats.Team[i].Feeling < stats.Team[j].Feeling
+	})
+	sort.Slice(stats.ByUser, func(i, j int) bool {
+		a, b := stats.ByUser[i], stats.ByUser[j]
+		if a.UserName != b.UserName {
+			return a.UserName < b.UserName
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Feeling < b.Feeling
+	})
+	return stats, nil
+}
+
+func (r *InMemoryLogRepository) CountByDay(ctx context.Context, viewer Viewer, query LogQuery) ([]DayCount, error) {
+	counts := map[[2]string]int64{}
+	for _, entry := range r.logs {
+		if !r.inOrg(ctx, entry) || !viewer.CanSee(entry) || !query.matches(entry) {
+			continue
+		}
+		// Postgres と同じく、UTC に直さず投稿者の時計での日付にする
+		ts, err := time.Parse(time.RFC3339, entry.Timestamp)
+		if err != nil {
+			continue
+		}
+		counts[[2]string{ts.Format(time.DateOnly), entry.Feeling}]++
+	}
+
+	days := make([]DayCount, 0, len(counts))
+	for key, count := range counts {
+		days = append(days, DayCount{Date: key[0], Feeling: key[1], Count: count})
+	}
+	sort.Slice(days, func(i, j int) bool {
+		if days[i].Date != days[j].Date {
+			return days[i].Date < days[j].Date
+		}
+		return days[i].Feeling < days[j].Feeling
+	})
+	return days, nil
+}
+
+func (r *InMemoryLogRepository) Delete(ctx context.Context, id int64) error {
+	for i, entry := range r.logs {
+		if entry.Id == id && r.inOrg(ctx, entry) {
+			r.logs = append(r.logs[:i], r.logs[i+1:]...)
+			delete(r.keys[r.orgs[id]], entry.IdempotencyKey)
+			delete(r.orgs, id)
+			return nil
+		}
+	}
+	return ErrLogNotFound
+}
+
+func (r *InMemoryLogRepository) FindByUser(ctx context.Context, userName string) ([]*proto.LogEntry, error) {
+	logs := []*proto.LogEntry{}
+	for _, entry := range r.logs {
+		if r.inOrg(ctx, entry) && entry.UserName == userName {
+			logs = append(logs, entry)
+		}
+	}
+	return logs, nil
+}
+
+func (r *InMemoryLogRepository) PseudonymizeUser(ctx context.Context, userName, pseudonym string) (int64, error) {
+	var n int64
+	for _, entry := range r.logs {
+		if r.inOrg(ctx, entry) && entry.UserName == userName {
+			entry.UserName = pseudonym
+			entry.Status = ""
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *InMemoryLogRepository) DeleteByUser(ctx context.Context, userName string) (int64, error) {
+	kept := []*proto.LogEntry{}
+	for _, entry := range r.logs {
+		if r.inOrg(ctx, entry) && entry.UserName == userName {
+			delete(r.keys[r.orgs[entry.Id]], entry.IdempotencyKey)
+			delete(r.orgs, entry.Id)
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	n := int64(len(r.logs) - len(kept))
+	r.logs = kept
+	return n, nil
+}
+
+// logTime は並べ替えに使う時刻。解釈できない timestamp は最も古い扱いにする
+func logTime(entry *proto.LogEntry) time.Time {
+	t, _ := time.Parse(time.RFC3339, entry.Timestamp)
+	return t
 }
@@ -1,11 +1,49 @@
 package repository
 
 import (
+	"errors"
+	"time"
+
 	"github.com/gensan0223/snulog/proto"
 	"golang.org/x/net/context"
 )
 
+var (
+	ErrLogNotFound = errors.New("log not found")
+	// ErrDuplicateLog は同じ idempotency_key のログが追加済みであることを表す。entry.Id には既存のログの ID が入る
+	ErrDuplicateLog = errors.New("log with the same idempotency key already exists")
+)
+
+// DayCount は 1 日に投稿された気分ごとのログの件数。Date は投稿者の時計での日付（YYYY-MM-DD）
+type DayCount struct {
+	Date    string
+	Feeling string
+	Count   int64
+}
+
+// LogRepository は context の組織（tenant.OrgID）のログだけを扱い、読み取りは必ず Viewer の公開範囲で絞り込む。
+// メモ（LogEntry.Note）は LogQuery.Notes で求めたときと FindByID・FindByUser 以外では返さず、集計にも含めない
 type LogRepository interface {
+	// Save は entry.IdempotencyKey が空でなく、同じ組織で追加済みなら ErrDuplicateLog を返す
 	Save(ctx context.Context, entry *proto.LogEntry) error
-	FindAll(ctx context.Context) ([]*proto.LogEntry, error)
+	// SaveBatch は entries を 1 つのトランザクションで追加し、追加した数を返す。
+	// 同じ idempotency_key のログが追加済みのものは追加せず、Id を 0 にする。失敗したらどれも追加しない
+	SaveBatch(ctx context.Context, entries []*proto.LogEntry) (int, error)
+	FindAll(ctx context.Context, viewer Viewer) ([]*proto.LogEntry, error)
+	// FindByID は viewer が読めないログも ErrLogNotFound として扱う
+	FindByID(ctx context.Context, id int64, viewer Viewer) (*proto.LogEntry, error)
+	Search(ctx context.Context, viewer Viewer, query LogQuery) ([]*proto.LogEntry, error)
+	// Each は viewer が読める query のログを古い順に 1 件ずつ fn に渡す。query.Limit は使わない。
+	// 全件をメモリに載せないので書き出しに使う。fn がエラーを返したらそこで止めてそのエラーを返す
+	Each(ctx context.Context, viewer Viewer, query LogQuery, fn func(*proto.LogEntry) error) error
+	// MoodStats は全体の集計を匿名で、ユーザー別の集計を viewer が読めるログだけで返す
+	MoodStats(ctx context.Context, viewer Viewer, since, until time.Time) (*proto.MoodStatsResponse, error)
+	// CountByDay は viewer が読める query のログを投稿した日と気分ごとに数え、日付と気分の順に返す。query.Limit は使わない
+	CountByDay(ctx context.Context, viewer Viewer, query LogQuery) ([]DayCount, error)
+	Delete(ctx context.Context, id int64) error
+	// FindByUser は公開範囲に関係なく userName のログをすべて返す。本人データの書き出し専用
+	FindByUser(ctx context.Context, userName string) ([]*proto.LogEntry, error)
+	// PseudonymizeUser は userName のログの投稿者を pseudonym に置き換えて本文を消す。気分は集計のために残す
+	PseudonymizeUser(ctx context.Context, userName, pseudonym string) (int64, error)
+	DeleteByUser(ctx context.Context, userName string) (int64, error)
 }
@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/tenant"
+	"github.com/gensan0223/snulog/internal/util"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrOrganizationNotFound = errors.New("organization not found")
+	ErrTeamNotFound         = errors.New("team not found")
+	// ErrAlreadyExists は slug などの一意制約に違反した場合に返す
+	ErrAlreadyExists = errors.New("already exists")
+)
+
+type Organization struct {
+	ID        int64
+	Slug      string
+	Name      string
+	CreatedAt time.Time
+}
+
+// OrganizationRepository は組織そのものを扱うため tenant では絞り込まない。
+// 呼び出しをインスタンス管理者に限定するのは usecase の役割
+type OrganizationRepository interface {
+	Create(ctx context.Context, org *Organization) error
+	FindByID(ctx context.Context, id int64) (*Organization, error)
+	FindBySlug(ctx context.Context, slug string) (*Organization, error)
+	List(ctx context.Context) ([]*Organization, error)
+}
+
+type Team struct {
+	ID        int64
+	OrgID     int64
+	Slug      string
+	Name      string
+	CreatedAt time.Time
+}
+
+// TeamRepository は context の組織（tenant.OrgID）のチームだけを扱う
+type TeamRepository interface {
+	Create(ctx context.Context, team *Team) error
+	FindBySlug(ctx context.Context, slug string) (*Team, error)
+	List(ctx context.Context) ([]*Team, error)
+}
+
+// isUniqueViolation は Postgres の一意制約違反（23505）かを返す
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+type postgresOrganizationRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresOrganizationRepository(db *sql.DB) OrganizationRepository {
+	return &postgresOrganizationRepository{db: db}
+}
+
+func (r *postgresOrganizationRepository) Create(ctx context.Context, org *Organization) error {
+	err := r.db.QueryRowContext(ctx,
+		"INSERT INTO organizations (slug, name) VALUES ($1, $2) RETURNING id, created_at",
+		org.Slug, org.Name).Scan(&org.ID, &org.CreatedAt)
+	if isUniqueViolation(err) {
+		return ErrAlreadyExists
+	}
+	return err
+}
+
+func (r *postgresOrganizationRepository) FindByID(ctx context.Context, id int64) (*Organization, error) {
+	return r.findOne(ctx, "SELECT id, slug, name, created_at FROM organizations WHERE id = $1", id)
+}
+
+func (r *postgresOrganizationRepository) FindBySlug(ctx context.Context, slug string) (*Organization, error) {
+	return r.findOne(ctx, "SELECT id, slug, name, created_at FROM organizations WHERE slug = $1", slug)
+}
+
+func (r *postgresOrganizationRepository) findOne(ctx context.Context, query string, arg any) (*Organization, error) {
+	org := &Organization{}
+	err := r.db.QueryRowContext(ctx, query, arg).Scan(&org.ID, &org.Slug, &org.Name, &org.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrOrganizationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+func (r *postgresOrganizationRepository) List(ctx context.Context) ([]*Organization, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT id, slug, name, created_at FROM organizations ORDER BY slug")
+	if err != nil {
+		return nil, err
+	}
+	defer util.CloseWithLog(rows)
+
+	var orgs []*Organization
+	for rows.Next() {
+		org := &Organization{}
+		if err := rows.Scan(&org.ID, &org.Slug, &org.Name, &org.CreatedAt); err != nil {
+			return nil, err
+		}
+		orgs = append(orgs, org)
+	}
+	return orgs, rows.Err()
+}
+
+type postgresTeamRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresTeamRepository(db *sql.DB) TeamRepository {
+	return &postgresTeamRepository{db: db}
+}
+
+func (r *postgresTeamRepository) Create(ctx context.Context, team *Team) error {
+	team.OrgID = tenant.OrgID(ctx)
+	err := r.db.QueryRowContext(ctx,
+		"INSERT INTO teams (org_id, slug, name) VALUES ($1, $2, $3) RETURNING id, created_at",
+		team.OrgID, team.Slug, team.Name).Scan(&team.ID, &team.CreatedAt)
+	if isUniqueViolation(err) {
+		return ErrAlreadyExists
+	}
+	return err
+}
+
+func (r *postgresTeamRepository) FindBySlug(ctx context.Context, slug string) (*Team, error) {
+	team := &Team{}
+	err := r.db.QueryRowContext(ctx,
+		"SELECT id, org_id, slug, name, created_at FROM teams WHERE org_id = $1 AND slug = $2",
+		tenant.OrgID(ctx), slug).Scan(&team.ID, &team.OrgID, &team.Slug, &team.Name, &team.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTeamNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+func (r *postgresTeamRepository) List(ctx context.Context) ([]*Team, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT id, org_id, slug, name, created_at FROM teams WHERE org_id = $1 ORDER BY slug", tenant.OrgID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer util.CloseWithLog(rows)
+
+	var teams []*Team
+	for rows.Next() {
+		team := &Team{}
+		if err := rows.Scan(&team.ID, &team.OrgID, &team.Slug, &team.Name, &team.CreatedAt); err != nil {
+			return nil, err
+		}
+		teams = append(teams, team)
+	}
+	return teams, rows.Err()
+}
+
+type InMemoryOrganizationRepository struct {
+	orgs  []*Organization
+	mutex sync.Mutex
+}
+
+// NewInMemoryOrganizationRepository は既定の組織だけが存在する状態で作る
+func NewInMemoryOrganizationRepository() *InMemoryOrganizationRepository {
+	return &InMemoryOrganizationRepository{
+		orgs: []*Organization{{ID: tenant.DefaultOrgID, Slug: "default", Name: "Default", CreatedAt: time.Now()}},
+	}
+}
+
+func (r *InMemoryOrganizationRepository) Create(ctx context.Context, org *Organization) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, existing := range r.orgs {
+		if existing.Slug == org.Slug {
+			return ErrAlreadyExists
+		}
+	}
+	org.ID = r.orgs[len(r.orgs)-1].ID + 1
+	org.CreatedAt = time.Now()
+	stored := *org
+	r.orgs = append(r.orgs, &stored)
+	return nil
+}
+
+func (r *InMemoryOrganizationRepository) FindByID(ctx context.Context, id int64) (*Organization, error) {
+	return r.find(func(org *Organization) bool { return org.ID == id })
+}
+
+func (r *InMemoryOrganizationRepository) FindBySlug(ctx context.Context, slug string) (*Organization, error) {
+	return r.find(func(org *Organization) bool { return org.Slug == slug })
+}
+
+func (r *InMemoryOrganizationRepository) find(match func(*Organization) bool) (*Organization, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, org := range r.orgs {
+		if match(org) {
+			found := *org
+			return &found, nil
+		}
+	}
+	return nil, ErrOrganizationNotFound
+}
+
+func (r *InMemoryOrganizationRepository) List(ctx context.Context) ([]*Organization, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	orgs := make([]*Organization, 0, len(r.orgs))
+	for _, org := range r.orgs {
+		found := *org
+		orgs = append(orgs, &found)
+	}
+	sort.Slice(orgs, func(i, j int) bool { return orgs[i].Slug < orgs[j].Slug })
+	return orgs, nil
+}
+
+type InMemoryTeamRepository struct {
+	teams []*Team
+	mutex sync.Mutex
+}
+
+func NewInMemoryTeamRepository() *InMemoryTeamRepository {
+	return &InMemoryTeamRepository{}
+}
+
+func (r *InMemoryTeamRepository) Create(ctx context.Context, team *Team) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	team.OrgID = tenant.OrgID(ctx)
+	for _, existing := range r.teams {
+		if existing.OrgID == team.OrgID && existing.Slug == team.Slug {
+			return ErrAlreadyExists
+		}
+	}
+	team.ID = int64(len(r.teams) + 1)
+	team.CreatedAt = time.Now()
+	stored := *team
+	r.teams = append(r.teams, &stored)
+	return nil
+}
+
+func (r *InMemoryTeamRepository) FindBySlug(ctx context.Context, slug string) (*Team, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, team := range r.teams {
+		if team.OrgID == tenant.OrgID(ctx) && team.Slug == slug {
+			found := *team
+			return &found, nil
+		}
+	}
+	return nil, ErrTeamNotFound
+}
+
+func (r *InMemoryTeamRepository) List(ctx context.Context) ([]*Team, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	teams := []*Team{}
+	for _, team := range r.teams {
+		if team.OrgID == tenant.OrgID(ctx) {
+			found := *team
+			teams = append(teams, &found)
+		}
+	}
+	sort.Slice(teams, func(i, j int) bool { return teams[i].Slug < teams[j].Slug })
+	return teams, nil
+}
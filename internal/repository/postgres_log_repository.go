@@ -3,11 +3,19 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/gensan0223/snulog/internal/tenant"
 	"github.com/gensan0223/snulog/internal/util"
 	"github.com/gensan0223/snulog/proto"
 )
 
+const logColumns = "id, user_name, status, feeling, timestamp, visibility, is_note"
+
+// PostgresLogRepository のクエリはすべて context の組織（tenant.OrgID）で絞り込む
 type PostgresLogRepository struct {
 	db *sql.DB
 }
@@ -16,18 +24,249 @@ func NewPostgresLogRepository(db *sql.DB) *PostgresLogRepository {
 	return &PostgresLogRepository{db: db}
 }
 
+// insertLogQuery は同じ idempotency_key のログが既にあれば何も返さない
+const insertLogQuery = `
+        INSERT INTO logs (org_id, user_name, status, feeling, timestamp, visibility, idempotency_key, is_note)
+        VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8)
+        ON CONFLICT (org_id, idempotency_key) WHERE idempotency_key IS NOT NULL DO NOTHING
+        RETURNING id
+        `
+
+func insertLogArgs(ctx context.Context, entry *proto.LogEntry) []any {
+	entry.Visibility = NormalizeVisibility(entry.Visibility)
+	return []any{tenant.OrgID(ctx), entry.UserName, entry.Status, entry.Feeling, entry.Timestamp, visibilityToDB(entry.Visibility), entry.IdempotencyKey, entry.Note}
+}
+
 func (r *PostgresLogRepository) Save(ctx context.Context, entry *proto.LogEntry) error {
-	_, err := r.db.ExecContext(ctx, `
-        INSERT INTO logs (user_name, status, feeling, timestamp)
-        VALUES ($1, $2, $3, $4)
-        `, entry.UserName, entry.Status, entry.Feeling, entry.Timestamp)
-	return err
+	err := r.db.QueryRowContext(ctx, insertLogQuery, insertLogArgs(ctx, entry)...).Scan(&entry.Id)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	// 同じキーのログが既にある
+	err = r.db.QueryRowContext(ctx, "SELECT id FROM logs WHERE org_id = $1 AND idempotency_key = $2",
+		tenant.OrgID(ctx), entry.IdempotencyKey).Scan(&entry.Id)
+	if err != nil {
+		return err
+	}
+	return ErrDuplicateLog
+}
+
+func (r *PostgresLogRepository) SaveBatch(ctx context.Context, entries []*proto.LogEntry) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback() // Commit 後は ErrTxDone になるだけ
+	}()
+
+	stmt, err := tx.PrepareContext(ctx, insertLogQuery)
+	if err != nil {
+		return 0, err
+	}
+	defer util.CloseWithLog(stmt)
+
+	inserted := 0
+	for _, entry := range entries {
+		err := stmt.QueryRowContext(ctx, insertLogArgs(ctx, entry)...).Scan(&entry.Id)
+		if errors.Is(err, sql.ErrNoRows) {
+			entry.Id = 0
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		inserted++
+	}
+	return inserted, tx.Commit()
+}
+
+func (r *PostgresLogRepository) FindAll(ctx context.Context, viewer Viewer) ([]*proto.LogEntry, error) {
+	visible, args := viewer.visibleClause(2)
+	return r.queryLogs(ctx, "SELECT "+logColumns+" FROM logs WHERE org_id = $1 AND NOT is_note AND "+visible+" ORDER BY timestamp desc",
+		append([]any{tenant.OrgID(ctx)}, args...)...)
+}
+
+func (r *PostgresLogRepository) FindByID(ctx context.Context, id int64, viewer Viewer) (*proto.LogEntry, error) {
+	visible, args := viewer.visibleClause(3)
+	logs, err := r.queryLogs(ctx, "SELECT "+logColumns+" FROM logs WHERE id = $1 AND org_id = $2 AND "+visible,
+		append([]any{id, tenant.OrgID(ctx)}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	if len(logs) == 0 {
+		return nil, ErrLogNotFound
+	}
+	return logs[0], nil
+}
+
+func (r *PostgresLogRepository) Search(ctx context.Context, viewer Viewer, query LogQuery) ([]*proto.LogEntry, error) {
+	where, args := searchWhere(ctx, viewer, query)
+	sqlQuery := "SELECT " + logColumns + " FROM logs WHERE " + where + " ORDER BY timestamp desc"
+	if query.Limit > 0 {
+		args = append(args, query.Limit)
+		sqlQuery += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	return r.queryLogs(ctx, sqlQuery, args...)
+}
+
+func (r *PostgresLogRepository) Each(ctx context.Context, viewer Viewer, query LogQuery, fn func(*proto.LogEntry) error) error {
+	where, args := searchWhere(ctx, viewer, query)
+	rows, err := r.db.QueryContext(ctx, "SELECT "+logColumns+" FROM logs WHERE "+where+" ORDER BY timestamp, id", args...)
+	if err != nil {
+		return err
+	}
+	defer util.CloseWithLog(rows)
+
+	for rows.Next() {
+		entry, err := scanLog(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// searchWhere は query の条件を WHERE 句と引数にする。$1 は組織
+func searchWhere(ctx context.Context, viewer Viewer, query LogQuery) (string, []any) {
+	visible, visibleArgs := viewer.visibleClause(2)
+	args := append([]any{tenant.OrgID(ctx)}, visibleArgs...)
+	conditions := []string{"org_id = $1", visible}
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	add("is_note = $%d", query.Notes)
+
+	if query.Text != "" {
+		args = append(args, "%"+escapeLike(query.Text)+"%")
+		conditions = append(conditions, fmt.Sprintf("(status ILIKE $%d OR feeling ILIKE $%d)", len(args), len(args)))
+	}
+	if query.UserName != "" {
+		add("user_name = $%d", query.UserName)
+	}
+	if !query.Since.IsZero() {
+		add("timestamp >= $%d", query.Since)
+	}
+	if !query.Until.IsZero() {
+		add("timestamp < $%d", query.Until)
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+func (r *PostgresLogRepository) MoodStats(ctx context.Context, viewer Viewer, since, until time.Time) (*proto.MoodStatsResponse, error) {
+	rangeConditions := []string{"org_id = $1", "NOT is_note"}
+	rangeArgs := []any{tenant.OrgID(ctx)}
+	if !since.IsZero() {
+		rangeArgs = append(rangeArgs, since)
+		rangeConditions = append(rangeConditions, fmt.Sprintf("timestamp >= $%d", len(rangeArgs)))
+	}
+	if !until.IsZero() {
+		rangeArgs = append(rangeArgs, until)
+		rangeConditions = append(rangeConditions, fmt.Sprintf("timestamp < $%d", len(rangeArgs)))
+	}
+
+	stats := &proto.MoodStatsResponse{}
+
+	// 全体の集計にはユーザー名を含めないので、private のログも数える
+	teamWhere := strings.Join(rangeConditions, " AND ")
+	rows, err := r.db.QueryContext(ctx, "SELECT feeling, COUNT(*) FROM logs WHERE "+teamWhere+" GROUP BY feeling ORDER BY COUNT(*) DESC, feeling", rangeArgs...)
+	if err != nil {
+		return nil, err
+	}
+	defer util.CloseWithLog(rows)
+	for rows.Next() {
+		mood := &proto.MoodCount{}
+		if err := rows.Scan(&mood.Feeling, &mood.Count); err != nil {
+			return nil, err
+		}
+		stats.Team = append(stats.Team, mood)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	visible, visibleArgs := viewer.visibleClause(len(rangeArgs) + 1)
+	userWhere := strings.Join(append(rangeConditions, visible), " AND ")
+	userRows, err := r.db.QueryContext(ctx,
+		"SELECT user_name, feeling, COUNT(*) FROM logs WHERE "+userWhere+" GROUP BY user_name, feeling ORDER BY user_name, COUNT(*) DESC, feeling",
+		append(rangeArgs, visibleArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer util.CloseWithLog(userRows)
+	for userRows.Next() {
+		mood := &proto.UserMoodCount{}
+		if err := userRows.Scan(&mood.UserName, &mood.Feeling, &mood.Count); err != nil {
+			return nil, err
+		}
+		stats.ByUser = append(stats.ByUser, mood)
+	}
+	return stats, userRows.Err()
+}
+
+// CountByDay は timestamp を投稿者の時計のまま保存しているので、その日付で分ける
+func (r *PostgresLogRepository) CountByDay(ctx context.Context, viewer Viewer, query LogQuery) ([]DayCount, error) {
+	where, args := searchWhere(ctx, viewer, query)
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT to_char(timestamp, 'YYYY-MM-DD') AS day, feeling, COUNT(*) FROM logs WHERE "+where+" GROUP BY day, feeling ORDER BY day, feeling",
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer util.CloseWithLog(rows)
+	var counts []DayCount
+	for rows.Next() {
+		var count DayCount
+		if err := rows.Scan(&count.Date, &count.Feeling, &count.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+	return counts, rows.Err()
+}
+
+func (r *PostgresLogRepository) Delete(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM logs WHERE id = $1 AND org_id = $2", id, tenant.OrgID(ctx))
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrLogNotFound
+	}
+	return nil
+}
+
+func (r *PostgresLogRepository) FindByUser(ctx context.Context, userName string) ([]*proto.LogEntry, error) {
+	return r.queryLogs(ctx, "SELECT "+logColumns+" FROM logs WHERE org_id = $1 AND user_name = $2 ORDER BY timestamp", tenant.OrgID(ctx), userName)
+}
+
+func (r *PostgresLogRepository) PseudonymizeUser(ctx context.Context, userName, pseudonym string) (int64, error) {
+	return r.execCount(ctx, "UPDATE logs SET user_name = $3, status = '' WHERE org_id = $1 AND user_name = $2", tenant.OrgID(ctx), userName, pseudonym)
+}
+
+func (r *PostgresLogRepository) DeleteByUser(ctx context.Context, userName string) (int64, error) {
+	return r.execCount(ctx, "DELETE FROM logs WHERE org_id = $1 AND user_name = $2", tenant.OrgID(ctx), userName)
+}
+
+func (r *PostgresLogRepository) execCount(ctx context.Context, query string, args ...any) (int64, error) {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
-func (r *PostgresLogRepository) FindAll(ctx context.Context) ([]*proto.LogEntry, error) {
-	rows, err := r.db.QueryContext(ctx, `
-        SELECT user_name, status, feeling, timestamp FROM logs ORDER BY timestamp desc
-        `)
+func (r *PostgresLogRepository) queryLogs(ctx context.Context, query string, args ...any) ([]*proto.LogEntry, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -35,11 +274,27 @@ func (r *PostgresLogRepository) FindAll(ctx context.Context) ([]*proto.LogEntry,
 
 	var logs []*proto.LogEntry
 	for rows.Next() {
-		var entry proto.LogEntry
-		if err := rows.Scan(&entry.UserName, &entry.Status, &entry.Feeling, &entry.Timestamp); err != nil {
+		entry, err := scanLog(rows)
+		if err != nil {
 			return nil, err
 		}
-		logs = append(logs, &entry)
+		logs = append(logs, entry)
 	}
-	return logs, nil
+	return logs, rows.Err()
+}
+
+// scanLog は logColumns の順に選んだ行を読む
+func scanLog(rows *sql.Rows) (*proto.LogEntry, error) {
+	var entry proto.LogEntry
+	var visibility string
+	if err := rows.Scan(&entry.Id, &entry.UserName, &entry.Status, &entry.Feeling, &entry.Timestamp, &visibility, &entry.Note); err != nil {
+		return nil, err
+	}
+	entry.Visibility = visibilityFromDB(visibility)
+	return &entry, nil
+}
+
+// escapeLike は LIKE のワイルドカードを文字として扱う
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
 }
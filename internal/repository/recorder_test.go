@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"sync"
+)
+
+// queryRecorder は発行された SQL と引数を記録するだけの database/sql ドライバ。
+// 結果は常に空で、Exec は 1 行に作用したことにする
+type queryRecorder struct {
+	mutex   sync.Mutex
+	queries []recordedQuery
+}
+
+type recordedQuery struct {
+	query string
+	args  []any
+}
+
+func (r *queryRecorder) record(query string, args []driver.NamedValue) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	values := make([]any, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	r.queries = append(r.queries, recordedQuery{query: query, args: values})
+}
+
+func (r *queryRecorder) take() []recordedQuery {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	queries := r.queries
+	r.queries = nil
+	return queries
+}
+
+func (r *queryRecorder) Connect(context.Context) (driver.Conn, error) { return recorderConn{r}, nil }
+func (r *queryRecorder) Driver() driver.Driver                        { return nil }
+
+type recorderConn struct{ recorder *queryRecorder }
+
+func (c recorderConn) Prepare(query string) (driver.Stmt, error) {
+	return recorderStmt{recorder: c.recorder, query: query}, nil
+}
+func (c recorderConn) Close() error              { return nil }
+func (c recorderConn) Begin() (driver.Tx, error) { return recorderTx{}, nil }
+
+type recorderTx struct{}
+
+func (recorderTx) Commit() error   { return nil }
+func (recorderTx) Rollback() error { return nil }
+
+type recorderStmt struct {
+	recorder *queryRecorder
+	query    string
+}
+
+func (s recorderStmt) Close() error  { return nil }
+func (s recorderStmt) NumInput() int { return -1 }
+func (s recorderStmt) Exec([]driver.Value) (driver.Result, error) {
+	panic("ExecContext を使う")
+}
+func (s recorderStmt) Query([]driver.Value) (driver.Rows, error) {
+	panic("QueryContext を使う")
+}
+
+func (s recorderStmt) ExecContext(_ context.Context, args []driver.NamedValue) (driver.Result, error) {
+	s.recorder.record(s.query, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (s recorderStmt) QueryContext(_ context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	s.recorder.record(s.query, args)
+	return emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string         { return nil }
+func (emptyRows) Close() error              { return nil }
+func (emptyRows) Next([]driver.Value) error { return io.EOF }
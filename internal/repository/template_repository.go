@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/tenant"
+	"github.com/gensan0223/snulog/internal/util"
+)
+
+var ErrTemplateNotFound = errors.New("template not found")
+
+// Template はチームで使う add の入力テンプレート
+type Template struct {
+	ID        int64
+	OrgID     int64
+	Team      string
+	Name      string
+	Fields    []TemplateField
+	UpdatedBy string
+	UpdatedAt time.Time
+}
+
+// TemplateField はテンプレートで尋ねる 1 項目
+type TemplateField struct {
+	Key      string `json:"key"`
+	Label    string `json:"label"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+// TemplateRepository は context の組織（tenant.OrgID）のテンプレートだけを扱う
+type TemplateRepository interface {
+	// Save は同じチーム・名前のテンプレートがあれば置き換える
+	Save(ctx context.Context, t *Template) error
+	// List は team のテンプレートを名前順に返す
+	List(ctx context.Context, team string) ([]*Template, error)
+	Delete(ctx context.Context, team, name string) error
+}
+
+type postgresTemplateRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresTemplateRepository(db *sql.DB) TemplateRepository {
+	return &postgresTemplateRepository{db: db}
+}
+
+func (r *postgresTemplateRepository) Save(ctx context.Context, t *Template) error {
+	fields, err := json.Marshal(t.Fields)
+	if err != nil {
+		return err
+	}
+	t.OrgID = tenant.OrgID(ctx)
+	return r.db.QueryRowContext(ctx, `
+        INSERT INTO templates (org_id, team, name, fields, updated_by)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (org_id, team, name) DO UPDATE
+        SET fields = EXCLUDED.fields, updated_by = EXCLUDED.updated_by, updated_at = CURRENT_TIMESTAMP
+        RETURNING id, updated_at`,
+		t.OrgID, t.Team, t.Name, fields, t.UpdatedBy).Scan(&t.ID, &t.UpdatedAt)
+}
+
+func (r *postgresTemplateRepository) List(ctx context.Context, team string) ([]*Template, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT id, org_id, team, name, fields, updated_by, updated_at FROM templates WHERE org_id = $1 AND team = $2 ORDER BY name",
+		tenant.OrgID(ctx), team)
+	if err != nil {
+		return nil, err
+	}
+	defer util.CloseWithLog(rows)
+
+	var templates []*Template
+	for rows.Next() {
+		t := &Template{}
+		var fields []byte
+		if err := rows.Scan(&t.ID, &t.OrgID, &t.Team, &t.Name, &fields, &t.UpdatedBy, &t.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(fields, &t.Fields); err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, rows.Err()
+}
+
+func (r *postgresTemplateRepository) Delete(ctx context.Context, team, name string) error {
+	res, err := r.db.ExecContext(ctx,
+		"DELETE FROM templates WHERE org_id = $1 AND team = $2 AND name = $3", tenant.OrgID(ctx), team, name)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrTemplateNotFound
+	}
+	return nil
+}
+
+type InMemoryTemplateRepository struct {
+	templates []*Template
+	nextID    int64
+	mutex     sync.Mutex
+}
+
+func NewInMemoryTemplateRepository() *InMemoryTemplateRepository {
+	return &InMemoryTemplateRepository{}
+}
+
+func (r *InMemoryTemplateRepository) Save(ctx context.Context, t *Template) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	t.OrgID = tenant.OrgID(ctx)
+	t.UpdatedAt = time.Now()
+	stored := *t
+	stored.Fields = append([]TemplateField(nil), t.Fields...)
+	for i, existing := range r.templates {
+		if existing.OrgID == t.OrgID && existing.Team == t.Team && existing.Name == t.Name {
+			t.ID = existing.ID
+			stored.ID = existing.ID
+			r.templates[i] = &stored
+			return nil
+		}
+	}
+	r.nextID++
+	t.ID = r.nextID
+	stored.ID = r.nextID
+	r.templates = append(r.templates, &stored)
+	return nil
+}
+
+func (r *InMemoryTemplateRepository) List(ctx context.Context, team string) ([]*Template, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	templates := []*Template{}
+	for _, t := range r.templates {
+		if t.OrgID == tenant.OrgID(ctx) && t.Team == team {
+			found := *t
+			templates = append(templates, &found)
+		}
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates, nil
+}
+
+func (r *InMemoryTemplateRepository) Delete(ctx context.Context, team, name string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i, t := range r.templates {
+		if t.OrgID == tenant.OrgID(ctx) && t.Team == team && t.Name == name {
+			r.templates = append(r.templates[:i], r.templates[i+1:]...)
+			return nil
+		}
+	}
+	return ErrTemplateNotFound
+}
@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/tenant"
+	"github.com/gensan0223/snulog/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// interfaceMethods は repository インターフェースのメソッドを "Interface.Method" の形で返す
+func interfaceMethods(ifaces ...any) []string {
+	var names []string
+	for _, iface := range ifaces {
+		typ := reflect.TypeOf(iface).Elem()
+		for i := 0; i < typ.NumMethod(); i++ {
+			names = append(names, typ.Name()+"."+typ.Method(i).Name)
+		}
+	}
+	return names
+}
+
+func TestPostgresRepositories_ScopeEveryQueryToOrg(t *testing.T) {
+	const orgID int64 = 42
+	ctx := tenant.WithOrg(context.Background(), orgID)
+	viewer := Viewer{Username: "alice", Role: RoleAdmin}
+
+	recorder := &queryRecorder{}
+	db := sql.OpenDB(recorder)
+	t.Cleanup(func() { _ = db.Close() })
+
+	logs := NewPostgresLogRepository(db)
+	audits := NewPostgresAuditRepository(db)
+	teams := NewPostgresTeamRepository(db)
+	users := NewPostgresUserRepository(db)
+	templates := NewPostgresTemplateRepository(db)
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// 組織で絞り込む操作。すべての SQL が org_id を条件に含み、context の組織を引数に渡す
+	scoped := map[string]func(){
+		"LogRepository.Save": func() { _ = logs.Save(ctx, &proto.LogEntry{UserName: "alice"}) },
+		"LogRepository.SaveBatch": func() {
+			_, _ = logs.SaveBatch(ctx, []*proto.LogEntry{{UserName: "alice"}, {UserName: "bob", IdempotencyKey: "k2"}})
+		},
+		"LogRepository.Save (idempotency key)": func() {
+			_ = logs.Save(ctx, &proto.LogEntry{UserName: "alice", IdempotencyKey: "k1"})
+		},
+		"LogRepository.FindAll":  func() { _, _ = logs.FindAll(ctx, viewer) },
+		"LogRepository.FindByID": func() { _, _ = logs.FindByID(ctx, 1, viewer) },
+		"LogRepository.Search": func() {
+			_, _ = logs.Search(ctx, viewer, LogQuery{Text: "x", UserName: "bob", Since: since, Until: since, Limit: 10})
+		},
+		"LogRepository.Each": func() {
+			_ = logs.Each(ctx, viewer, LogQuery{Text: "x", UserName: "bob", Since: since, Until: since}, func(*proto.LogEntry) error { return nil })
+		},
+		"LogRepository.MoodStats": func() { _, _ = logs.MoodStats(ctx, viewer, since, since) },
+		"LogRepository.CountByDay": func() {
+			_, _ = logs.CountByDay(ctx, viewer, LogQuery{Text: "x", UserName: "bob", Since: since, Until: since})
+		},
+		"LogRepository.Delete":     func() { _ = logs.Delete(ctx, 1) },
+		"LogRepository.FindByUser": func() { _, _ = logs.FindByUser(ctx, "alice") },
+		"LogRepository.PseudonymizeUser": func() {
+			_, _ = logs.PseudonymizeUser(ctx, "alice", "former-member-1")
+		},
+		"LogRepository.DeleteByUser":   func() { _, _ = logs.DeleteByUser(ctx, "alice") },
+		"AuditRepository.Append":       func() { _ = audits.Append(ctx, &AuditEvent{Actor: "alice"}) },
+		"AuditRepository.List":         func() { _, _ = audits.List(ctx, AuditFilter{Actor: "alice", Since: since}) },
+		"AuditRepository.ListByUser":   func() { _, _ = audits.ListByUser(ctx, "alice") },
+		"AuditRepository.DeleteByUser": func() { _, _ = audits.DeleteByUser(ctx, "alice") },
+		"AuditRepository.PseudonymizeUser": func() {
+			_, _ = audits.PseudonymizeUser(ctx, "alice", "former-member-1")
+		},
+		"TeamRepository.Create":     func() { _ = teams.Create(ctx, &Team{Slug: "dev"}) },
+		"TeamRepository.FindBySlug": func() { _, _ = teams.FindBySlug(ctx, "dev") },
+		"TeamRepository.List":       func() { _, _ = teams.List(ctx) },
+		"TemplateRepository.Save": func() {
+			_ = templates.Save(ctx, &Template{Team: "dev", Name: "standup", Fields: []TemplateField{{Key: "today"}}})
+		},
+		"TemplateRepository.List":   func() { _, _ = templates.List(ctx, "dev") },
+		"TemplateRepository.Delete": func() { _ = templates.Delete(ctx, "dev", "standup") },
+		"UserRepository.CreateUser": func() { _ = users.CreateUser(&User{OrgID: orgID, Username: "carol"}) },
+		"UserRepository.DeleteUser": func() { _ = users.DeleteUser(orgID, 1) },
+	}
+
+	// 組織で絞り込まない操作と、その理由。メソッドを追加したらどちらかに必ず加える
+	unscoped := map[string]string{
+		"UserRepository.GetUserByUsername":        "ログイン時に組織を特定する検索。ユーザー名はインスタンス全体で一意",
+		"UserRepository.GetUserByOIDCSubject":     "OIDC ログイン時に組織を特定する検索。sub はインスタンス全体で一意",
+		"UserRepository.EnableTOTP":               "本人のユーザー ID で呼び出す",
+		"UserRepository.ConsumeRecoveryCode":      "本人のユーザー ID で呼び出す",
+		"UserRepository.UpdatePassword":           "本人のユーザー ID で呼び出す",
+		"UserRepository.UpdateEmail":              "本人のユーザー ID で呼び出す",
+		"UserRepository.CreatePasswordResetToken": "本人のユーザー ID で呼び出す",
+		"UserRepository.FindPasswordResetToken":   "推測できないトークンが対象ユーザーを特定する",
+		"UserRepository.ResetPassword":            "推測できないトークンが対象ユーザーを特定する",
+		"AuditRepository.DeleteBefore":            "保持期間による削除は全組織が対象",
+		"OrganizationRepository.Create":           "組織そのものを扱う。usecase でインスタンス管理者に限定する",
+		"OrganizationRepository.FindByID":         "組織そのものを扱う。usecase でインスタンス管理者に限定する",
+		"OrganizationRepository.FindBySlug":       "組織そのものを扱う。usecase でインスタンス管理者に限定する",
+		"OrganizationRepository.List":             "組織そのものを扱う。usecase でインスタンス管理者に限定する",
+	}
+
+	for _, method := range interfaceMethods(
+		(*LogRepository)(nil), (*AuditRepository)(nil), (*TeamRepository)(nil),
+		(*UserRepository)(nil), (*OrganizationRepository)(nil), (*TemplateRepository)(nil),
+	) {
+		_, isScoped := scoped[method]
+		_, isUnscoped := unscoped[method]
+		assert.True(t, isScoped || isUnscoped, "%s が組織の分離のテストに含まれていない", method)
+	}
+
+	for name, call := range scoped {
+		t.Run(name, func(t *testing.T) {
+			recorder.take()
+			call()
+			queries := recorder.take()
+			require.NotEmpty(t, queries, "SQL が発行されていない")
+			for _, q := range queries {
+				// トリガーの解除はトランザクション内の設定で、行には触れない
+				if strings.HasPrefix(q.query, "SET LOCAL ") {
+					continue
+				}
+				assert.Contains(t, q.query, "org_id", "組織で絞り込んでいない: %s", q.query)
+				assert.Contains(t, q.args, orgID, "context の組織が渡されていない: %s %v", q.query, q.args)
+			}
+		})
+	}
+}
+
+func TestInMemoryLogRepository_IsolatesOrgs(t *testing.T) {
+	repo := NewInMemoryLogRepository()
+	orgA := tenant.WithOrg(context.Background(), 1)
+	orgB := tenant.WithOrg(context.Background(), 2)
+	admin := Viewer{Username: "root", Role: RoleAdmin}
+
+	entry := &proto.LogEntry{UserName: "alice", Status: "secret project", Feeling: "😊", Timestamp: "2025-01-01T09:00:00Z"}
+	require.NoError(t, repo.Save(orgA, entry))
+
+	logs, err := repo.FindAll(orgB, admin)
+	assert.NoError(t, err)
+	assert.Empty(t, logs)
+
+	_, err = repo.FindByID(orgB, entry.Id, admin)
+	assert.ErrorIs(t, err, ErrLogNotFound)
+
+	logs, err = repo.Search(orgB, admin, LogQuery{Text: "secret"})
+	assert.NoError(t, err)
+	assert.Empty(t, logs)
+
+	stats, err := repo.MoodStats(orgB, admin, time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	assert.Empty(t, stats.Team)
+	assert.Empty(t, stats.ByUser)
+
+	days, err := repo.CountByDay(orgB, admin, LogQuery{})
+	assert.NoError(t, err)
+	assert.Empty(t, days)
+
+	assert.ErrorIs(t, repo.Delete(orgB, entry.Id), ErrLogNotFound)
+
+	logs, err = repo.FindAll(orgA, admin)
+	assert.NoError(t, err)
+	assert.Len(t, logs, 1, "他の組織からの削除は効かない")
+}
+
+func TestInMemoryAuditAndTeamRepositories_IsolateOrgs(t *testing.T) {
+	orgA := tenant.WithOrg(context.Background(), 1)
+	orgB := tenant.WithOrg(context.Background(), 2)
+
+	audits := NewInMemoryAuditRepository()
+	require.NoError(t, audits.Append(orgA, &AuditEvent{Actor: "alice", Action: "log.create"}))
+	require.NoError(t, audits.Append(orgB, &AuditEvent{Actor: "bob", Action: "log.create", OrgID: 1}))
+	events, err := audits.List(orgB, AuditFilter{})
+	assert.NoError(t, err)
+	assert.Empty(t, events, "OrgID を指定したイベントはその組織に記録する")
+	events, err = audits.List(orgA, AuditFilter{})
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+
+	teams := NewInMemoryTeamRepository()
+	require.NoError(t, teams.Create(orgA, &Team{Slug: "dev", Name: "Dev"}))
+	assert.NoError(t, teams.Create(orgB, &Team{Slug: "dev", Name: "Dev"}), "slug は組織ごとに一意")
+	assert.ErrorIs(t, teams.Create(orgA, &Team{Slug: "dev"}), ErrAlreadyExists)
+
+	list, err := teams.List(orgB)
+	assert.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, int64(2), list[0].OrgID)
+
+	_, err = teams.FindBySlug(tenant.WithOrg(context.Background(), 3), "dev")
+	assert.ErrorIs(t, err, ErrTeamNotFound)
+}
+
+func TestInMemoryTemplateRepository(t *testing.T) {
+	orgA := tenant.WithOrg(context.Background(), 1)
+	orgB := tenant.WithOrg(context.Background(), 2)
+	repo := NewInMemoryTemplateRepository()
+
+	standup := &Template{Team: "dev", Name: "standup", Fields: []TemplateField{{Key: "today", Label: "今日"}}}
+	require.NoError(t, repo.Save(orgA, standup))
+	replaced := &Template{Team: "dev", Name: "standup", Fields: []TemplateField{{Key: "yesterday"}, {Key: "today"}}}
+	require.NoError(t, repo.Save(orgA, replaced))
+	assert.Equal(t, standup.ID, replaced.ID, "同じチーム・名前は置き換える")
+
+	list, err := repo.List(orgA, "dev")
+	assert.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Len(t, list[0].Fields, 2)
+
+	list, err = repo.List(orgB, "dev")
+	assert.NoError(t, err)
+	assert.Empty(t, list)
+	assert.ErrorIs(t, repo.Delete(orgB, "dev", "standup"), ErrTemplateNotFound)
+
+	assert.NoError(t, repo.Delete(orgA, "dev", "standup"))
+	assert.ErrorIs(t, repo.Delete(orgA, "dev", "standup"), ErrTemplateNotFound)
+}
@@ -2,17 +2,52 @@ package repository
 
 import (
 	"database/sql"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/util"
+)
+
+const (
+	RoleMember  = "member"
+	RoleManager = "manager"
+	RoleAdmin   = "admin"
 )
 
 type User struct {
 	ID           int    `json:"id"`
+	OrgID        int64  `json:"org_id"`
 	Username     string `json:"username"`
 	PasswordHash string `json:"password_hash"`
+	Email        string `json:"email"`
+	Role         string `json:"role"`
+	TOTPSecret   string `json:"-"`
+	TOTPEnabled  bool   `json:"totp_enabled"`
+	// OIDCSubject は OIDC でログインするユーザーの IdP の sub。空ならパスワードのユーザー
+	OIDCSubject string `json:"-"`
+	// PasswordChangedAt は最後にパスワードを変更・リセットした日時（UTC）。一度も変更していなければゼロ値
+	PasswordChangedAt time.Time `json:"-"`
 }
 
+// UserRepository のユーザー名はインスタンス全体で一意で、GetUserByUsername がログイン時に組織を特定する。
+// 組織の管理操作は orgID で、本人の操作はユーザー ID で対象を絞る
 type UserRepository interface {
 	GetUserByUsername(username string) (*User, error)
-	CreateUser(username, passwordHash string) error
+	// GetUserByOIDCSubject は IdP の sub に結び付いたユーザーを返す。なければ sql.ErrNoRows
+	GetUserByOIDCSubject(subject string) (*User, error)
+	// CreateUser は user.OrgID の組織にユーザーを作成し、user.ID を設定する
+	CreateUser(user *User) error
+	EnableTOTP(userID int, secret string, recoveryCodeHashes []string) error
+	ConsumeRecoveryCode(userID int, codeHash string) (bool, error)
+	UpdatePassword(userID int, passwordHash string) error
+	UpdateEmail(userID int, email string) error
+	CreatePasswordResetToken(userID int, tokenHash string, expiresAt time.Time) error
+	// FindPasswordResetToken は有効なトークンの対象ユーザーを、トークンを使用済みにせずに返す。無効なら sql.ErrNoRows
+	FindPasswordResetToken(tokenHash string) (*User, error)
+	// ResetPassword はトークンの使用済みへの更新とパスワードの更新を 1 つのトランザクションで行う。
+	// トークンが無効（他のリクエストが先に使った場合を含む）なら sql.ErrNoRows
+	ResetPassword(tokenHash, passwordHash string) (*User, error)
+	// DeleteUser はリカバリーコードとリセット用トークンも含めて削除する。組織が違えば sql.ErrNoRows
+	DeleteUser(orgID int64, userID int) error
 }
 
 type postgresUserRepository struct {
@@ -24,24 +59,203 @@ func NewPostgresUserRepository(db *sql.DB) UserRepository {
 }
 
 func (r *postgresUserRepository) GetUserByUsername(username string) (*User, error) {
+	return r.getUser("username", username)
+}
+
+func (r *postgresUserRepository) GetUserByOIDCSubject(subject string) (*User, error) {
+	return r.getUser("oidc_subject", subject)
+}
+
+// getUser は column（username か oidc_subject）が value のユーザーを返す
+func (r *postgresUserRepository) getUser(column, value string) (*User, error) {
 	user := &User{}
-	query := "SELECT id, username, password_hash FROM users WHERE username = $1"
+	var email, totpSecret, oidcSubject sql.NullString
+	var passwordChangedAt sql.NullTime
+	query := "SELECT id, org_id, username, password_hash, email, role, totp_secret, totp_enabled, oidc_subject, password_changed_at FROM users WHERE " + column + " = $1"
 
-	err := r.db.QueryRow(query, username).Scan(
+	err := r.db.QueryRow(query, value).Scan(
 		&user.ID,
+		&user.OrgID,
 		&user.Username,
 		&user.PasswordHash,
+		&email,
+		&user.Role,
+		&totpSecret,
+		&user.TOTPEnabled,
+		&oidcSubject,
+		&passwordChangedAt,
 	)
 
 	if err != nil {
 		return nil, err
 	}
 
+	user.Email = email.String
+	user.TOTPSecret = totpSecret.String
+	user.OIDCSubject = oidcSubject.String
+	user.PasswordChangedAt = passwordChangedAt.Time
 	return user, nil
 }
 
-func (r *postgresUserRepository) CreateUser(username, passwordHash string) error {
-	query := "INSERT INTO users (username, password_hash) VALUES ($1, $2)"
-	_, err := r.db.Exec(query, username, passwordHash)
+func (r *postgresUserRepository) CreateUser(user *User) error {
+	if user.Role == "" {
+		user.Role = RoleMember
+	}
+	query := "INSERT INTO users (org_id, username, password_hash, email, role, oidc_subject) VALUES ($1, $2, $3, NULLIF($4, ''), $5, NULLIF($6, '')) RETURNING id"
+	err := r.db.QueryRow(query, user.OrgID, user.Username, user.PasswordHash, user.Email, user.Role, user.OIDCSubject).Scan(&user.ID)
+	if isUniqueViolation(err) {
+		return ErrAlreadyExists
+	}
+	return err
+}
+
+// EnableTOTP は共有鍵を保存し、リカバリーコードを入れ替える
+func (r *postgresUserRepository) EnableTOTP(userID int, secret string, recoveryCodeHashes []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback() // Commit 後は ErrTxDone になるだけ
+	}()
+
+	if _, err := tx.Exec("UPDATE users SET totp_secret = $1, totp_enabled = TRUE WHERE id = $2", secret, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM user_recovery_codes WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO user_recovery_codes (user_id, code_hash) VALUES ($1, $2)")
+	if err != nil {
+		return err
+	}
+	defer util.CloseWithLog(stmt)
+
+	for _, hash := range recoveryCodeHashes {
+		if _, err := stmt.Exec(userID, hash); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ConsumeRecoveryCode は未使用のリカバリーコードを使用済みにし、成功したかを返す
+func (r *postgresUserRepository) ConsumeRecoveryCode(userID int, codeHash string) (bool, error) {
+	result, err := r.db.Exec(`
+        UPDATE user_recovery_codes SET used_at = CURRENT_TIMESTAMP
+        WHERE id = (
+            SELECT id FROM user_recovery_codes
+            WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+            LIMIT 1
+        )
+        `, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+func (r *postgresUserRepository) UpdatePassword(userID int, passwordHash string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback() // Commit 後は ErrTxDone になるだけ
+	}()
+
+	if err := updatePasswordTx(tx, userID, passwordHash); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func updatePasswordTx(tx *sql.Tx, userID int, passwordHash string) error {
+	// password_changed_at は TIMESTAMP 型なので、発行済みトークンの iat と比べられるよう UTC で保存する
+	if _, err := tx.Exec("UPDATE users SET password_hash = $1, password_changed_at = (CURRENT_TIMESTAMP AT TIME ZONE 'UTC') WHERE id = $2", passwordHash, userID); err != nil {
+		return err
+	}
+	// 変更前に発行されたリセット用リンクは使えなくする
+	_, err := tx.Exec("UPDATE password_reset_tokens SET used_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND used_at IS NULL", userID)
+	return err
+}
+
+func (r *postgresUserRepository) UpdateEmail(userID int, email string) error {
+	_, err := r.db.Exec("UPDATE users SET email = NULLIF($1, '') WHERE id = $2", email, userID)
+	return err
+}
+
+// CreatePasswordResetToken は expires_at をタイムゾーンなしの UTC で保存する。
+// 有効期限の比較も (CURRENT_TIMESTAMP AT TIME ZONE 'UTC') と行う
+func (r *postgresUserRepository) CreatePasswordResetToken(userID int, tokenHash string, expiresAt time.Time) error {
+	_, err := r.db.Exec(`
+        INSERT INTO password_reset_tokens (token_hash, user_id, expires_at) VALUES ($1, $2, $3)
+        `, tokenHash, userID, expiresAt.UTC())
 	return err
 }
+
+func (r *postgresUserRepository) FindPasswordResetToken(tokenHash string) (*User, error) {
+	user := &User{}
+	err := r.db.QueryRow(`
+        SELECT u.id, u.org_id, u.username FROM password_reset_tokens t
+        JOIN users u ON u.id = t.user_id
+        WHERE t.token_hash = $1 AND t.used_at IS NULL AND t.expires_at > (CURRENT_TIMESTAMP AT TIME ZONE 'UTC')
+        `, tokenHash).Scan(&user.ID, &user.OrgID, &user.Username)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *postgresUserRepository) ResetPassword(tokenHash, passwordHash string) (*User, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback() // Commit 後は ErrTxDone になるだけ
+	}()
+
+	user := &User{}
+	err = tx.QueryRow(`
+        UPDATE password_reset_tokens t SET used_at = CURRENT_TIMESTAMP
+        FROM users u
+        WHERE t.user_id = u.id AND t.token_hash = $1 AND t.used_at IS NULL AND t.expires_at > (CURRENT_TIMESTAMP AT TIME ZONE 'UTC')
+        RETURNING u.id, u.org_id, u.username
+        `, tokenHash).Scan(&user.ID, &user.OrgID, &user.Username)
+	if err != nil {
+		return nil, err
+	}
+	if err := updatePasswordTx(tx, user.ID, passwordHash); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *postgresUserRepository) DeleteUser(orgID int64, userID int) error {
+	return r.execOne("DELETE FROM users WHERE id = $1 AND org_id = $2", userID, orgID)
+}
+
+// execOne は 1 行も更新しなければ sql.ErrNoRows を返す
+func (r *postgresUserRepository) execOne(query string, args ...any) error {
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// expires_at はタイムゾーンなしの UTC なので、比較する現在時刻も UTC に揃える
+const resetTokenNotExpired = "t.expires_at > (CURRENT_TIMESTAMP AT TIME ZONE 'UTC')"
+
+func TestPostgresUserRepository_PasswordResetTokenExpiryIsUTC(t *testing.T) {
+	recorder := &queryRecorder{}
+	db := sql.OpenDB(recorder)
+	t.Cleanup(func() { _ = db.Close() })
+	users := NewPostgresUserRepository(db)
+
+	jst := time.FixedZone("JST", 9*60*60)
+	require.NoError(t, users.CreatePasswordResetToken(1, "hash", time.Date(2025, 1, 1, 10, 0, 0, 0, jst)))
+	queries := recorder.take()
+	require.Len(t, queries, 1)
+	assert.Equal(t, time.Date(2025, 1, 1, 1, 0, 0, 0, time.UTC), queries[0].args[2])
+
+	_, err := users.FindPasswordResetToken("hash")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	queries = recorder.take()
+	require.Len(t, queries, 1)
+	assert.Contains(t, queries[0].query, resetTokenNotExpired)
+
+	_, err = users.ResetPassword("hash", "new-hash")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	queries = recorder.take()
+	require.NotEmpty(t, queries)
+	assert.Contains(t, queries[0].query, resetTokenNotExpired)
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gensan0223/snulog/proto"
+)
+
+// logs.visibility に保存する値
+const (
+	visibilityTeam     = "team"
+	visibilityManagers = "managers"
+	visibilityPrivate  = "private"
+)
+
+// Viewer はログを読むユーザー。匿名の呼び出しでは Username が空になり、team のログだけが見える
+type Viewer struct {
+	Username string
+	Role     string
+}
+
+// canReadManagersOnly は managers 公開のログを読めるロールか
+func (v Viewer) canReadManagersOnly() bool {
+	return v.Role == RoleManager || v.Role == RoleAdmin
+}
+
+// CanSee は公開範囲に基づいてログを読めるかを返す。Postgres 側の visibleClause と同じ規則
+func (v Viewer) CanSee(entry *proto.LogEntry) bool {
+	if v.Username != "" && entry.UserName == v.Username {
+		return true
+	}
+	switch NormalizeVisibility(entry.Visibility) {
+	case proto.Visibility_VISIBILITY_TEAM:
+		return true
+	case proto.Visibility_VISIBILITY_MANAGERS:
+		return v.canReadManagersOnly()
+	default:
+		return false
+	}
+}
+
+// visibleClause は viewer が読めるログだけに絞る WHERE 句を返す。プレースホルダは $n から始める
+func (v Viewer) visibleClause(n int) (string, []any) {
+	clause := fmt.Sprintf("(visibility = 'team' OR (user_name = $%d AND $%d <> '')", n, n)
+	if v.canReadManagersOnly() {
+		clause += " OR visibility = 'managers'"
+	}
+	return clause + ")", []any{v.Username}
+}
+
+// NormalizeVisibility は未指定を team として扱う
+func NormalizeVisibility(v proto.Visibility) proto.Visibility {
+	if v == proto.Visibility_VISIBILITY_UNSPECIFIED {
+		return proto.Visibility_VISIBILITY_TEAM
+	}
+	return v
+}
+
+func visibilityToDB(v proto.Visibility) string {
+	switch NormalizeVisibility(v) {
+	case proto.Visibility_VISIBILITY_MANAGERS:
+		return visibilityManagers
+	case proto.Visibility_VISIBILITY_PRIVATE:
+		return visibilityPrivate
+	default:
+		return visibilityTeam
+	}
+}
+
+func visibilityFromDB(s string) proto.Visibility {
+	switch s {
+	case visibilityManagers:
+		return proto.Visibility_VISIBILITY_MANAGERS
+	case visibilityPrivate:
+		return proto.Visibility_VISIBILITY_PRIVATE
+	default:
+		return proto.Visibility_VISIBILITY_TEAM
+	}
+}
+
+// LogQuery は検索条件。空のフィールドは条件に含めない
+type LogQuery struct {
+	// Text は status と feeling の部分一致（大文字小文字を区別しない）
+	Text     string
+	UserName string
+	Since    time.Time
+	Until    time.Time
+	Limit    int
+	// Notes が true ならメモ（LogEntry.Note）だけを、false ならメモ以外のログを対象にする
+	Notes bool
+}
+
+func (q LogQuery) matches(entry *proto.LogEntry) bool {
+	if entry.Note != q.Notes {
+		return false
+	}
+	if q.UserName != "" && entry.UserName != q.UserName {
+		return false
+	}
+	if q.Text != "" {
+		text := strings.ToLower(q.Text)
+		if !strings.Contains(strings.ToLower(entry.Status), text) && !strings.Contains(strings.ToLower(entry.Feeling), text) {
+			return false
+		}
+	}
+	return inRange(entry, q.Since, q.Until)
+}
+
+// inRange は since <= timestamp < until を判定する。解釈できない timestamp は範囲外とする
+func inRange(entry *proto.LogEntry, since, until time.Time) bool {
+	if since.IsZero() && until.IsZero() {
+		return true
+	}
+	ts, err := time.Parse(time.RFC3339, entry.Timestamp)
+	if err != nil {
+		return false
+	}
+	if !since.IsZero() && ts.Before(since) {
+		return false
+	}
+	if !until.IsZero() && !ts.Before(until) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,22 @@
+// Package tenant は呼び出し元の組織（テナント）を context で受け渡す
+package tenant
+
+import "context"
+
+// DefaultOrgID は既存のデータと匿名の呼び出しが属する組織。
+// この組織の管理者はインスタンス全体の管理者として組織を作成できる
+const DefaultOrgID int64 = 1
+
+type orgKey struct{}
+
+func WithOrg(ctx context.Context, orgID int64) context.Context {
+	return context.WithValue(ctx, orgKey{}, orgID)
+}
+
+// OrgID は context の組織を返す。設定されていなければ DefaultOrgID
+func OrgID(ctx context.Context) int64 {
+	if orgID, ok := ctx.Value(orgKey{}).(int64); ok && orgID != 0 {
+		return orgID
+	}
+	return DefaultOrgID
+}
@@ -0,0 +1,113 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// ServerConfig は gRPC サーバーの TLS 設定。CertFile が空なら平文で待ち受ける
+type ServerConfig struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+	// ClientCAFile を指定した場合、false ならクライアント証明書を必須にし、true なら提示された場合だけ検証する
+	ClientCertOptional bool
+}
+
+// ServerConfigFromEnv は SNULOG_TLS_* 環境変数から設定を読む
+func ServerConfigFromEnv() ServerConfig {
+	return ServerConfig{
+		CertFile:           os.Getenv("SNULOG_TLS_CERT"),
+		KeyFile:            os.Getenv("SNULOG_TLS_KEY"),
+		ClientCAFile:       os.Getenv("SNULOG_TLS_CLIENT_CA"),
+		ClientCertOptional: strings.EqualFold(os.Getenv("SNULOG_TLS_CLIENT_AUTH"), "optional"),
+	}
+}
+
+func (c ServerConfig) Enabled() bool {
+	return c.CertFile != ""
+}
+
+// NewServerTLS はリローダーの最新の証明書とクライアント CA を使う tls.Config を返す
+func NewServerTLS(c ServerConfig, reloader *CertReloader) *tls.Config {
+	config := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+		NextProtos:     []string{"h2"},
+	}
+	if c.ClientCAFile == "" {
+		return config
+	}
+
+	clientAuth := tls.RequireAndVerifyClientCert
+	if c.ClientCertOptional {
+		clientAuth = tls.VerifyClientCertIfGiven
+	}
+	// ClientCAs はハンドシェイクごとに差し替えるため、接続ごとに設定を複製する
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		perConn := config.Clone()
+		perConn.GetConfigForClient = nil
+		perConn.ClientAuth = clientAuth
+		perConn.ClientCAs = reloader.clientCAs()
+		return perConn, nil
+	}
+	return config
+}
+
+// ClientConfig は gRPC クライアントの TLS 設定。何も指定しなければ平文で接続する
+type ClientConfig struct {
+	// Enabled は CA を指定せずにシステムの信頼ストアで TLS 接続する場合に使う
+	Enabled    bool
+	CAFile     string
+	CertFile   string
+	KeyFile    string
+	ServerName string
+}
+
+func (c ClientConfig) enabled() bool {
+	return c.Enabled || c.CAFile != "" || c.CertFile != ""
+}
+
+// TransportCredentials は grpc.WithTransportCredentials に渡す認証情報を返す
+func (c ClientConfig) TransportCredentials() (credentials.TransportCredentials, error) {
+	config, err := c.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return insecure.NewCredentials(), nil
+	}
+	return credentials.NewTLS(config), nil
+}
+
+// TLSConfig はクライアントの TLS 設定を返す。平文で接続する設定なら nil
+func (c ClientConfig) TLSConfig() (*tls.Config, error) {
+	if !c.enabled() {
+		return nil, nil
+	}
+
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: c.ServerName,
+	}
+	if c.CAFile != "" {
+		pool, err := LoadCertPool(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = pool
+	}
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
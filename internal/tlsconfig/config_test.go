@@ -0,0 +1,143 @@
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gensan0223/snulog/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+type fetchServer struct {
+	proto.UnimplementedLogServiceServer
+}
+
+func (fetchServer) FetchLogs(ctx context.Context, req *proto.FetchRequest) (*proto.FetchResponse, error) {
+	return &proto.FetchResponse{}, nil
+}
+
+func startServer(t *testing.T, c ServerConfig) (*CertReloader, string) {
+	t.Helper()
+	reloader, err := NewCertReloader(c.CertFile, c.KeyFile, c.ClientCAFile)
+	require.NoError(t, err)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer(grpc.Creds(credentials.NewTLS(NewServerTLS(c, reloader))))
+	proto.RegisterLogServiceServer(server, fetchServer{})
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	return reloader, lis.Addr().String()
+}
+
+func fetch(t *testing.T, addr string, c ClientConfig) error {
+	t.Helper()
+	creds, err := c.TransportCredentials()
+	require.NoError(t, err)
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = proto.NewLogServiceClient(conn).FetchLogs(ctx, &proto.FetchRequest{TeamId: "default"})
+	return err
+}
+
+func TestMutualTLS(t *testing.T) {
+	files, err := GenerateDevCertificates(t.TempDir(), nil, "alice")
+	require.NoError(t, err)
+
+	_, addr := startServer(t, ServerConfig{CertFile: files.ServerCert, KeyFile: files.ServerKey, ClientCAFile: files.CACert})
+
+	t.Run("client certificate accepted", func(t *testing.T) {
+		assert.NoError(t, fetch(t, addr, ClientConfig{CAFile: files.CACert, CertFile: files.ClientCert, KeyFile: files.ClientKey}))
+	})
+
+	t.Run("missing client certificate rejected", func(t *testing.T) {
+		assert.Error(t, fetch(t, addr, ClientConfig{CAFile: files.CACert}))
+	})
+
+	t.Run("certificate from another CA rejected", func(t *testing.T) {
+		other, err := GenerateDevCertificates(t.TempDir(), nil, "mallory")
+		require.NoError(t, err)
+		assert.Error(t, fetch(t, addr, ClientConfig{CAFile: files.CACert, CertFile: other.ClientCert, KeyFile: other.ClientKey}))
+	})
+
+	t.Run("unknown server CA rejected", func(t *testing.T) {
+		assert.Error(t, fetch(t, addr, ClientConfig{Enabled: true}), "システムの信頼ストアでは開発用 CA を検証できない")
+	})
+}
+
+func TestCertReloader_Reload(t *testing.T) {
+	dir := t.TempDir()
+	first, err := GenerateDevCertificates(dir, nil, "alice")
+	require.NoError(t, err)
+
+	reloader, addr := startServer(t, ServerConfig{CertFile: first.ServerCert, KeyFile: first.ServerKey})
+	require.NoError(t, fetch(t, addr, ClientConfig{CAFile: first.CACert}))
+
+	// 同じパスに新しい CA で証明書を作り直す
+	caPEM, err := os.ReadFile(first.CACert)
+	require.NoError(t, err)
+	second, err := GenerateDevCertificates(dir, nil, "alice")
+	require.NoError(t, err)
+	oldCA := t.TempDir() + "/old-ca.pem"
+	require.NoError(t, os.WriteFile(oldCA, caPEM, 0o644))
+
+	require.NoError(t, reloader.Reload())
+
+	assert.NoError(t, fetch(t, addr, ClientConfig{CAFile: second.CACert}))
+	assert.Error(t, fetch(t, addr, ClientConfig{CAFile: oldCA}), "古い CA では新しい証明書を検証できない")
+}
+
+func TestCertReloader_Watch(t *testing.T) {
+	dir := t.TempDir()
+	files, err := GenerateDevCertificates(dir, nil, "alice")
+	require.NoError(t, err)
+
+	reloader, err := NewCertReloader(files.ServerCert, files.ServerKey, "")
+	require.NoError(t, err)
+	require.NoError(t, reloader.Watch())
+	defer func() { _ = reloader.Close() }()
+
+	before := serial(t, reloader)
+	_, err = GenerateDevCertificates(dir, nil, "alice")
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return serial(t, reloader) != before
+	}, 5*time.Second, 50*time.Millisecond, "ファイルの変更後に証明書が読み直される")
+}
+
+func TestCertReloader_KeepsPreviousOnError(t *testing.T) {
+	dir := t.TempDir()
+	files, err := GenerateDevCertificates(dir, nil, "alice")
+	require.NoError(t, err)
+
+	reloader, err := NewCertReloader(files.ServerCert, files.ServerKey, "")
+	require.NoError(t, err)
+	before := serial(t, reloader)
+
+	require.NoError(t, os.WriteFile(files.ServerCert, []byte("broken"), 0o644))
+	assert.Error(t, reloader.Reload())
+	assert.Equal(t, before, serial(t, reloader))
+}
+
+func serial(t *testing.T, r *CertReloader) string {
+	t.Helper()
+	cert, err := r.GetCertificate(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	parsed, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	return parsed.SerialNumber.String()
+}
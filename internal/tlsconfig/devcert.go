@@ -0,0 +1,149 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// 開発用証明書の有効期限
+const (
+	devCAValidity   = 5 * 365 * 24 * time.Hour
+	devLeafValidity = 365 * 24 * time.Hour
+)
+
+// DevFiles は GenerateDevCertificates が書き出したファイルのパス
+type DevFiles struct {
+	CACert     string
+	CAKey      string
+	ServerCert string
+	ServerKey  string
+	ClientCert string
+	ClientKey  string
+}
+
+type keyPair struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+// GenerateDevCertificates はローカル開発用の CA と、それで署名したサーバー・クライアント証明書を dir に書き出す。
+// hosts にはサーバー証明書の SAN（ホスト名または IP）を指定する
+func GenerateDevCertificates(dir string, hosts []string, clientName string) (*DevFiles, error) {
+	if len(hosts) == 0 {
+		hosts = []string{"localhost", "127.0.0.1"}
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	ca, err := newKeyPair(&x509.Certificate{
+		Subject:               pkix.Name{CommonName: "snulog dev CA", Organization: []string{"snulog"}},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}, devCAValidity, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	serverTemplate := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: hosts[0], Organization: []string{"snulog"}},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			serverTemplate.IPAddresses = append(serverTemplate.IPAddresses, ip)
+		} else {
+			serverTemplate.DNSNames = append(serverTemplate.DNSNames, host)
+		}
+	}
+	server, err := newKeyPair(serverTemplate, devLeafValidity, ca)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := newKeyPair(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: clientName, Organization: []string{"snulog"}},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, devLeafValidity, ca)
+	if err != nil {
+		return nil, err
+	}
+
+	files := &DevFiles{
+		CACert:     filepath.Join(dir, "ca.pem"),
+		CAKey:      filepath.Join(dir, "ca-key.pem"),
+		ServerCert: filepath.Join(dir, "server.pem"),
+		ServerKey:  filepath.Join(dir, "server-key.pem"),
+		ClientCert: filepath.Join(dir, "client.pem"),
+		ClientKey:  filepath.Join(dir, "client-key.pem"),
+	}
+	for _, out := range []struct {
+		pair      *keyPair
+		cert, key string
+	}{
+		{ca, files.CACert, files.CAKey},
+		{server, files.ServerCert, files.ServerKey},
+		{client, files.ClientCert, files.ClientKey},
+	} {
+		if err := writeKeyPair(out.pair, out.cert, out.key); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// newKeyPair は鍵を生成して証明書に署名する。parent が nil なら自己署名
+func newKeyPair(template *x509.Certificate, validity time.Duration, parent *keyPair) (*keyPair, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	template.SerialNumber = serial
+	template.NotBefore = now.Add(-time.Hour)
+	template.NotAfter = now.Add(validity)
+
+	signerCert, signerKey := template, key
+	if parent != nil {
+		signerCert, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signerCert, &key.PublicKey, signerKey)
+	if err != nil {
+		return nil, fmt.Errorf("create certificate %q: %w", template.Subject.CommonName, err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &keyPair{cert: cert, key: key, der: der}, nil
+}
+
+func writeKeyPair(pair *keyPair, certPath, keyPath string) error {
+	keyDER, err := x509.MarshalECPrivateKey(pair.key)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: pair.der}), 0o644); err != nil {
+		return err
+	}
+	return os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+}
@@ -0,0 +1,159 @@
+// Package tlsconfig は gRPC サーバーとクライアントの TLS / mTLS 設定を組み立てる
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// 証明書の書き換えは複数ファイルにまたがるため、イベントが落ち着くまで待ってから読み直す
+const reloadDebounce = 500 * time.Millisecond
+
+// CertReloader は証明書・秘密鍵・クライアント CA をファイルから読み込み、変更されたら差し替える
+type CertReloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+
+	mutex    sync.RWMutex
+	cert     *tls.Certificate
+	clientCA *x509.CertPool
+
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+}
+
+// NewCertReloader は初回の読み込みを行う。clientCAFile は空でもよい
+func NewCertReloader(certFile, keyFile, clientCAFile string) (*CertReloader, error) {
+	r := &CertReloader{
+		certFile:     certFile,
+		keyFile:      keyFile,
+		clientCAFile: clientCAFile,
+		done:         make(chan struct{}),
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload はファイルを読み直す。失敗した場合は以前の証明書を使い続ける
+func (r *CertReloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("load server certificate: %w", err)
+	}
+
+	var pool *x509.CertPool
+	if r.clientCAFile != "" {
+		if pool, err = LoadCertPool(r.clientCAFile); err != nil {
+			return err
+		}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.cert = &cert
+	r.clientCA = pool
+	return nil
+}
+
+// Watch は証明書のディレクトリを監視し、変更があれば読み直す。Close で停止する
+func (r *CertReloader) Watch() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	// cert-manager などはシンボリックリンクの差し替えで更新するため、ファイルではなくディレクトリを監視する
+	dirs := map[string]bool{}
+	for _, file := range []string{r.certFile, r.keyFile, r.clientCAFile} {
+		if file == "" {
+			continue
+		}
+		dir := filepath.Dir(file)
+		if dirs[dir] {
+			continue
+		}
+		dirs[dir] = true
+		if err := watcher.Add(dir); err != nil {
+			_ = watcher.Close()
+			return err
+		}
+	}
+	r.watcher = watcher
+
+	go r.watch()
+	return nil
+}
+
+func (r *CertReloader) watch() {
+	var timer <-chan time.Time
+	for {
+		select {
+		case <-r.done:
+			return
+		case event, ok := <-r.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Has(fsnotify.Write) || event.Has(fsnotify.Create) || event.Has(fsnotify.Rename) {
+				timer = time.After(reloadDebounce)
+			}
+		case err, ok := <-r.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("tls: watch error: %v", err)
+		case <-timer:
+			timer = nil
+			if err := r.Reload(); err != nil {
+				log.Printf("tls: reload failed, keeping the previous certificate: %v", err)
+				continue
+			}
+			log.Printf("tls: reloaded certificate from %s", r.certFile)
+		}
+	}
+}
+
+func (r *CertReloader) Close() error {
+	if r.watcher == nil {
+		return nil
+	}
+	close(r.done)
+	return r.watcher.Close()
+}
+
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.cert, nil
+}
+
+func (r *CertReloader) clientCAs() *x509.CertPool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.clientCA
+}
+
+// LoadCertPool は PEM 形式の CA バンドルを読み込む
+func LoadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read CA bundle: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("tls: no certificates found in " + path)
+	}
+	return pool, nil
+}
@@ -0,0 +1,426 @@
+// Package tui は snulog tui のチームボード。WatchLogs でログの追加と削除を受け取り、
+// サーバーが対応していなければ FetchLogs の定期取得に切り替える
+package tui
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	pb "github.com/gensan0223/snulog/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Client は TUI が使う RPC。pb.LogServiceClient が満たす
+type Client interface {
+	FetchLogs(ctx context.Context, in *pb.FetchRequest, opts ...grpc.CallOption) (*pb.FetchResponse, error)
+	AddLogs(ctx context.Context, in *pb.LogEntry, opts ...grpc.CallOption) (*pb.AddResponse, error)
+	WatchLogs(ctx context.Context, in *pb.WatchLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[pb.LogEvent], error)
+}
+
+type Options struct {
+	// User は未ログインでログを追加するときの名前。ログイン中はサーバーがトークンの名前に置き換える
+	User         string
+	TimeZone     *time.Location
+	Timeout      time.Duration
+	PollInterval time.Duration
+}
+
+// Moods は追加フォームで選べる気分
+var Moods = []string{"😊", "🙂", "😐", "😫", "😡", "🤒", "🔥", "🆒"}
+
+type mode int
+
+const (
+	modeBoard mode = iota
+	modeAdd
+	modeFilter
+)
+
+// 絞り込み欄の並び
+const (
+	filterUser = iota
+	filterDate
+)
+
+type Model struct {
+	ctx    context.Context
+	client Client
+	opts   Options
+
+	logs []*pb.LogEntry
+	err  string
+
+	// live は WatchLogs を受信中か。watchable が false ならサーバーが WatchLogs に対応していない
+	live        bool
+	watchable   bool
+	cancelWatch context.CancelFunc
+
+	mode    mode
+	status  textinput.Model
+	mood    int
+	filters []textinput.Model
+	user    string
+	since   time.Time
+
+	history       viewport.Model
+	width, height int
+}
+
+type (
+	logsMsg struct {
+		logs []*pb.LogEntry
+		err  error
+	}
+	watchStartedMsg struct {
+		stream grpc.ServerStreamingClient[pb.LogEvent]
+		cancel context.CancelFunc
+	}
+	logEventMsg struct {
+		stream grpc.ServerStreamingClient[pb.LogEvent]
+		event  *pb.LogEvent
+	}
+	watchEndedMsg struct{ err error }
+	pollMsg       struct{}
+	addedMsg      struct{ err error }
+)
+
+func New(ctx context.Context, client Client, opts Options) *Model {
+	if opts.TimeZone == nil {
+		opts.TimeZone = time.Local
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 30 * time.Second
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+
+	status := textinput.New()
+	status.Placeholder = "いまの状況"
+	status.CharLimit = 200
+
+	filters := make([]textinput.Model, 2)
+	for i, placeholder := range []string{"ユーザー（空ならすべて）", "日付 2006-01-02（この日以降）"} {
+		filters[i] = textinput.New()
+		filters[i].Placeholder = placeholder
+	}
+
+	return &Model{
+		ctx:       ctx,
+		client:    client,
+		opts:      opts,
+		watchable: true,
+		status:    status,
+		filters:   filters,
+		history:   viewport.New(80, 10),
+	}
+}
+
+func (m *Model) Init() tea.Cmd {
+	return tea.Batch(m.fetch(), m.watch())
+}
+
+func (m *Model) fetch() tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(m.ctx, m.opts.Timeout)
+		defer cancel()
+		res, err := m.client.FetchLogs(ctx, &pb.FetchRequest{})
+		if err != nil {
+			return logsMsg{err: err}
+		}
+		return logsMsg{logs: res.Logs}
+	}
+}
+
+func (m *Model) watch() tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithCancel(m.ctx)
+		stream, err := m.client.WatchLogs(ctx, &pb.WatchLogsRequest{})
+		if err != nil {
+			cancel()
+			return watchEndedMsg{err: err}
+		}
+		return watchStartedMsg{stream: stream, cancel: cancel}
+	}
+}
+
+func receive(stream grpc.ServerStreamingClient[pb.LogEvent]) tea.Cmd {
+	return func() tea.Msg {
+		event, err := stream.Recv()
+		if err != nil {
+			return watchEndedMsg{err: err}
+		}
+		return logEventMsg{stream: stream, event: event}
+	}
+}
+
+func (m *Model) poll() tea.Cmd {
+	return tea.Tick(m.opts.PollInterval, func(time.Time) tea.Msg { return pollMsg{} })
+}
+
+func (m *Model) add(status, feeling string) tea.Cmd {
+	entry := &pb.LogEntry{
+		UserName:  m.opts.User,
+		Status:    status,
+		Feeling:   feeling,
+		Timestamp: time.Now().In(m.opts.TimeZone).Format(time.RFC3339),
+	}
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(m.ctx, m.opts.Timeout)
+		defer cancel()
+		_, err := m.client.AddLogs(ctx, entry)
+		return addedMsg{err: err}
+	}
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.resize()
+		return m, nil
+
+	case logsMsg:
+		if msg.err != nil {
+			m.err = "取得に失敗: " + status.Convert(msg.err).Message()
+			return m, nil
+		}
+		m.err = ""
+		m.logs = msg.logs
+		m.refreshHistory()
+		return m, nil
+
+	case watchStartedMsg:
+		m.live = true
+		m.cancelWatch = msg.cancel
+		// 切断していた間の変更を取り直す
+		return m, tea.Batch(m.fetch(), receive(msg.stream))
+
+	case logEventMsg:
+		m.apply(msg.event)
+		m.refreshHistory()
+		return m, receive(msg.stream)
+
+	case watchEndedMsg:
+		wasLive := m.live
+		m.live = false
+		if m.cancelWatch != nil {
+			m.cancelWatch()
+			m.cancelWatch = nil
+		}
+		if status.Code(msg.err) == codes.Unimplemented {
+			m.watchable = false
+		}
+		if m.ctx.Err() != nil {
+			return m, nil
+		}
+		if wasLive {
+			// 受信中に切れたらすぐ取り直し、以後は再接続できるまで定期取得する
+			return m, tea.Batch(m.fetch(), m.poll())
+		}
+		return m, m.poll()
+
+	case pollMsg:
+		cmds := []tea.Cmd{m.fetch()}
+		if m.watchable {
+			cmds = append(cmds, m.watch())
+		} else {
+			cmds = append(cmds, m.poll())
+		}
+		return m, tea.Batch(cmds...)
+
+	case addedMsg:
+		if msg.err != nil {
+			m.err = "追加に失敗: " + status.Convert(msg.err).Message()
+			return m, nil
+		}
+		m.err = ""
+		if !m.live {
+			return m, m.fetch()
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "ctrl+c" {
+		return m, tea.Quit
+	}
+
+	switch m.mode {
+	case modeAdd:
+		switch msg.String() {
+		case "esc":
+			m.closeForm()
+			return m, nil
+		case "tab", "right":
+			if msg.String() == "tab" || m.status.Value() == "" {
+				m.mood = (m.mood + 1) % len(Moods)
+				return m, nil
+			}
+		case "shift+tab", "left":
+			if msg.String() == "shift+tab" || m.status.Value() == "" {
+				m.mood = (m.mood + len(Moods) - 1) % len(Moods)
+				return m, nil
+			}
+		case "enter":
+			text := strings.TrimSpace(m.status.Value())
+			if text == "" {
+				return m, nil
+			}
+			m.closeForm()
+			return m, m.add(text, Moods[m.mood])
+		}
+		var cmd tea.Cmd
+		m.status, cmd = m.status.Update(msg)
+		return m, cmd
+
+	case modeFilter:
+		focused := m.focusedFilter()
+		switch msg.String() {
+		case "esc":
+			m.closeForm()
+			return m, nil
+		case "tab", "down":
+			m.focusFilter((focused + 1) % len(m.filters))
+			return m, nil
+		case "shift+tab", "up":
+			m.focusFilter((focused + len(m.filters) - 1) % len(m.filters))
+			return m, nil
+		case "enter":
+			m.applyFilters()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.filters[focused], cmd = m.filters[focused].Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "q":
+		return m, tea.Quit
+	case "a":
+		m.mode = modeAdd
+		m.status.SetValue("")
+		return m, m.status.Focus()
+	case "f", "/":
+		m.mode = modeFilter
+		m.focusFilter(filterUser)
+		return m, nil
+	case "r":
+		return m, m.fetch()
+	}
+	var cmd tea.Cmd
+	m.history, cmd = m.history.Update(msg)
+	return m, cmd
+}
+
+func (m *Model) closeForm() {
+	m.mode = modeBoard
+	m.status.Blur()
+	for i := range m.filters {
+		m.filters[i].Blur()
+	}
+}
+
+func (m *Model) focusedFilter() int {
+	for i, f := range m.filters {
+		if f.Focused() {
+			return i
+		}
+	}
+	return filterUser
+}
+
+func (m *Model) focusFilter(i int) {
+	for j := range m.filters {
+		m.filters[j].Blur()
+	}
+	m.filters[i].Focus()
+}
+
+// applyFilters は絞り込み欄の値を反映する。絞り込みは取得済みのログに対して行う
+func (m *Model) applyFilters() {
+	date := strings.TrimSpace(m.filters[filterDate].Value())
+	since := time.Time{}
+	if date != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", date, m.opts.TimeZone)
+		if err != nil {
+			m.err = "日付は 2006-01-02 の形式で入力してください"
+			return
+		}
+		since = parsed
+	}
+
+	m.err = ""
+	m.since = since
+	m.user = strings.TrimSpace(m.filters[filterUser].Value())
+	m.closeForm()
+	m.refreshHistory()
+}
+
+// apply は WatchLogs のイベントを手元のログに反映する
+func (m *Model) apply(event *pb.LogEvent) {
+	if event.Entry == nil {
+		return
+	}
+	for i, entry := range m.logs {
+		if entry.Id == event.Entry.Id {
+			m.logs = append(m.logs[:i], m.logs[i+1:]...)
+			break
+		}
+	}
+	if event.Type == pb.LogEventType_LOG_EVENT_TYPE_ADDED {
+		m.logs = append(m.logs, event.Entry)
+	}
+}
+
+// visible は絞り込み後のログを新しい順に返す
+func (m *Model) visible() []*pb.LogEntry {
+	var logs []*pb.LogEntry
+	for _, entry := range m.logs {
+		if m.user != "" && entry.UserName != m.user {
+			continue
+		}
+		if !m.since.IsZero() {
+			if timestamp(entry).Before(m.since) {
+				continue
+			}
+		}
+		logs = append(logs, entry)
+	}
+	sort.SliceStable(logs, func(i, j int) bool { return timestamp(logs[i]).After(timestamp(logs[j])) })
+	return logs
+}
+
+// timestamp は entry の時刻。読めなければゼロ値にして最も古い扱いにする
+func timestamp(entry *pb.LogEntry) time.Time {
+	t, _ := time.Parse(time.RFC3339, entry.Timestamp)
+	return t
+}
+
+// latestByUser はメンバーごとの最新のログを名前順に返す
+func latestByUser(logs []*pb.LogEntry) []*pb.LogEntry {
+	seen := map[string]bool{}
+	var latest []*pb.LogEntry
+	// logs は新しい順
+	for _, entry := range logs {
+		if !seen[entry.UserName] {
+			seen[entry.UserName] = true
+			latest = append(latest, entry)
+		}
+	}
+	sort.Slice(latest, func(i, j int) bool { return latest[i].UserName < latest[j].UserName })
+	return latest
+}
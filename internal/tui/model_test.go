@@ -0,0 +1,168 @@
+package tui
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	pb "github.com/gensan0223/snulog/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeClient struct {
+	logs    []*pb.LogEntry
+	added   []*pb.LogEntry
+	fetches int
+}
+
+func (c *fakeClient) FetchLogs(ctx context.Context, in *pb.FetchRequest, opts ...grpc.CallOption) (*pb.FetchResponse, error) {
+	c.fetches++
+	return &pb.FetchResponse{Logs: c.logs}, nil
+}
+
+func (c *fakeClient) AddLogs(ctx context.Context, in *pb.LogEntry, opts ...grpc.CallOption) (*pb.AddResponse, error) {
+	c.added = append(c.added, in)
+	return &pb.AddResponse{}, nil
+}
+
+func (c *fakeClient) WatchLogs(ctx context.Context, in *pb.WatchLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[pb.LogEvent], error) {
+	return nil, status.Error(codes.Unimplemented, "unknown method WatchLogs")
+}
+
+func entry(id int64, user, feeling, timestamp string) *pb.LogEntry {
+	return &pb.LogEntry{Id: id, UserName: user, Status: "status " + user, Feeling: feeling, Timestamp: timestamp}
+}
+
+// run は cmd を実行して得たメッセージを model に渡す。tea.Batch はそれぞれ順に実行し、
+// 定期取得やカーソルの点滅のようなタイマーはすぐには返らないので待たずに捨てる
+func run(m *Model, cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	result := make(chan tea.Msg, 1)
+	go func() { result <- cmd() }()
+	var msg tea.Msg
+	select {
+	case msg = <-result:
+	case <-time.After(50 * time.Millisecond):
+		return
+	}
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		for _, c := range batch {
+			run(m, c)
+		}
+		return
+	}
+	_, next := m.Update(msg)
+	run(m, next)
+}
+
+func key(s string) tea.KeyMsg {
+	switch s {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func users(logs []*pb.LogEntry) []string {
+	result := make([]string, len(logs))
+	for i, entry := range logs {
+		result[i] = entry.UserName
+	}
+	return result
+}
+
+func TestModel_FallsBackToPolling(t *testing.T) {
+	client := &fakeClient{logs: []*pb.LogEntry{entry(1, "alice", "😊", "2025-01-01T09:00:00Z")}}
+	m := New(context.Background(), client, Options{})
+	run(m, m.Init())
+
+	assert.False(t, m.live)
+	assert.False(t, m.watchable, "WatchLogs に対応していないサーバーでは定期取得にする")
+	assert.Equal(t, 1, client.fetches)
+	assert.Contains(t, m.View(), "ごとに更新")
+	assert.Contains(t, m.View(), "alice")
+
+	_, cmd := m.Update(pollMsg{})
+	run(m, cmd)
+	assert.Equal(t, 2, client.fetches)
+}
+
+func TestModel_AppliesEvents(t *testing.T) {
+	m := New(context.Background(), &fakeClient{}, Options{})
+	m.logs = []*pb.LogEntry{entry(1, "alice", "😊", "2025-01-01T09:00:00Z")}
+
+	m.apply(&pb.LogEvent{Type: pb.LogEventType_LOG_EVENT_TYPE_ADDED, Entry: entry(2, "bob", "😫", "2025-01-01T10:00:00Z")})
+	assert.Equal(t, []string{"bob", "alice"}, users(m.visible()), "新しい順に並べる")
+
+	m.apply(&pb.LogEvent{Type: pb.LogEventType_LOG_EVENT_TYPE_DELETED, Entry: entry(1, "alice", "😊", "")})
+	assert.Equal(t, []string{"bob"}, users(m.visible()))
+}
+
+func TestModel_Filters(t *testing.T) {
+	client := &fakeClient{}
+	m := New(context.Background(), client, Options{TimeZone: time.UTC})
+	m.logs = []*pb.LogEntry{
+		entry(1, "alice", "😊", "2025-01-01T09:00:00Z"),
+		entry(2, "bob", "😫", "2025-01-02T09:00:00Z"),
+		entry(3, "alice", "🔥", "2025-01-03T09:00:00Z"),
+	}
+
+	run(m, m.filters[filterUser].Focus())
+	m.filters[filterUser].SetValue("alice")
+	m.filters[filterDate].SetValue("2025-01-02")
+	m.applyFilters()
+	assert.Equal(t, []string{"alice"}, users(m.visible()))
+	assert.Zero(t, client.fetches, "絞り込みでは取り直さない")
+
+	m.filters[filterDate].SetValue("1/2")
+	m.applyFilters()
+	assert.NotEmpty(t, m.err)
+
+	m.filters[filterDate].SetValue("")
+	m.filters[filterUser].SetValue("")
+	m.applyFilters()
+	assert.Equal(t, []string{"alice", "bob", "alice"}, users(m.visible()))
+}
+
+func TestLatestByUser(t *testing.T) {
+	m := New(context.Background(), &fakeClient{}, Options{})
+	m.logs = []*pb.LogEntry{
+		entry(1, "bob", "😊", "2025-01-01T09:00:00Z"),
+		entry(2, "alice", "😫", "2025-01-01T10:00:00+09:00"),
+		entry(3, "alice", "🔥", "2025-01-01T02:00:00Z"),
+	}
+
+	latest := latestByUser(m.visible())
+	require.Len(t, latest, 2)
+	assert.Equal(t, "alice", latest[0].UserName)
+	assert.Equal(t, "🔥", latest[0].Feeling, "タイムゾーンが違っても時刻で比べる")
+	assert.Equal(t, "bob", latest[1].UserName)
+}
+
+func TestModel_QuickAdd(t *testing.T) {
+	client := &fakeClient{}
+	m := New(context.Background(), client, Options{User: "alice"})
+
+	run(m, func() tea.Msg { return key("a") })
+	for _, r := range "レビュー中" {
+		run(m, func() tea.Msg { return key(string(r)) })
+	}
+	run(m, func() tea.Msg { return key("tab") })
+	run(m, func() tea.Msg { return key("tab") })
+	run(m, func() tea.Msg { return key("enter") })
+
+	require.Len(t, client.added, 1)
+	assert.Equal(t, "alice", client.added[0].UserName)
+	assert.Equal(t, "レビュー中", client.added[0].Status)
+	assert.Equal(t, Moods[2], client.added[0].Feeling)
+	assert.Equal(t, modeBoard, m.mode)
+}
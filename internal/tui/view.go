@@ -0,0 +1,160 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// メンバーの列の幅（枠を含まない）
+	cardWidth = 22
+	// ボード以外（見出し、フォーム、操作説明）に使う行数
+	chromeHeight = 12
+)
+
+var (
+	titleStyle = lipgloss.NewStyle().Bold(true)
+	dimStyle   = lipgloss.NewStyle().Faint(true)
+	errorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	liveStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	cardStyle  = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1).Width(cardWidth)
+	paneStyle  = lipgloss.NewStyle().Border(lipgloss.NormalBorder(), true, false, false, false)
+)
+
+func (m *Model) resize() {
+	m.history.Width = max(m.width, 20)
+	m.history.Height = max(m.height-chromeHeight-boardHeight, 3)
+}
+
+// boardHeight はメンバーのカード 1 段の行数（枠 2 行と名前・気分・状況・時刻）
+const boardHeight = 6
+
+func (m *Model) refreshHistory() {
+	var b strings.Builder
+	for _, entry := range m.visible() {
+		fmt.Fprintf(&b, "%s  %-12s %s  %s\n", m.clock(entry.Timestamp, "01/02 15:04"), entry.UserName, entry.Feeling, entry.Status)
+	}
+	if b.Len() == 0 {
+		b.WriteString(dimStyle.Render("まだログがありません"))
+	}
+	m.history.SetContent(strings.TrimSuffix(b.String(), "\n"))
+}
+
+// clock は時刻を設定のタイムゾーンで layout の形にする
+func (m *Model) clock(timestamp, layout string) string {
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return timestamp
+	}
+	return t.In(m.opts.TimeZone).Format(layout)
+}
+
+func (m *Model) View() string {
+	var b strings.Builder
+	b.WriteString(m.header())
+	b.WriteString("\n\n")
+	b.WriteString(m.board())
+	b.WriteString("\n")
+	b.WriteString(paneStyle.Render(titleStyle.Render("履歴")))
+	b.WriteString("\n")
+	b.WriteString(m.history.View())
+	b.WriteString("\n\n")
+
+	switch m.mode {
+	case modeAdd:
+		b.WriteString(m.addForm())
+	case modeFilter:
+		b.WriteString(m.filterForm())
+	default:
+		b.WriteString(dimStyle.Render("[a] 追加  [f] 絞り込み  [r] 更新  [↑↓] 履歴のスクロール  [q] 終了"))
+	}
+	if m.err != "" {
+		b.WriteString("\n" + errorStyle.Render(m.err))
+	}
+	return b.String()
+}
+
+func (m *Model) header() string {
+	filters := []string{"ユーザー: " + orAll(m.user)}
+	if !m.since.IsZero() {
+		filters = append(filters, "日付: "+m.since.Format("2006-01-02")+" 以降")
+	}
+	state := dimStyle.Render(fmt.Sprintf("○ %s ごとに更新", m.opts.PollInterval))
+	if m.live {
+		state = liveStyle.Render("● ライブ")
+	}
+	return titleStyle.Render("snulog") + "  " + strings.Join(filters, "  ") + "  " + state
+}
+
+func orAll(value string) string {
+	if value == "" {
+		return "すべて"
+	}
+	return value
+}
+
+// board はメンバーごとに最新の状況と気分のカードを横に並べる。入り切らない人数は末尾に数だけ出す
+func (m *Model) board() string {
+	latest := latestByUser(m.visible())
+	if len(latest) == 0 {
+		return dimStyle.Render("表示できるメンバーがいません")
+	}
+
+	fit := len(latest)
+	if m.width > 0 {
+		fit = max(m.width/(cardWidth+4), 1)
+	}
+	var cards []string
+	for i, entry := range latest {
+		if i == fit {
+			cards = append(cards, dimStyle.Render(fmt.Sprintf(" +%d 人", len(latest)-fit)))
+			break
+		}
+		cards = append(cards, cardStyle.Render(strings.Join([]string{
+			titleStyle.Render(truncate(entry.UserName, cardWidth-2)),
+			entry.Feeling,
+			truncate(entry.Status, cardWidth-2),
+			dimStyle.Render(m.clock(entry.Timestamp, "01/02 15:04")),
+		}, "\n")))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, cards...)
+}
+
+// truncate は表示幅が width を超える文字列を省略する
+func truncate(s string, width int) string {
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+func (m *Model) addForm() string {
+	var moods []string
+	for i, mood := range Moods {
+		if i == m.mood {
+			moods = append(moods, "["+mood+"]")
+		} else {
+			moods = append(moods, " "+mood+" ")
+		}
+	}
+	return titleStyle.Render("ログを追加") + "\n" +
+		m.status.View() + "\n" +
+		"気分: " + strings.Join(moods, "") + "\n" +
+		dimStyle.Render("[tab] 気分を選ぶ  [enter] 送信  [esc] やめる")
+}
+
+func (m *Model) filterForm() string {
+	lines := []string{titleStyle.Render("絞り込み")}
+	for _, f := range m.filters {
+		lines = append(lines, f.View())
+	}
+	lines = append(lines, dimStyle.Render("[tab] 次の欄  [enter] 適用  [esc] やめる"))
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/auth"
+	"github.com/gensan0223/snulog/internal/repository"
+	"github.com/gensan0223/snulog/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+type AuditUsecase interface {
+	ListAuditEvents(ctx context.Context, req *proto.ListAuditEventsRequest) (*proto.ListAuditEventsResponse, error)
+}
+
+type auditUsecase struct {
+	repo repository.AuditRepository
+}
+
+func NewAuditUsecase(repo repository.AuditRepository) AuditUsecase {
+	return &auditUsecase{repo: repo}
+}
+
+// ListAuditEvents は管理者だけが呼び出せる
+func (u *auditUsecase) ListAuditEvents(ctx context.Context, req *proto.ListAuditEventsRequest) (*proto.ListAuditEventsResponse, error) {
+	if _, err := auth.RequireRole(ctx, repository.RoleAdmin); err != nil {
+		return nil, status.Error(codes.PermissionDenied, "admin role required")
+	}
+
+	filter := repository.AuditFilter{
+		Actor:  req.Actor,
+		Action: req.Action,
+		Target: req.Target,
+		Limit:  int(req.Limit),
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	if filter.Limit > maxAuditLimit {
+		filter.Limit = maxAuditLimit
+	}
+
+	var err error
+	if filter.Since, err = parseTime(req.Since); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid since: %v", err)
+	}
+	if filter.Until, err = parseTime(req.Until); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid until: %v", err)
+	}
+
+	events, err := u.repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &proto.ListAuditEventsResponse{}
+	for _, e := range events {
+		resp.Events = append(resp.Events, &proto.AuditEvent{
+			Id:         e.ID,
+			OccurredAt: e.OccurredAt.UTC().Format(time.RFC3339),
+			Actor:      e.Actor,
+			Action:     e.Action,
+			Target:     e.Target,
+			Source:     e.Source,
+			Before:     string(e.Before),
+			After:      string(e.After),
+		})
+	}
+	return resp, nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// actor は監査ログの操作者名を返す。未認証の呼び出しでは自己申告の名前であることを明示する
+func actor(ctx context.Context, claimed string) string {
+	if identity, ok := auth.IdentityFromContext(ctx); ok {
+		return identity.Username
+	}
+	return "anonymous:" + claimed
+}
@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/repository"
+	"github.com/gensan0223/snulog/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestListAuditEvents(t *testing.T) {
+	repo := repository.NewInMemoryAuditRepository()
+	ctx := context.Background()
+	base := time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.Append(ctx, &repository.AuditEvent{OccurredAt: base, Actor: "alice", Action: "log.create", Target: "log:1"}))
+	require.NoError(t, repo.Append(ctx, &repository.AuditEvent{OccurredAt: base.Add(time.Hour), Actor: "bob", Action: "log.delete", Target: "log:1"}))
+	require.NoError(t, repo.Append(ctx, &repository.AuditEvent{OccurredAt: base.Add(48 * time.Hour), Actor: "alice", Action: "auth.logout", Target: "user:alice"}))
+
+	uc := NewAuditUsecase(repo)
+
+	t.Run("admin only", func(t *testing.T) {
+		_, err := uc.ListAuditEvents(withIdentity("alice", repository.RoleManager), &proto.ListAuditEventsRequest{})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+		_, err = uc.ListAuditEvents(ctx, &proto.ListAuditEventsRequest{})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	admin := withIdentity("root", repository.RoleAdmin)
+
+	t.Run("filters", func(t *testing.T) {
+		res, err := uc.ListAuditEvents(admin, &proto.ListAuditEventsRequest{Actor: "alice"})
+		require.NoError(t, err)
+		assert.Len(t, res.Events, 2)
+
+		res, err = uc.ListAuditEvents(admin, &proto.ListAuditEventsRequest{Target: "log:1", Action: "log.delete"})
+		require.NoError(t, err)
+		require.Len(t, res.Events, 1)
+		assert.Equal(t, "bob", res.Events[0].Actor)
+
+		res, err = uc.ListAuditEvents(admin, &proto.ListAuditEventsRequest{
+			Since: base.Add(30 * time.Minute).Format(time.RFC3339),
+			Until: base.Add(24 * time.Hour).Format(time.RFC3339),
+		})
+		require.NoError(t, err)
+		require.Len(t, res.Events, 1)
+		assert.Equal(t, "log.delete", res.Events[0].Action)
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		res, err := uc.ListAuditEvents(admin, &proto.ListAuditEventsRequest{Limit: 1})
+		require.NoError(t, err)
+		assert.Len(t, res.Events, 1)
+	})
+
+	t.Run("invalid time", func(t *testing.T) {
+		_, err := uc.ListAuditEvents(admin, &proto.ListAuditEventsRequest{Since: "yesterday"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
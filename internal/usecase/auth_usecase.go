@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/audit"
+	"github.com/gensan0223/snulog/internal/auth"
+	"github.com/gensan0223/snulog/internal/auth/totp"
+	"github.com/gensan0223/snulog/internal/ratelimit"
+	"github.com/gensan0223/snulog/internal/repository"
+	"github.com/gensan0223/snulog/internal/tenant"
+	"github.com/gensan0223/snulog/proto"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type AuthUsecase interface {
+	Login(ctx context.Context, req *proto.LoginRequest) (*proto.LoginResponse, error)
+}
+
+type authUsecase struct {
+	users    repository.UserRepository
+	signer   *auth.TokenSigner
+	limiter  *ratelimit.Limiter
+	recorder audit.Recorder
+	now      func() time.Time
+}
+
+func NewAuthUsecase(users repository.UserRepository, signer *auth.TokenSigner, limiter *ratelimit.Limiter, recorder audit.Recorder) AuthUsecase {
+	return &authUsecase{
+		users:    users,
+		signer:   signer,
+		limiter:  limiter,
+		recorder: recorder,
+		now:      time.Now,
+	}
+}
+
+// Login はパスワード（と 2 段階認証のコード）を確認して CLI 用のトークンを発行する。
+// 失敗理由はログにだけ残し、呼び出し元には区別できないエラーを返す
+func (u *authUsecase) Login(ctx context.Context, req *proto.LoginRequest) (*proto.LoginResponse, error) {
+	ip := ratelimit.PeerIP(ctx)
+	limitKeys := []string{"user:" + req.UserName, "ip:" + ip}
+	if u.limiter != nil {
+		allowed, _, err := u.limiter.Allow(ctx, limitKeys...)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			log.Printf("security: grpc login blocked user=%q ip=%s", req.UserName, ip)
+			audit.RecordOrLog(ctx, u.recorder, audit.Event{Actor: "anonymous", Action: audit.ActionLoginBlocked, Target: "user:" + req.UserName})
+			return nil, status.Error(codes.ResourceExhausted, "too many login attempts")
+		}
+	}
+
+	user, err := u.users.GetUserByUsername(req.UserName)
+	if err == nil {
+		// 以降の監査ログはユーザーの組織に記録する
+		ctx = tenant.WithOrg(ctx, user.OrgID)
+	}
+	if err != nil || bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)) != nil {
+		return nil, u.fail(ctx, req.UserName, ip, limitKeys)
+	}
+
+	if user.TOTPEnabled {
+		code := strings.TrimSpace(req.TotpCode)
+		if code == "" {
+			// パスワードは正しいので、試行回数には数えずにコードを求める
+			return nil, status.Error(codes.FailedPrecondition, "totp code required")
+		}
+		verified := totp.Validate(code, user.TOTPSecret, u.now(), totp.DefaultOptions)
+		if !verified {
+			if verified, err = u.users.ConsumeRecoveryCode(user.ID, auth.HashRecoveryCode(code)); err != nil {
+				return nil, err
+			}
+			if verified {
+				audit.RecordOrLog(ctx, u.recorder, audit.Event{Actor: user.Username, Action: audit.ActionRecoveryCodeUsed, Target: "user:" + user.Username})
+			}
+		}
+		if !verified {
+			audit.RecordOrLog(ctx, u.recorder, audit.Event{Actor: user.Username, Action: audit.ActionTwoFactorFailure, Target: "user:" + user.Username})
+			return nil, u.fail(ctx, req.UserName, ip, limitKeys)
+		}
+	}
+
+	if u.limiter != nil {
+		if err := u.limiter.Reset(ctx, limitKeys...); err != nil {
+			log.Printf("ratelimit: %v", err)
+		}
+	}
+
+	token, err := u.signer.Issue(user.Username, auth.CLITokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("security: grpc login success user=%q ip=%s", user.Username, ip)
+	audit.RecordOrLog(ctx, u.recorder, audit.Event{
+		Actor:  user.Username,
+		Action: audit.ActionLoginSuccess,
+		Target: "user:" + user.Username,
+		After:  map[string]any{"client": "grpc", "2fa": user.TOTPEnabled},
+	})
+	return &proto.LoginResponse{
+		Token:     token,
+		ExpiresAt: u.now().Add(auth.CLITokenTTL).UTC().Format(time.RFC3339),
+	}, nil
+}
+
+func (u *authUsecase) fail(ctx context.Context, username, ip string, limitKeys []string) error {
+	log.Printf("security: grpc login failure user=%q ip=%s", username, ip)
+	audit.RecordOrLog(ctx, u.recorder, audit.Event{Actor: "anonymous", Action: audit.ActionLoginFailure, Target: "user:" + username})
+	if u.limiter != nil {
+		if err := u.limiter.Record(ctx, limitKeys...); err != nil {
+			log.Printf("ratelimit: %v", err)
+		}
+	}
+	return status.Error(codes.Unauthenticated, "invalid username or password")
+}
@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/audit"
+	"github.com/gensan0223/snulog/internal/auth"
+	"github.com/gensan0223/snulog/internal/auth/totp"
+	"github.com/gensan0223/snulog/internal/ratelimit"
+	"github.com/gensan0223/snulog/internal/repository"
+	"github.com/gensan0223/snulog/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type stubUserRepository struct {
+	repository.UserRepository
+	users         map[string]*repository.User
+	recoveryCodes map[string]bool
+}
+
+func (r *stubUserRepository) GetUserByUsername(username string) (*repository.User, error) {
+	user, ok := r.users[username]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+func (r *stubUserRepository) CreateUser(user *repository.User) error {
+	if _, exists := r.users[user.Username]; exists {
+		return repository.ErrAlreadyExists
+	}
+	user.ID = len(r.users) + 1
+	r.users[user.Username] = user
+	return nil
+}
+
+func (r *stubUserRepository) DeleteUser(orgID int64, userID int) error {
+	for name, user := range r.users {
+		if user.ID == userID && user.OrgID == orgID {
+			delete(r.users, name)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (r *stubUserRepository) ConsumeRecoveryCode(userID int, codeHash string) (bool, error) {
+	if r.recoveryCodes[codeHash] {
+		delete(r.recoveryCodes, codeHash)
+		return true, nil
+	}
+	return false, nil
+}
+
+func newTestAuthUsecase(t *testing.T) (*authUsecase, *repository.InMemoryAuditRepository, string) {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	require.NoError(t, err)
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+
+	users := &stubUserRepository{
+		users: map[string]*repository.User{
+			"alice": {ID: 1, Username: "alice", PasswordHash: string(hash), Role: repository.RoleMember},
+			"bob":   {ID: 2, Username: "bob", PasswordHash: string(hash), Role: repository.RoleMember, TOTPEnabled: true, TOTPSecret: secret},
+		},
+		recoveryCodes: map[string]bool{auth.HashRecoveryCode("recovery-1"): true},
+	}
+	auditRepo := repository.NewInMemoryAuditRepository()
+	limiter := ratelimit.NewLimiter("login", repository.NewInMemoryAttemptRepository(), ratelimit.DefaultLoginPolicy)
+	uc := NewAuthUsecase(users, auth.NewTokenSigner([]byte("test-secret")), limiter, audit.NewRecorder(auditRepo)).(*authUsecase)
+	return uc, auditRepo, secret
+}
+
+func TestLogin(t *testing.T) {
+	uc, auditRepo, _ := newTestAuthUsecase(t)
+
+	res, err := uc.Login(context.Background(), &proto.LoginRequest{UserName: "alice", Password: "correct horse"})
+	require.NoError(t, err)
+	username, _, err := auth.NewTokenSigner([]byte("test-secret")).Verify(res.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", username)
+	expiresAt, err := time.Parse(time.RFC3339, res.ExpiresAt)
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(auth.CLITokenTTL), expiresAt, time.Minute)
+
+	events, err := auditRepo.List(context.Background(), repository.AuditFilter{Action: audit.ActionLoginSuccess})
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+}
+
+func TestLogin_Failures(t *testing.T) {
+	uc, _, _ := newTestAuthUsecase(t)
+
+	_, err := uc.Login(context.Background(), &proto.LoginRequest{UserName: "alice", Password: "wrong"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, unknownErr := uc.Login(context.Background(), &proto.LoginRequest{UserName: "nobody", Password: "wrong"})
+	assert.Equal(t, status.Convert(err).Message(), status.Convert(unknownErr).Message(), "ユーザーの存在有無を区別できない")
+}
+
+func TestLogin_TwoFactor(t *testing.T) {
+	uc, _, secret := newTestAuthUsecase(t)
+
+	_, err := uc.Login(context.Background(), &proto.LoginRequest{UserName: "bob", Password: "correct horse"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err), "2 段階認証が有効ならコードを求める")
+
+	_, err = uc.Login(context.Background(), &proto.LoginRequest{UserName: "bob", Password: "wrong", TotpCode: "000000"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = uc.Login(context.Background(), &proto.LoginRequest{UserName: "bob", Password: "correct horse", TotpCode: "not-a-code"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	code, err := totp.Generate(secret, time.Now(), totp.DefaultOptions)
+	require.NoError(t, err)
+	_, err = uc.Login(context.Background(), &proto.LoginRequest{UserName: "bob", Password: "correct horse", TotpCode: code})
+	assert.NoError(t, err)
+
+	_, err = uc.Login(context.Background(), &proto.LoginRequest{UserName: "bob", Password: "correct horse", TotpCode: "recovery-1"})
+	assert.NoError(t, err, "リカバリーコードでもログインできる")
+	_, err = uc.Login(context.Background(), &proto.LoginRequest{UserName: "bob", Password: "correct horse", TotpCode: "recovery-1"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "リカバリーコードは 1 回だけ使える")
+}
+
+func TestLogin_Lockout(t *testing.T) {
+	uc, auditRepo, _ := newTestAuthUsecase(t)
+
+	for i := 0; i < ratelimit.DefaultLoginPolicy.MaxAttempts; i++ {
+		_, err := uc.Login(context.Background(), &proto.LoginRequest{UserName: "alice", Password: "wrong"})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	}
+
+	_, err := uc.Login(context.Background(), &proto.LoginRequest{UserName: "alice", Password: "correct horse"})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err), "ロック中は正しいパスワードでもログインできない")
+
+	events, err := auditRepo.List(context.Background(), repository.AuditFilter{Action: audit.ActionLoginBlocked})
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+}
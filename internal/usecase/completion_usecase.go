@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gensan0223/snulog/internal/auth"
+	"github.com/gensan0223/snulog/internal/gitlog"
+	"github.com/gensan0223/snulog/internal/repository"
+	"github.com/gensan0223/snulog/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// 補完の候補を集める最近のログの件数
+	completionLogLimit = 500
+	// ログ ID の候補の件数と説明に使うステータスの長さ
+	completionLogIDLimit  = 50
+	completionStatusRunes = 40
+)
+
+// tagPattern はステータスの #タグ。#123 は課題の参照として扱うので数字から始まるものは含めない
+var tagPattern = regexp.MustCompile(`#[\p{L}_][\p{L}\p{N}_-]*`)
+
+type CompletionUsecase interface {
+	Complete(ctx context.Context, req *proto.CompleteRequest) (*proto.CompleteResponse, error)
+}
+
+type completionUsecase struct {
+	logs  repository.LogRepository
+	teams repository.TeamRepository
+}
+
+func NewCompletionUsecase(logs repository.LogRepository, teams repository.TeamRepository) CompletionUsecase {
+	return &completionUsecase{
+		logs:  logs,
+		teams: teams,
+	}
+}
+
+// Complete はシェルの補完の候補を返す。ユーザー名・タグ・課題・ログ ID は呼び出し元が読める最近のログから集め、
+// チームはログインしているときだけ返す
+func (u *completionUsecase) Complete(ctx context.Context, req *proto.CompleteRequest) (*proto.CompleteResponse, error) {
+	if req.Kind == proto.CompletionKind_COMPLETION_KIND_TEAM {
+		return u.completeTeams(ctx)
+	}
+
+	var values func(*proto.LogEntry) []string
+	switch req.Kind {
+	case proto.CompletionKind_COMPLETION_KIND_USER:
+		values = func(e *proto.LogEntry) []string { return []string{e.UserName} }
+	case proto.CompletionKind_COMPLETION_KIND_TAG:
+		values = func(e *proto.LogEntry) []string { return tagPattern.FindAllString(e.Status, -1) }
+	case proto.CompletionKind_COMPLETION_KIND_TICKET:
+		values = func(e *proto.LogEntry) []string { return gitlog.Tickets(e.Status) }
+	case proto.CompletionKind_COMPLETION_KIND_LOG_ID:
+	default:
+		return nil, status.Error(codes.InvalidArgument, "unknown completion kind")
+	}
+
+	logs, err := u.logs.Search(ctx, viewer(ctx), repository.LogQuery{Limit: completionLogLimit})
+	if err != nil {
+		return nil, err
+	}
+	if values == nil {
+		return &proto.CompleteResponse{Completions: logIDCompletions(logs)}, nil
+	}
+	return &proto.CompleteResponse{Completions: countCompletions(logs, values)}, nil
+}
+
+func (u *completionUsecase) completeTeams(ctx context.Context) (*proto.CompleteResponse, error) {
+	res := &proto.CompleteResponse{}
+	if _, ok := auth.IdentityFromContext(ctx); !ok {
+		return res, nil
+	}
+	teams, err := u.teams.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, team := range teams {
+		res.Completions = append(res.Completions, &proto.Completion{Value: team.Slug, Description: team.Name})
+	}
+	sort.Slice(res.Completions, func(i, j int) bool { return res.Completions[i].Value < res.Completions[j].Value })
+	return res, nil
+}
+
+// countCompletions は logs に現れた値を重複なく値の順に並べ、件数を説明にする
+func countCompletions(logs []*proto.LogEntry, values func(*proto.LogEntry) []string) []*proto.Completion {
+	counts := map[string]int{}
+	for _, entry := range logs {
+		seen := map[string]bool{}
+		for _, value := range values(entry) {
+			if value != "" && !seen[value] {
+				seen[value] = true
+				counts[value]++
+			}
+		}
+	}
+	completions := make([]*proto.Completion, 0, len(counts))
+	for value, count := range counts {
+		completions = append(completions, &proto.Completion{Value: value, Description: fmt.Sprintf("%d 件", count)})
+	}
+	sort.Slice(completions, func(i, j int) bool { return completions[i].Value < completions[j].Value })
+	return completions
+}
+
+// logIDCompletions は新しいログの ID を「投稿者: ステータスの 1 行目」の説明付きで返す
+func logIDCompletions(logs []*proto.LogEntry) []*proto.Completion {
+	completions := []*proto.Completion{}
+	for _, entry := range logs {
+		if len(completions) == completionLogIDLimit {
+			break
+		}
+		line, _, _ := strings.Cut(entry.Status, "\n")
+		if runes := []rune(line); len(runes) > completionStatusRunes {
+			line = string(runes[:completionStatusRunes]) + "…"
+		}
+		completions = append(completions, &proto.Completion{
+			Value:       strconv.FormatInt(entry.Id, 10),
+			Description: entry.UserName + ": " + line,
+		})
+	}
+	return completions
+}
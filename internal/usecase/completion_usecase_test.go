@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gensan0223/snulog/internal/repository"
+	"github.com/gensan0223/snulog/internal/tenant"
+	"github.com/gensan0223/snulog/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func completionValues(res *proto.CompleteResponse) []string {
+	values := make([]string, len(res.Completions))
+	for i, c := range res.Completions {
+		values[i] = c.Value
+	}
+	return values
+}
+
+func TestComplete(t *testing.T) {
+	ctx := context.Background()
+	logs := repository.NewInMemoryLogRepository()
+	for _, entry := range []*proto.LogEntry{
+		{UserName: "bob", Status: "#review PROJ-1 のレビュー", Timestamp: "2025-01-01T09:00:00Z", Visibility: proto.Visibility_VISIBILITY_TEAM},
+		{UserName: "alice", Status: "#review と #設計 (#12)", Timestamp: "2025-01-02T09:00:00Z", Visibility: proto.Visibility_VISIBILITY_TEAM},
+		{UserName: "carol", Status: "#secret PROJ-9", Timestamp: "2025-01-03T09:00:00Z", Visibility: proto.Visibility_VISIBILITY_PRIVATE},
+	} {
+		require.NoError(t, logs.Save(ctx, entry))
+	}
+	teams := repository.NewInMemoryTeamRepository()
+	require.NoError(t, teams.Create(tenant.WithOrg(ctx, 1), &repository.Team{Slug: "backend", Name: "バックエンド"}))
+	uc := NewCompletionUsecase(logs, teams)
+
+	res, err := uc.Complete(ctx, &proto.CompleteRequest{Kind: proto.CompletionKind_COMPLETION_KIND_USER})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob"}, completionValues(res), "読めないログの投稿者は候補にしない")
+
+	res, err = uc.Complete(ctx, &proto.CompleteRequest{Kind: proto.CompletionKind_COMPLETION_KIND_TAG})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"#review", "#設計"}, completionValues(res))
+	assert.Equal(t, "2 件", res.Completions[0].Description)
+
+	res, err = uc.Complete(ctx, &proto.CompleteRequest{Kind: proto.CompletionKind_COMPLETION_KIND_TICKET})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"#12", "PROJ-1"}, completionValues(res))
+
+	res, err = uc.Complete(withIdentity("carol", repository.RoleMember), &proto.CompleteRequest{Kind: proto.CompletionKind_COMPLETION_KIND_LOG_ID})
+	require.NoError(t, err)
+	require.Len(t, res.Completions, 3)
+	assert.Equal(t, "carol: #secret PROJ-9", res.Completions[0].Description, "ログ ID は新しい順")
+
+	res, err = uc.Complete(ctx, &proto.CompleteRequest{Kind: proto.CompletionKind_COMPLETION_KIND_TEAM})
+	require.NoError(t, err)
+	assert.Empty(t, res.Completions, "チームはログインしていないと返さない")
+	res, err = uc.Complete(withIdentity("alice", repository.RoleMember), &proto.CompleteRequest{Kind: proto.CompletionKind_COMPLETION_KIND_TEAM})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backend"}, completionValues(res))
+
+	_, err = uc.Complete(ctx, &proto.CompleteRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
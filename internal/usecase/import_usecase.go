@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/audit"
+	"github.com/gensan0223/snulog/internal/auth"
+	"github.com/gensan0223/snulog/internal/repository"
+	"github.com/gensan0223/snulog/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// 1 つのトランザクションで追加する行の数
+const importBatchSize = 500
+
+type ImportUsecase interface {
+	ImportLogs(ctx context.Context, recv func() (*proto.ImportLogsRequest, error)) (*proto.ImportLogsResponse, error)
+}
+
+type importUsecase struct {
+	logs     repository.LogRepository
+	users    repository.UserRepository
+	recorder audit.Recorder
+}
+
+func NewImportUsecase(logs repository.LogRepository, users repository.UserRepository, recorder audit.Recorder) ImportUsecase {
+	return &importUsecase{
+		logs:     logs,
+		users:    users,
+		recorder: recorder,
+	}
+}
+
+// ImportLogs は recv が io.EOF を返すまで受け取った行を検証し、importBatchSize 行ずつ追加する。
+// 組織の管理者だけが使え、投稿者は同じ組織の既存のユーザーでなければならない。
+// 不正な行と追加に失敗した行は行番号付きで返し、他の行の取り込みは続ける
+func (u *importUsecase) ImportLogs(ctx context.Context, recv func() (*proto.ImportLogsRequest, error)) (*proto.ImportLogsResponse, error) {
+	identity, err := auth.RequireRole(ctx, repository.RoleAdmin)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, "admin role required")
+	}
+
+	res := &proto.ImportLogsResponse{}
+	members := map[string]bool{}
+	var batch []*proto.ImportLogsRequest
+	save := func() {
+		if len(batch) == 0 {
+			return
+		}
+		defer func() { batch = batch[:0] }()
+		if res.DryRun {
+			res.Imported += int32(len(batch))
+			return
+		}
+
+		entries := make([]*proto.LogEntry, len(batch))
+		for i, row := range batch {
+			entries[i] = row.Entry
+		}
+		inserted, err := u.logs.SaveBatch(ctx, entries)
+		if err != nil {
+			log.Printf("import: failed to save %d rows from row %d: %v", len(batch), batch[0].Row, err)
+			for _, row := range batch {
+				res.Errors = append(res.Errors, &proto.ImportRowError{Row: row.Row, Message: "failed to save"})
+			}
+			return
+		}
+		res.Imported += int32(inserted)
+		res.Duplicates += int32(len(batch) - inserted)
+	}
+
+	for first := true; ; first = false {
+		req, err := recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if first {
+			res.DryRun = req.DryRun
+		}
+		if err := u.validate(identity, req.Entry, members); err != nil {
+			res.Errors = append(res.Errors, &proto.ImportRowError{Row: req.Row, Message: err.Error()})
+			continue
+		}
+		batch = append(batch, req)
+		if len(batch) == importBatchSize {
+			save()
+		}
+	}
+	save()
+
+	if !res.DryRun && res.Imported > 0 {
+		audit.RecordOrLog(ctx, u.recorder, audit.Event{
+			Actor:  identity.Username,
+			Action: audit.ActionLogsImported,
+			Target: fmt.Sprintf("org:#%d", identity.OrgID),
+			After: map[string]int32{
+				"imported":   res.Imported,
+				"duplicates": res.Duplicates,
+				"errors":     int32(len(res.Errors)),
+			},
+		})
+	}
+	return res, nil
+}
+
+// validate は 1 行を確かめる。members は組織のユーザーかどうかの確認結果を覚えておく
+func (u *importUsecase) validate(identity auth.Identity, entry *proto.LogEntry, members map[string]bool) error {
+	if entry == nil {
+		return errors.New("entry is required")
+	}
+	if entry.UserName == "" {
+		return errors.New("user_name is required")
+	}
+	if entry.Status == "" {
+		return errors.New("status is required")
+	}
+	if _, err := time.Parse(time.RFC3339, entry.Timestamp); err != nil {
+		return fmt.Errorf("timestamp must be RFC3339: %q", entry.Timestamp)
+	}
+	entry.Visibility = repository.NormalizeVisibility(entry.Visibility)
+	if _, known := proto.Visibility_name[int32(entry.Visibility)]; !known {
+		return errors.New("unknown visibility")
+	}
+	if len(entry.IdempotencyKey) > maxIdempotencyKeyLength {
+		return fmt.Errorf("idempotency key must be at most %d bytes", maxIdempotencyKeyLength)
+	}
+
+	member, checked := members[entry.UserName]
+	if !checked {
+		user, err := u.users.GetUserByUsername(entry.UserName)
+		member = err == nil && user.OrgID == identity.OrgID
+		members[entry.UserName] = member
+	}
+	if !member {
+		return fmt.Errorf("unknown user %q", entry.UserName)
+	}
+	entry.Id = 0
+	return nil
+}
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/gensan0223/snulog/internal/audit"
+	"github.com/gensan0223/snulog/internal/repository"
+	"github.com/gensan0223/snulog/internal/tenant"
+	"github.com/gensan0223/snulog/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// rows は ImportLogs の recv として requests を順に返し、最後に io.EOF を返す
+func rows(requests ...*proto.ImportLogsRequest) func() (*proto.ImportLogsRequest, error) {
+	return func() (*proto.ImportLogsRequest, error) {
+		if len(requests) == 0 {
+			return nil, io.EOF
+		}
+		req := requests[0]
+		requests = requests[1:]
+		return req, nil
+	}
+}
+
+func importRow(row int32, user, status, timestamp string) *proto.ImportLogsRequest {
+	return &proto.ImportLogsRequest{Row: row, Entry: &proto.LogEntry{UserName: user, Status: status, Feeling: "😊", Timestamp: timestamp}}
+}
+
+func newTestImportUsecase() (ImportUsecase, *repository.InMemoryLogRepository, *repository.InMemoryAuditRepository) {
+	users := &stubUserRepository{users: map[string]*repository.User{
+		"alice": {ID: 7, OrgID: tenant.DefaultOrgID, Username: "alice", Role: repository.RoleMember},
+		"boss":  {ID: 10, OrgID: 2, Username: "boss", Role: repository.RoleAdmin},
+	}}
+	logs := repository.NewInMemoryLogRepository()
+	audits := repository.NewInMemoryAuditRepository()
+	return NewImportUsecase(logs, users, audit.NewRecorder(audits)), logs, audits
+}
+
+func TestImportLogs(t *testing.T) {
+	uc, logs, audits := newTestImportUsecase()
+	root := withOrgIdentity("root", repository.RoleAdmin, tenant.DefaultOrgID)
+
+	res, err := uc.ImportLogs(root, rows(
+		importRow(2, "alice", "sprint planning", "2024-04-01T09:00:00+09:00"),
+		importRow(3, "boss", "other org", "2024-04-01T09:00:00Z"),
+		importRow(4, "alice", "", "2024-04-01T09:00:00Z"),
+		importRow(5, "alice", "retro", "2024/04/02"),
+		&proto.ImportLogsRequest{Row: 6},
+	))
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, res.Imported)
+	var errors []string
+	for _, e := range res.Errors {
+		errors = append(errors, fmt.Sprintf("%d: %s", e.Row, e.Message))
+	}
+	assert.Equal(t, []string{
+		`3: unknown user "boss"`,
+		"4: status is required",
+		`5: timestamp must be RFC3339: "2024/04/02"`,
+		"6: entry is required",
+	}, errors, "不正な行は行番号付きで報告し、他の行は取り込む")
+
+	saved, err := logs.FindAll(root, repository.Viewer{Username: "root", Role: repository.RoleAdmin})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sprint planning"}, statuses(saved))
+
+	events, err := audits.List(context.Background(), repository.AuditFilter{Action: audit.ActionLogsImported})
+	require.NoError(t, err)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, "root", events[0].Actor)
+	}
+}
+
+func TestImportLogs_DryRunAndDuplicates(t *testing.T) {
+	uc, logs, _ := newTestImportUsecase()
+	root := withOrgIdentity("root", repository.RoleAdmin, tenant.DefaultOrgID)
+	viewer := repository.Viewer{Username: "root", Role: repository.RoleAdmin}
+	requests := func(dryRun bool) func() (*proto.ImportLogsRequest, error) {
+		var reqs []*proto.ImportLogsRequest
+		for i := range importBatchSize + 10 {
+			req := importRow(int32(i+2), "alice", fmt.Sprintf("day %d", i), "2024-04-01T09:00:00Z")
+			req.Entry.IdempotencyKey = fmt.Sprintf("import-%d", i)
+			reqs = append(reqs, req)
+		}
+		reqs[0].DryRun = dryRun
+		return rows(reqs...)
+	}
+
+	res, err := uc.ImportLogs(root, requests(true))
+	require.NoError(t, err)
+	assert.True(t, res.DryRun)
+	assert.EqualValues(t, importBatchSize+10, res.Imported)
+	saved, err := logs.FindAll(root, viewer)
+	require.NoError(t, err)
+	assert.Empty(t, saved, "dry-run では追加しない")
+
+	res, err = uc.ImportLogs(root, requests(false))
+	require.NoError(t, err)
+	assert.EqualValues(t, importBatchSize+10, res.Imported)
+
+	res, err = uc.ImportLogs(root, requests(false))
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, res.Imported)
+	assert.EqualValues(t, importBatchSize+10, res.Duplicates, "同じファイルを取り込み直しても二重に追加しない")
+}
+
+func TestImportLogs_RequiresAdmin(t *testing.T) {
+	uc, _, _ := newTestImportUsecase()
+	_, err := uc.ImportLogs(withOrgIdentity("alice", repository.RoleMember, tenant.DefaultOrgID), rows())
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
@@ -2,36 +2,97 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
+	"github.com/gensan0223/snulog/internal/audit"
+	"github.com/gensan0223/snulog/internal/auth"
+	"github.com/gensan0223/snulog/internal/mood"
 	"github.com/gensan0223/snulog/internal/repository"
+	"github.com/gensan0223/snulog/internal/tenant"
 	"github.com/gensan0223/snulog/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type LogUsecase interface {
 	AddLogs(ctx context.Context, entry *proto.LogEntry) (*proto.AddResponse, error)
 	FetchLogs(ctx context.Context) (*proto.FetchResponse, error)
+	DeleteLog(ctx context.Context, id int64) (*proto.DeleteLogResponse, error)
+	SearchLogs(ctx context.Context, req *proto.SearchLogsRequest) (*proto.FetchResponse, error)
+	GetMoodStats(ctx context.Context, req *proto.MoodStatsRequest) (*proto.MoodStatsResponse, error)
+	GetCalendar(ctx context.Context, req *proto.CalendarRequest) (*proto.CalendarResponse, error)
+	ExportLogs(ctx context.Context, req *proto.ExportLogsRequest, send func(*proto.LogEntry) error) error
+	WatchLogs(ctx context.Context, send func(*proto.LogEvent) error) error
 }
 
+const (
+	defaultSearchLimit = 100
+	maxSearchLimit     = 1000
+	// idempotency_key の列の長さ
+	maxIdempotencyKeyLength = 64
+)
+
 type logUsecase struct {
-	repo repository.LogRepository
+	repo     repository.LogRepository
+	recorder audit.Recorder
+	hub      *logHub
 }
 
-func NewLogUsecase(repo repository.LogRepository) LogUsecase {
+func NewLogUsecase(repo repository.LogRepository, recorder audit.Recorder) LogUsecase {
 	return &logUsecase{
-		repo: repo,
+		repo:     repo,
+		recorder: recorder,
+		hub:      newLogHub(),
 	}
 }
 
 func (u *logUsecase) AddLogs(ctx context.Context, entry *proto.LogEntry) (*proto.AddResponse, error) {
+	// 認証済みの呼び出しでは他人の名前でログを書けないようにする
+	identity, authenticated := auth.IdentityFromContext(ctx)
+	if authenticated {
+		entry.UserName = identity.Username
+	}
+	// メモは本人だけが読めるものとして保存する
+	if entry.Note {
+		entry.Visibility = proto.Visibility_VISIBILITY_PRIVATE
+	}
+	// 本人を確認できない呼び出しでは、後から本人が読めないため非公開のログを受け付けない
+	entry.Visibility = repository.NormalizeVisibility(entry.Visibility)
+	if entry.Visibility != proto.Visibility_VISIBILITY_TEAM && !authenticated {
+		return nil, status.Error(codes.Unauthenticated, "login is required to post non-team logs")
+	}
+	if _, known := proto.Visibility_name[int32(entry.Visibility)]; !known {
+		return nil, status.Error(codes.InvalidArgument, "unknown visibility")
+	}
+	if len(entry.IdempotencyKey) > maxIdempotencyKeyLength {
+		return nil, status.Errorf(codes.InvalidArgument, "idempotency key must be at most %d bytes", maxIdempotencyKeyLength)
+	}
+
 	err := u.repo.Save(ctx, entry)
+	// 再送されたログは追加済みとして扱い、監査ログや通知を重ねない
+	if errors.Is(err, repository.ErrDuplicateLog) {
+		return &proto.AddResponse{Message: "already added", Duplicate: true}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
+
+	audit.RecordOrLog(ctx, u.recorder, audit.Event{
+		Actor:  actor(ctx, entry.UserName),
+		Action: audit.ActionLogCreate,
+		Target: logTarget(entry.Id),
+		After:  entry,
+	})
+	// メモはタイムラインに出さないので通知しない
+	if !entry.Note {
+		u.hub.publish(tenant.OrgID(ctx), proto.LogEventType_LOG_EVENT_TYPE_ADDED, entry)
+	}
 	return &proto.AddResponse{Message: "added successfully"}, nil
 }
 
 func (u *logUsecase) FetchLogs(ctx context.Context) (*proto.FetchResponse, error) {
-	logs, err := u.repo.FindAll(ctx)
+	logs, err := u.repo.FindAll(ctx, viewer(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -39,3 +100,149 @@ func (u *logUsecase) FetchLogs(ctx context.Context) (*proto.FetchResponse, error
 		Logs: logs,
 	}, nil
 }
+
+// DeleteLog は本人か管理者だけがログを削除できる
+func (u *logUsecase) DeleteLog(ctx context.Context, id int64) (*proto.DeleteLogResponse, error) {
+	identity, ok := auth.IdentityFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	entry, err := u.repo.FindByID(ctx, id, viewer(ctx))
+	if errors.Is(err, repository.ErrLogNotFound) {
+		return nil, status.Error(codes.NotFound, "log not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if entry.UserName != identity.Username && identity.Role != repository.RoleAdmin {
+		return nil, status.Error(codes.PermissionDenied, "only the author or an admin can delete this log")
+	}
+
+	if err := u.repo.Delete(ctx, id); err != nil {
+		return nil, err
+	}
+
+	audit.RecordOrLog(ctx, u.recorder, audit.Event{
+		Actor:  identity.Username,
+		Action: audit.ActionLogDelete,
+		Target: logTarget(id),
+		Before: entry,
+	})
+	if !entry.Note {
+		u.hub.publish(tenant.OrgID(ctx), proto.LogEventType_LOG_EVENT_TYPE_DELETED, entry)
+	}
+	return &proto.DeleteLogResponse{Message: "deleted successfully"}, nil
+}
+
+func (u *logUsecase) SearchLogs(ctx context.Context, req *proto.SearchLogsRequest) (*proto.FetchResponse, error) {
+	query := repository.LogQuery{
+		Text:     req.Query,
+		UserName: req.UserName,
+		Limit:    int(req.Limit),
+		Notes:    req.Notes,
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultSearchLimit
+	}
+	if query.Limit > maxSearchLimit {
+		query.Limit = maxSearchLimit
+	}
+
+	var err error
+	if query.Since, err = parseTime(req.Since); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid since: %v", err)
+	}
+	if query.Until, err = parseTime(req.Until); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid until: %v", err)
+	}
+
+	logs, err := u.repo.Search(ctx, viewer(ctx), query)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.FetchResponse{Logs: logs}, nil
+}
+
+// ExportLogs は条件に合うログを古い順に send で返す。件数の上限はなく、ログインが必要
+func (u *logUsecase) ExportLogs(ctx context.Context, req *proto.ExportLogsRequest, send func(*proto.LogEntry) error) error {
+	if _, ok := auth.IdentityFromContext(ctx); !ok {
+		return status.Error(codes.Unauthenticated, "authentication required")
+	}
+	query := repository.LogQuery{
+		Text:     req.Query,
+		UserName: req.UserName,
+	}
+	var err error
+	if query.Since, err = parseTime(req.Since); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid since: %v", err)
+	}
+	if query.Until, err = parseTime(req.Until); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid until: %v", err)
+	}
+	return u.repo.Each(ctx, viewer(ctx), query, send)
+}
+
+func (u *logUsecase) GetMoodStats(ctx context.Context, req *proto.MoodStatsRequest) (*proto.MoodStatsResponse, error) {
+	since, err := parseTime(req.Since)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid since: %v", err)
+	}
+	until, err := parseTime(req.Until)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid until: %v", err)
+	}
+	return u.repo.MoodStats(ctx, viewer(ctx), since, until)
+}
+
+// GetCalendar は viewer が読めるログを日ごとに数え、気分を点数にして平均する
+func (u *logUsecase) GetCalendar(ctx context.Context, req *proto.CalendarRequest) (*proto.CalendarResponse, error) {
+	query := repository.LogQuery{UserName: req.UserName}
+	var err error
+	if query.Since, err = parseTime(req.Since); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid since: %v", err)
+	}
+	if query.Until, err = parseTime(req.Until); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid until: %v", err)
+	}
+	counts, err := u.repo.CountByDay(ctx, viewer(ctx), query)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &proto.CalendarResponse{}
+	var day *proto.CalendarDay
+	var total float64
+	var top int64
+	for _, c := range counts {
+		if day == nil || day.Date != c.Date {
+			day = &proto.CalendarDay{Date: c.Date}
+			res.Days = append(res.Days, day)
+			total, top = 0, 0
+		}
+		day.Count += c.Count
+		// 同じ数なら気分の並び（文字列順）で先のものにする
+		if c.Count > top {
+			day.TopFeeling, top = c.Feeling, c.Count
+		}
+		if score, ok := mood.Score(c.Feeling); ok {
+			total += score * float64(c.Count)
+			day.Scored += c.Count
+			day.Mood = total / float64(day.Scored)
+		}
+	}
+	return res, nil
+}
+
+// viewer は呼び出し元を公開範囲の判定に使う形にする。未認証なら team のログだけが見える
+func viewer(ctx context.Context) repository.Viewer {
+	identity, ok := auth.IdentityFromContext(ctx)
+	if !ok {
+		return repository.Viewer{}
+	}
+	return repository.Viewer{Username: identity.Username, Role: identity.Role}
+}
+
+func logTarget(id int64) string {
+	return fmt.Sprintf("log:%d", id)
+}
@@ -2,17 +2,25 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/gensan0223/snulog/internal/audit"
+	"github.com/gensan0223/snulog/internal/auth"
 	"github.com/gensan0223/snulog/internal/repository"
 	"github.com/gensan0223/snulog/proto"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestAddLogsAndFetchLogs(t *testing.T) {
 	repo := repository.NewInMemoryLogRepository()
-	uc := NewLogUsecase(repo)
+	uc := NewLogUsecase(repo, audit.Nop)
 
 	entry := &proto.LogEntry{
 		UserName:  "tester",
@@ -29,3 +37,438 @@ func TestAddLogsAndFetchLogs(t *testing.T) {
 	assert.Len(t, res.Logs, 1)
 	assert.Equal(t, "tester", res.Logs[0].UserName)
 }
+
+func withIdentity(username, role string) context.Context {
+	return auth.WithIdentity(context.Background(), auth.Identity{Username: username, Role: role})
+}
+
+func TestAddLogs_UsesCallerIdentity(t *testing.T) {
+	repo := repository.NewInMemoryLogRepository()
+	auditRepo := repository.NewInMemoryAuditRepository()
+	uc := NewLogUsecase(repo, audit.NewRecorder(auditRepo))
+
+	_, err := uc.AddLogs(withIdentity("alice", repository.RoleMember), &proto.LogEntry{UserName: "bob", Status: "s", Feeling: "f"})
+	assert.NoError(t, err)
+
+	res, err := uc.FetchLogs(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", res.Logs[0].UserName, "他人の名前では書き込めない")
+
+	events, err := auditRepo.List(context.Background(), repository.AuditFilter{Action: audit.ActionLogCreate})
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, "alice", events[0].Actor)
+}
+
+func TestDeleteLog_Permissions(t *testing.T) {
+	repo := repository.NewInMemoryLogRepository()
+	auditRepo := repository.NewInMemoryAuditRepository()
+	uc := NewLogUsecase(repo, audit.NewRecorder(auditRepo))
+
+	entry := &proto.LogEntry{UserName: "alice", Status: "working", Feeling: "😊"}
+	_, err := uc.AddLogs(withIdentity("alice", repository.RoleMember), entry)
+	assert.NoError(t, err)
+
+	_, err = uc.DeleteLog(context.Background(), entry.Id)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = uc.DeleteLog(withIdentity("bob", repository.RoleMember), entry.Id)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = uc.DeleteLog(withIdentity("admin", repository.RoleAdmin), 9999)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = uc.DeleteLog(withIdentity("admin", repository.RoleAdmin), entry.Id)
+	assert.NoError(t, err)
+
+	res, err := uc.FetchLogs(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, res.Logs)
+
+	events, err := auditRepo.List(context.Background(), repository.AuditFilter{Action: audit.ActionLogDelete})
+	assert.NoError(t, err)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, "admin", events[0].Actor)
+		assert.Equal(t, fmt.Sprintf("log:%d", entry.Id), events[0].Target)
+		assert.Contains(t, string(events[0].Before), "working", "削除前の内容が残る")
+	}
+}
+
+func addVisibilityFixtures(t *testing.T, uc LogUsecase) {
+	t.Helper()
+	for _, entry := range []*proto.LogEntry{
+		{Status: "team work", Feeling: "😊", Visibility: proto.Visibility_VISIBILITY_TEAM},
+		{Status: "burned out", Feeling: "😫", Visibility: proto.Visibility_VISIBILITY_MANAGERS},
+		{Status: "secret", Feeling: "😫", Visibility: proto.Visibility_VISIBILITY_PRIVATE},
+	} {
+		_, err := uc.AddLogs(withIdentity("alice", repository.RoleMember), entry)
+		assert.NoError(t, err)
+	}
+}
+
+func statuses(logs []*proto.LogEntry) []string {
+	result := make([]string, len(logs))
+	for i, entry := range logs {
+		result[i] = entry.Status
+	}
+	return result
+}
+
+func TestFetchLogs_Visibility(t *testing.T) {
+	uc := NewLogUsecase(repository.NewInMemoryLogRepository(), audit.Nop)
+	addVisibilityFixtures(t, uc)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want []string
+	}{
+		{"anonymous", context.Background(), []string{"team work"}},
+		{"other member", withIdentity("bob", repository.RoleMember), []string{"team work"}},
+		{"manager", withIdentity("carol", repository.RoleManager), []string{"team work", "burned out"}},
+		{"admin cannot read private", withIdentity("root", repository.RoleAdmin), []string{"team work", "burned out"}},
+		{"author", withIdentity("alice", repository.RoleMember), []string{"team work", "burned out", "secret"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := uc.FetchLogs(tt.ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, statuses(res.Logs))
+		})
+	}
+}
+
+func TestAddLogs_Visibility(t *testing.T) {
+	uc := NewLogUsecase(repository.NewInMemoryLogRepository(), audit.Nop)
+
+	_, err := uc.AddLogs(context.Background(), &proto.LogEntry{UserName: "alice", Visibility: proto.Visibility_VISIBILITY_PRIVATE})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "匿名では非公開のログを書けない")
+
+	_, err = uc.AddLogs(withIdentity("alice", repository.RoleMember), &proto.LogEntry{Visibility: proto.Visibility(42)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	entry := &proto.LogEntry{UserName: "alice", Status: "s", Feeling: "f"}
+	_, err = uc.AddLogs(context.Background(), entry)
+	assert.NoError(t, err)
+	assert.Equal(t, proto.Visibility_VISIBILITY_TEAM, entry.Visibility, "未指定は team として保存する")
+}
+
+func TestDeleteLog_HidesInvisibleEntries(t *testing.T) {
+	repo := repository.NewInMemoryLogRepository()
+	uc := NewLogUsecase(repo, audit.Nop)
+	entry := &proto.LogEntry{Status: "secret", Visibility: proto.Visibility_VISIBILITY_PRIVATE}
+	_, err := uc.AddLogs(withIdentity("alice", repository.RoleMember), entry)
+	assert.NoError(t, err)
+
+	_, err = uc.DeleteLog(withIdentity("root", repository.RoleAdmin), entry.Id)
+	assert.Equal(t, codes.NotFound, status.Code(err), "読めないログは存在しないものとして扱う")
+}
+
+func TestSearchLogs(t *testing.T) {
+	uc := NewLogUsecase(repository.NewInMemoryLogRepository(), audit.Nop)
+	addVisibilityFixtures(t, uc)
+	_, err := uc.AddLogs(withIdentity("bob", repository.RoleMember), &proto.LogEntry{
+		Status:    "Burned the toast",
+		Feeling:   "😅",
+		Timestamp: "2025-01-01T09:00:00Z",
+	})
+	assert.NoError(t, err)
+
+	res, err := uc.SearchLogs(withIdentity("bob", repository.RoleMember), &proto.SearchLogsRequest{Query: "burn"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Burned the toast"}, statuses(res.Logs), "他人の managers/private のログは検索にも出ない")
+
+	res, err = uc.SearchLogs(withIdentity("carol", repository.RoleManager), &proto.SearchLogsRequest{Query: "burn", UserName: "alice"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"burned out"}, statuses(res.Logs))
+
+	res, err = uc.SearchLogs(context.Background(), &proto.SearchLogsRequest{Since: "2025-01-01T00:00:00Z", Until: "2025-01-02T00:00:00Z"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Burned the toast"}, statuses(res.Logs))
+
+	_, err = uc.SearchLogs(context.Background(), &proto.SearchLogsRequest{Since: "yesterday"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestExportLogs(t *testing.T) {
+	uc := NewLogUsecase(repository.NewInMemoryLogRepository(), audit.Nop)
+	for _, entry := range []*proto.LogEntry{
+		{Status: "day 2", Timestamp: "2025-01-02T09:00:00Z"},
+		{Status: "day 1", Timestamp: "2025-01-01T09:00:00Z"},
+		{Status: "day 3", Timestamp: "2025-01-03T09:00:00Z", Visibility: proto.Visibility_VISIBILITY_PRIVATE},
+	} {
+		_, err := uc.AddLogs(withIdentity("alice", repository.RoleMember), entry)
+		require.NoError(t, err)
+	}
+	export := func(ctx context.Context, req *proto.ExportLogsRequest) ([]string, error) {
+		var logs []*proto.LogEntry
+		err := uc.ExportLogs(ctx, req, func(entry *proto.LogEntry) error {
+			logs = append(logs, entry)
+			return nil
+		})
+		return statuses(logs), err
+	}
+
+	got, err := export(withIdentity("carol", repository.RoleManager), &proto.ExportLogsRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"day 1", "day 2"}, got, "古い順で、読めないログは含めない")
+
+	got, err = export(withIdentity("alice", repository.RoleMember), &proto.ExportLogsRequest{Since: "2025-01-02T00:00:00Z"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"day 2", "day 3"}, got)
+
+	_, err = export(context.Background(), &proto.ExportLogsRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = export(withIdentity("alice", repository.RoleMember), &proto.ExportLogsRequest{Until: "tomorrow"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	stop := errors.New("client gone")
+	err = uc.ExportLogs(withIdentity("alice", repository.RoleMember), &proto.ExportLogsRequest{}, func(*proto.LogEntry) error { return stop })
+	assert.ErrorIs(t, err, stop, "送れなくなったらそこで止める")
+}
+
+func TestGetMoodStats_AnonymizesInvisibleEntries(t *testing.T) {
+	uc := NewLogUsecase(repository.NewInMemoryLogRepository(), audit.Nop)
+	addVisibilityFixtures(t, uc)
+
+	res, err := uc.GetMoodStats(withIdentity("bob", repository.RoleMember), &proto.MoodStatsRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, []*proto.MoodCount{{Feeling: "😫", Count: 2}, {Feeling: "😊", Count: 1}}, res.Team, "チーム全体の集計には非公開の気分も含める")
+	assert.Equal(t, []*proto.UserMoodCount{{UserName: "alice", Feeling: "😊", Count: 1}}, res.ByUser, "個人別の集計には読めるログだけを含める")
+
+	res, err = uc.GetMoodStats(withIdentity("carol", repository.RoleManager), &proto.MoodStatsRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, res.ByUser, 2)
+
+	res, err = uc.GetMoodStats(withIdentity("alice", repository.RoleMember), &proto.MoodStatsRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, []*proto.UserMoodCount{{UserName: "alice", Feeling: "😫", Count: 2}, {UserName: "alice", Feeling: "😊", Count: 1}}, res.ByUser)
+}
+
+func TestGetCalendar_CountsAndScoresByDay(t *testing.T) {
+	uc := NewLogUsecase(repository.NewInMemoryLogRepository(), audit.Nop)
+	for _, entry := range []*proto.LogEntry{
+		// UTC では 1 月 1 日だが、投稿者の時計では 1 月 2 日
+		{UserName: "alice", Status: "a", Feeling: "😄", Timestamp: "2025-01-02T08:00:00+09:00"},
+		{UserName: "alice", Status: "b", Feeling: "😄", Timestamp: "2025-01-02T18:00:00+09:00"},
+		{UserName: "alice", Status: "c", Feeling: "🍣", Timestamp: "2025-01-02T19:00:00+09:00"},
+		{UserName: "bob", Status: "d", Feeling: "😊", Timestamp: "2025-01-02T10:00:00+09:00"},
+		{UserName: "alice", Status: "e", Feeling: "😊", Timestamp: "2025-01-05T10:00:00+09:00"},
+		{UserName: "alice", Status: "f", Feeling: "😊", Timestamp: "2025-02-01T10:00:00+09:00"},
+	} {
+		_, err := uc.AddLogs(context.Background(), entry)
+		require.NoError(t, err)
+	}
+	_, err := uc.AddLogs(withIdentity("alice", repository.RoleMember),
+		&proto.LogEntry{Status: "secret", Feeling: "😫", Timestamp: "2025-01-05T11:00:00+09:00", Visibility: proto.Visibility_VISIBILITY_PRIVATE})
+	require.NoError(t, err)
+
+	res, err := uc.GetCalendar(context.Background(), &proto.CalendarRequest{
+		UserName: "alice",
+		Since:    "2025-01-01T00:00:00+09:00",
+		Until:    "2025-02-01T00:00:00+09:00",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []*proto.CalendarDay{
+		{Date: "2025-01-02", Count: 3, Mood: 2, Scored: 2, TopFeeling: "😄"},
+		{Date: "2025-01-05", Count: 1, Mood: 1, Scored: 1, TopFeeling: "😊"},
+	}, res.Days, "非公開のログは本人以外の集計に含めない")
+
+	res, err = uc.GetCalendar(withIdentity("alice", repository.RoleMember), &proto.CalendarRequest{UserName: "alice", Since: "2025-01-05T00:00:00+09:00"})
+	assert.NoError(t, err)
+	require.Len(t, res.Days, 2)
+	assert.Equal(t, &proto.CalendarDay{Date: "2025-01-05", Count: 2, Mood: -0.5, Scored: 2, TopFeeling: "😊"}, res.Days[0])
+
+	res, err = uc.GetCalendar(context.Background(), &proto.CalendarRequest{Until: "2025-01-03T00:00:00+09:00"})
+	assert.NoError(t, err)
+	assert.Equal(t, []*proto.CalendarDay{{Date: "2025-01-02", Count: 4, Mood: 5.0 / 3, Scored: 3, TopFeeling: "😄"}}, res.Days)
+
+	_, err = uc.GetCalendar(context.Background(), &proto.CalendarRequest{Since: "2025-01"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestNotes_AreKeptOutOfLogs(t *testing.T) {
+	uc := NewLogUsecase(repository.NewInMemoryLogRepository(), audit.Nop)
+	alice := withIdentity("alice", repository.RoleMember)
+	_, err := uc.AddLogs(alice, &proto.LogEntry{Status: "log", Feeling: "😊", Timestamp: "2025-01-02T09:00:00Z"})
+	require.NoError(t, err)
+	_, err = uc.AddLogs(alice, &proto.LogEntry{Status: "memo", Feeling: "📝", Timestamp: "2025-01-02T10:00:00Z", Note: true})
+	require.NoError(t, err)
+
+	fetched, err := uc.FetchLogs(alice)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"log"}, statuses(fetched.Logs))
+
+	searched, err := uc.SearchLogs(alice, &proto.SearchLogsRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"log"}, statuses(searched.Logs))
+
+	stats, err := uc.GetMoodStats(alice, &proto.MoodStatsRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, []*proto.MoodCount{{Feeling: "😊", Count: 1}}, stats.Team, "メモは気分の集計に含めない")
+
+	calendar, err := uc.GetCalendar(alice, &proto.CalendarRequest{UserName: "alice"})
+	assert.NoError(t, err)
+	require.Len(t, calendar.Days, 1)
+	assert.Equal(t, int64(1), calendar.Days[0].Count)
+
+	var exported []*proto.LogEntry
+	err = uc.ExportLogs(alice, &proto.ExportLogsRequest{}, func(entry *proto.LogEntry) error {
+		exported = append(exported, entry)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"log"}, statuses(exported))
+
+	notes, err := uc.SearchLogs(alice, &proto.SearchLogsRequest{Notes: true})
+	assert.NoError(t, err)
+	require.Len(t, notes.Logs, 1)
+	assert.True(t, notes.Logs[0].Note)
+	assert.Equal(t, proto.Visibility_VISIBILITY_PRIVATE, notes.Logs[0].Visibility, "メモは本人だけが読める")
+
+	others, err := uc.SearchLogs(withIdentity("carol", repository.RoleAdmin), &proto.SearchLogsRequest{Notes: true})
+	assert.NoError(t, err)
+	assert.Empty(t, others.Logs)
+}
+
+func TestLogUsecase_IsolatesOrgs(t *testing.T) {
+	uc := NewLogUsecase(repository.NewInMemoryLogRepository(), audit.Nop)
+	alice := withOrgIdentity("alice", repository.RoleMember, 2)
+	otherAdmin := withOrgIdentity("root", repository.RoleAdmin, 3)
+
+	entry := &proto.LogEntry{Status: "quarterly numbers", Feeling: "😊"}
+	_, err := uc.AddLogs(alice, entry)
+	require.NoError(t, err)
+
+	res, err := uc.FetchLogs(otherAdmin)
+	assert.NoError(t, err)
+	assert.Empty(t, res.Logs)
+
+	res, err = uc.FetchLogs(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, res.Logs, "匿名の呼び出しは既定の組織のログだけを読む")
+
+	res, err = uc.SearchLogs(otherAdmin, &proto.SearchLogsRequest{Query: "quarterly"})
+	assert.NoError(t, err)
+	assert.Empty(t, res.Logs)
+
+	stats, err := uc.GetMoodStats(otherAdmin, &proto.MoodStatsRequest{})
+	assert.NoError(t, err)
+	assert.Empty(t, stats.Team)
+
+	_, err = uc.DeleteLog(otherAdmin, entry.Id)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	res, err = uc.FetchLogs(alice)
+	assert.NoError(t, err)
+	assert.Len(t, res.Logs, 1)
+}
+
+// watch は ctx で WatchLogs を始め、受け取ったイベントを返すチャネルと止める関数を返す
+func watch(t *testing.T, uc LogUsecase, ctx context.Context) (<-chan *proto.LogEvent, func() error) {
+	t.Helper()
+	hub := uc.(*logUsecase).hub
+	hub.mutex.Lock()
+	before := len(hub.subscribers)
+	hub.mutex.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	events := make(chan *proto.LogEvent, watchBufferSize)
+	done := make(chan error, 1)
+	go func() {
+		done <- uc.WatchLogs(ctx, func(event *proto.LogEvent) error {
+			events <- event
+			return nil
+		})
+	}()
+	require.Eventually(t, func() bool {
+		hub.mutex.Lock()
+		defer hub.mutex.Unlock()
+		return len(hub.subscribers) > before
+	}, time.Second, time.Millisecond)
+	return events, func() error {
+		cancel()
+		return <-done
+	}
+}
+
+func TestWatchLogs(t *testing.T) {
+	uc := NewLogUsecase(repository.NewInMemoryLogRepository(), audit.Nop)
+	bob, stopBob := watch(t, uc, withIdentity("bob", repository.RoleMember))
+	carol, stopCarol := watch(t, uc, withIdentity("carol", repository.RoleManager))
+	other, stopOther := watch(t, uc, withOrgIdentity("root", repository.RoleAdmin, 3))
+
+	addVisibilityFixtures(t, uc)
+	entry := &proto.LogEntry{Status: "done"}
+	_, err := uc.AddLogs(withIdentity("alice", repository.RoleMember), entry)
+	require.NoError(t, err)
+	_, err = uc.DeleteLog(withIdentity("alice", repository.RoleMember), entry.Id)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool { return len(bob) == 3 && len(carol) == 4 }, time.Second, time.Millisecond)
+	require.NoError(t, stopBob())
+	require.NoError(t, stopCarol())
+	require.NoError(t, stopOther())
+
+	received := func(events <-chan *proto.LogEvent) []string {
+		var result []string
+		for len(events) > 0 {
+			event := <-events
+			result = append(result, event.Type.String()+" "+event.Entry.Status)
+		}
+		return result
+	}
+	assert.Equal(t, []string{
+		"LOG_EVENT_TYPE_ADDED team work",
+		"LOG_EVENT_TYPE_ADDED done",
+		"LOG_EVENT_TYPE_DELETED done",
+	}, received(bob), "読めないログのイベントは届かない")
+	assert.Equal(t, []string{
+		"LOG_EVENT_TYPE_ADDED team work",
+		"LOG_EVENT_TYPE_ADDED burned out",
+		"LOG_EVENT_TYPE_ADDED done",
+		"LOG_EVENT_TYPE_DELETED done",
+	}, received(carol))
+	assert.Empty(t, received(other), "他の組織のイベントは届かない")
+}
+
+func TestWatchLogs_DropsSlowWatcher(t *testing.T) {
+	uc := NewLogUsecase(repository.NewInMemoryLogRepository(), audit.Nop)
+	hub := uc.(*logUsecase).hub
+	s := hub.subscribe(1, repository.Viewer{})
+
+	for range watchBufferSize + 1 {
+		hub.publish(1, proto.LogEventType_LOG_EVENT_TYPE_ADDED, &proto.LogEntry{})
+	}
+	assert.Empty(t, hub.subscribers, "溢れた購読者は切断する")
+	assert.Len(t, s.events, watchBufferSize)
+	hub.unsubscribe(s)
+}
+
+func TestAddLogs_IdempotencyKey(t *testing.T) {
+	auditRepo := repository.NewInMemoryAuditRepository()
+	uc := NewLogUsecase(repository.NewInMemoryLogRepository(), audit.NewRecorder(auditRepo))
+	alice := withIdentity("alice", repository.RoleMember)
+
+	res, err := uc.AddLogs(alice, &proto.LogEntry{Status: "offline", Feeling: "😊", IdempotencyKey: "key-1"})
+	require.NoError(t, err)
+	assert.False(t, res.Duplicate)
+
+	res, err = uc.AddLogs(alice, &proto.LogEntry{Status: "offline", Feeling: "😊", IdempotencyKey: "key-1"})
+	require.NoError(t, err)
+	assert.True(t, res.Duplicate, "再送は成功として扱う")
+
+	_, err = uc.AddLogs(withOrgIdentity("bob", repository.RoleMember, 2), &proto.LogEntry{Status: "other org", IdempotencyKey: "key-1"})
+	require.NoError(t, err)
+
+	logs, err := uc.FetchLogs(alice)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"offline"}, statuses(logs.Logs), "同じキーのログは一度だけ追加する")
+	events, err := auditRepo.List(context.Background(), repository.AuditFilter{Action: audit.ActionLogCreate})
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+
+	_, err = uc.AddLogs(alice, &proto.LogEntry{IdempotencyKey: strings.Repeat("x", 65)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
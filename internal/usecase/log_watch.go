@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gensan0223/snulog/internal/repository"
+	"github.com/gensan0223/snulog/internal/tenant"
+	"github.com/gensan0223/snulog/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// 購読者ごとにためておけるイベントの数。溢れた購読者は切断し、クライアントに取り直してもらう
+const watchBufferSize = 64
+
+// logHub は同じサーバープロセスで追加・削除されたログを WatchLogs の購読者に配る
+type logHub struct {
+	mutex       sync.Mutex
+	subscribers map[*logSubscriber]struct{}
+}
+
+type logSubscriber struct {
+	orgID  int64
+	viewer repository.Viewer
+	events chan *proto.LogEvent
+}
+
+func newLogHub() *logHub {
+	return &logHub{subscribers: map[*logSubscriber]struct{}{}}
+}
+
+func (h *logHub) subscribe(orgID int64, viewer repository.Viewer) *logSubscriber {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	s := &logSubscriber{orgID: orgID, viewer: viewer, events: make(chan *proto.LogEvent, watchBufferSize)}
+	h.subscribers[s] = struct{}{}
+	return s
+}
+
+func (h *logHub) unsubscribe(s *logSubscriber) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if _, ok := h.subscribers[s]; ok {
+		delete(h.subscribers, s)
+		close(s.events)
+	}
+}
+
+// publish は orgID の組織で entry を読める購読者にだけイベントを送る。送り手を待たせないよう、詰まった購読者は切断する
+func (h *logHub) publish(orgID int64, eventType proto.LogEventType, entry *proto.LogEntry) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	for s := range h.subscribers {
+		if s.orgID != orgID || !s.viewer.CanSee(entry) {
+			continue
+		}
+		select {
+		case s.events <- &proto.LogEvent{Type: eventType, Entry: entry}:
+		default:
+			delete(h.subscribers, s)
+			close(s.events)
+		}
+	}
+}
+
+// WatchLogs は ctx が終わるまで、呼び出し元が読めるログの追加と削除を send に渡す
+func (u *logUsecase) WatchLogs(ctx context.Context, send func(*proto.LogEvent) error) error {
+	s := u.hub.subscribe(tenant.OrgID(ctx), viewer(ctx))
+	defer u.hub.unsubscribe(s)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-s.events:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "watcher fell behind, fetch logs again")
+			}
+			if err := send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+
+	"github.com/gensan0223/snulog/internal/audit"
+	"github.com/gensan0223/snulog/internal/auth"
+	"github.com/gensan0223/snulog/internal/repository"
+	"github.com/gensan0223/snulog/internal/tenant"
+	"github.com/gensan0223/snulog/proto"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// 組織やチームの slug に使える文字（URL やコマンドライン引数にそのまま使う）
+var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,63}$`)
+
+// 作成時に指定できるロール
+var creatableRoles = []string{repository.RoleMember, repository.RoleManager, repository.RoleAdmin}
+
+// 組織を作成したときに用意するチーム
+const defaultTeamSlug = "default"
+
+type OrgUsecase interface {
+	CreateOrganization(ctx context.Context, req *proto.CreateOrganizationRequest) (*proto.Organization, error)
+	ListOrganizations(ctx context.Context) (*proto.ListOrganizationsResponse, error)
+	CreateTeam(ctx context.Context, req *proto.CreateTeamRequest) (*proto.Team, error)
+	ListTeams(ctx context.Context) (*proto.ListTeamsResponse, error)
+	CreateUser(ctx context.Context, req *proto.CreateUserRequest) (*proto.CreateUserResponse, error)
+}
+
+type orgUsecase struct {
+	orgs     repository.OrganizationRepository
+	teams    repository.TeamRepository
+	users    repository.UserRepository
+	recorder audit.Recorder
+}
+
+func NewOrgUsecase(orgs repository.OrganizationRepository, teams repository.TeamRepository, users repository.UserRepository, recorder audit.Recorder) OrgUsecase {
+	return &orgUsecase{
+		orgs:     orgs,
+		teams:    teams,
+		users:    users,
+		recorder: recorder,
+	}
+}
+
+// CreateOrganization はインスタンス管理者だけが呼び出せる。既定のチームも作成する
+func (u *orgUsecase) CreateOrganization(ctx context.Context, req *proto.CreateOrganizationRequest) (*proto.Organization, error) {
+	identity, err := requireSystemAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !slugPattern.MatchString(req.Slug) {
+		return nil, status.Error(codes.InvalidArgument, "slug must be lowercase letters, digits and hyphens")
+	}
+	name := req.Name
+	if name == "" {
+		name = req.Slug
+	}
+
+	org := &repository.Organization{Slug: req.Slug, Name: name}
+	if err := u.orgs.Create(ctx, org); err != nil {
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return nil, status.Error(codes.AlreadyExists, "organization already exists")
+		}
+		return nil, err
+	}
+	orgCtx := tenant.WithOrg(ctx, org.ID)
+	if err := u.teams.Create(orgCtx, &repository.Team{Slug: defaultTeamSlug, Name: "Default"}); err != nil {
+		return nil, err
+	}
+
+	audit.RecordOrLog(ctx, u.recorder, audit.Event{
+		Actor:  identity.Username,
+		Action: audit.ActionOrgCreated,
+		Target: "org:" + org.Slug,
+		After:  map[string]string{"slug": org.Slug, "name": org.Name},
+	})
+	return toProtoOrganization(org), nil
+}
+
+func (u *orgUsecase) ListOrganizations(ctx context.Context) (*proto.ListOrganizationsResponse, error) {
+	if _, err := requireSystemAdmin(ctx); err != nil {
+		return nil, err
+	}
+	orgs, err := u.orgs.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := &proto.ListOrganizationsResponse{}
+	for _, org := range orgs {
+		res.Organizations = append(res.Organizations, toProtoOrganization(org))
+	}
+	return res, nil
+}
+
+// CreateTeam は呼び出し元の組織の管理者だけが呼び出せる
+func (u *orgUsecase) CreateTeam(ctx context.Context, req *proto.CreateTeamRequest) (*proto.Team, error) {
+	identity, err := auth.RequireRole(ctx, repository.RoleAdmin)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, "admin role required")
+	}
+	if !slugPattern.MatchString(req.Slug) {
+		return nil, status.Error(codes.InvalidArgument, "slug must be lowercase letters, digits and hyphens")
+	}
+	name := req.Name
+	if name == "" {
+		name = req.Slug
+	}
+
+	team := &repository.Team{Slug: req.Slug, Name: name}
+	if err := u.teams.Create(ctx, team); err != nil {
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return nil, status.Error(codes.AlreadyExists, "team already exists")
+		}
+		return nil, err
+	}
+
+	audit.RecordOrLog(ctx, u.recorder, audit.Event{
+		Actor:  identity.Username,
+		Action: audit.ActionTeamCreated,
+		Target: "team:" + team.Slug,
+		After:  map[string]string{"slug": team.Slug, "name": team.Name},
+	})
+	return &proto.Team{Id: team.ID, Slug: team.Slug, Name: team.Name}, nil
+}
+
+// ListTeams は呼び出し元の組織のチームを返す
+func (u *orgUsecase) ListTeams(ctx context.Context) (*proto.ListTeamsResponse, error) {
+	if _, ok := auth.IdentityFromContext(ctx); !ok {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+	teams, err := u.teams.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := &proto.ListTeamsResponse{}
+	for _, team := range teams {
+		res.Teams = append(res.Teams, &proto.Team{Id: team.ID, Slug: team.Slug, Name: team.Name})
+	}
+	return res, nil
+}
+
+// CreateUser は組織の管理者が自分の組織に、インスタンス管理者が任意の組織にユーザーを作成する
+func (u *orgUsecase) CreateUser(ctx context.Context, req *proto.CreateUserRequest) (*proto.CreateUserResponse, error) {
+	identity, err := auth.RequireRole(ctx, repository.RoleAdmin)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, "admin role required")
+	}
+
+	var org *repository.Organization
+	if req.OrgSlug == "" {
+		org, err = u.orgs.FindByID(ctx, identity.OrgID)
+	} else {
+		org, err = u.orgs.FindBySlug(ctx, req.OrgSlug)
+	}
+	if errors.Is(err, repository.ErrOrganizationNotFound) {
+		return nil, status.Error(codes.NotFound, "organization not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if org.ID != identity.OrgID && !identity.IsSystemAdmin() {
+		// 他の組織の存在を知られないよう、見つからない場合と同じ応答にする
+		return nil, status.Error(codes.NotFound, "organization not found")
+	}
+
+	role := req.Role
+	if role == "" {
+		role = repository.RoleMember
+	}
+	if !slices.Contains(creatableRoles, role) {
+		return nil, status.Error(codes.InvalidArgument, "unknown role")
+	}
+	if req.UserName == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_name is required")
+	}
+	if err := auth.DefaultPasswordPolicy.Validate(req.UserName, req.Password); err != nil {
+		var policyErr *auth.PasswordPolicyError
+		if errors.As(err, &policyErr) {
+			return nil, status.Error(codes.InvalidArgument, policyErr.Reason)
+		}
+		return nil, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &repository.User{OrgID: org.ID, Username: req.UserName, PasswordHash: string(hash), Email: req.Email, Role: role}
+	if err := u.users.CreateUser(user); err != nil {
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return nil, status.Error(codes.AlreadyExists, "user already exists")
+		}
+		return nil, err
+	}
+
+	audit.RecordOrLog(ctx, u.recorder, audit.Event{
+		Actor:  identity.Username,
+		Action: audit.ActionUserCreated,
+		Target: "user:" + user.Username,
+		After:  map[string]string{"role": role, "org": org.Slug},
+		OrgID:  org.ID,
+	})
+	return &proto.CreateUserResponse{UserName: user.Username, Role: role, OrgSlug: org.Slug}, nil
+}
+
+func requireSystemAdmin(ctx context.Context) (auth.Identity, error) {
+	identity, ok := auth.IdentityFromContext(ctx)
+	if !ok || !identity.IsSystemAdmin() {
+		return identity, status.Error(codes.PermissionDenied, "instance admin role required")
+	}
+	return identity, nil
+}
+
+func toProtoOrganization(org *repository.Organization) *proto.Organization {
+	return &proto.Organization{Id: org.ID, Slug: org.Slug, Name: org.Name}
+}
@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gensan0223/snulog/internal/audit"
+	"github.com/gensan0223/snulog/internal/auth"
+	"github.com/gensan0223/snulog/internal/repository"
+	"github.com/gensan0223/snulog/internal/tenant"
+	"github.com/gensan0223/snulog/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func withOrgIdentity(username, role string, orgID int64) context.Context {
+	return auth.WithIdentity(context.Background(), auth.Identity{Username: username, Role: role, OrgID: orgID})
+}
+
+func newTestOrgUsecase() (OrgUsecase, *stubUserRepository, *repository.InMemoryAuditRepository) {
+	users := &stubUserRepository{users: map[string]*repository.User{}}
+	auditRepo := repository.NewInMemoryAuditRepository()
+	uc := NewOrgUsecase(repository.NewInMemoryOrganizationRepository(), repository.NewInMemoryTeamRepository(), users, audit.NewRecorder(auditRepo))
+	return uc, users, auditRepo
+}
+
+func TestCreateOrganization(t *testing.T) {
+	uc, _, _ := newTestOrgUsecase()
+	root := withOrgIdentity("root", repository.RoleAdmin, tenant.DefaultOrgID)
+
+	org, err := uc.CreateOrganization(root, &proto.CreateOrganizationRequest{Slug: "sales", Name: "営業部"})
+	require.NoError(t, err)
+	assert.Equal(t, "sales", org.Slug)
+
+	_, err = uc.CreateOrganization(root, &proto.CreateOrganizationRequest{Slug: "sales"})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	_, err = uc.CreateOrganization(root, &proto.CreateOrganizationRequest{Slug: "Sales Dept"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// 他の組織の管理者はインスタンス全体を管理できない
+	salesAdmin := withOrgIdentity("boss", repository.RoleAdmin, org.Id)
+	_, err = uc.CreateOrganization(salesAdmin, &proto.CreateOrganizationRequest{Slug: "hr"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = uc.ListOrganizations(salesAdmin)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	teams, err := uc.ListTeams(salesAdmin)
+	require.NoError(t, err)
+	require.Len(t, teams.Teams, 1, "組織の作成時に既定のチームを作る")
+	assert.Equal(t, "default", teams.Teams[0].Slug)
+
+	orgs, err := uc.ListOrganizations(root)
+	require.NoError(t, err)
+	assert.Len(t, orgs.Organizations, 2)
+}
+
+func TestCreateTeam_ScopedToCallerOrg(t *testing.T) {
+	uc, _, auditRepo := newTestOrgUsecase()
+	adminA := withOrgIdentity("alice", repository.RoleAdmin, 2)
+	memberB := withOrgIdentity("bob", repository.RoleMember, 3)
+
+	_, err := uc.CreateTeam(adminA, &proto.CreateTeamRequest{Slug: "backend"})
+	require.NoError(t, err)
+
+	_, err = uc.CreateTeam(memberB, &proto.CreateTeamRequest{Slug: "frontend"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	teams, err := uc.ListTeams(memberB)
+	require.NoError(t, err)
+	assert.Empty(t, teams.Teams, "他の組織のチームは見えない")
+
+	_, err = uc.ListTeams(context.Background())
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	events, err := auditRepo.List(tenant.WithOrg(context.Background(), 2), repository.AuditFilter{Action: audit.ActionTeamCreated})
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+}
+
+func TestCreateUser_OrgScoped(t *testing.T) {
+	uc, users, _ := newTestOrgUsecase()
+	root := withOrgIdentity("root", repository.RoleAdmin, tenant.DefaultOrgID)
+	sales, err := uc.CreateOrganization(root, &proto.CreateOrganizationRequest{Slug: "sales"})
+	require.NoError(t, err)
+	_, err = uc.CreateOrganization(root, &proto.CreateOrganizationRequest{Slug: "hr"})
+	require.NoError(t, err)
+
+	// インスタンス管理者は任意の組織にユーザーを作れる
+	res, err := uc.CreateUser(root, &proto.CreateUserRequest{UserName: "boss", Password: "a-long-passw0rd", Role: repository.RoleAdmin, OrgSlug: "sales"})
+	require.NoError(t, err)
+	assert.Equal(t, "sales", res.OrgSlug)
+	assert.Equal(t, sales.Id, users.users["boss"].OrgID)
+
+	salesAdmin := withOrgIdentity("boss", repository.RoleAdmin, sales.Id)
+	res, err = uc.CreateUser(salesAdmin, &proto.CreateUserRequest{UserName: "seller", Password: "a-long-passw0rd"})
+	require.NoError(t, err)
+	assert.Equal(t, "sales", res.OrgSlug, "省略すると自分の組織に作る")
+	assert.Equal(t, repository.RoleMember, users.users["seller"].Role)
+
+	_, err = uc.CreateUser(salesAdmin, &proto.CreateUserRequest{UserName: "spy", Password: "a-long-passw0rd", OrgSlug: "hr"})
+	assert.Equal(t, codes.NotFound, status.Code(err), "他の組織にはユーザーを作れない")
+	assert.NotContains(t, users.users, "spy")
+
+	_, err = uc.CreateUser(withOrgIdentity("seller", repository.RoleMember, sales.Id), &proto.CreateUserRequest{UserName: "x", Password: "a-long-passw0rd"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = uc.CreateUser(salesAdmin, &proto.CreateUserRequest{UserName: "weak", Password: "short"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = uc.CreateUser(salesAdmin, &proto.CreateUserRequest{UserName: "seller", Password: "a-long-passw0rd"})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	_, err = uc.CreateUser(salesAdmin, &proto.CreateUserRequest{UserName: "odd", Password: "a-long-passw0rd", Role: "owner"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/auth"
+	"github.com/gensan0223/snulog/internal/version"
+	"github.com/gensan0223/snulog/proto"
+)
+
+type PingUsecase interface {
+	Ping(ctx context.Context, req *proto.PingRequest) (*proto.PingResponse, error)
+}
+
+type pingUsecase struct {
+	now func() time.Time
+}
+
+func NewPingUsecase() PingUsecase {
+	return &pingUsecase{now: time.Now}
+}
+
+// Ping はサーバーのバージョンと時刻、呼び出し元を返す。何も書き込まず、監査ログにも残さない
+func (u *pingUsecase) Ping(ctx context.Context, req *proto.PingRequest) (*proto.PingResponse, error) {
+	res := &proto.PingResponse{
+		ServerVersion: version.String(),
+		Api:           version.API,
+		MinClientApi:  version.MinClientAPI,
+		ServerTime:    u.now().UTC().Format(time.RFC3339Nano),
+	}
+	if identity, ok := auth.IdentityFromContext(ctx); ok {
+		res.UserName = identity.Username
+		res.Role = identity.Role
+	}
+	return res, nil
+}
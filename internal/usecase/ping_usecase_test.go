@@ -0,0 +1,29 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/repository"
+	"github.com/gensan0223/snulog/internal/version"
+	"github.com/gensan0223/snulog/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPing(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 600, time.FixedZone("JST", 9*60*60))
+	uc := &pingUsecase{now: func() time.Time { return now }}
+
+	res, err := uc.Ping(context.Background(), &proto.PingRequest{ClientApi: version.API})
+	require.NoError(t, err)
+	assert.Equal(t, "2025-01-01T18:04:05.0000006Z", res.ServerTime)
+	assert.Equal(t, int32(version.API), res.Api)
+	assert.Empty(t, res.UserName, "匿名の呼び出し")
+
+	res, err = uc.Ping(withIdentity("alice", repository.RoleManager), &proto.PingRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", res.UserName)
+	assert.Equal(t, repository.RoleManager, res.Role)
+}
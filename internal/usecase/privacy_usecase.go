@@ -0,0 +1,334 @@
+package usecase
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/audit"
+	"github.com/gensan0223/snulog/internal/auth"
+	"github.com/gensan0223/snulog/internal/ratelimit"
+	"github.com/gensan0223/snulog/internal/repository"
+	"github.com/gensan0223/snulog/internal/tenant"
+	"github.com/gensan0223/snulog/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErasureConfig は EraseUser でモードを省略したときの扱い
+type ErasureConfig struct {
+	DefaultMode proto.ErasureMode
+	// PseudonymPrefix にユーザー ID を付けたものを仮名にする
+	PseudonymPrefix string
+}
+
+var DefaultErasureConfig = ErasureConfig{
+	DefaultMode:     proto.ErasureMode_ERASURE_MODE_PSEUDONYMIZE,
+	PseudonymPrefix: "former-member-",
+}
+
+// ParseErasureMode は設定やコマンドラインの値（pseudonymize / delete）を変換する。空なら UNSPECIFIED
+func ParseErasureMode(value string) (proto.ErasureMode, error) {
+	switch value {
+	case "":
+		return proto.ErasureMode_ERASURE_MODE_UNSPECIFIED, nil
+	case "pseudonymize":
+		return proto.ErasureMode_ERASURE_MODE_PSEUDONYMIZE, nil
+	case "delete":
+		return proto.ErasureMode_ERASURE_MODE_DELETE, nil
+	}
+	return proto.ErasureMode_ERASURE_MODE_UNSPECIFIED, fmt.Errorf("unknown erasure mode %q (pseudonymize or delete)", value)
+}
+
+func erasureModeName(mode proto.ErasureMode) string {
+	return strings.ToLower(strings.TrimPrefix(mode.String(), "ERASURE_MODE_"))
+}
+
+type PrivacyUsecase interface {
+	ExportMyData(ctx context.Context, req *proto.ExportMyDataRequest) (*proto.ExportMyDataResponse, error)
+	EraseUser(ctx context.Context, req *proto.EraseUserRequest) (*proto.EraseUserResponse, error)
+}
+
+type privacyUsecase struct {
+	logs     repository.LogRepository
+	audits   repository.AuditRepository
+	users    repository.UserRepository
+	orgs     repository.OrganizationRepository
+	recorder audit.Recorder
+	config   ErasureConfig
+	// limiters の user: と 2fa: のキーは消去したユーザーの名前を含むので一緒に消す
+	limiters []*ratelimit.Limiter
+	now      func() time.Time
+}
+
+func NewPrivacyUsecase(logs repository.LogRepository, audits repository.AuditRepository, users repository.UserRepository,
+	orgs repository.OrganizationRepository, recorder audit.Recorder, config ErasureConfig, limiters ...*ratelimit.Limiter) PrivacyUsecase {
+	return &privacyUsecase{
+		logs:     logs,
+		audits:   audits,
+		users:    users,
+		orgs:     orgs,
+		recorder: recorder,
+		config:   config,
+		limiters: limiters,
+		now:      time.Now,
+	}
+}
+
+// findUser は呼び出し元の組織のユーザーを探す。他の組織のユーザーは存在しないものとして扱う
+func (u *privacyUsecase) findUser(identity auth.Identity, username string) (*repository.User, error) {
+	user, err := u.users.GetUserByUsername(username)
+	if err != nil || user.OrgID != identity.OrgID {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	return user, nil
+}
+
+// ExportMyData は本人のデータを zip にまとめて返す。他のユーザーのデータは同じ組織の管理者だけが書き出せる
+func (u *privacyUsecase) ExportMyData(ctx context.Context, req *proto.ExportMyDataRequest) (*proto.ExportMyDataResponse, error) {
+	identity, ok := auth.IdentityFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+	username := req.UserName
+	if username == "" {
+		username = identity.Username
+	}
+	if username != identity.Username && identity.Role != repository.RoleAdmin {
+		return nil, status.Error(codes.PermissionDenied, "admin role required")
+	}
+	user, err := u.findUser(identity, username)
+	if err != nil {
+		return nil, err
+	}
+
+	org, err := u.orgs.FindByID(ctx, user.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	logs, err := u.logs.FindByUser(ctx, user.Username)
+	if err != nil {
+		return nil, err
+	}
+	events, err := u.audits.ListByUser(ctx, user.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	now := u.now()
+	archive, err := buildExportArchive(now, identity.Username, user, org, logs, events)
+	if err != nil {
+		return nil, err
+	}
+
+	audit.RecordOrLog(ctx, u.recorder, audit.Event{
+		Actor:  identity.Username,
+		Action: audit.ActionUserDataExported,
+		Target: "user:" + user.Username,
+		After:  map[string]int{"logs": len(logs), "audit_events": len(events)},
+	})
+	return &proto.ExportMyDataResponse{
+		Archive:  archive,
+		FileName: fmt.Sprintf("snulog-export-%s-%s.zip", user.Username, now.Format("20060102")),
+	}, nil
+}
+
+// EraseUser はユーザーのログと監査ログを仮名化または削除し、ユーザーを削除する。
+// 仮名化ではログの気分と日時を残すので、チームの気分の集計は変わらない
+func (u *privacyUsecase) EraseUser(ctx context.Context, req *proto.EraseUserRequest) (*proto.EraseUserResponse, error) {
+	identity, err := auth.RequireRole(ctx, repository.RoleAdmin)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, "admin role required")
+	}
+	if req.UserName == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_name is required")
+	}
+	// 管理者がいなくならないよう、自分自身は消去できない
+	if req.UserName == identity.Username {
+		return nil, status.Error(codes.FailedPrecondition, "cannot erase yourself")
+	}
+	user, err := u.findUser(identity, req.UserName)
+	if err != nil {
+		return nil, err
+	}
+	ctx = tenant.WithOrg(ctx, user.OrgID)
+
+	mode := req.Mode
+	if mode == proto.ErasureMode_ERASURE_MODE_UNSPECIFIED {
+		mode = u.config.DefaultMode
+	}
+
+	res := &proto.EraseUserResponse{Mode: mode}
+	// 失敗しても再実行できるよう、ユーザーは最後に削除する
+	switch mode {
+	case proto.ErasureMode_ERASURE_MODE_PSEUDONYMIZE:
+		res.Pseudonym = u.config.PseudonymPrefix + strconv.Itoa(user.ID)
+		if res.LogsAffected, err = u.logs.PseudonymizeUser(ctx, user.Username, res.Pseudonym); err != nil {
+			return nil, err
+		}
+		if res.AuditEventsAffected, err = u.audits.PseudonymizeUser(ctx, user.Username, res.Pseudonym); err != nil {
+			return nil, err
+		}
+	case proto.ErasureMode_ERASURE_MODE_DELETE:
+		if res.LogsAffected, err = u.logs.DeleteByUser(ctx, user.Username); err != nil {
+			return nil, err
+		}
+		if res.AuditEventsAffected, err = u.audits.DeleteByUser(ctx, user.Username); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, status.Error(codes.InvalidArgument, "unknown erasure mode")
+	}
+	if err := u.users.DeleteUser(user.OrgID, user.ID); err != nil {
+		return nil, err
+	}
+	for _, limiter := range u.limiters {
+		if err := limiter.Reset(ctx, "user:"+user.Username, "2fa:"+user.Username); err != nil {
+			log.Printf("privacy: failed to reset rate limit for erased user id=%d: %v", user.ID, err)
+		}
+	}
+
+	// 消去したユーザーの名前は記録しない
+	target := "user:" + res.Pseudonym
+	if res.Pseudonym == "" {
+		target = fmt.Sprintf("user:#%d", user.ID)
+	}
+	log.Printf("security: user erased id=%d mode=%s by=%q", user.ID, erasureModeName(mode), identity.Username)
+	audit.RecordOrLog(ctx, u.recorder, audit.Event{
+		Actor:  identity.Username,
+		Action: audit.ActionUserErased,
+		Target: target,
+		After: map[string]any{
+			"mode":         erasureModeName(mode),
+			"logs":         res.LogsAffected,
+			"audit_events": res.AuditEventsAffected,
+		},
+	})
+	return res, nil
+}
+
+type exportManifest struct {
+	UserName   string            `json:"user_name"`
+	ExportedAt time.Time         `json:"exported_at"`
+	ExportedBy string            `json:"exported_by"`
+	Files      []string          `json:"files"`
+	NotStored  map[string]string `json:"not_stored"`
+}
+
+type exportProfile struct {
+	UserName    string `json:"user_name"`
+	Email       string `json:"email,omitempty"`
+	Role        string `json:"role"`
+	Org         string `json:"org"`
+	OrgName     string `json:"org_name"`
+	TOTPEnabled bool   `json:"totp_enabled"`
+}
+
+type exportLog struct {
+	ID         int64  `json:"id"`
+	Status     string `json:"status"`
+	Feeling    string `json:"feeling"`
+	Timestamp  string `json:"timestamp"`
+	Visibility string `json:"visibility"`
+}
+
+type exportAuditEvent struct {
+	ID         int64           `json:"id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	Target     string          `json:"target,omitempty"`
+	Source     string          `json:"source,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+}
+
+// buildExportArchive は manifest.json と各データの JSON を zip にまとめる
+func buildExportArchive(now time.Time, exportedBy string, user *repository.User, org *repository.Organization,
+	logs []*proto.LogEntry, events []*repository.AuditEvent) ([]byte, error) {
+	exportedLogs := []exportLog{}
+	for _, entry := range logs {
+		exportedLogs = append(exportedLogs, exportLog{
+			ID:         entry.Id,
+			Status:     entry.Status,
+			Feeling:    entry.Feeling,
+			Timestamp:  entry.Timestamp,
+			Visibility: strings.ToLower(strings.TrimPrefix(entry.Visibility.String(), "VISIBILITY_")),
+		})
+	}
+	exportedEvents := []exportAuditEvent{}
+	for _, e := range events {
+		exportedEvents = append(exportedEvents, exportAuditEvent{
+			ID:         e.ID,
+			OccurredAt: e.OccurredAt,
+			Actor:      e.Actor,
+			Action:     e.Action,
+			Target:     e.Target,
+			Source:     e.Source,
+			Before:     e.Before,
+			After:      e.After,
+		})
+	}
+
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", exportProfile{
+			UserName:    user.Username,
+			Email:       user.Email,
+			Role:        user.Role,
+			Org:         org.Slug,
+			OrgName:     org.Name,
+			TOTPEnabled: user.TOTPEnabled,
+		}},
+		{"logs.json", exportedLogs},
+		{"audit_events.json", exportedEvents},
+	}
+	manifest := exportManifest{
+		UserName:   user.Username,
+		ExportedAt: now.UTC(),
+		ExportedBy: exportedBy,
+		NotStored: map[string]string{
+			"comments":  "このバージョンではコメント機能がないため、保存しているデータはありません",
+			"reactions": "このバージョンではリアクション機能がないため、保存しているデータはありません",
+			"sessions":  "Web のセッションは Web サーバーのメモリ上にだけあり、有効期限で消えます。ユーザーを消去すると CLI のトークンも使えなくなります",
+		},
+	}
+	for _, f := range files {
+		manifest.Files = append(manifest.Files, f.name)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	write := func(name string, data any) error {
+		content, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return err
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: now})
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(content)
+		return err
+	}
+	if err := write("manifest.json", manifest); err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if err := write(f.name, f.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
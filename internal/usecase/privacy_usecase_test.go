@@ -0,0 +1,223 @@
+package usecase
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/gensan0223/snulog/internal/audit"
+	"github.com/gensan0223/snulog/internal/ratelimit"
+	"github.com/gensan0223/snulog/internal/repository"
+	"github.com/gensan0223/snulog/internal/tenant"
+	"github.com/gensan0223/snulog/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type privacyFixture struct {
+	uc      PrivacyUsecase
+	logUc   LogUsecase
+	users   *stubUserRepository
+	audits  *repository.InMemoryAuditRepository
+	limiter *ratelimit.Limiter
+}
+
+// newTestPrivacyUsecase は既定の組織の alice（visibility 違いのログ 3 件）、bob、管理者の root と、
+// 別の組織の管理者 boss を用意する
+func newTestPrivacyUsecase(t *testing.T, config ErasureConfig) *privacyFixture {
+	t.Helper()
+	users := &stubUserRepository{users: map[string]*repository.User{
+		"alice": {ID: 7, OrgID: tenant.DefaultOrgID, Username: "alice", Email: "alice@example.com", Role: repository.RoleMember},
+		"bob":   {ID: 8, OrgID: tenant.DefaultOrgID, Username: "bob", Role: repository.RoleMember},
+		"root":  {ID: 9, OrgID: tenant.DefaultOrgID, Username: "root", Role: repository.RoleAdmin},
+		"boss":  {ID: 10, OrgID: 2, Username: "boss", Role: repository.RoleAdmin},
+	}}
+	logs := repository.NewInMemoryLogRepository()
+	audits := repository.NewInMemoryAuditRepository()
+	recorder := audit.NewRecorder(audits)
+	limiter := ratelimit.NewLimiter("login", repository.NewInMemoryAttemptRepository(), ratelimit.DefaultLoginPolicy)
+
+	f := &privacyFixture{
+		uc:      NewPrivacyUsecase(logs, audits, users, repository.NewInMemoryOrganizationRepository(), recorder, config, limiter),
+		logUc:   NewLogUsecase(logs, recorder),
+		users:   users,
+		audits:  audits,
+		limiter: limiter,
+	}
+	addVisibilityFixtures(t, f.logUc)
+	_, err := f.logUc.AddLogs(withIdentity("bob", repository.RoleMember), &proto.LogEntry{Status: "bob's day", Feeling: "😊"})
+	require.NoError(t, err)
+	return f
+}
+
+func readArchive(t *testing.T, archive []byte) map[string][]byte {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	require.NoError(t, err)
+	files := map[string][]byte{}
+	for _, file := range zr.File {
+		r, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(r)
+		require.NoError(t, err)
+		files[file.Name] = content
+	}
+	return files
+}
+
+func TestExportMyData(t *testing.T) {
+	f := newTestPrivacyUsecase(t, DefaultErasureConfig)
+
+	res, err := f.uc.ExportMyData(withIdentity("alice", repository.RoleMember), &proto.ExportMyDataRequest{})
+	require.NoError(t, err)
+	assert.Contains(t, res.FileName, "alice")
+
+	files := readArchive(t, res.Archive)
+	assert.ElementsMatch(t, []string{"manifest.json", "profile.json", "logs.json", "audit_events.json"}, keys(files))
+
+	var profile map[string]any
+	require.NoError(t, json.Unmarshal(files["profile.json"], &profile))
+	assert.Equal(t, "alice@example.com", profile["email"])
+	assert.NotContains(t, string(files["profile.json"]), "password")
+
+	var logs []exportLog
+	require.NoError(t, json.Unmarshal(files["logs.json"], &logs))
+	assert.Len(t, logs, 3, "自分のログは公開範囲に関係なくすべて含める")
+	assert.Equal(t, "private", logs[2].Visibility)
+	assert.NotContains(t, string(files["logs.json"]), "bob's day")
+
+	var events []exportAuditEvent
+	require.NoError(t, json.Unmarshal(files["audit_events.json"], &events))
+	assert.Len(t, events, 3)
+
+	var manifest exportManifest
+	require.NoError(t, json.Unmarshal(files["manifest.json"], &manifest))
+	assert.Contains(t, manifest.NotStored, "comments")
+
+	exported, err := f.audits.List(context.Background(), repository.AuditFilter{Action: audit.ActionUserDataExported})
+	require.NoError(t, err)
+	require.Len(t, exported, 1)
+	assert.Equal(t, "user:alice", exported[0].Target)
+}
+
+func TestExportMyData_Permissions(t *testing.T) {
+	f := newTestPrivacyUsecase(t, DefaultErasureConfig)
+
+	_, err := f.uc.ExportMyData(context.Background(), &proto.ExportMyDataRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = f.uc.ExportMyData(withIdentity("bob", repository.RoleMember), &proto.ExportMyDataRequest{UserName: "alice"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = f.uc.ExportMyData(withOrgIdentity("boss", repository.RoleAdmin, 2), &proto.ExportMyDataRequest{UserName: "alice"})
+	assert.Equal(t, codes.NotFound, status.Code(err), "他の組織のユーザーは見つからない扱い")
+
+	res, err := f.uc.ExportMyData(withIdentity("root", repository.RoleAdmin), &proto.ExportMyDataRequest{UserName: "alice"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, res.Archive)
+}
+
+func TestEraseUser_PseudonymizeKeepsTeamMood(t *testing.T) {
+	f := newTestPrivacyUsecase(t, DefaultErasureConfig)
+	root := withIdentity("root", repository.RoleAdmin)
+	before, err := f.logUc.GetMoodStats(root, &proto.MoodStatsRequest{})
+	require.NoError(t, err)
+
+	// ロック中のログイン制限も消える
+	for range ratelimit.DefaultLoginPolicy.MaxAttempts {
+		require.NoError(t, f.limiter.Record(context.Background(), "user:alice"))
+	}
+
+	res, err := f.uc.EraseUser(root, &proto.EraseUserRequest{UserName: "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, proto.ErasureMode_ERASURE_MODE_PSEUDONYMIZE, res.Mode, "省略するとサーバーの既定")
+	assert.Equal(t, "former-member-7", res.Pseudonym)
+	assert.EqualValues(t, 3, res.LogsAffected)
+	assert.EqualValues(t, 3, res.AuditEventsAffected)
+	assert.NotContains(t, f.users.users, "alice")
+
+	after, err := f.logUc.GetMoodStats(root, &proto.MoodStatsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, before.Team, after.Team, "チームの集計は変わらない")
+	for _, mood := range after.ByUser {
+		assert.NotEqual(t, "alice", mood.UserName)
+	}
+
+	logs, err := f.logUc.SearchLogs(root, &proto.SearchLogsRequest{UserName: "former-member-7"})
+	require.NoError(t, err)
+	require.Len(t, logs.Logs, 2, "private のログは仮名化後も誰にも見えない")
+	assert.Equal(t, []string{"", ""}, statuses(logs.Logs))
+
+	events, err := f.audits.List(context.Background(), repository.AuditFilter{})
+	require.NoError(t, err)
+	for _, e := range events {
+		raw, _ := json.Marshal(e)
+		assert.NotContains(t, string(raw), "alice", "監査ログに元の名前を残さない: %s", raw)
+	}
+
+	allowed, _, err := f.limiter.Allow(context.Background(), "user:alice")
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestEraseUser_Delete(t *testing.T) {
+	f := newTestPrivacyUsecase(t, ErasureConfig{DefaultMode: proto.ErasureMode_ERASURE_MODE_DELETE})
+	root := withIdentity("root", repository.RoleAdmin)
+
+	res, err := f.uc.EraseUser(root, &proto.EraseUserRequest{UserName: "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, proto.ErasureMode_ERASURE_MODE_DELETE, res.Mode)
+	assert.Empty(t, res.Pseudonym)
+	assert.EqualValues(t, 3, res.LogsAffected)
+
+	stats, err := f.logUc.GetMoodStats(root, &proto.MoodStatsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, []*proto.MoodCount{{Feeling: "😊", Count: 1}}, stats.Team)
+
+	events, err := f.audits.List(context.Background(), repository.AuditFilter{})
+	require.NoError(t, err)
+	require.Len(t, events, 2, "bob の投稿と消去の記録だけが残る")
+	assert.Equal(t, audit.ActionUserErased, events[0].Action)
+	assert.Equal(t, "user:#7", events[0].Target)
+}
+
+func TestEraseUser_Permissions(t *testing.T) {
+	f := newTestPrivacyUsecase(t, DefaultErasureConfig)
+
+	_, err := f.uc.EraseUser(withIdentity("bob", repository.RoleMember), &proto.EraseUserRequest{UserName: "alice"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = f.uc.EraseUser(withIdentity("root", repository.RoleAdmin), &proto.EraseUserRequest{UserName: "root"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = f.uc.EraseUser(withOrgIdentity("boss", repository.RoleAdmin, 2), &proto.EraseUserRequest{UserName: "alice"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Contains(t, f.users.users, "alice")
+}
+
+func TestParseErasureMode(t *testing.T) {
+	for value, want := range map[string]proto.ErasureMode{
+		"":             proto.ErasureMode_ERASURE_MODE_UNSPECIFIED,
+		"pseudonymize": proto.ErasureMode_ERASURE_MODE_PSEUDONYMIZE,
+		"delete":       proto.ErasureMode_ERASURE_MODE_DELETE,
+	} {
+		got, err := ParseErasureMode(value)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	_, err := ParseErasureMode("shred")
+	assert.Error(t, err)
+}
+
+func keys(files map[string][]byte) []string {
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	return names
+}
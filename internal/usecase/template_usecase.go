@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/audit"
+	"github.com/gensan0223/snulog/internal/auth"
+	"github.com/gensan0223/snulog/internal/repository"
+	"github.com/gensan0223/snulog/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// 1 つのテンプレートで尋ねる項目の上限
+	maxTemplateFields = 20
+	maxTemplateLabel  = 100
+)
+
+// テンプレートの項目のキー（add の --field のキーと front matter のキーに使う）
+var templateKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+type TemplateUsecase interface {
+	ListTemplates(ctx context.Context, req *proto.ListTemplatesRequest) (*proto.ListTemplatesResponse, error)
+	SaveTemplate(ctx context.Context, req *proto.Template) (*proto.Template, error)
+	DeleteTemplate(ctx context.Context, req *proto.DeleteTemplateRequest) (*proto.DeleteTemplateResponse, error)
+}
+
+type templateUsecase struct {
+	templates repository.TemplateRepository
+	teams     repository.TeamRepository
+	recorder  audit.Recorder
+}
+
+func NewTemplateUsecase(templates repository.TemplateRepository, teams repository.TeamRepository, recorder audit.Recorder) TemplateUsecase {
+	return &templateUsecase{
+		templates: templates,
+		teams:     teams,
+		recorder:  recorder,
+	}
+}
+
+// ListTemplates はチームのテンプレートを返す。ログの取得と同じく未認証でも呼び出せる
+func (u *templateUsecase) ListTemplates(ctx context.Context, req *proto.ListTemplatesRequest) (*proto.ListTemplatesResponse, error) {
+	templates, err := u.templates.List(ctx, teamOrDefault(req.Team))
+	if err != nil {
+		return nil, err
+	}
+	res := &proto.ListTemplatesResponse{}
+	for _, t := range templates {
+		res.Templates = append(res.Templates, toProtoTemplate(t))
+	}
+	return res, nil
+}
+
+// SaveTemplate は組織のマネージャーと管理者だけが呼び出せる。同じ名前のテンプレートは置き換える
+func (u *templateUsecase) SaveTemplate(ctx context.Context, req *proto.Template) (*proto.Template, error) {
+	identity, err := auth.RequireRole(ctx, repository.RoleManager, repository.RoleAdmin)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, "manager role required")
+	}
+	team := teamOrDefault(req.Team)
+	if err := u.requireTeam(ctx, team); err != nil {
+		return nil, err
+	}
+	if !slugPattern.MatchString(req.Name) {
+		return nil, status.Error(codes.InvalidArgument, "name must be lowercase letters, digits and hyphens")
+	}
+	fields, err := templateFields(req.Fields)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	t := &repository.Template{Team: team, Name: req.Name, Fields: fields, UpdatedBy: identity.Username}
+	if err := u.templates.Save(ctx, t); err != nil {
+		return nil, err
+	}
+	audit.RecordOrLog(ctx, u.recorder, audit.Event{
+		Actor:  identity.Username,
+		Action: audit.ActionTemplateSaved,
+		Target: templateTarget(team, req.Name),
+		After:  fields,
+	})
+	return toProtoTemplate(t), nil
+}
+
+// DeleteTemplate は組織のマネージャーと管理者だけが呼び出せる
+func (u *templateUsecase) DeleteTemplate(ctx context.Context, req *proto.DeleteTemplateRequest) (*proto.DeleteTemplateResponse, error) {
+	identity, err := auth.RequireRole(ctx, repository.RoleManager, repository.RoleAdmin)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, "manager role required")
+	}
+	team := teamOrDefault(req.Team)
+	err = u.templates.Delete(ctx, team, req.Name)
+	if errors.Is(err, repository.ErrTemplateNotFound) {
+		return nil, status.Error(codes.NotFound, "template not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	audit.RecordOrLog(ctx, u.recorder, audit.Event{
+		Actor:  identity.Username,
+		Action: audit.ActionTemplateDeleted,
+		Target: templateTarget(team, req.Name),
+	})
+	return &proto.DeleteTemplateResponse{}, nil
+}
+
+func (u *templateUsecase) requireTeam(ctx context.Context, team string) error {
+	_, err := u.teams.FindBySlug(ctx, team)
+	if errors.Is(err, repository.ErrTeamNotFound) {
+		return status.Error(codes.NotFound, "team not found")
+	}
+	return err
+}
+
+// templateFields は項目を確かめる。ラベルを省略した項目はキーをラベルにする
+func templateFields(fields []*proto.TemplateField) ([]repository.TemplateField, error) {
+	if len(fields) == 0 || len(fields) > maxTemplateFields {
+		return nil, fmt.Errorf("a template needs 1 to %d fields", maxTemplateFields)
+	}
+	result := make([]repository.TemplateField, 0, len(fields))
+	seen := map[string]bool{}
+	for _, f := range fields {
+		if !templateKeyPattern.MatchString(f.Key) {
+			return nil, fmt.Errorf("field key must be lowercase letters, digits and underscores: %q", f.Key)
+		}
+		if seen[f.Key] {
+			return nil, fmt.Errorf("duplicate field key %q", f.Key)
+		}
+		seen[f.Key] = true
+		label := f.Label
+		if label == "" {
+			label = f.Key
+		}
+		if len([]rune(label)) > maxTemplateLabel {
+			return nil, fmt.Errorf("field label must be at most %d characters", maxTemplateLabel)
+		}
+		result = append(result, repository.TemplateField{Key: f.Key, Label: label, Optional: f.Optional})
+	}
+	return result, nil
+}
+
+func teamOrDefault(team string) string {
+	if team == "" {
+		return defaultTeamSlug
+	}
+	return team
+}
+
+func templateTarget(team, name string) string {
+	return "template:" + team + "/" + name
+}
+
+func toProtoTemplate(t *repository.Template) *proto.Template {
+	res := &proto.Template{
+		Team:      t.Team,
+		Name:      t.Name,
+		UpdatedBy: t.UpdatedBy,
+		UpdatedAt: t.UpdatedAt.Format(time.RFC3339),
+	}
+	for _, f := range t.Fields {
+		res.Fields = append(res.Fields, &proto.TemplateField{Key: f.Key, Label: f.Label, Optional: f.Optional})
+	}
+	return res
+}
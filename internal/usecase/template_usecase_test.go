@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gensan0223/snulog/internal/audit"
+	"github.com/gensan0223/snulog/internal/repository"
+	"github.com/gensan0223/snulog/internal/tenant"
+	"github.com/gensan0223/snulog/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newTestTemplateUsecase(t *testing.T) (TemplateUsecase, *repository.InMemoryAuditRepository) {
+	t.Helper()
+	teams := repository.NewInMemoryTeamRepository()
+	require.NoError(t, teams.Create(tenant.WithOrg(context.Background(), 1), &repository.Team{Slug: defaultTeamSlug}))
+	auditRepo := repository.NewInMemoryAuditRepository()
+	return NewTemplateUsecase(repository.NewInMemoryTemplateRepository(), teams, audit.NewRecorder(auditRepo)), auditRepo
+}
+
+var standupFields = []*proto.TemplateField{
+	{Key: "yesterday", Label: "昨日やったこと"},
+	{Key: "today", Label: "今日やること"},
+	{Key: "blockers", Optional: true},
+}
+
+func TestSaveTemplate(t *testing.T) {
+	uc, auditRepo := newTestTemplateUsecase(t)
+	manager := withIdentity("carol", repository.RoleManager)
+
+	saved, err := uc.SaveTemplate(manager, &proto.Template{Name: "standup", Fields: standupFields})
+	require.NoError(t, err)
+	assert.Equal(t, "default", saved.Team, "チームを省略すると default")
+	assert.Equal(t, "carol", saved.UpdatedBy)
+	assert.Equal(t, "blockers", saved.Fields[2].Label, "ラベルを省略するとキー")
+
+	res, err := uc.ListTemplates(context.Background(), &proto.ListTemplatesRequest{})
+	require.NoError(t, err)
+	require.Len(t, res.Templates, 1, "一覧は未認証でも読める")
+	assert.Len(t, res.Templates[0].Fields, 3)
+
+	events, err := auditRepo.List(context.Background(), repository.AuditFilter{Action: audit.ActionTemplateSaved})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "template:default/standup", events[0].Target)
+
+	_, err = uc.DeleteTemplate(manager, &proto.DeleteTemplateRequest{Name: "standup"})
+	assert.NoError(t, err)
+	_, err = uc.DeleteTemplate(manager, &proto.DeleteTemplateRequest{Name: "standup"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestSaveTemplate_Rejected(t *testing.T) {
+	uc, _ := newTestTemplateUsecase(t)
+	manager := withIdentity("carol", repository.RoleManager)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		req  *proto.Template
+		code codes.Code
+	}{
+		{"member", withIdentity("alice", repository.RoleMember), &proto.Template{Name: "standup", Fields: standupFields}, codes.PermissionDenied},
+		{"anonymous", context.Background(), &proto.Template{Name: "standup", Fields: standupFields}, codes.PermissionDenied},
+		{"unknown team", manager, &proto.Template{Team: "ghost", Name: "standup", Fields: standupFields}, codes.NotFound},
+		{"bad name", manager, &proto.Template{Name: "Stand Up", Fields: standupFields}, codes.InvalidArgument},
+		{"no fields", manager, &proto.Template{Name: "standup"}, codes.InvalidArgument},
+		{"bad key", manager, &proto.Template{Name: "standup", Fields: []*proto.TemplateField{{Key: "Today"}}}, codes.InvalidArgument},
+		{"duplicate key", manager, &proto.Template{Name: "standup", Fields: []*proto.TemplateField{{Key: "today"}, {Key: "today"}}}, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := uc.SaveTemplate(tt.ctx, tt.req)
+			assert.Equal(t, tt.code, status.Code(err))
+		})
+	}
+
+	_, err := uc.DeleteTemplate(withIdentity("alice", repository.RoleMember), &proto.DeleteTemplateRequest{Name: "standup"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
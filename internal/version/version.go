@@ -0,0 +1,40 @@
+// Package version は snulog の CLI とサーバーのバージョンと、両者の互換性を表す
+package version
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// Version はリリースのバージョン。go build -ldflags "-X github.com/gensan0223/snulog/internal/version.Version=v1.2.3" で埋め込む
+var Version = "dev"
+
+const (
+	// API は CLI とサーバーの間の RPC の互換性の番号。古い CLI が使えなくなる変更をしたら上げる
+	API = 1
+	// MinClientAPI はサーバーが受け付ける CLI の API の最小
+	MinClientAPI = 1
+)
+
+// String は埋め込んだバージョンを返す。埋め込んでいなければ go install で付いたモジュールのバージョン
+func String() string {
+	if Version != "dev" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return Version
+}
+
+// Check は API が clientAPI の CLI と、API が serverAPI で minClientAPI 以上の CLI を受け付けるサーバーが
+// 一緒に使えるかを返す。CLI がサーバーより新しいときも、新しい RPC が使えないのでエラーにする
+func Check(clientAPI, serverAPI, minClientAPI int32) error {
+	if clientAPI < minClientAPI {
+		return fmt.Errorf("client API %d is older than the server requires (%d or later); upgrade snulog", clientAPI, minClientAPI)
+	}
+	if clientAPI > serverAPI {
+		return fmt.Errorf("client API %d is newer than the server (%d); some commands may fail until the server is upgraded", clientAPI, serverAPI)
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	assert.NoError(t, Check(API, API, MinClientAPI))
+	assert.NoError(t, Check(2, 3, 1))
+	assert.ErrorContains(t, Check(1, 3, 2), "upgrade snulog")
+	assert.ErrorContains(t, Check(3, 2, 1), "newer than the server")
+}
@@ -21,6 +21,223 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// ログの公開範囲
+type Visibility int32
+
+const (
+	// team として扱う
+	Visibility_VISIBILITY_UNSPECIFIED Visibility = 0
+	Visibility_VISIBILITY_TEAM        Visibility = 1
+	// 本人とマネージャー・管理者だけが読める
+	Visibility_VISIBILITY_MANAGERS Visibility = 2
+	// 本人だけが読める
+	Visibility_VISIBILITY_PRIVATE Visibility = 3
+)
+
+// Enum value maps for Visibility.
+var (
+	Visibility_name = map[int32]string{
+		0: "VISIBILITY_UNSPECIFIED",
+		1: "VISIBILITY_TEAM",
+		2: "VISIBILITY_MANAGERS",
+		3: "VISIBILITY_PRIVATE",
+	}
+	Visibility_value = map[string]int32{
+		"VISIBILITY_UNSPECIFIED": 0,
+		"VISIBILITY_TEAM":        1,
+		"VISIBILITY_MANAGERS":    2,
+		"VISIBILITY_PRIVATE":     3,
+	}
+)
+
+func (x Visibility) Enum() *Visibility {
+	p := new(Visibility)
+	*p = x
+	return p
+}
+
+func (x Visibility) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Visibility) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_logs_proto_enumTypes[0].Descriptor()
+}
+
+func (Visibility) Type() protoreflect.EnumType {
+	return &file_proto_logs_proto_enumTypes[0]
+}
+
+func (x Visibility) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Visibility.Descriptor instead.
+func (Visibility) EnumDescriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{0}
+}
+
+type ErasureMode int32
+
+const (
+	// サーバーの既定（SNULOG_ERASURE_MODE）に従う
+	ErasureMode_ERASURE_MODE_UNSPECIFIED ErasureMode = 0
+	// 投稿者を仮名に置き換えて本文を消す。気分はチームの集計に残る
+	ErasureMode_ERASURE_MODE_PSEUDONYMIZE ErasureMode = 1
+	ErasureMode_ERASURE_MODE_DELETE       ErasureMode = 2
+)
+
+// Enum value maps for ErasureMode.
+var (
+	ErasureMode_name = map[int32]string{
+		0: "ERASURE_MODE_UNSPECIFIED",
+		1: "ERASURE_MODE_PSEUDONYMIZE",
+		2: "ERASURE_MODE_DELETE",
+	}
+	ErasureMode_value = map[string]int32{
+		"ERASURE_MODE_UNSPECIFIED":  0,
+		"ERASURE_MODE_PSEUDONYMIZE": 1,
+		"ERASURE_MODE_DELETE":       2,
+	}
+)
+
+func (x ErasureMode) Enum() *ErasureMode {
+	p := new(ErasureMode)
+	*p = x
+	return p
+}
+
+func (x ErasureMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ErasureMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_logs_proto_enumTypes[1].Descriptor()
+}
+
+func (ErasureMode) Type() protoreflect.EnumType {
+	return &file_proto_logs_proto_enumTypes[1]
+}
+
+func (x ErasureMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ErasureMode.Descriptor instead.
+func (ErasureMode) EnumDescriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{1}
+}
+
+type LogEventType int32
+
+const (
+	LogEventType_LOG_EVENT_TYPE_UNSPECIFIED LogEventType = 0
+	LogEventType_LOG_EVENT_TYPE_ADDED       LogEventType = 1
+	LogEventType_LOG_EVENT_TYPE_DELETED     LogEventType = 2
+)
+
+// Enum value maps for LogEventType.
+var (
+	LogEventType_name = map[int32]string{
+		0: "LOG_EVENT_TYPE_UNSPECIFIED",
+		1: "LOG_EVENT_TYPE_ADDED",
+		2: "LOG_EVENT_TYPE_DELETED",
+	}
+	LogEventType_value = map[string]int32{
+		"LOG_EVENT_TYPE_UNSPECIFIED": 0,
+		"LOG_EVENT_TYPE_ADDED":       1,
+		"LOG_EVENT_TYPE_DELETED":     2,
+	}
+)
+
+func (x LogEventType) Enum() *LogEventType {
+	p := new(LogEventType)
+	*p = x
+	return p
+}
+
+func (x LogEventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LogEventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_logs_proto_enumTypes[2].Descriptor()
+}
+
+func (LogEventType) Type() protoreflect.EnumType {
+	return &file_proto_logs_proto_enumTypes[2]
+}
+
+func (x LogEventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LogEventType.Descriptor instead.
+func (LogEventType) EnumDescriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{2}
+}
+
+// シェルの補完で候補にするもの
+type CompletionKind int32
+
+const (
+	CompletionKind_COMPLETION_KIND_UNSPECIFIED CompletionKind = 0
+	CompletionKind_COMPLETION_KIND_USER        CompletionKind = 1
+	CompletionKind_COMPLETION_KIND_TEAM        CompletionKind = 2
+	// ステータスの #タグ
+	CompletionKind_COMPLETION_KIND_TAG CompletionKind = 3
+	// ステータスの課題の参照（PROJ-123 や #123）
+	CompletionKind_COMPLETION_KIND_TICKET CompletionKind = 4
+	CompletionKind_COMPLETION_KIND_LOG_ID CompletionKind = 5
+)
+
+// Enum value maps for CompletionKind.
+var (
+	CompletionKind_name = map[int32]string{
+		0: "COMPLETION_KIND_UNSPECIFIED",
+		1: "COMPLETION_KIND_USER",
+		2: "COMPLETION_KIND_TEAM",
+		3: "COMPLETION_KIND_TAG",
+		4: "COMPLETION_KIND_TICKET",
+		5: "COMPLETION_KIND_LOG_ID",
+	}
+	CompletionKind_value = map[string]int32{
+		"COMPLETION_KIND_UNSPECIFIED": 0,
+		"COMPLETION_KIND_USER":        1,
+		"COMPLETION_KIND_TEAM":        2,
+		"COMPLETION_KIND_TAG":         3,
+		"COMPLETION_KIND_TICKET":      4,
+		"COMPLETION_KIND_LOG_ID":      5,
+	}
+)
+
+func (x CompletionKind) Enum() *CompletionKind {
+	p := new(CompletionKind)
+	*p = x
+	return p
+}
+
+func (x CompletionKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CompletionKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_logs_proto_enumTypes[3].Descriptor()
+}
+
+func (CompletionKind) Type() protoreflect.EnumType {
+	return &file_proto_logs_proto_enumTypes[3]
+}
+
+func (x CompletionKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CompletionKind.Descriptor instead.
+func (CompletionKind) EnumDescriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{3}
+}
+
 type FetchRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	TeamId        string                 `protobuf:"bytes,1,opt,name=team_id,json=teamId,proto3" json:"team_id,omitempty"`
@@ -66,11 +283,17 @@ func (x *FetchRequest) GetTeamId() string {
 }
 
 type LogEntry struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserName      string                 `protobuf:"bytes,1,opt,name=user_name,json=userName,proto3" json:"user_name,omitempty"`
-	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
-	Feeling       string                 `protobuf:"bytes,3,opt,name=feeling,proto3" json:"feeling,omitempty"`
-	Timestamp     string                 `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	UserName   string                 `protobuf:"bytes,1,opt,name=user_name,json=userName,proto3" json:"user_name,omitempty"`
+	Status     string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Feeling    string                 `protobuf:"bytes,3,opt,name=feeling,proto3" json:"feeling,omitempty"`
+	Timestamp  string                 `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Id         int64                  `protobuf:"varint,5,opt,name=id,proto3" json:"id,omitempty"`
+	Visibility Visibility             `protobuf:"varint,6,opt,name=visibility,proto3,enum=logs.Visibility" json:"visibility,omitempty"`
+	// クライアントが生成する再送用のキー。同じ組織で同じキーのログは一度だけ追加する
+	IdempotencyKey string `protobuf:"bytes,7,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// snulog note --remote で保存したメモ。常に private で、タイムラインや検索、集計、カレンダー、書き出し、補完には含めない
+	Note          bool `protobuf:"varint,8,opt,name=note,proto3" json:"note,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -133,9 +356,39 @@ func (x *LogEntry) GetTimestamp() string {
 	return ""
 }
 
+func (x *LogEntry) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *LogEntry) GetVisibility() Visibility {
+	if x != nil {
+		return x.Visibility
+	}
+	return Visibility_VISIBILITY_UNSPECIFIED
+}
+
+func (x *LogEntry) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *LogEntry) GetNote() bool {
+	if x != nil {
+		return x.Note
+	}
+	return false
+}
+
 type AddResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Message string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	// 同じ idempotency_key のログが追加済みだったため、何もしなかった
+	Duplicate     bool `protobuf:"varint,2,opt,name=duplicate,proto3" json:"duplicate,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -177,6 +430,13 @@ func (x *AddResponse) GetMessage() string {
 	return ""
 }
 
+func (x *AddResponse) GetDuplicate() bool {
+	if x != nil {
+		return x.Duplicate
+	}
+	return false
+}
+
 type FetchResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Logs          []*LogEntry            `protobuf:"bytes,1,rep,name=logs,proto3" json:"logs,omitempty"`
@@ -221,26 +481,2865 @@ func (x *FetchResponse) GetLogs() []*LogEntry {
 	return nil
 }
 
-var File_proto_logs_proto protoreflect.FileDescriptor
+type DeleteLogRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_proto_logs_proto_rawDesc = "" +
-	"\n" +
-	"\x10proto/logs.proto\x12\x04logs\"'\n" +
-	"\fFetchRequest\x12\x17\n" +
-	"\ateam_id\x18\x01 \x01(\tR\x06teamId\"w\n" +
-	"\bLogEntry\x12\x1b\n" +
-	"\tuser_name\x18\x01 \x01(\tR\buserName\x12\x16\n" +
-	"\x06status\x18\x02 \x01(\tR\x06status\x12\x18\n" +
-	"\afeeling\x18\x03 \x01(\tR\afeeling\x12\x1c\n" +
-	"\ttimestamp\x18\x04 \x01(\tR\ttimestamp\"'\n" +
-	"\vAddResponse\x12\x18\n" +
-	"\amessage\x18\x01 \x01(\tR\amessage\"3\n" +
-	"\rFetchResponse\x12\"\n" +
-	"\x04logs\x18\x01 \x03(\v2\x0e.logs.LogEntryR\x04logs2p\n" +
-	"\n" +
-	"LogService\x12,\n" +
-	"\aAddLogs\x12\x0e.logs.LogEntry\x1a\x11.logs.AddResponse\x124\n" +
-	"\tFetchLogs\x12\x12.logs.FetchRequest\x1a\x13.logs.FetchResponseB\bZ\x06/protob\x06proto3"
+func (x *DeleteLogRequest) Reset() {
+	*x = DeleteLogRequest{}
+	mi := &file_proto_logs_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteLogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteLogRequest) ProtoMessage() {}
+
+func (x *DeleteLogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteLogRequest.ProtoReflect.Descriptor instead.
+func (*DeleteLogRequest) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DeleteLogRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteLogResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteLogResponse) Reset() {
+	*x = DeleteLogResponse{}
+	mi := &file_proto_logs_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteLogResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteLogResponse) ProtoMessage() {}
+
+func (x *DeleteLogResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteLogResponse.ProtoReflect.Descriptor instead.
+func (*DeleteLogResponse) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DeleteLogResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ListAuditEventsRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Actor  string                 `protobuf:"bytes,1,opt,name=actor,proto3" json:"actor,omitempty"`
+	Action string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	Target string                 `protobuf:"bytes,3,opt,name=target,proto3" json:"target,omitempty"`
+	// RFC3339
+	Since         string `protobuf:"bytes,4,opt,name=since,proto3" json:"since,omitempty"`
+	Until         string `protobuf:"bytes,5,opt,name=until,proto3" json:"until,omitempty"`
+	Limit         int32  `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAuditEventsRequest) Reset() {
+	*x = ListAuditEventsRequest{}
+	mi := &file_proto_logs_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAuditEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAuditEventsRequest) ProtoMessage() {}
+
+func (x *ListAuditEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAuditEventsRequest.ProtoReflect.Descriptor instead.
+func (*ListAuditEventsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListAuditEventsRequest) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *ListAuditEventsRequest) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *ListAuditEventsRequest) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *ListAuditEventsRequest) GetSince() string {
+	if x != nil {
+		return x.Since
+	}
+	return ""
+}
+
+func (x *ListAuditEventsRequest) GetUntil() string {
+	if x != nil {
+		return x.Until
+	}
+	return ""
+}
+
+func (x *ListAuditEventsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type AuditEvent struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Id         int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	OccurredAt string                 `protobuf:"bytes,2,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	Actor      string                 `protobuf:"bytes,3,opt,name=actor,proto3" json:"actor,omitempty"`
+	Action     string                 `protobuf:"bytes,4,opt,name=action,proto3" json:"action,omitempty"`
+	Target     string                 `protobuf:"bytes,5,opt,name=target,proto3" json:"target,omitempty"`
+	Source     string                 `protobuf:"bytes,6,opt,name=source,proto3" json:"source,omitempty"`
+	// JSON
+	Before        string `protobuf:"bytes,7,opt,name=before,proto3" json:"before,omitempty"`
+	After         string `protobuf:"bytes,8,opt,name=after,proto3" json:"after,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuditEvent) Reset() {
+	*x = AuditEvent{}
+	mi := &file_proto_logs_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditEvent) ProtoMessage() {}
+
+func (x *AuditEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditEvent.ProtoReflect.Descriptor instead.
+func (*AuditEvent) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *AuditEvent) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *AuditEvent) GetOccurredAt() string {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetBefore() string {
+	if x != nil {
+		return x.Before
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetAfter() string {
+	if x != nil {
+		return x.After
+	}
+	return ""
+}
+
+type ListAuditEventsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Events        []*AuditEvent          `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAuditEventsResponse) Reset() {
+	*x = ListAuditEventsResponse{}
+	mi := &file_proto_logs_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAuditEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAuditEventsResponse) ProtoMessage() {}
+
+func (x *ListAuditEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAuditEventsResponse.ProtoReflect.Descriptor instead.
+func (*ListAuditEventsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListAuditEventsResponse) GetEvents() []*AuditEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+type LoginRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	UserName string                 `protobuf:"bytes,1,opt,name=user_name,json=userName,proto3" json:"user_name,omitempty"`
+	Password string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	// 2 段階認証が有効なユーザーのみ必要
+	TotpCode      string `protobuf:"bytes,3,opt,name=totp_code,json=totpCode,proto3" json:"totp_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoginRequest) Reset() {
+	*x = LoginRequest{}
+	mi := &file_proto_logs_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoginRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginRequest) ProtoMessage() {}
+
+func (x *LoginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoginRequest.ProtoReflect.Descriptor instead.
+func (*LoginRequest) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *LoginRequest) GetUserName() string {
+	if x != nil {
+		return x.UserName
+	}
+	return ""
+}
+
+func (x *LoginRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *LoginRequest) GetTotpCode() string {
+	if x != nil {
+		return x.TotpCode
+	}
+	return ""
+}
+
+type LoginResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Token string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	// RFC3339
+	ExpiresAt     string `protobuf:"bytes,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoginResponse) Reset() {
+	*x = LoginResponse{}
+	mi := &file_proto_logs_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoginResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginResponse) ProtoMessage() {}
+
+func (x *LoginResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoginResponse.ProtoReflect.Descriptor instead.
+func (*LoginResponse) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *LoginResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *LoginResponse) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return ""
+}
+
+type SearchLogsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// status と feeling の部分一致
+	Query    string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	UserName string `protobuf:"bytes,2,opt,name=user_name,json=userName,proto3" json:"user_name,omitempty"`
+	// RFC3339
+	Since string `protobuf:"bytes,3,opt,name=since,proto3" json:"since,omitempty"`
+	Until string `protobuf:"bytes,4,opt,name=until,proto3" json:"until,omitempty"`
+	Limit int32  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	// true ならログの代わりにメモ（LogEntry.note）を検索する
+	Notes         bool `protobuf:"varint,6,opt,name=notes,proto3" json:"notes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchLogsRequest) Reset() {
+	*x = SearchLogsRequest{}
+	mi := &file_proto_logs_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchLogsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchLogsRequest) ProtoMessage() {}
+
+func (x *SearchLogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchLogsRequest.ProtoReflect.Descriptor instead.
+func (*SearchLogsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SearchLogsRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchLogsRequest) GetUserName() string {
+	if x != nil {
+		return x.UserName
+	}
+	return ""
+}
+
+func (x *SearchLogsRequest) GetSince() string {
+	if x != nil {
+		return x.Since
+	}
+	return ""
+}
+
+func (x *SearchLogsRequest) GetUntil() string {
+	if x != nil {
+		return x.Until
+	}
+	return ""
+}
+
+func (x *SearchLogsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *SearchLogsRequest) GetNotes() bool {
+	if x != nil {
+		return x.Notes
+	}
+	return false
+}
+
+type MoodStatsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// RFC3339
+	Since         string `protobuf:"bytes,1,opt,name=since,proto3" json:"since,omitempty"`
+	Until         string `protobuf:"bytes,2,opt,name=until,proto3" json:"until,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MoodStatsRequest) Reset() {
+	*x = MoodStatsRequest{}
+	mi := &file_proto_logs_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MoodStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MoodStatsRequest) ProtoMessage() {}
+
+func (x *MoodStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MoodStatsRequest.ProtoReflect.Descriptor instead.
+func (*MoodStatsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *MoodStatsRequest) GetSince() string {
+	if x != nil {
+		return x.Since
+	}
+	return ""
+}
+
+func (x *MoodStatsRequest) GetUntil() string {
+	if x != nil {
+		return x.Until
+	}
+	return ""
+}
+
+type MoodCount struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Feeling       string                 `protobuf:"bytes,1,opt,name=feeling,proto3" json:"feeling,omitempty"`
+	Count         int64                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MoodCount) Reset() {
+	*x = MoodCount{}
+	mi := &file_proto_logs_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MoodCount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MoodCount) ProtoMessage() {}
+
+func (x *MoodCount) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MoodCount.ProtoReflect.Descriptor instead.
+func (*MoodCount) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *MoodCount) GetFeeling() string {
+	if x != nil {
+		return x.Feeling
+	}
+	return ""
+}
+
+func (x *MoodCount) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type UserMoodCount struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserName      string                 `protobuf:"bytes,1,opt,name=user_name,json=userName,proto3" json:"user_name,omitempty"`
+	Feeling       string                 `protobuf:"bytes,2,opt,name=feeling,proto3" json:"feeling,omitempty"`
+	Count         int64                  `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserMoodCount) Reset() {
+	*x = UserMoodCount{}
+	mi := &file_proto_logs_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserMoodCount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserMoodCount) ProtoMessage() {}
+
+func (x *UserMoodCount) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserMoodCount.ProtoReflect.Descriptor instead.
+func (*UserMoodCount) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *UserMoodCount) GetUserName() string {
+	if x != nil {
+		return x.UserName
+	}
+	return ""
+}
+
+func (x *UserMoodCount) GetFeeling() string {
+	if x != nil {
+		return x.Feeling
+	}
+	return ""
+}
+
+func (x *UserMoodCount) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type MoodStatsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 公開範囲に関係なくすべてのログを数える。誰の気分かは含めない
+	Team []*MoodCount `protobuf:"bytes,1,rep,name=team,proto3" json:"team,omitempty"`
+	// 呼び出し元が読めるログだけをユーザー別に数える
+	ByUser        []*UserMoodCount `protobuf:"bytes,2,rep,name=by_user,json=byUser,proto3" json:"by_user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MoodStatsResponse) Reset() {
+	*x = MoodStatsResponse{}
+	mi := &file_proto_logs_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MoodStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MoodStatsResponse) ProtoMessage() {}
+
+func (x *MoodStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MoodStatsResponse.ProtoReflect.Descriptor instead.
+func (*MoodStatsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *MoodStatsResponse) GetTeam() []*MoodCount {
+	if x != nil {
+		return x.Team
+	}
+	return nil
+}
+
+func (x *MoodStatsResponse) GetByUser() []*UserMoodCount {
+	if x != nil {
+		return x.ByUser
+	}
+	return nil
+}
+
+type CalendarRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 空なら呼び出し元が読めるすべてのログ
+	UserName string `protobuf:"bytes,1,opt,name=user_name,json=userName,proto3" json:"user_name,omitempty"`
+	// RFC3339
+	Since         string `protobuf:"bytes,2,opt,name=since,proto3" json:"since,omitempty"`
+	Until         string `protobuf:"bytes,3,opt,name=until,proto3" json:"until,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CalendarRequest) Reset() {
+	*x = CalendarRequest{}
+	mi := &file_proto_logs_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CalendarRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CalendarRequest) ProtoMessage() {}
+
+func (x *CalendarRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CalendarRequest.ProtoReflect.Descriptor instead.
+func (*CalendarRequest) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *CalendarRequest) GetUserName() string {
+	if x != nil {
+		return x.UserName
+	}
+	return ""
+}
+
+func (x *CalendarRequest) GetSince() string {
+	if x != nil {
+		return x.Since
+	}
+	return ""
+}
+
+func (x *CalendarRequest) GetUntil() string {
+	if x != nil {
+		return x.Until
+	}
+	return ""
+}
+
+type CalendarDay struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 投稿者の時計での日付（YYYY-MM-DD）
+	Date  string `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	Count int64  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	// 点数にできた気分の平均（-2〜2）。scored が 0 なら意味を持たない
+	Mood float64 `protobuf:"fixed64,3,opt,name=mood,proto3" json:"mood,omitempty"`
+	// 気分を点数にできたログの数
+	Scored int64 `protobuf:"varint,4,opt,name=scored,proto3" json:"scored,omitempty"`
+	// いちばん多かった気分
+	TopFeeling    string `protobuf:"bytes,5,opt,name=top_feeling,json=topFeeling,proto3" json:"top_feeling,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CalendarDay) Reset() {
+	*x = CalendarDay{}
+	mi := &file_proto_logs_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CalendarDay) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CalendarDay) ProtoMessage() {}
+
+func (x *CalendarDay) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CalendarDay.ProtoReflect.Descriptor instead.
+func (*CalendarDay) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *CalendarDay) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *CalendarDay) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *CalendarDay) GetMood() float64 {
+	if x != nil {
+		return x.Mood
+	}
+	return 0
+}
+
+func (x *CalendarDay) GetScored() int64 {
+	if x != nil {
+		return x.Scored
+	}
+	return 0
+}
+
+func (x *CalendarDay) GetTopFeeling() string {
+	if x != nil {
+		return x.TopFeeling
+	}
+	return ""
+}
+
+type CalendarResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ログのある日だけを日付順に並べる
+	Days          []*CalendarDay `protobuf:"bytes,1,rep,name=days,proto3" json:"days,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CalendarResponse) Reset() {
+	*x = CalendarResponse{}
+	mi := &file_proto_logs_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CalendarResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CalendarResponse) ProtoMessage() {}
+
+func (x *CalendarResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CalendarResponse.ProtoReflect.Descriptor instead.
+func (*CalendarResponse) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *CalendarResponse) GetDays() []*CalendarDay {
+	if x != nil {
+		return x.Days
+	}
+	return nil
+}
+
+type Organization struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Slug          string                 `protobuf:"bytes,2,opt,name=slug,proto3" json:"slug,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Organization) Reset() {
+	*x = Organization{}
+	mi := &file_proto_logs_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Organization) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Organization) ProtoMessage() {}
+
+func (x *Organization) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Organization.ProtoReflect.Descriptor instead.
+func (*Organization) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *Organization) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Organization) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *Organization) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// インスタンス管理者（既定の組織の管理者）だけが呼べる
+type CreateOrganizationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Slug          string                 `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateOrganizationRequest) Reset() {
+	*x = CreateOrganizationRequest{}
+	mi := &file_proto_logs_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateOrganizationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateOrganizationRequest) ProtoMessage() {}
+
+func (x *CreateOrganizationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateOrganizationRequest.ProtoReflect.Descriptor instead.
+func (*CreateOrganizationRequest) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *CreateOrganizationRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *CreateOrganizationRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type ListOrganizationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOrganizationsRequest) Reset() {
+	*x = ListOrganizationsRequest{}
+	mi := &file_proto_logs_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOrganizationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOrganizationsRequest) ProtoMessage() {}
+
+func (x *ListOrganizationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOrganizationsRequest.ProtoReflect.Descriptor instead.
+func (*ListOrganizationsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{21}
+}
+
+type ListOrganizationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Organizations []*Organization        `protobuf:"bytes,1,rep,name=organizations,proto3" json:"organizations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOrganizationsResponse) Reset() {
+	*x = ListOrganizationsResponse{}
+	mi := &file_proto_logs_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOrganizationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOrganizationsResponse) ProtoMessage() {}
+
+func (x *ListOrganizationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOrganizationsResponse.ProtoReflect.Descriptor instead.
+func (*ListOrganizationsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ListOrganizationsResponse) GetOrganizations() []*Organization {
+	if x != nil {
+		return x.Organizations
+	}
+	return nil
+}
+
+type Team struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Slug          string                 `protobuf:"bytes,2,opt,name=slug,proto3" json:"slug,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Team) Reset() {
+	*x = Team{}
+	mi := &file_proto_logs_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Team) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Team) ProtoMessage() {}
+
+func (x *Team) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Team.ProtoReflect.Descriptor instead.
+func (*Team) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *Team) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Team) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *Team) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// 呼び出し元の組織にチームを作成する。組織の管理者だけが呼べる
+type CreateTeamRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Slug          string                 `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTeamRequest) Reset() {
+	*x = CreateTeamRequest{}
+	mi := &file_proto_logs_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTeamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTeamRequest) ProtoMessage() {}
+
+func (x *CreateTeamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTeamRequest.ProtoReflect.Descriptor instead.
+func (*CreateTeamRequest) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *CreateTeamRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *CreateTeamRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type ListTeamsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTeamsRequest) Reset() {
+	*x = ListTeamsRequest{}
+	mi := &file_proto_logs_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTeamsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTeamsRequest) ProtoMessage() {}
+
+func (x *ListTeamsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTeamsRequest.ProtoReflect.Descriptor instead.
+func (*ListTeamsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{25}
+}
+
+type ListTeamsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Teams         []*Team                `protobuf:"bytes,1,rep,name=teams,proto3" json:"teams,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTeamsResponse) Reset() {
+	*x = ListTeamsResponse{}
+	mi := &file_proto_logs_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTeamsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTeamsResponse) ProtoMessage() {}
+
+func (x *ListTeamsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTeamsResponse.ProtoReflect.Descriptor instead.
+func (*ListTeamsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ListTeamsResponse) GetTeams() []*Team {
+	if x != nil {
+		return x.Teams
+	}
+	return nil
+}
+
+type CreateUserRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	UserName string                 `protobuf:"bytes,1,opt,name=user_name,json=userName,proto3" json:"user_name,omitempty"`
+	Password string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	Email    string                 `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
+	// 省略すると member
+	Role string `protobuf:"bytes,4,opt,name=role,proto3" json:"role,omitempty"`
+	// 省略すると呼び出し元の組織。他の組織を指定できるのはインスタンス管理者だけ
+	OrgSlug       string `protobuf:"bytes,5,opt,name=org_slug,json=orgSlug,proto3" json:"org_slug,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateUserRequest) Reset() {
+	*x = CreateUserRequest{}
+	mi := &file_proto_logs_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateUserRequest) ProtoMessage() {}
+
+func (x *CreateUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateUserRequest.ProtoReflect.Descriptor instead.
+func (*CreateUserRequest) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *CreateUserRequest) GetUserName() string {
+	if x != nil {
+		return x.UserName
+	}
+	return ""
+}
+
+func (x *CreateUserRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *CreateUserRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *CreateUserRequest) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *CreateUserRequest) GetOrgSlug() string {
+	if x != nil {
+		return x.OrgSlug
+	}
+	return ""
+}
+
+type CreateUserResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserName      string                 `protobuf:"bytes,1,opt,name=user_name,json=userName,proto3" json:"user_name,omitempty"`
+	Role          string                 `protobuf:"bytes,2,opt,name=role,proto3" json:"role,omitempty"`
+	OrgSlug       string                 `protobuf:"bytes,3,opt,name=org_slug,json=orgSlug,proto3" json:"org_slug,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateUserResponse) Reset() {
+	*x = CreateUserResponse{}
+	mi := &file_proto_logs_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateUserResponse) ProtoMessage() {}
+
+func (x *CreateUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateUserResponse.ProtoReflect.Descriptor instead.
+func (*CreateUserResponse) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *CreateUserResponse) GetUserName() string {
+	if x != nil {
+		return x.UserName
+	}
+	return ""
+}
+
+func (x *CreateUserResponse) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *CreateUserResponse) GetOrgSlug() string {
+	if x != nil {
+		return x.OrgSlug
+	}
+	return ""
+}
+
+// 省略すると呼び出し元のデータ。他のユーザーを指定できるのは同じ組織の管理者だけ
+type ExportMyDataRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserName      string                 `protobuf:"bytes,1,opt,name=user_name,json=userName,proto3" json:"user_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportMyDataRequest) Reset() {
+	*x = ExportMyDataRequest{}
+	mi := &file_proto_logs_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportMyDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportMyDataRequest) ProtoMessage() {}
+
+func (x *ExportMyDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportMyDataRequest.ProtoReflect.Descriptor instead.
+func (*ExportMyDataRequest) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ExportMyDataRequest) GetUserName() string {
+	if x != nil {
+		return x.UserName
+	}
+	return ""
+}
+
+type ExportMyDataResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// JSON ファイルをまとめた zip
+	Archive       []byte `protobuf:"bytes,1,opt,name=archive,proto3" json:"archive,omitempty"`
+	FileName      string `protobuf:"bytes,2,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportMyDataResponse) Reset() {
+	*x = ExportMyDataResponse{}
+	mi := &file_proto_logs_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportMyDataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportMyDataResponse) ProtoMessage() {}
+
+func (x *ExportMyDataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportMyDataResponse.ProtoReflect.Descriptor instead.
+func (*ExportMyDataResponse) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ExportMyDataResponse) GetArchive() []byte {
+	if x != nil {
+		return x.Archive
+	}
+	return nil
+}
+
+func (x *ExportMyDataResponse) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+// 同じ組織の管理者だけが呼べる。自分自身は消去できない
+type EraseUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserName      string                 `protobuf:"bytes,1,opt,name=user_name,json=userName,proto3" json:"user_name,omitempty"`
+	Mode          ErasureMode            `protobuf:"varint,2,opt,name=mode,proto3,enum=logs.ErasureMode" json:"mode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EraseUserRequest) Reset() {
+	*x = EraseUserRequest{}
+	mi := &file_proto_logs_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EraseUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EraseUserRequest) ProtoMessage() {}
+
+func (x *EraseUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EraseUserRequest.ProtoReflect.Descriptor instead.
+func (*EraseUserRequest) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *EraseUserRequest) GetUserName() string {
+	if x != nil {
+		return x.UserName
+	}
+	return ""
+}
+
+func (x *EraseUserRequest) GetMode() ErasureMode {
+	if x != nil {
+		return x.Mode
+	}
+	return ErasureMode_ERASURE_MODE_UNSPECIFIED
+}
+
+type EraseUserResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Mode  ErasureMode            `protobuf:"varint,1,opt,name=mode,proto3,enum=logs.ErasureMode" json:"mode,omitempty"`
+	// 仮名化したときの置き換え後の名前
+	Pseudonym           string `protobuf:"bytes,2,opt,name=pseudonym,proto3" json:"pseudonym,omitempty"`
+	LogsAffected        int64  `protobuf:"varint,3,opt,name=logs_affected,json=logsAffected,proto3" json:"logs_affected,omitempty"`
+	AuditEventsAffected int64  `protobuf:"varint,4,opt,name=audit_events_affected,json=auditEventsAffected,proto3" json:"audit_events_affected,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *EraseUserResponse) Reset() {
+	*x = EraseUserResponse{}
+	mi := &file_proto_logs_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EraseUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EraseUserResponse) ProtoMessage() {}
+
+func (x *EraseUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EraseUserResponse.ProtoReflect.Descriptor instead.
+func (*EraseUserResponse) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *EraseUserResponse) GetMode() ErasureMode {
+	if x != nil {
+		return x.Mode
+	}
+	return ErasureMode_ERASURE_MODE_UNSPECIFIED
+}
+
+func (x *EraseUserResponse) GetPseudonym() string {
+	if x != nil {
+		return x.Pseudonym
+	}
+	return ""
+}
+
+func (x *EraseUserResponse) GetLogsAffected() int64 {
+	if x != nil {
+		return x.LogsAffected
+	}
+	return 0
+}
+
+func (x *EraseUserResponse) GetAuditEventsAffected() int64 {
+	if x != nil {
+		return x.AuditEventsAffected
+	}
+	return 0
+}
+
+// 同じサーバープロセスで追加・削除されたログを、呼び出し元が読めるものだけ配信する
+type WatchLogsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchLogsRequest) Reset() {
+	*x = WatchLogsRequest{}
+	mi := &file_proto_logs_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchLogsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchLogsRequest) ProtoMessage() {}
+
+func (x *WatchLogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchLogsRequest.ProtoReflect.Descriptor instead.
+func (*WatchLogsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{33}
+}
+
+type LogEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          LogEventType           `protobuf:"varint,1,opt,name=type,proto3,enum=logs.LogEventType" json:"type,omitempty"`
+	Entry         *LogEntry              `protobuf:"bytes,2,opt,name=entry,proto3" json:"entry,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogEvent) Reset() {
+	*x = LogEvent{}
+	mi := &file_proto_logs_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogEvent) ProtoMessage() {}
+
+func (x *LogEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogEvent.ProtoReflect.Descriptor instead.
+func (*LogEvent) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *LogEvent) GetType() LogEventType {
+	if x != nil {
+		return x.Type
+	}
+	return LogEventType_LOG_EVENT_TYPE_UNSPECIFIED
+}
+
+func (x *LogEvent) GetEntry() *LogEntry {
+	if x != nil {
+		return x.Entry
+	}
+	return nil
+}
+
+// ImportLogs で送る 1 行
+type ImportLogsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Entry *LogEntry              `protobuf:"bytes,1,opt,name=entry,proto3" json:"entry,omitempty"`
+	// 取り込むファイルの行番号。エラーの報告に使う
+	Row int32 `protobuf:"varint,2,opt,name=row,proto3" json:"row,omitempty"`
+	// 最初のメッセージの値を使う。true なら検証だけして追加しない
+	DryRun        bool `protobuf:"varint,3,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportLogsRequest) Reset() {
+	*x = ImportLogsRequest{}
+	mi := &file_proto_logs_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportLogsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportLogsRequest) ProtoMessage() {}
+
+func (x *ImportLogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportLogsRequest.ProtoReflect.Descriptor instead.
+func (*ImportLogsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ImportLogsRequest) GetEntry() *LogEntry {
+	if x != nil {
+		return x.Entry
+	}
+	return nil
+}
+
+func (x *ImportLogsRequest) GetRow() int32 {
+	if x != nil {
+		return x.Row
+	}
+	return 0
+}
+
+func (x *ImportLogsRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type ImportRowError struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Row           int32                  `protobuf:"varint,1,opt,name=row,proto3" json:"row,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportRowError) Reset() {
+	*x = ImportRowError{}
+	mi := &file_proto_logs_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportRowError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportRowError) ProtoMessage() {}
+
+func (x *ImportRowError) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportRowError.ProtoReflect.Descriptor instead.
+func (*ImportRowError) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *ImportRowError) GetRow() int32 {
+	if x != nil {
+		return x.Row
+	}
+	return 0
+}
+
+func (x *ImportRowError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ImportLogsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 追加した（dry_run なら追加できる）行の数
+	Imported int32 `protobuf:"varint,1,opt,name=imported,proto3" json:"imported,omitempty"`
+	// 同じ内容を取り込み済みだったため追加しなかった行の数
+	Duplicates    int32             `protobuf:"varint,2,opt,name=duplicates,proto3" json:"duplicates,omitempty"`
+	Errors        []*ImportRowError `protobuf:"bytes,3,rep,name=errors,proto3" json:"errors,omitempty"`
+	DryRun        bool              `protobuf:"varint,4,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportLogsResponse) Reset() {
+	*x = ImportLogsResponse{}
+	mi := &file_proto_logs_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportLogsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportLogsResponse) ProtoMessage() {}
+
+func (x *ImportLogsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportLogsResponse.ProtoReflect.Descriptor instead.
+func (*ImportLogsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *ImportLogsResponse) GetImported() int32 {
+	if x != nil {
+		return x.Imported
+	}
+	return 0
+}
+
+func (x *ImportLogsResponse) GetDuplicates() int32 {
+	if x != nil {
+		return x.Duplicates
+	}
+	return 0
+}
+
+func (x *ImportLogsResponse) GetErrors() []*ImportRowError {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *ImportLogsResponse) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+// ExportLogs は条件に合う、呼び出し元が読めるログを古い順に 1 件ずつ返す
+type ExportLogsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// status と feeling の部分一致
+	Query    string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	UserName string `protobuf:"bytes,2,opt,name=user_name,json=userName,proto3" json:"user_name,omitempty"`
+	// RFC3339
+	Since         string `protobuf:"bytes,3,opt,name=since,proto3" json:"since,omitempty"`
+	Until         string `protobuf:"bytes,4,opt,name=until,proto3" json:"until,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportLogsRequest) Reset() {
+	*x = ExportLogsRequest{}
+	mi := &file_proto_logs_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportLogsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportLogsRequest) ProtoMessage() {}
+
+func (x *ExportLogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportLogsRequest.ProtoReflect.Descriptor instead.
+func (*ExportLogsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *ExportLogsRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *ExportLogsRequest) GetUserName() string {
+	if x != nil {
+		return x.UserName
+	}
+	return ""
+}
+
+func (x *ExportLogsRequest) GetSince() string {
+	if x != nil {
+		return x.Since
+	}
+	return ""
+}
+
+func (x *ExportLogsRequest) GetUntil() string {
+	if x != nil {
+		return x.Until
+	}
+	return ""
+}
+
+// add で尋ねる 1 項目
+type TemplateField struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 例: yesterday, today, blockers
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// 入力を求めるときの表示と、ステータスの各行の見出し
+	Label         string `protobuf:"bytes,2,opt,name=label,proto3" json:"label,omitempty"`
+	Optional      bool   `protobuf:"varint,3,opt,name=optional,proto3" json:"optional,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TemplateField) Reset() {
+	*x = TemplateField{}
+	mi := &file_proto_logs_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TemplateField) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TemplateField) ProtoMessage() {}
+
+func (x *TemplateField) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TemplateField.ProtoReflect.Descriptor instead.
+func (*TemplateField) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *TemplateField) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *TemplateField) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *TemplateField) GetOptional() bool {
+	if x != nil {
+		return x.Optional
+	}
+	return false
+}
+
+// チームで使う add の入力テンプレート。保存と削除はマネージャーか管理者だけができる
+type Template struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Team      string                 `protobuf:"bytes,1,opt,name=team,proto3" json:"team,omitempty"`
+	Name      string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Fields    []*TemplateField       `protobuf:"bytes,3,rep,name=fields,proto3" json:"fields,omitempty"`
+	UpdatedBy string                 `protobuf:"bytes,4,opt,name=updated_by,json=updatedBy,proto3" json:"updated_by,omitempty"`
+	// RFC3339
+	UpdatedAt     string `protobuf:"bytes,5,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Template) Reset() {
+	*x = Template{}
+	mi := &file_proto_logs_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Template) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Template) ProtoMessage() {}
+
+func (x *Template) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Template.ProtoReflect.Descriptor instead.
+func (*Template) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *Template) GetTeam() string {
+	if x != nil {
+		return x.Team
+	}
+	return ""
+}
+
+func (x *Template) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Template) GetFields() []*TemplateField {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *Template) GetUpdatedBy() string {
+	if x != nil {
+		return x.UpdatedBy
+	}
+	return ""
+}
+
+func (x *Template) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+type ListTemplatesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Team          string                 `protobuf:"bytes,1,opt,name=team,proto3" json:"team,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTemplatesRequest) Reset() {
+	*x = ListTemplatesRequest{}
+	mi := &file_proto_logs_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTemplatesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTemplatesRequest) ProtoMessage() {}
+
+func (x *ListTemplatesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTemplatesRequest.ProtoReflect.Descriptor instead.
+func (*ListTemplatesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *ListTemplatesRequest) GetTeam() string {
+	if x != nil {
+		return x.Team
+	}
+	return ""
+}
+
+type ListTemplatesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Templates     []*Template            `protobuf:"bytes,1,rep,name=templates,proto3" json:"templates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTemplatesResponse) Reset() {
+	*x = ListTemplatesResponse{}
+	mi := &file_proto_logs_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTemplatesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTemplatesResponse) ProtoMessage() {}
+
+func (x *ListTemplatesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTemplatesResponse.ProtoReflect.Descriptor instead.
+func (*ListTemplatesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *ListTemplatesResponse) GetTemplates() []*Template {
+	if x != nil {
+		return x.Templates
+	}
+	return nil
+}
+
+type DeleteTemplateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Team          string                 `protobuf:"bytes,1,opt,name=team,proto3" json:"team,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTemplateRequest) Reset() {
+	*x = DeleteTemplateRequest{}
+	mi := &file_proto_logs_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTemplateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTemplateRequest) ProtoMessage() {}
+
+func (x *DeleteTemplateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTemplateRequest.ProtoReflect.Descriptor instead.
+func (*DeleteTemplateRequest) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *DeleteTemplateRequest) GetTeam() string {
+	if x != nil {
+		return x.Team
+	}
+	return ""
+}
+
+func (x *DeleteTemplateRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type DeleteTemplateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTemplateResponse) Reset() {
+	*x = DeleteTemplateResponse{}
+	mi := &file_proto_logs_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTemplateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTemplateResponse) ProtoMessage() {}
+
+func (x *DeleteTemplateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTemplateResponse.ProtoReflect.Descriptor instead.
+func (*DeleteTemplateResponse) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{44}
+}
+
+type CompleteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          CompletionKind         `protobuf:"varint,1,opt,name=kind,proto3,enum=logs.CompletionKind" json:"kind,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompleteRequest) Reset() {
+	*x = CompleteRequest{}
+	mi := &file_proto_logs_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompleteRequest) ProtoMessage() {}
+
+func (x *CompleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompleteRequest.ProtoReflect.Descriptor instead.
+func (*CompleteRequest) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *CompleteRequest) GetKind() CompletionKind {
+	if x != nil {
+		return x.Kind
+	}
+	return CompletionKind_COMPLETION_KIND_UNSPECIFIED
+}
+
+type Completion struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Value string                 `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	// シェルが候補の横に表示する説明
+	Description   string `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Completion) Reset() {
+	*x = Completion{}
+	mi := &file_proto_logs_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Completion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Completion) ProtoMessage() {}
+
+func (x *Completion) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Completion.ProtoReflect.Descriptor instead.
+func (*Completion) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *Completion) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *Completion) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type CompleteResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ログ ID は新しい順、それ以外は値の順
+	Completions   []*Completion `protobuf:"bytes,1,rep,name=completions,proto3" json:"completions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompleteResponse) Reset() {
+	*x = CompleteResponse{}
+	mi := &file_proto_logs_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompleteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompleteResponse) ProtoMessage() {}
+
+func (x *CompleteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompleteResponse.ProtoReflect.Descriptor instead.
+func (*CompleteResponse) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *CompleteResponse) GetCompletions() []*Completion {
+	if x != nil {
+		return x.Completions
+	}
+	return nil
+}
+
+type PingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ClientVersion string                 `protobuf:"bytes,1,opt,name=client_version,json=clientVersion,proto3" json:"client_version,omitempty"`
+	ClientApi     int32                  `protobuf:"varint,2,opt,name=client_api,json=clientApi,proto3" json:"client_api,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PingRequest) Reset() {
+	*x = PingRequest{}
+	mi := &file_proto_logs_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PingRequest) ProtoMessage() {}
+
+func (x *PingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PingRequest.ProtoReflect.Descriptor instead.
+func (*PingRequest) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *PingRequest) GetClientVersion() string {
+	if x != nil {
+		return x.ClientVersion
+	}
+	return ""
+}
+
+func (x *PingRequest) GetClientApi() int32 {
+	if x != nil {
+		return x.ClientApi
+	}
+	return 0
+}
+
+type PingResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ServerVersion string                 `protobuf:"bytes,1,opt,name=server_version,json=serverVersion,proto3" json:"server_version,omitempty"`
+	// サーバーの RPC の互換性の番号と、受け付ける CLI の最小
+	Api          int32 `protobuf:"varint,2,opt,name=api,proto3" json:"api,omitempty"`
+	MinClientApi int32 `protobuf:"varint,3,opt,name=min_client_api,json=minClientApi,proto3" json:"min_client_api,omitempty"`
+	// 時刻のずれを測るためのサーバーの時刻（RFC3339、ナノ秒まで）
+	ServerTime string `protobuf:"bytes,4,opt,name=server_time,json=serverTime,proto3" json:"server_time,omitempty"`
+	// トークンを付けて呼び出したときの呼び出し元。匿名なら空
+	UserName      string `protobuf:"bytes,5,opt,name=user_name,json=userName,proto3" json:"user_name,omitempty"`
+	Role          string `protobuf:"bytes,6,opt,name=role,proto3" json:"role,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PingResponse) Reset() {
+	*x = PingResponse{}
+	mi := &file_proto_logs_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PingResponse) ProtoMessage() {}
+
+func (x *PingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logs_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PingResponse.ProtoReflect.Descriptor instead.
+func (*PingResponse) Descriptor() ([]byte, []int) {
+	return file_proto_logs_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *PingResponse) GetServerVersion() string {
+	if x != nil {
+		return x.ServerVersion
+	}
+	return ""
+}
+
+func (x *PingResponse) GetApi() int32 {
+	if x != nil {
+		return x.Api
+	}
+	return 0
+}
+
+func (x *PingResponse) GetMinClientApi() int32 {
+	if x != nil {
+		return x.MinClientApi
+	}
+	return 0
+}
+
+func (x *PingResponse) GetServerTime() string {
+	if x != nil {
+		return x.ServerTime
+	}
+	return ""
+}
+
+func (x *PingResponse) GetUserName() string {
+	if x != nil {
+		return x.UserName
+	}
+	return ""
+}
+
+func (x *PingResponse) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+var File_proto_logs_proto protoreflect.FileDescriptor
+
+const file_proto_logs_proto_rawDesc = "" +
+	"\n" +
+	"\x10proto/logs.proto\x12\x04logs\"'\n" +
+	"\fFetchRequest\x12\x17\n" +
+	"\ateam_id\x18\x01 \x01(\tR\x06teamId\"\xf6\x01\n" +
+	"\bLogEntry\x12\x1b\n" +
+	"\tuser_name\x18\x01 \x01(\tR\buserName\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x18\n" +
+	"\afeeling\x18\x03 \x01(\tR\afeeling\x12\x1c\n" +
+	"\ttimestamp\x18\x04 \x01(\tR\ttimestamp\x12\x0e\n" +
+	"\x02id\x18\x05 \x01(\x03R\x02id\x120\n" +
+	"\n" +
+	"visibility\x18\x06 \x01(\x0e2\x10.logs.VisibilityR\n" +
+	"visibility\x12'\n" +
+	"\x0fidempotency_key\x18\a \x01(\tR\x0eidempotencyKey\x12\x12\n" +
+	"\x04note\x18\b \x01(\bR\x04note\"E\n" +
+	"\vAddResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x12\x1c\n" +
+	"\tduplicate\x18\x02 \x01(\bR\tduplicate\"3\n" +
+	"\rFetchResponse\x12\"\n" +
+	"\x04logs\x18\x01 \x03(\v2\x0e.logs.LogEntryR\x04logs\"\"\n" +
+	"\x10DeleteLogRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"-\n" +
+	"\x11DeleteLogResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"\xa0\x01\n" +
+	"\x16ListAuditEventsRequest\x12\x14\n" +
+	"\x05actor\x18\x01 \x01(\tR\x05actor\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x12\x16\n" +
+	"\x06target\x18\x03 \x01(\tR\x06target\x12\x14\n" +
+	"\x05since\x18\x04 \x01(\tR\x05since\x12\x14\n" +
+	"\x05until\x18\x05 \x01(\tR\x05until\x12\x14\n" +
+	"\x05limit\x18\x06 \x01(\x05R\x05limit\"\xc9\x01\n" +
+	"\n" +
+	"AuditEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1f\n" +
+	"\voccurred_at\x18\x02 \x01(\tR\n" +
+	"occurredAt\x12\x14\n" +
+	"\x05actor\x18\x03 \x01(\tR\x05actor\x12\x16\n" +
+	"\x06action\x18\x04 \x01(\tR\x06action\x12\x16\n" +
+	"\x06target\x18\x05 \x01(\tR\x06target\x12\x16\n" +
+	"\x06source\x18\x06 \x01(\tR\x06source\x12\x16\n" +
+	"\x06before\x18\a \x01(\tR\x06before\x12\x14\n" +
+	"\x05after\x18\b \x01(\tR\x05after\"C\n" +
+	"\x17ListAuditEventsResponse\x12(\n" +
+	"\x06events\x18\x01 \x03(\v2\x10.logs.AuditEventR\x06events\"d\n" +
+	"\fLoginRequest\x12\x1b\n" +
+	"\tuser_name\x18\x01 \x01(\tR\buserName\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\x12\x1b\n" +
+	"\ttotp_code\x18\x03 \x01(\tR\btotpCode\"D\n" +
+	"\rLoginResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x02 \x01(\tR\texpiresAt\"\x9e\x01\n" +
+	"\x11SearchLogsRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x1b\n" +
+	"\tuser_name\x18\x02 \x01(\tR\buserName\x12\x14\n" +
+	"\x05since\x18\x03 \x01(\tR\x05since\x12\x14\n" +
+	"\x05until\x18\x04 \x01(\tR\x05until\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\x12\x14\n" +
+	"\x05notes\x18\x06 \x01(\bR\x05notes\">\n" +
+	"\x10MoodStatsRequest\x12\x14\n" +
+	"\x05since\x18\x01 \x01(\tR\x05since\x12\x14\n" +
+	"\x05until\x18\x02 \x01(\tR\x05until\";\n" +
+	"\tMoodCount\x12\x18\n" +
+	"\afeeling\x18\x01 \x01(\tR\afeeling\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x03R\x05count\"\\\n" +
+	"\rUserMoodCount\x12\x1b\n" +
+	"\tuser_name\x18\x01 \x01(\tR\buserName\x12\x18\n" +
+	"\afeeling\x18\x02 \x01(\tR\afeeling\x12\x14\n" +
+	"\x05count\x18\x03 \x01(\x03R\x05count\"f\n" +
+	"\x11MoodStatsResponse\x12#\n" +
+	"\x04team\x18\x01 \x03(\v2\x0f.logs.MoodCountR\x04team\x12,\n" +
+	"\aby_user\x18\x02 \x03(\v2\x13.logs.UserMoodCountR\x06byUser\"Z\n" +
+	"\x0fCalendarRequest\x12\x1b\n" +
+	"\tuser_name\x18\x01 \x01(\tR\buserName\x12\x14\n" +
+	"\x05since\x18\x02 \x01(\tR\x05since\x12\x14\n" +
+	"\x05until\x18\x03 \x01(\tR\x05until\"\x84\x01\n" +
+	"\vCalendarDay\x12\x12\n" +
+	"\x04date\x18\x01 \x01(\tR\x04date\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x03R\x05count\x12\x12\n" +
+	"\x04mood\x18\x03 \x01(\x01R\x04mood\x12\x16\n" +
+	"\x06scored\x18\x04 \x01(\x03R\x06scored\x12\x1f\n" +
+	"\vtop_feeling\x18\x05 \x01(\tR\n" +
+	"topFeeling\"9\n" +
+	"\x10CalendarResponse\x12%\n" +
+	"\x04days\x18\x01 \x03(\v2\x11.logs.CalendarDayR\x04days\"F\n" +
+	"\fOrganization\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04slug\x18\x02 \x01(\tR\x04slug\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\"C\n" +
+	"\x19CreateOrganizationRequest\x12\x12\n" +
+	"\x04slug\x18\x01 \x01(\tR\x04slug\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"\x1a\n" +
+	"\x18ListOrganizationsRequest\"U\n" +
+	"\x19ListOrganizationsResponse\x128\n" +
+	"\rorganizations\x18\x01 \x03(\v2\x12.logs.OrganizationR\rorganizations\">\n" +
+	"\x04Team\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04slug\x18\x02 \x01(\tR\x04slug\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\";\n" +
+	"\x11CreateTeamRequest\x12\x12\n" +
+	"\x04slug\x18\x01 \x01(\tR\x04slug\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"\x12\n" +
+	"\x10ListTeamsRequest\"5\n" +
+	"\x11ListTeamsResponse\x12 \n" +
+	"\x05teams\x18\x01 \x03(\v2\n" +
+	".logs.TeamR\x05teams\"\x91\x01\n" +
+	"\x11CreateUserRequest\x12\x1b\n" +
+	"\tuser_name\x18\x01 \x01(\tR\buserName\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\x12\x14\n" +
+	"\x05email\x18\x03 \x01(\tR\x05email\x12\x12\n" +
+	"\x04role\x18\x04 \x01(\tR\x04role\x12\x19\n" +
+	"\borg_slug\x18\x05 \x01(\tR\aorgSlug\"`\n" +
+	"\x12CreateUserResponse\x12\x1b\n" +
+	"\tuser_name\x18\x01 \x01(\tR\buserName\x12\x12\n" +
+	"\x04role\x18\x02 \x01(\tR\x04role\x12\x19\n" +
+	"\borg_slug\x18\x03 \x01(\tR\aorgSlug\"2\n" +
+	"\x13ExportMyDataRequest\x12\x1b\n" +
+	"\tuser_name\x18\x01 \x01(\tR\buserName\"M\n" +
+	"\x14ExportMyDataResponse\x12\x18\n" +
+	"\aarchive\x18\x01 \x01(\fR\aarchive\x12\x1b\n" +
+	"\tfile_name\x18\x02 \x01(\tR\bfileName\"V\n" +
+	"\x10EraseUserRequest\x12\x1b\n" +
+	"\tuser_name\x18\x01 \x01(\tR\buserName\x12%\n" +
+	"\x04mode\x18\x02 \x01(\x0e2\x11.logs.ErasureModeR\x04mode\"\xb1\x01\n" +
+	"\x11EraseUserResponse\x12%\n" +
+	"\x04mode\x18\x01 \x01(\x0e2\x11.logs.ErasureModeR\x04mode\x12\x1c\n" +
+	"\tpseudonym\x18\x02 \x01(\tR\tpseudonym\x12#\n" +
+	"\rlogs_affected\x18\x03 \x01(\x03R\flogsAffected\x122\n" +
+	"\x15audit_events_affected\x18\x04 \x01(\x03R\x13auditEventsAffected\"\x12\n" +
+	"\x10WatchLogsRequest\"X\n" +
+	"\bLogEvent\x12&\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x12.logs.LogEventTypeR\x04type\x12$\n" +
+	"\x05entry\x18\x02 \x01(\v2\x0e.logs.LogEntryR\x05entry\"d\n" +
+	"\x11ImportLogsRequest\x12$\n" +
+	"\x05entry\x18\x01 \x01(\v2\x0e.logs.LogEntryR\x05entry\x12\x10\n" +
+	"\x03row\x18\x02 \x01(\x05R\x03row\x12\x17\n" +
+	"\adry_run\x18\x03 \x01(\bR\x06dryRun\"<\n" +
+	"\x0eImportRowError\x12\x10\n" +
+	"\x03row\x18\x01 \x01(\x05R\x03row\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\x97\x01\n" +
+	"\x12ImportLogsResponse\x12\x1a\n" +
+	"\bimported\x18\x01 \x01(\x05R\bimported\x12\x1e\n" +
+	"\n" +
+	"duplicates\x18\x02 \x01(\x05R\n" +
+	"duplicates\x12,\n" +
+	"\x06errors\x18\x03 \x03(\v2\x14.logs.ImportRowErrorR\x06errors\x12\x17\n" +
+	"\adry_run\x18\x04 \x01(\bR\x06dryRun\"r\n" +
+	"\x11ExportLogsRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x1b\n" +
+	"\tuser_name\x18\x02 \x01(\tR\buserName\x12\x14\n" +
+	"\x05since\x18\x03 \x01(\tR\x05since\x12\x14\n" +
+	"\x05until\x18\x04 \x01(\tR\x05until\"S\n" +
+	"\rTemplateField\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05label\x18\x02 \x01(\tR\x05label\x12\x1a\n" +
+	"\boptional\x18\x03 \x01(\bR\boptional\"\x9d\x01\n" +
+	"\bTemplate\x12\x12\n" +
+	"\x04team\x18\x01 \x01(\tR\x04team\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12+\n" +
+	"\x06fields\x18\x03 \x03(\v2\x13.logs.TemplateFieldR\x06fields\x12\x1d\n" +
+	"\n" +
+	"updated_by\x18\x04 \x01(\tR\tupdatedBy\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x05 \x01(\tR\tupdatedAt\"*\n" +
+	"\x14ListTemplatesRequest\x12\x12\n" +
+	"\x04team\x18\x01 \x01(\tR\x04team\"E\n" +
+	"\x15ListTemplatesResponse\x12,\n" +
+	"\ttemplates\x18\x01 \x03(\v2\x0e.logs.TemplateR\ttemplates\"?\n" +
+	"\x15DeleteTemplateRequest\x12\x12\n" +
+	"\x04team\x18\x01 \x01(\tR\x04team\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"\x18\n" +
+	"\x16DeleteTemplateResponse\";\n" +
+	"\x0fCompleteRequest\x12(\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\x14.logs.CompletionKindR\x04kind\"D\n" +
+	"\n" +
+	"Completion\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\tR\x05value\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\"F\n" +
+	"\x10CompleteResponse\x122\n" +
+	"\vcompletions\x18\x01 \x03(\v2\x10.logs.CompletionR\vcompletions\"S\n" +
+	"\vPingRequest\x12%\n" +
+	"\x0eclient_version\x18\x01 \x01(\tR\rclientVersion\x12\x1d\n" +
+	"\n" +
+	"client_api\x18\x02 \x01(\x05R\tclientApi\"\xbf\x01\n" +
+	"\fPingResponse\x12%\n" +
+	"\x0eserver_version\x18\x01 \x01(\tR\rserverVersion\x12\x10\n" +
+	"\x03api\x18\x02 \x01(\x05R\x03api\x12$\n" +
+	"\x0emin_client_api\x18\x03 \x01(\x05R\fminClientApi\x12\x1f\n" +
+	"\vserver_time\x18\x04 \x01(\tR\n" +
+	"serverTime\x12\x1b\n" +
+	"\tuser_name\x18\x05 \x01(\tR\buserName\x12\x12\n" +
+	"\x04role\x18\x06 \x01(\tR\x04role*n\n" +
+	"\n" +
+	"Visibility\x12\x1a\n" +
+	"\x16VISIBILITY_UNSPECIFIED\x10\x00\x12\x13\n" +
+	"\x0fVISIBILITY_TEAM\x10\x01\x12\x17\n" +
+	"\x13VISIBILITY_MANAGERS\x10\x02\x12\x16\n" +
+	"\x12VISIBILITY_PRIVATE\x10\x03*c\n" +
+	"\vErasureMode\x12\x1c\n" +
+	"\x18ERASURE_MODE_UNSPECIFIED\x10\x00\x12\x1d\n" +
+	"\x19ERASURE_MODE_PSEUDONYMIZE\x10\x01\x12\x17\n" +
+	"\x13ERASURE_MODE_DELETE\x10\x02*d\n" +
+	"\fLogEventType\x12\x1e\n" +
+	"\x1aLOG_EVENT_TYPE_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14LOG_EVENT_TYPE_ADDED\x10\x01\x12\x1a\n" +
+	"\x16LOG_EVENT_TYPE_DELETED\x10\x02*\xb6\x01\n" +
+	"\x0eCompletionKind\x12\x1f\n" +
+	"\x1bCOMPLETION_KIND_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14COMPLETION_KIND_USER\x10\x01\x12\x18\n" +
+	"\x14COMPLETION_KIND_TEAM\x10\x02\x12\x17\n" +
+	"\x13COMPLETION_KIND_TAG\x10\x03\x12\x1a\n" +
+	"\x16COMPLETION_KIND_TICKET\x10\x04\x12\x1a\n" +
+	"\x16COMPLETION_KIND_LOG_ID\x10\x052\xa7\v\n" +
+	"\n" +
+	"LogService\x12,\n" +
+	"\aAddLogs\x12\x0e.logs.LogEntry\x1a\x11.logs.AddResponse\x124\n" +
+	"\tFetchLogs\x12\x12.logs.FetchRequest\x1a\x13.logs.FetchResponse\x12<\n" +
+	"\tDeleteLog\x12\x16.logs.DeleteLogRequest\x1a\x17.logs.DeleteLogResponse\x12N\n" +
+	"\x0fListAuditEvents\x12\x1c.logs.ListAuditEventsRequest\x1a\x1d.logs.ListAuditEventsResponse\x120\n" +
+	"\x05Login\x12\x12.logs.LoginRequest\x1a\x13.logs.LoginResponse\x12:\n" +
+	"\n" +
+	"SearchLogs\x12\x17.logs.SearchLogsRequest\x1a\x13.logs.FetchResponse\x12?\n" +
+	"\fGetMoodStats\x12\x16.logs.MoodStatsRequest\x1a\x17.logs.MoodStatsResponse\x12<\n" +
+	"\vGetCalendar\x12\x15.logs.CalendarRequest\x1a\x16.logs.CalendarResponse\x12I\n" +
+	"\x12CreateOrganization\x12\x1f.logs.CreateOrganizationRequest\x1a\x12.logs.Organization\x12T\n" +
+	"\x11ListOrganizations\x12\x1e.logs.ListOrganizationsRequest\x1a\x1f.logs.ListOrganizationsResponse\x121\n" +
+	"\n" +
+	"CreateTeam\x12\x17.logs.CreateTeamRequest\x1a\n" +
+	".logs.Team\x12<\n" +
+	"\tListTeams\x12\x16.logs.ListTeamsRequest\x1a\x17.logs.ListTeamsResponse\x12?\n" +
+	"\n" +
+	"CreateUser\x12\x17.logs.CreateUserRequest\x1a\x18.logs.CreateUserResponse\x12E\n" +
+	"\fExportMyData\x12\x19.logs.ExportMyDataRequest\x1a\x1a.logs.ExportMyDataResponse\x12<\n" +
+	"\tEraseUser\x12\x16.logs.EraseUserRequest\x1a\x17.logs.EraseUserResponse\x125\n" +
+	"\tWatchLogs\x12\x16.logs.WatchLogsRequest\x1a\x0e.logs.LogEvent0\x01\x12A\n" +
+	"\n" +
+	"ImportLogs\x12\x17.logs.ImportLogsRequest\x1a\x18.logs.ImportLogsResponse(\x01\x127\n" +
+	"\n" +
+	"ExportLogs\x12\x17.logs.ExportLogsRequest\x1a\x0e.logs.LogEntry0\x01\x12H\n" +
+	"\rListTemplates\x12\x1a.logs.ListTemplatesRequest\x1a\x1b.logs.ListTemplatesResponse\x12.\n" +
+	"\fSaveTemplate\x12\x0e.logs.Template\x1a\x0e.logs.Template\x12K\n" +
+	"\x0eDeleteTemplate\x12\x1b.logs.DeleteTemplateRequest\x1a\x1c.logs.DeleteTemplateResponse\x129\n" +
+	"\bComplete\x12\x15.logs.CompleteRequest\x1a\x16.logs.CompleteResponse\x12-\n" +
+	"\x04Ping\x12\x11.logs.PingRequest\x1a\x12.logs.PingResponseB\bZ\x06/protob\x06proto3"
 
 var (
 	file_proto_logs_proto_rawDescOnce sync.Once
@@ -254,24 +3353,134 @@ func file_proto_logs_proto_rawDescGZIP() []byte {
 	return file_proto_logs_proto_rawDescData
 }
 
-var file_proto_logs_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_proto_logs_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_proto_logs_proto_msgTypes = make([]protoimpl.MessageInfo, 50)
 var file_proto_logs_proto_goTypes = []any{
-	(*FetchRequest)(nil),  // 0: logs.FetchRequest
-	(*LogEntry)(nil),      // 1: logs.LogEntry
-	(*AddResponse)(nil),   // 2: logs.AddResponse
-	(*FetchResponse)(nil), // 3: logs.FetchResponse
+	(Visibility)(0),                   // 0: logs.Visibility
+	(ErasureMode)(0),                  // 1: logs.ErasureMode
+	(LogEventType)(0),                 // 2: logs.LogEventType
+	(CompletionKind)(0),               // 3: logs.CompletionKind
+	(*FetchRequest)(nil),              // 4: logs.FetchRequest
+	(*LogEntry)(nil),                  // 5: logs.LogEntry
+	(*AddResponse)(nil),               // 6: logs.AddResponse
+	(*FetchResponse)(nil),             // 7: logs.FetchResponse
+	(*DeleteLogRequest)(nil),          // 8: logs.DeleteLogRequest
+	(*DeleteLogResponse)(nil),         // 9: logs.DeleteLogResponse
+	(*ListAuditEventsRequest)(nil),    // 10: logs.ListAuditEventsRequest
+	(*AuditEvent)(nil),                // 11: logs.AuditEvent
+	(*ListAuditEventsResponse)(nil),   // 12: logs.ListAuditEventsResponse
+	(*LoginRequest)(nil),              // 13: logs.LoginRequest
+	(*LoginResponse)(nil),             // 14: logs.LoginResponse
+	(*SearchLogsRequest)(nil),         // 15: logs.SearchLogsRequest
+	(*MoodStatsRequest)(nil),          // 16: logs.MoodStatsRequest
+	(*MoodCount)(nil),                 // 17: logs.MoodCount
+	(*UserMoodCount)(nil),             // 18: logs.UserMoodCount
+	(*MoodStatsResponse)(nil),         // 19: logs.MoodStatsResponse
+	(*CalendarRequest)(nil),           // 20: logs.CalendarRequest
+	(*CalendarDay)(nil),               // 21: logs.CalendarDay
+	(*CalendarResponse)(nil),          // 22: logs.CalendarResponse
+	(*Organization)(nil),              // 23: logs.Organization
+	(*CreateOrganizationRequest)(nil), // 24: logs.CreateOrganizationRequest
+	(*ListOrganizationsRequest)(nil),  // 25: logs.ListOrganizationsRequest
+	(*ListOrganizationsResponse)(nil), // 26: logs.ListOrganizationsResponse
+	(*Team)(nil),                      // 27: logs.Team
+	(*CreateTeamRequest)(nil),         // 28: logs.CreateTeamRequest
+	(*ListTeamsRequest)(nil),          // 29: logs.ListTeamsRequest
+	(*ListTeamsResponse)(nil),         // 30: logs.ListTeamsResponse
+	(*CreateUserRequest)(nil),         // 31: logs.CreateUserRequest
+	(*CreateUserResponse)(nil),        // 32: logs.CreateUserResponse
+	(*ExportMyDataRequest)(nil),       // 33: logs.ExportMyDataRequest
+	(*ExportMyDataResponse)(nil),      // 34: logs.ExportMyDataResponse
+	(*EraseUserRequest)(nil),          // 35: logs.EraseUserRequest
+	(*EraseUserResponse)(nil),         // 36: logs.EraseUserResponse
+	(*WatchLogsRequest)(nil),          // 37: logs.WatchLogsRequest
+	(*LogEvent)(nil),                  // 38: logs.LogEvent
+	(*ImportLogsRequest)(nil),         // 39: logs.ImportLogsRequest
+	(*ImportRowError)(nil),            // 40: logs.ImportRowError
+	(*ImportLogsResponse)(nil),        // 41: logs.ImportLogsResponse
+	(*ExportLogsRequest)(nil),         // 42: logs.ExportLogsRequest
+	(*TemplateField)(nil),             // 43: logs.TemplateField
+	(*Template)(nil),                  // 44: logs.Template
+	(*ListTemplatesRequest)(nil),      // 45: logs.ListTemplatesRequest
+	(*ListTemplatesResponse)(nil),     // 46: logs.ListTemplatesResponse
+	(*DeleteTemplateRequest)(nil),     // 47: logs.DeleteTemplateRequest
+	(*DeleteTemplateResponse)(nil),    // 48: logs.DeleteTemplateResponse
+	(*CompleteRequest)(nil),           // 49: logs.CompleteRequest
+	(*Completion)(nil),                // 50: logs.Completion
+	(*CompleteResponse)(nil),          // 51: logs.CompleteResponse
+	(*PingRequest)(nil),               // 52: logs.PingRequest
+	(*PingResponse)(nil),              // 53: logs.PingResponse
 }
 var file_proto_logs_proto_depIdxs = []int32{
-	1, // 0: logs.FetchResponse.logs:type_name -> logs.LogEntry
-	1, // 1: logs.LogService.AddLogs:input_type -> logs.LogEntry
-	0, // 2: logs.LogService.FetchLogs:input_type -> logs.FetchRequest
-	2, // 3: logs.LogService.AddLogs:output_type -> logs.AddResponse
-	3, // 4: logs.LogService.FetchLogs:output_type -> logs.FetchResponse
-	3, // [3:5] is the sub-list for method output_type
-	1, // [1:3] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	0,  // 0: logs.LogEntry.visibility:type_name -> logs.Visibility
+	5,  // 1: logs.FetchResponse.logs:type_name -> logs.LogEntry
+	11, // 2: logs.ListAuditEventsResponse.events:type_name -> logs.AuditEvent
+	17, // 3: logs.MoodStatsResponse.team:type_name -> logs.MoodCount
+	18, // 4: logs.MoodStatsResponse.by_user:type_name -> logs.UserMoodCount
+	21, // 5: logs.CalendarResponse.days:type_name -> logs.CalendarDay
+	23, // 6: logs.ListOrganizationsResponse.organizations:type_name -> logs.Organization
+	27, // 7: logs.ListTeamsResponse.teams:type_name -> logs.Team
+	1,  // 8: logs.EraseUserRequest.mode:type_name -> logs.ErasureMode
+	1,  // 9: logs.EraseUserResponse.mode:type_name -> logs.ErasureMode
+	2,  // 10: logs.LogEvent.type:type_name -> logs.LogEventType
+	5,  // 11: logs.LogEvent.entry:type_name -> logs.LogEntry
+	5,  // 12: logs.ImportLogsRequest.entry:type_name -> logs.LogEntry
+	40, // 13: logs.ImportLogsResponse.errors:type_name -> logs.ImportRowError
+	43, // 14: logs.Template.fields:type_name -> logs.TemplateField
+	44, // 15: logs.ListTemplatesResponse.templates:type_name -> logs.Template
+	3,  // 16: logs.CompleteRequest.kind:type_name -> logs.CompletionKind
+	50, // 17: logs.CompleteResponse.completions:type_name -> logs.Completion
+	5,  // 18: logs.LogService.AddLogs:input_type -> logs.LogEntry
+	4,  // 19: logs.LogService.FetchLogs:input_type -> logs.FetchRequest
+	8,  // 20: logs.LogService.DeleteLog:input_type -> logs.DeleteLogRequest
+	10, // 21: logs.LogService.ListAuditEvents:input_type -> logs.ListAuditEventsRequest
+	13, // 22: logs.LogService.Login:input_type -> logs.LoginRequest
+	15, // 23: logs.LogService.SearchLogs:input_type -> logs.SearchLogsRequest
+	16, // 24: logs.LogService.GetMoodStats:input_type -> logs.MoodStatsRequest
+	20, // 25: logs.LogService.GetCalendar:input_type -> logs.CalendarRequest
+	24, // 26: logs.LogService.CreateOrganization:input_type -> logs.CreateOrganizationRequest
+	25, // 27: logs.LogService.ListOrganizations:input_type -> logs.ListOrganizationsRequest
+	28, // 28: logs.LogService.CreateTeam:input_type -> logs.CreateTeamRequest
+	29, // 29: logs.LogService.ListTeams:input_type -> logs.ListTeamsRequest
+	31, // 30: logs.LogService.CreateUser:input_type -> logs.CreateUserRequest
+	33, // 31: logs.LogService.ExportMyData:input_type -> logs.ExportMyDataRequest
+	35, // 32: logs.LogService.EraseUser:input_type -> logs.EraseUserRequest
+	37, // 33: logs.LogService.WatchLogs:input_type -> logs.WatchLogsRequest
+	39, // 34: logs.LogService.ImportLogs:input_type -> logs.ImportLogsRequest
+	42, // 35: logs.LogService.ExportLogs:input_type -> logs.ExportLogsRequest
+	45, // 36: logs.LogService.ListTemplates:input_type -> logs.ListTemplatesRequest
+	44, // 37: logs.LogService.SaveTemplate:input_type -> logs.Template
+	47, // 38: logs.LogService.DeleteTemplate:input_type -> logs.DeleteTemplateRequest
+	49, // 39: logs.LogService.Complete:input_type -> logs.CompleteRequest
+	52, // 40: logs.LogService.Ping:input_type -> logs.PingRequest
+	6,  // 41: logs.LogService.AddLogs:output_type -> logs.AddResponse
+	7,  // 42: logs.LogService.FetchLogs:output_type -> logs.FetchResponse
+	9,  // 43: logs.LogService.DeleteLog:output_type -> logs.DeleteLogResponse
+	12, // 44: logs.LogService.ListAuditEvents:output_type -> logs.ListAuditEventsResponse
+	14, // 45: logs.LogService.Login:output_type -> logs.LoginResponse
+	7,  // 46: logs.LogService.SearchLogs:output_type -> logs.FetchResponse
+	19, // 47: logs.LogService.GetMoodStats:output_type -> logs.MoodStatsResponse
+	22, // 48: logs.LogService.GetCalendar:output_type -> logs.CalendarResponse
+	23, // 49: logs.LogService.CreateOrganization:output_type -> logs.Organization
+	26, // 50: logs.LogService.ListOrganizations:output_type -> logs.ListOrganizationsResponse
+	27, // 51: logs.LogService.CreateTeam:output_type -> logs.Team
+	30, // 52: logs.LogService.ListTeams:output_type -> logs.ListTeamsResponse
+	32, // 53: logs.LogService.CreateUser:output_type -> logs.CreateUserResponse
+	34, // 54: logs.LogService.ExportMyData:output_type -> logs.ExportMyDataResponse
+	36, // 55: logs.LogService.EraseUser:output_type -> logs.EraseUserResponse
+	38, // 56: logs.LogService.WatchLogs:output_type -> logs.LogEvent
+	41, // 57: logs.LogService.ImportLogs:output_type -> logs.ImportLogsResponse
+	5,  // 58: logs.LogService.ExportLogs:output_type -> logs.LogEntry
+	46, // 59: logs.LogService.ListTemplates:output_type -> logs.ListTemplatesResponse
+	44, // 60: logs.LogService.SaveTemplate:output_type -> logs.Template
+	48, // 61: logs.LogService.DeleteTemplate:output_type -> logs.DeleteTemplateResponse
+	51, // 62: logs.LogService.Complete:output_type -> logs.CompleteResponse
+	53, // 63: logs.LogService.Ping:output_type -> logs.PingResponse
+	41, // [41:64] is the sub-list for method output_type
+	18, // [18:41] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
 }
 
 func init() { file_proto_logs_proto_init() }
@@ -284,13 +3493,14 @@ func file_proto_logs_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_logs_proto_rawDesc), len(file_proto_logs_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   4,
+			NumEnums:      4,
+			NumMessages:   50,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_proto_logs_proto_goTypes,
 		DependencyIndexes: file_proto_logs_proto_depIdxs,
+		EnumInfos:         file_proto_logs_proto_enumTypes,
 		MessageInfos:      file_proto_logs_proto_msgTypes,
 	}.Build()
 	File_proto_logs_proto = out.File
@@ -0,0 +1,203 @@
+// Package sdk は snulog のプラグインを Go で書くためのパッケージ。
+//
+// PATH にある snulog-<名前> の実行ファイルは snulog <名前> で呼ばれ、解決済みの接続先・トークン・プロファイルを
+// SNULOG_* 環境変数で受け取る。Connect はそれらからログイン済みのユーザーとして呼べる LogServiceClient を作る。
+//
+//	client, err := sdk.Connect()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer client.Close()
+//	ctx, cancel := client.Context(context.Background())
+//	defer cancel()
+//	res, err := client.SearchLogs(ctx, &proto.SearchLogsRequest{Query: "oncall"})
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gensan0223/snulog/internal/tlsconfig"
+	pb "github.com/gensan0223/snulog/proto"
+	"google.golang.org/grpc"
+)
+
+// snulog がプラグインに渡す環境変数。SNULOG_TOKEN 以外は snulog の設定と同じ名前なので、
+// プラグインから snulog を呼び直しても同じ接続先とプロファイルを使う
+const (
+	EnvServer        = "SNULOG_SERVER"
+	EnvToken         = "SNULOG_TOKEN"
+	EnvProfile       = "SNULOG_PROFILE"
+	EnvTeam          = "SNULOG_TEAM"
+	EnvUser          = "SNULOG_USER"
+	EnvTimeout       = "SNULOG_TIMEOUT"
+	EnvTimeZone      = "SNULOG_TIMEZONE"
+	EnvTLSEnabled    = "SNULOG_TLS_ENABLED"
+	EnvTLSCA         = "SNULOG_TLS_CA"
+	EnvTLSCert       = "SNULOG_TLS_CERT"
+	EnvTLSKey        = "SNULOG_TLS_KEY"
+	EnvTLSServerName = "SNULOG_TLS_SERVER_NAME"
+	// EnvBinary はプラグインを呼んだ snulog の実行ファイル
+	EnvBinary = "SNULOG_BIN"
+)
+
+// DefaultTimeout は SNULOG_TIMEOUT がないときの RPC のタイムアウト
+const DefaultTimeout = 5 * time.Second
+
+// ErrNotPlugin は snulog から呼ばれていない（SNULOG_SERVER がない）ことを表す
+var ErrNotPlugin = errors.New("SNULOG_SERVER is not set; run the plugin as snulog <name>")
+
+// Env は snulog がプラグインに渡す設定
+type Env struct {
+	Server string
+	// Token は snulog login で保存したトークン。空なら未ログイン
+	Token    string
+	Profile  string
+	Team     string
+	User     string
+	Timeout  time.Duration
+	TimeZone *time.Location
+	TLS      TLS
+	Binary   string
+}
+
+// TLS はサーバーへの TLS 接続の設定。何も指定しなければ平文で接続する
+type TLS struct {
+	// Enabled は CA を指定せずにシステムの信頼ストアで TLS 接続する場合に使う
+	Enabled    bool
+	CA         string
+	Cert       string
+	Key        string
+	ServerName string
+}
+
+// Environ は e を KEY=value の形にする。snulog がプラグインを起動するときに使う
+func (e Env) Environ() []string {
+	timeZone := ""
+	if e.TimeZone != nil {
+		timeZone = e.TimeZone.String()
+	}
+	return []string{
+		EnvServer + "=" + e.Server,
+		EnvToken + "=" + e.Token,
+		EnvProfile + "=" + e.Profile,
+		EnvTeam + "=" + e.Team,
+		EnvUser + "=" + e.User,
+		EnvTimeout + "=" + e.Timeout.String(),
+		EnvTimeZone + "=" + timeZone,
+		EnvTLSEnabled + "=" + strconv.FormatBool(e.TLS.Enabled),
+		EnvTLSCA + "=" + e.TLS.CA,
+		EnvTLSCert + "=" + e.TLS.Cert,
+		EnvTLSKey + "=" + e.TLS.Key,
+		EnvTLSServerName + "=" + e.TLS.ServerName,
+		EnvBinary + "=" + e.Binary,
+	}
+}
+
+// FromEnv は環境変数から設定を読む。snulog から呼ばれていなければ ErrNotPlugin を返す
+func FromEnv() (Env, error) {
+	return lookupEnv(os.Getenv)
+}
+
+func lookupEnv(getenv func(string) string) (Env, error) {
+	env := Env{
+		Server:  getenv(EnvServer),
+		Token:   getenv(EnvToken),
+		Profile: getenv(EnvProfile),
+		Team:    getenv(EnvTeam),
+		User:    getenv(EnvUser),
+		Timeout: DefaultTimeout,
+		TLS: TLS{
+			CA:         getenv(EnvTLSCA),
+			Cert:       getenv(EnvTLSCert),
+			Key:        getenv(EnvTLSKey),
+			ServerName: getenv(EnvTLSServerName),
+		},
+		Binary: getenv(EnvBinary),
+	}
+	if env.Server == "" {
+		return env, ErrNotPlugin
+	}
+	var err error
+	if value := getenv(EnvTimeout); value != "" {
+		if env.Timeout, err = time.ParseDuration(value); err != nil || env.Timeout <= 0 {
+			return env, fmt.Errorf("%s must be a positive duration such as 5s: %q", EnvTimeout, value)
+		}
+	}
+	if value := getenv(EnvTLSEnabled); value != "" {
+		if env.TLS.Enabled, err = strconv.ParseBool(value); err != nil {
+			return env, fmt.Errorf("%s: %w", EnvTLSEnabled, err)
+		}
+	}
+	timeZone := getenv(EnvTimeZone)
+	if timeZone == "" {
+		timeZone = "Local"
+	}
+	if env.TimeZone, err = time.LoadLocation(timeZone); err != nil {
+		return env, fmt.Errorf("%s: %w", EnvTimeZone, err)
+	}
+	return env, nil
+}
+
+// Client はトークンを各 RPC に付けて呼ぶ LogServiceClient
+type Client struct {
+	pb.LogServiceClient
+	Env  Env
+	conn *grpc.ClientConn
+}
+
+// Connect は環境変数の設定でサーバーに接続する
+func Connect(opts ...grpc.DialOption) (*Client, error) {
+	env, err := FromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return Dial(env, opts...)
+}
+
+// Dial は env の接続先に接続する。Token が空でなければ各 RPC に付ける
+func Dial(env Env, opts ...grpc.DialOption) (*Client, error) {
+	creds, err := tlsconfig.ClientConfig{
+		Enabled:    env.TLS.Enabled,
+		CAFile:     env.TLS.CA,
+		CertFile:   env.TLS.Cert,
+		KeyFile:    env.TLS.Key,
+		ServerName: env.TLS.ServerName,
+	}.TransportCredentials()
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, grpc.WithTransportCredentials(creds))
+	if env.Token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken(env.Token)))
+	}
+	conn, err := grpc.NewClient(env.Server, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{LogServiceClient: pb.NewLogServiceClient(conn), Env: env, conn: conn}, nil
+}
+
+// Context は snulog の設定のタイムアウト付きの context を返す
+func (c *Client) Context(parent context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(parent, c.Env.Timeout)
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+type bearerToken string
+
+func (t bearerToken) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+// snulog と同じく平文接続の開発環境でも使えるようにする
+func (t bearerToken) RequireTransportSecurity() bool {
+	return false
+}
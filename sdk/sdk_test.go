@@ -0,0 +1,94 @@
+package sdk
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/gensan0223/snulog/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// environ は KEY=value の一覧を getenv にする
+func environ(pairs []string) func(string) string {
+	values := map[string]string{}
+	for _, pair := range pairs {
+		key, value, _ := strings.Cut(pair, "=")
+		values[key] = value
+	}
+	return func(key string) string { return values[key] }
+}
+
+func TestEnv_RoundTrip(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	want := Env{
+		Server:   "snulog.example.com:443",
+		Token:    "secret",
+		Profile:  "work",
+		Team:     "platform",
+		User:     "alice",
+		Timeout:  3 * time.Second,
+		TimeZone: tokyo,
+		TLS:      TLS{Enabled: true, CA: "/etc/snulog/ca.pem", ServerName: "snulog"},
+		Binary:   "/usr/local/bin/snulog",
+	}
+	got, err := lookupEnv(environ(want.Environ()))
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestLookupEnv(t *testing.T) {
+	_, err := lookupEnv(environ(nil))
+	assert.ErrorIs(t, err, ErrNotPlugin)
+
+	env, err := lookupEnv(environ([]string{EnvServer + "=localhost:50051"}))
+	require.NoError(t, err)
+	assert.Equal(t, DefaultTimeout, env.Timeout)
+	assert.Equal(t, time.Local, env.TimeZone)
+	assert.False(t, env.TLS.Enabled)
+
+	_, err = lookupEnv(environ([]string{EnvServer + "=localhost:50051", EnvTimeout + "=soon"}))
+	assert.Error(t, err)
+	_, err = lookupEnv(environ([]string{EnvServer + "=localhost:50051", EnvTLSEnabled + "=maybe"}))
+	assert.Error(t, err)
+}
+
+type pingServer struct {
+	pb.UnimplementedLogServiceServer
+}
+
+// Ping は受け取ったトークンをユーザー名として返す
+func (pingServer) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	res := &pb.PingResponse{}
+	if tokens := md.Get("authorization"); len(tokens) > 0 {
+		res.UserName = tokens[0]
+	}
+	return res, nil
+}
+
+func TestDial_AttachesToken(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := grpc.NewServer()
+	pb.RegisterLogServiceServer(s, pingServer{})
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	for token, want := range map[string]string{"secret": "Bearer secret", "": ""} {
+		client, err := Dial(Env{Server: lis.Addr().String(), Token: token, Timeout: 5 * time.Second})
+		require.NoError(t, err)
+		ctx, cancel := client.Context(context.Background())
+		res, err := client.Ping(ctx, &pb.PingRequest{})
+		cancel()
+		require.NoError(t, err)
+		assert.Equal(t, want, res.UserName)
+		assert.NoError(t, client.Close())
+	}
+}
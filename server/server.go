@@ -7,20 +7,40 @@ import (
 	"log"
 	"net"
 	"os"
+	"time"
 
+	"github.com/gensan0223/snulog/internal/audit"
+	"github.com/gensan0223/snulog/internal/auth"
+	"github.com/gensan0223/snulog/internal/ratelimit"
 	"github.com/gensan0223/snulog/internal/repository"
+	"github.com/gensan0223/snulog/internal/tlsconfig"
 	"github.com/gensan0223/snulog/internal/usecase"
 	"github.com/gensan0223/snulog/internal/util"
+	"github.com/gensan0223/snulog/internal/version"
 	pb "github.com/gensan0223/snulog/proto"
 
 	_ "github.com/lib/pq"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
+// 監査ログの既定の保持期間（SNULOG_AUDIT_RETENTION で変更、0 で無期限）
+const defaultAuditRetention = 90 * 24 * time.Hour
+
 type logServer struct {
 	pb.UnimplementedLogServiceServer
-	usecase usecase.LogUsecase
+	usecase           usecase.LogUsecase
+	auditUsecase      usecase.AuditUsecase
+	authUsecase       usecase.AuthUsecase
+	orgUsecase        usecase.OrgUsecase
+	privacyUsecase    usecase.PrivacyUsecase
+	importUsecase     usecase.ImportUsecase
+	templateUsecase   usecase.TemplateUsecase
+	completionUsecase usecase.CompletionUsecase
+	pingUsecase       usecase.PingUsecase
 }
 
 func (s *logServer) AddLogs(ctx context.Context, entry *pb.LogEntry) (*pb.AddResponse, error) {
@@ -31,6 +51,133 @@ func (s *logServer) FetchLogs(ctx context.Context, req *pb.FetchRequest) (*pb.Fe
 	return s.usecase.FetchLogs(ctx)
 }
 
+func (s *logServer) SearchLogs(ctx context.Context, req *pb.SearchLogsRequest) (*pb.FetchResponse, error) {
+	return s.usecase.SearchLogs(ctx, req)
+}
+
+func (s *logServer) GetMoodStats(ctx context.Context, req *pb.MoodStatsRequest) (*pb.MoodStatsResponse, error) {
+	return s.usecase.GetMoodStats(ctx, req)
+}
+
+func (s *logServer) GetCalendar(ctx context.Context, req *pb.CalendarRequest) (*pb.CalendarResponse, error) {
+	return s.usecase.GetCalendar(ctx, req)
+}
+
+func (s *logServer) WatchLogs(req *pb.WatchLogsRequest, stream grpc.ServerStreamingServer[pb.LogEvent]) error {
+	return s.usecase.WatchLogs(stream.Context(), stream.Send)
+}
+
+func (s *logServer) ExportLogs(req *pb.ExportLogsRequest, stream grpc.ServerStreamingServer[pb.LogEntry]) error {
+	return s.usecase.ExportLogs(stream.Context(), req, stream.Send)
+}
+
+func (s *logServer) ImportLogs(stream grpc.ClientStreamingServer[pb.ImportLogsRequest, pb.ImportLogsResponse]) error {
+	res, err := s.importUsecase.ImportLogs(stream.Context(), stream.Recv)
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(res)
+}
+
+func (s *logServer) ListTemplates(ctx context.Context, req *pb.ListTemplatesRequest) (*pb.ListTemplatesResponse, error) {
+	return s.templateUsecase.ListTemplates(ctx, req)
+}
+
+func (s *logServer) SaveTemplate(ctx context.Context, req *pb.Template) (*pb.Template, error) {
+	return s.templateUsecase.SaveTemplate(ctx, req)
+}
+
+func (s *logServer) DeleteTemplate(ctx context.Context, req *pb.DeleteTemplateRequest) (*pb.DeleteTemplateResponse, error) {
+	return s.templateUsecase.DeleteTemplate(ctx, req)
+}
+
+func (s *logServer) Complete(ctx context.Context, req *pb.CompleteRequest) (*pb.CompleteResponse, error) {
+	return s.completionUsecase.Complete(ctx, req)
+}
+
+func (s *logServer) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingResponse, error) {
+	return s.pingUsecase.Ping(ctx, req)
+}
+
+func (s *logServer) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
+	return s.authUsecase.Login(ctx, req)
+}
+
+func (s *logServer) CreateOrganization(ctx context.Context, req *pb.CreateOrganizationRequest) (*pb.Organization, error) {
+	return s.orgUsecase.CreateOrganization(ctx, req)
+}
+
+func (s *logServer) ListOrganizations(ctx context.Context, req *pb.ListOrganizationsRequest) (*pb.ListOrganizationsResponse, error) {
+	return s.orgUsecase.ListOrganizations(ctx)
+}
+
+func (s *logServer) CreateTeam(ctx context.Context, req *pb.CreateTeamRequest) (*pb.Team, error) {
+	return s.orgUsecase.CreateTeam(ctx, req)
+}
+
+func (s *logServer) ListTeams(ctx context.Context, req *pb.ListTeamsRequest) (*pb.ListTeamsResponse, error) {
+	return s.orgUsecase.ListTeams(ctx)
+}
+
+func (s *logServer) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
+	return s.orgUsecase.CreateUser(ctx, req)
+}
+
+func (s *logServer) ExportMyData(ctx context.Context, req *pb.ExportMyDataRequest) (*pb.ExportMyDataResponse, error) {
+	return s.privacyUsecase.ExportMyData(ctx, req)
+}
+
+func (s *logServer) EraseUser(ctx context.Context, req *pb.EraseUserRequest) (*pb.EraseUserResponse, error) {
+	return s.privacyUsecase.EraseUser(ctx, req)
+}
+
+func (s *logServer) DeleteLog(ctx context.Context, req *pb.DeleteLogRequest) (*pb.DeleteLogResponse, error) {
+	return s.usecase.DeleteLog(ctx, req.Id)
+}
+
+func (s *logServer) ListAuditEvents(ctx context.Context, req *pb.ListAuditEventsRequest) (*pb.ListAuditEventsResponse, error) {
+	return s.auditUsecase.ListAuditEvents(ctx, req)
+}
+
+func auditRetention() time.Duration {
+	value := os.Getenv("SNULOG_AUDIT_RETENTION")
+	if value == "" {
+		return defaultAuditRetention
+	}
+	retention, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("invalid SNULOG_AUDIT_RETENTION: %v", err)
+	}
+	return retention
+}
+
+// erasureConfig は SNULOG_ERASURE_MODE（pseudonymize / delete）と SNULOG_PSEUDONYM_PREFIX を読む
+func erasureConfig() usecase.ErasureConfig {
+	config := usecase.DefaultErasureConfig
+	mode, err := usecase.ParseErasureMode(os.Getenv("SNULOG_ERASURE_MODE"))
+	if err != nil {
+		log.Fatalf("invalid SNULOG_ERASURE_MODE: %v", err)
+	}
+	if mode != pb.ErasureMode_ERASURE_MODE_UNSPECIFIED {
+		config.DefaultMode = mode
+	}
+	if prefix := os.Getenv("SNULOG_PSEUDONYM_PREFIX"); prefix != "" {
+		config.PseudonymPrefix = prefix
+	}
+	return config
+}
+
+func clientAuthMode(c tlsconfig.ServerConfig) string {
+	switch {
+	case c.ClientCAFile == "":
+		return "not requested"
+	case c.ClientCertOptional:
+		return "verified if given"
+	default:
+		return "required"
+	}
+}
+
 func main() {
 	dsn := os.Getenv("DATABASE_URL")
 	db, err := sql.Open("postgres", dsn)
@@ -45,14 +192,69 @@ func main() {
 	}
 	// repo := repository.NewInMemoryLogRepository()
 	repo := repository.NewPostgresLogRepository(db)
-	uc := usecase.NewLogUsecase(repo)
+	auditRepo := repository.NewPostgresAuditRepository(db)
+	recorder := audit.NewRecorder(auditRepo)
+	userRepo := repository.NewPostgresUserRepository(db)
+	attemptRepo := repository.NewPostgresAttemptRepository(db)
+	orgRepo := repository.NewPostgresOrganizationRepository(db)
+	teamRepo := repository.NewPostgresTeamRepository(db)
+	signer := auth.TokenSignerFromEnv()
+	loginLimiter := ratelimit.NewLimiter("login", attemptRepo, ratelimit.DefaultLoginPolicy)
+	limiter := ratelimit.NewLimiter("rpc", attemptRepo, ratelimit.DefaultRPCPolicy)
+	uc := usecase.NewLogUsecase(repo, recorder)
 	srv := &logServer{
-		usecase: uc,
+		usecase:      uc,
+		auditUsecase: usecase.NewAuditUsecase(auditRepo),
+		authUsecase:  usecase.NewAuthUsecase(userRepo, signer, loginLimiter, recorder),
+		orgUsecase: usecase.NewOrgUsecase(
+			orgRepo,
+			teamRepo,
+			userRepo,
+			recorder,
+		),
+		privacyUsecase:    usecase.NewPrivacyUsecase(repo, auditRepo, userRepo, orgRepo, recorder, erasureConfig(), loginLimiter, limiter),
+		importUsecase:     usecase.NewImportUsecase(repo, userRepo, recorder),
+		templateUsecase:   usecase.NewTemplateUsecase(repository.NewPostgresTemplateRepository(db), teamRepo, recorder),
+		completionUsecase: usecase.NewCompletionUsecase(repo, teamRepo),
+		pingUsecase:       usecase.NewPingUsecase(),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go audit.RunRetention(ctx, auditRepo, auditRetention(), 24*time.Hour)
+
+	authenticator := auth.NewGRPCAuthenticator(signer, userRepo)
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			authenticator.UnaryServerInterceptor(),
+			limiter.UnaryServerInterceptor(pb.LogService_AddLogs_FullMethodName),
+		),
+		grpc.StreamInterceptor(authenticator.StreamServerInterceptor()),
+	}
+
+	tlsConfig := tlsconfig.ServerConfigFromEnv()
+	if tlsConfig.Enabled() {
+		reloader, err := tlsconfig.NewCertReloader(tlsConfig.CertFile, tlsConfig.KeyFile, tlsConfig.ClientCAFile)
+		if err != nil {
+			log.Fatalf("failed to load TLS certificate: %v", err)
+		}
+		if err := reloader.Watch(); err != nil {
+			log.Fatalf("failed to watch TLS certificate: %v", err)
+		}
+		defer util.CloseWithLog(reloader)
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsconfig.NewServerTLS(tlsConfig, reloader))))
+		log.Printf("TLS enabled (client certificates: %s)", clientAuthMode(tlsConfig))
+	} else {
+		log.Printf("warn: SNULOG_TLS_CERT is not set, serving gRPC in plaintext")
 	}
 
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(opts...)
 	pb.RegisterLogServiceServer(grpcServer, srv)
-	fmt.Printf("✅ Mock gRPC server listening on %s", lis.Addr())
+	// 標準の gRPC ヘルスチェック。snulog doctor やロードバランサーが使う
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(pb.LogService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	fmt.Printf("✅ Mock gRPC server %s listening on %s", version.String(), lis.Addr())
 	if err := grpcServer.Serve(lis); err != nil {
 		log.Fatalf("failed to serve: %v", err)
 	}
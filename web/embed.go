@@ -0,0 +1,22 @@
+// Package web は HTML テンプレートと静的ファイルをバイナリに埋め込む
+package web
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed templates
+var Templates embed.FS
+
+//go:embed static
+var static embed.FS
+
+// Static は /static/ で配信するファイル（htmx もここから配信し、外部 CDN には依存しない）
+func Static() fs.FS {
+	sub, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
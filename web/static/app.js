@@ -0,0 +1,19 @@
+// hx-on などのインラインスクリプトは CSP で禁止しているため、画面の振る舞いはここで登録する
+htmx.config.allowEval = false;
+htmx.config.includeIndicatorStyles = false;
+
+// ログインの試行制限（429）でもエラーメッセージを表示する
+document.addEventListener("htmx:beforeSwap", function (event) {
+  if (event.detail.xhr.status === 429) {
+    event.detail.shouldSwap = true;
+    event.detail.isError = false;
+  }
+});
+
+// 送信に成功したフォームを空に戻す
+document.addEventListener("htmx:afterRequest", function (event) {
+  var form = event.detail.elt;
+  if (event.detail.successful && form.matches("form[data-reset-on-success]")) {
+    form.reset();
+  }
+});